@@ -0,0 +1,132 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !notui
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	"github.com/aws/amazon-ec2-instance-selector/v3/cmd/pkg/tui"
+)
+
+// interactiveOutputAvailable is false when the binary is built with the notui tag.
+const interactiveOutputAvailable = true
+
+func disableInteractiveColor() {
+	tui.DisableColor()
+}
+
+func isInteractiveTerminal() bool {
+	return tui.IsInteractiveTerminal()
+}
+
+// runInteractiveOutput displays the instance types in the interactive table until the user quits. The prices are
+// retrieved while the table is displayed when isLazilyPriced is true.
+func runInteractiveOutput(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters, instanceTypesDetails []*instancetypes.Details, extraColumns []string, customColumns []outputs.CustomColumn, isLazilyPriced bool, spotPricingDaysBack int) error {
+	opts := []tui.Option{tui.WithColumns(extraColumns...), tui.WithCustomColumns(customColumns...)}
+	pricingCtx, cancelPricing := context.WithCancel(ctx)
+	defer cancelPricing()
+	var pricing sync.WaitGroup
+	if isLazilyPriced {
+		prices := make(chan tui.PricesMsg)
+		opts = append(opts, tui.WithPrices(prices))
+		// log messages would be drawn over the table
+		instanceSelector.SetLogger(log.New(io.Discard, "", 0))
+		pricing.Add(1)
+		go func() {
+			defer pricing.Done()
+			hydratePricesLazily(pricingCtx, instanceSelector, filters, instanceTypesDetails, spotPricingDaysBack, prices)
+		}()
+	}
+	model, err := tui.New(instanceTypesDetails, opts...)
+	if err != nil {
+		cancelPricing()
+		pricing.Wait()
+		return fmt.Errorf("unable to create the interactive output: %w", err)
+	}
+	_, err = tui.Run(model, tea.WithMouseCellMotion())
+	// stop retrieving the prices which were not retrieved before the user quit
+	cancelPricing()
+	pricing.Wait()
+	if err != nil {
+		return fmt.Errorf("unable to start bubble tea: %w", err)
+	}
+	return nil
+}
+
+// hydratePricesLazily hydrates the pricing caches while the interactive output is displayed and sends the instance
+// types with the prices of each cache once it is hydrated. prices is closed once both caches are hydrated or ctx is done.
+func hydratePricesLazily(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters, instanceTypesDetails []*instancetypes.Details, spotPricingDaysBack int, prices chan<- tui.PricesMsg) {
+	defer close(prices)
+	send := func(msg tui.PricesMsg) {
+		select {
+		case prices <- msg:
+		case <-ctx.Done():
+		}
+	}
+	pricingCacheNames := map[string]string{selector.OnDemandPricingCache: "on-demand", selector.SpotPricingCache: "spot"}
+	pending := []string{selector.OnDemandPricingCache, selector.SpotPricingCache}
+	pendingStatus := func() string {
+		names := []string{}
+		for _, cache := range pending {
+			names = append(names, pricingCacheNames[cache])
+		}
+		return fmt.Sprintf("Retrieving %s prices...", strings.Join(names, " and "))
+	}
+	send(tui.PricesMsg{Status: pendingStatus()})
+
+	pricingCaches := slices.Clone(pending)
+	err := instanceSelector.HydrateCaches(ctx, selector.HydrateOptions{Caches: pricingCaches, SpotPricingDaysBack: spotPricingDaysBack}, func(progress selector.HydrateProgress) {
+		if progress.Event == selector.HydrateStarted {
+			return
+		}
+		pending = slices.DeleteFunc(pending, func(cache string) bool { return cache == progress.Cache })
+		if len(pending) == 0 {
+			return
+		}
+		msg := tui.PricesMsg{Status: pendingStatus()}
+		if progress.Event != selector.HydrateFailed {
+			priced, err := instanceSelector.AddPrices(ctx, filters, instanceTypesDetails)
+			if err == nil {
+				msg.InstanceTypes = priced
+			}
+		}
+		send(msg)
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	msg := tui.PricesMsg{Done: true}
+	if err != nil {
+		msg.Status = fmt.Sprintf("Unable to retrieve all prices: %v", err)
+	}
+	priced, pricingErr := instanceSelector.AddPrices(ctx, filters, instanceTypesDetails)
+	if pricingErr != nil {
+		msg.Status = fmt.Sprintf("Unable to add the prices: %v", pricingErr)
+	} else {
+		msg.InstanceTypes = priced
+	}
+	send(msg)
+}
@@ -0,0 +1,38 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build notui
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+)
+
+// interactiveOutputAvailable is false when the binary is built with the notui tag, which leaves the bubble tea
+// dependencies of the interactive output out of the binary.
+const interactiveOutputAvailable = false
+
+func disableInteractiveColor() {}
+
+func isInteractiveTerminal() bool {
+	return false
+}
+
+func runInteractiveOutput(_ context.Context, _ *selector.Selector, _ selector.Filters, _ []*instancetypes.Details, _ []string, _ []outputs.CustomColumn, _ bool, _ int) error {
+	return errors.New("the interactive output is not available in this build")
+}
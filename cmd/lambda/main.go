@@ -0,0 +1,25 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The lambda command is the bootstrap of an AWS Lambda function on the provided.al2023 runtime which selects
+// instance types for API Gateway requests, it is built with make build-lambda.
+package main
+
+import (
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/cmd/pkg/lambda"
+)
+
+func main() {
+	awslambda.Start(lambda.New().Handle)
+}
@@ -14,28 +14,51 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	tea "github.com/charmbracelet/bubbletea"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/spf13/cobra"
 	"go.uber.org/multierr"
 
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
 	commandline "github.com/aws/amazon-ec2-instance-selector/v3/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/destination"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/env"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/manifest"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/notify"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/rightsizing"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ssmparameter"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/upgrade"
 )
 
 const (
@@ -46,64 +69,20 @@ const (
 	awsConfigFile       = "~/.aws/config"
 	// 0 means the last price
 	// increasing this results in a lot more API calls to EC2 which can slow things down.
-	spotPricingDaysBack = 0
-
-	tableOutput     = "table"
-	tableWideOutput = "table-wide"
-	oneLine         = "one-line"
-	bubbleTeaOutput = "interactive"
+	defaultSpotPricingDaysBack = 0
 
 	// Sort filter default.
 	instanceNamePath = ".InstanceType"
 )
 
-// Filter Flag Constants.
+// Filter Flag Constants - the remaining filter flags are generated from the selector.Filters struct tags.
 const (
-	vcpus                            = "vcpus"
-	memory                           = "memory"
-	vcpusToMemoryRatio               = "vcpus-to-memory-ratio"
-	cpuArchitecture                  = "cpu-architecture"
-	cpuManufacturer                  = "cpu-manufacturer"
-	gpus                             = "gpus"
-	gpuMemoryTotal                   = "gpu-memory-total"
-	gpuManufacturer                  = "gpu-manufacturer"
-	gpuModel                         = "gpu-model"
-	inferenceAccelerators            = "inference-accelerators"
-	inferenceAcceleratorManufacturer = "inference-accelerator-manufacturer"
-	inferenceAcceleratorModel        = "inference-accelerator-model"
-	placementGroupStrategy           = "placement-group-strategy"
-	usageClass                       = "usage-class"
-	rootDeviceType                   = "root-device-type"
-	enaSupport                       = "ena-support"
-	efaSupport                       = "efa-support"
-	hibernationSupport               = "hibernation-support"
-	baremetal                        = "baremetal"
-	fpgaSupport                      = "fpga-support"
-	burstSupport                     = "burst-support"
-	hypervisor                       = "hypervisor"
-	availabilityZones                = "availability-zones"
-	currentGeneration                = "current-generation"
-	networkInterfaces                = "network-interfaces"
-	networkPerformance               = "network-performance"
-	networkEncryption                = "network-encryption"
-	ipv6                             = "ipv6"
-	allowList                        = "allow-list"
-	denyList                         = "deny-list"
-	virtualizationType               = "virtualization-type"
-	pricePerHour                     = "price-per-hour"
-	instanceStorage                  = "instance-storage"
-	diskType                         = "disk-type"
-	diskEncryption                   = "disk-encryption"
-	nvme                             = "nvme"
-	ebsOptimized                     = "ebs-optimized"
-	ebsOptimizedBaselineBandwidth    = "ebs-optimized-baseline-bandwidth"
-	ebsOptimizedBaselineThroughput   = "ebs-optimized-baseline-throughput"
-	ebsOptimizedBaselineIOPS         = "ebs-optimized-baseline-iops"
-	freeTier                         = "free-tier"
-	autoRecovery                     = "auto-recovery"
-	dedicatedHosts                   = "dedicated-hosts"
-	debug                            = "debug"
-	generation                       = "generation"
+	allowListFile             = "allow-list-file"
+	denyListFile              = "deny-list-file"
+	currentGeneration         = "current-generation"
+	excludeDeprecated         = "exclude-deprecated"
+	includePreviousGeneration = "include-previous-generation"
+	usageClass                = "usage-class"
 )
 
 // Aggregate Filter Flags.
@@ -111,26 +90,143 @@ const (
 	instanceTypeBase = "base-instance-type"
 	flexible         = "flexible"
 	service          = "service"
+	scaleFrom        = "scale-from"
+	scaleSteps       = "steps"
+	scaleEquivalents = "scale-equivalents"
+)
+
+// Sub-Command Constants.
+const (
+	checkLaunchTemplate   = "check-launch-template"
+	launchTemplateID      = "lt-id"
+	launchTemplateVersion = "lt-version"
+	watch                 = "watch"
+	watchInterval         = "interval"
+	exitOnChange          = "exit-on-change"
+	watchStateFile        = "state-file"
+	notifyWebhook         = "notify-webhook"
+	notifySNSTopic        = "notify-sns-topic"
+	rightsize             = "rightsize"
+	rightsizeInstanceID   = "instance-id"
+	rightsizeASGName      = "asg-name"
+	rightsizeLookback     = "lookback"
+	rightsizeHeadroom     = "headroom"
+	rightsizeCrossCheck   = "cross-check"
+	regionsList           = "regions list"
+	zonesList             = "zones list"
+	upgradeCmdName        = "upgrade"
+	pricingCmdName        = "pricing"
+	refreshCmdName        = "refresh"
+	compare               = "compare"
+	upgradeCheck          = "check"
+)
+
+// maxUpgradeHighlights is the number of release note highlights printed by upgrade --check.
+const maxUpgradeHighlights = 10
+
+// crossCheckComputeOptimizer cross-checks the rightsized instance types with the AWS Compute Optimizer recommendation.
+const crossCheckComputeOptimizer = "compute-optimizer"
+
+// maxSuggestions is the number of suggestions printed by --suggest when no instance types match.
+const maxSuggestions = 5
+
+// watchChangedExitCode is the exit code of the watch sub-command when --exit-on-change is set and the matching instance types changed.
+const watchChangedExitCode = 2
+
+// timedOutExitCode is the exit code when the command does not finish within --timeout, the same as the timeout command.
+const timedOutExitCode = 124
+
+// Modes of --output-file-mode.
+const (
+	overwriteOutputFile = "overwrite"
+	appendOutputFile    = "append"
+)
+
+// Layouts of --layout.
+const (
+	horizontalLayout = "horizontal"
+	verticalLayout   = "vertical"
 )
 
+// interruptedExitCode is the exit code when SIGINT or SIGTERM interrupts the command, following the 128 + SIGINT convention.
+const interruptedExitCode = 130
+
+// shutdownGracePeriod is how long the in-flight API calls canceled by SIGINT or SIGTERM are given to return before the caches
+// are saved and the process exits regardless.
+const shutdownGracePeriod = 10 * time.Second
+
 // Configuration Flag Constants.
 const (
-	maxResults    = "max-results"
-	profile       = "profile"
-	help          = "help"
-	verbose       = "verbose"
-	version       = "version"
-	region        = "region"
-	output        = "output"
-	cacheTTL      = "cache-ttl"
-	cacheDir      = "cache-dir"
-	sortDirection = "sort-direction"
-	sortBy        = "sort-by"
+	maxResults     = "max-results"
+	profile        = "profile"
+	help           = "help"
+	verbose        = "verbose"
+	version        = "version"
+	region         = "region"
+	output         = "output"
+	cacheTTL       = "cache-ttl"
+	offeringsTTL   = "offerings-cache-ttl"
+	cacheDir       = "cache-dir"
+	cacheReadOnly  = "cache-read-only"
+	carbonData     = "carbon-data"
+	deprecations   = "deprecations-file"
+	pricingFile    = "pricing-file"
+	priceDiscount  = "price-discount-percent"
+	noColor        = "no-color"
+	filtersFile    = "filters-file"
+	sortDirection  = "sort-direction"
+	sortBy         = "sort-by"
+	orderFile      = "order-preference-file"
+	columnsFile    = "columns-file"
+	outputFile     = "output-file"
+	outputFileMode = "output-file-mode"
+	s3SSE          = "s3-sse"
+	s3SSEKMSKeyID  = "s3-sse-kms-key-id"
+	ssmParameter   = "write-ssm-parameter"
+	ssmDryRun      = "ssm-dry-run"
+	emitManifest   = "emit-manifest"
+	layout         = "layout"
+	siUnits        = "si-units"
+	suggest        = "suggest"
+	stats          = "stats"
+	summary        = "summary"
+	sageMakerNames = "sagemaker-names"
+	allRegions     = "all-regions"
+	debug          = "debug"
+	debugAWS       = "debug-aws"
+	maxAPICalls    = "max-api-calls"
+	spotDaysBack   = "spot-days-back"
+	timeout        = "timeout"
+	schema         = "schema"
+)
+
+// Environment Variable Constants.
+const (
+	maxResultsEnvVar          = "EC2_INSTANCE_SELECTOR_MAX_RESULTS"
+	cacheTTLEnvVar            = "EC2_INSTANCE_SELECTOR_CACHE_TTL"
+	offeringsTTLEnvVar        = "EC2_INSTANCE_SELECTOR_OFFERINGS_CACHE_TTL"
+	cacheDirEnvVar            = "EC2_INSTANCE_SELECTOR_CACHE_DIR"
+	cacheReadOnlyEnvVar       = "EC2_INSTANCE_SELECTOR_CACHE_READ_ONLY"
+	debugEnvVar               = "EC2_INSTANCE_SELECTOR_DEBUG"
+	spotPricingDaysBackEnvVar = "EC2_INSTANCE_SELECTOR_SPOT_PRICING_DAYS_BACK"
+	timeoutEnvVar             = "EC2_INSTANCE_SELECTOR_TIMEOUT"
+	noColorEnvVar             = "NO_COLOR"
 )
 
 // versionID is overridden at compilation with the version based on the git tag
 var versionID = "dev"
 
+// filterCaches are hydrated in parallel before filtering when prices are displayed, the offerings cache is only
+// needed by the outputs which display zones.
+var filterCaches = []string{selector.OnDemandPricingCache, selector.SpotPricingCache, selector.InstanceTypesCache}
+
+const (
+	// refreshLockFile is created in the cache directory while the caches are refreshed
+	refreshLockFile = ".refresh.lock"
+	// refreshLockStaleAfter is how long a refresh lock is held before it is assumed to be left behind by a crashed refresh
+	refreshLockStaleAfter = time.Hour
+)
+
 func main() {
 	log.SetOutput(os.Stderr)
 	log.SetPrefix("NOTE: ")
@@ -146,12 +242,7 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	runFunc := func(cmd *cobra.Command, args []string) {}
 	cli := commandline.New(binName, shortUsage, longUsage, examples, runFunc)
 
-	cliOutputTypes := []string{
-		tableOutput,
-		tableWideOutput,
-		oneLine,
-		bubbleTeaOutput,
-	}
+	outputDispatcher := outputs.NewDispatcher()
 	resultsOutputFn := outputs.SimpleInstanceTypeOutput
 
 	cliSortDirections := []string{
@@ -162,73 +253,164 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	}
 
 	// Registers flags with specific input types from the cli pkg
-	// Filter Flags - These will be grouped at the top of the help flags
-
-	cli.Int32MinMaxRangeFlags(vcpus, cli.StringMe("c"), nil, "Number of vcpus available to the instance type.")
-	cli.ByteQuantityMinMaxRangeFlags(memory, cli.StringMe("m"), nil, "Amount of Memory available (Example: 4 GiB)")
-	cli.RatioFlag(vcpusToMemoryRatio, nil, nil, "The ratio of vcpus to GiBs of memory. (Example: 1:2)")
-	cli.StringOptionsFlag(cpuArchitecture, cli.StringMe("a"), nil, "CPU architecture [x86_64, amd64, x86_64_mac, i386, or arm64]", []string{"x86_64", "x86_64_mac", "amd64", "i386", "arm64"})
-	cli.StringOptionsFlag(cpuManufacturer, nil, nil, "CPU manufacturer [amd, intel, aws]", []string{"amd", "intel", "aws"})
-	cli.Int32MinMaxRangeFlags(gpus, cli.StringMe("g"), nil, "Total Number of GPUs (Example: 4)")
-	cli.ByteQuantityMinMaxRangeFlags(gpuMemoryTotal, nil, nil, "Number of GPUs' total memory (Example: 4 GiB)")
-	cli.StringFlag(gpuManufacturer, nil, nil, "GPU Manufacturer name (Example: NVIDIA)", nil)
-	cli.StringFlag(gpuModel, nil, nil, "GPU Model name (Example: K520)", nil)
-	cli.IntMinMaxRangeFlags(inferenceAccelerators, nil, nil, "Total Number of inference accelerators (Example: 4)")
-	cli.StringFlag(inferenceAcceleratorManufacturer, nil, nil, "Inference Accelerator Manufacturer name (Example: AWS)", nil)
-	cli.StringFlag(inferenceAcceleratorModel, nil, nil, "Inference Accelerator Model name (Example: Inferentia)", nil)
-	cli.StringOptionsFlag(placementGroupStrategy, nil, nil, "Placement group strategy: [cluster, partition, spread]", []string{"cluster", "partition", "spread"})
-	cli.StringOptionsFlag(usageClass, cli.StringMe("u"), nil, "Usage class: [spot or on-demand]", []string{"spot", "on-demand"})
-	cli.StringOptionsFlag(rootDeviceType, nil, nil, "Supported root device types: [ebs or instance-store]", []string{"ebs", "instance-store"})
-	cli.BoolFlag(enaSupport, cli.StringMe("e"), nil, "Instance types where ENA is supported or required")
-	cli.BoolFlag(efaSupport, nil, nil, "Instance types that support Elastic Fabric Adapters (EFA)")
-	cli.BoolFlag(hibernationSupport, nil, nil, "Hibernation supported")
-	cli.BoolFlag(baremetal, nil, nil, "Bare Metal instance types (.metal instances)")
-	cli.BoolFlag(fpgaSupport, cli.StringMe("f"), nil, "FPGA instance types")
-	cli.BoolFlag(burstSupport, cli.StringMe("b"), nil, "Burstable instance types")
-	cli.StringOptionsFlag(hypervisor, nil, nil, "Hypervisor: [xen or nitro]", []string{"xen", "nitro"})
-	cli.StringSliceFlag(availabilityZones, cli.StringMe("z"), nil, "Availability zones or zone ids to check EC2 capacity offered in specific AZs")
-	cli.BoolFlag(currentGeneration, nil, nil, "Current generation instance types (explicitly set this to false to not return current generation instance types)")
-	cli.Int32MinMaxRangeFlags(networkInterfaces, nil, nil, "Number of network interfaces (ENIs) that can be attached to the instance")
-	cli.IntMinMaxRangeFlags(networkPerformance, nil, nil, "Bandwidth in Gib/s of network performance (Example: 100)")
-	cli.BoolFlag(networkEncryption, nil, nil, "Instance Types that support automatic network encryption in-transit")
-	cli.BoolFlag(ipv6, nil, nil, "Instance Types that support IPv6")
-	cli.RegexFlag(allowList, nil, nil, "List of allowed instance types to select from w/ regex syntax (Example: m[3-5]\\.*)")
-	cli.RegexFlag(denyList, nil, nil, "List of instance types which should be excluded w/ regex syntax (Example: m[1-2]\\.*)")
-	cli.StringOptionsFlag(virtualizationType, nil, nil, "Virtualization Type supported: [hvm or pv]", []string{"hvm", "paravirtual", "pv"})
-	cli.Float64MinMaxRangeFlags(pricePerHour, nil, nil, "Price/hour in USD (Example: 0.09)")
-	cli.ByteQuantityMinMaxRangeFlags(instanceStorage, nil, nil, "Amount of local instance storage (Example: 4 GiB)")
-	cli.StringOptionsFlag(diskType, nil, nil, "Disk Type: [hdd or ssd]", []string{"hdd", "ssd"})
-	cli.BoolFlag(nvme, nil, nil, "EBS or local instance storage where NVME is supported or required")
-	cli.BoolFlag(diskEncryption, nil, nil, "EBS or local instance storage where encryption is supported or required")
-	cli.BoolFlag(ebsOptimized, nil, nil, "EBS Optimized is supported or default")
-	cli.ByteQuantityMinMaxRangeFlags(ebsOptimizedBaselineBandwidth, nil, nil, "EBS Optimized baseline bandwidth (Example: 4 GiB)")
-	cli.ByteQuantityMinMaxRangeFlags(ebsOptimizedBaselineThroughput, nil, nil, "EBS Optimized baseline throughput per second (Example: 4 GiB)")
-	cli.IntMinMaxRangeFlags(ebsOptimizedBaselineIOPS, nil, nil, "EBS Optimized baseline IOPS per second (Example: 10000)")
-	cli.BoolFlag(freeTier, nil, nil, "Free Tier supported")
-	cli.BoolFlag(autoRecovery, nil, nil, "EC2 Auto-Recovery supported")
-	cli.BoolFlag(dedicatedHosts, nil, nil, "Dedicated Hosts supported")
-	cli.IntMinMaxRangeFlags(generation, nil, nil, "Generation of the instance type (i.e. c7i.xlarge is 7)")
-
-	// Suite Flags - higher level aggregate filters that return opinionated result
-
-	cli.SuiteStringFlag(instanceTypeBase, nil, nil, "Instance Type used to retrieve similarly spec'd instance types", nil)
-	cli.SuiteBoolFlag(flexible, nil, nil, "Retrieves a group of instance types spanning multiple generations based on opinionated defaults and user overridden resource filters")
-	cli.SuiteStringFlag(service, nil, nil, "Filter instance types based on service support (Example: emr-5.20.0)", nil)
+	// Filter Flags - These will be grouped at the top of the help flags, one is generated for each tagged selector.Filters field
+
+	if err := cli.FilterFlags(); err != nil {
+		log.Fatalf("Unable to register the filter flags: %v", err)
+	}
+	cli.RegexFileFlag(allowListFile, nil, nil, "File of newline-delimited instance type names, globs, or regex patterns to select from, combined with --allow-list (Example: ./allowed-instance-types.txt)")
+	cli.RegexFileFlag(denyListFile, nil, nil, "File of newline-delimited instance type names, globs, or regex patterns which should be excluded, combined with --deny-list (Example: ./denied-instance-types.txt)")
+	cli.BoolFlag(includePreviousGeneration, nil, nil, "Include previous generation instance types, marked in the Prev Gen column of the table-wide and interactive outputs and sorted after the current generation instance types unless --sort-by is set")
+
+	// Sub-Commands - accept all filter and configuration flags in addition to their own flags
+
+	checkLaunchTemplateCmd := cli.SubCommand(checkLaunchTemplate,
+		"Retrieve instance types compatible with a launch template",
+		"Extracts the AMI, network interfaces, and placement constraints from a launch template and returns the instance types compatible with it and any additional filters",
+		fmt.Sprintf("%s %s --%s lt-0123456789abcdef0 --%s 5 --vcpus-min 4", binName, checkLaunchTemplate, launchTemplateID, launchTemplateVersion),
+		runFunc)
+	cli.StringFlagOnFlagSet(checkLaunchTemplateCmd.Flags(), launchTemplateID, nil, nil, "Launch template ID to check instance type compatibility against", nil, nil)
+	cli.StringFlagOnFlagSet(checkLaunchTemplateCmd.Flags(), launchTemplateVersion, nil, nil, "Launch template version to use (Example: 5, $Latest, or $Default) (default $Default)", nil, nil)
+	if err := checkLaunchTemplateCmd.MarkFlagRequired(launchTemplateID); err != nil {
+		log.Fatalf("Unable to register the %s sub-command: %v", checkLaunchTemplate, err)
+	}
+
+	watchCmd := cli.SubCommand(watch,
+		"Periodically re-run a filter and report when the matching instance types change",
+		"Re-runs the filter every interval and prints the instance types which were added or removed since the previous run, e.g. when a new instance type becomes available in the region",
+		fmt.Sprintf("%s %s --%s 24h --vcpus-min 4 --cpu-architecture arm64 --%s", binName, watch, watchInterval, exitOnChange),
+		runFunc)
+	cli.DurationFlagOnFlagSet(watchCmd.Flags(), watchInterval, nil, aws.Duration(24*time.Hour), "How often to re-run the filter (Example: 30m, 24h)")
+	cli.BoolFlagOnFlagSet(watchCmd.Flags(), exitOnChange, nil, nil, fmt.Sprintf("Exit with code %d as soon as the matching instance types change", watchChangedExitCode))
+	cli.PathFlagOnFlagSet(watchCmd.Flags(), watchStateFile, nil, nil, "File to persist the matching instance types to so that changes are detected across restarts")
+	cli.StringFlagOnFlagSet(watchCmd.Flags(), notifyWebhook, nil, nil, "URL to POST a JSON diff of the added and removed instance types to when they change", nil, validateWebhookURL)
+	cli.StringFlagOnFlagSet(watchCmd.Flags(), notifySNSTopic, nil, nil, "SNS topic ARN to publish a JSON diff of the added and removed instance types to when they change", nil, validateSNSTopicARN)
+
+	rightsizeCmd := cli.SubCommand(rightsize,
+		"Retrieve instance types sized to the CloudWatch utilization of an instance or Auto Scaling group",
+		"Retrieves the peak hourly p95 CPU utilization and, when published by the CloudWatch agent, memory utilization (CWAgent mem_used_percent) of a running instance or Auto Scaling group and returns the instance types with enough vCPUs and memory for that utilization plus headroom and at most twice as much. vCPU and memory filters passed as flags take precedence.",
+		fmt.Sprintf("%s %s --%s i-0123456789abcdef0 --%s 336h --%s 30", binName, rightsize, rightsizeInstanceID, rightsizeLookback, rightsizeHeadroom),
+		runFunc)
+	cli.StringFlagOnFlagSet(rightsizeCmd.Flags(), rightsizeInstanceID, nil, nil, "ID of the running instance to rightsize", nil, nil)
+	cli.StringFlagOnFlagSet(rightsizeCmd.Flags(), rightsizeASGName, nil, nil, "Name of the Auto Scaling group to rightsize, all of its instances must be the same instance type", nil, nil)
+	cli.PositiveDurationFlagOnFlagSet(rightsizeCmd.Flags(), rightsizeLookback, nil, aws.Duration(rightsizing.DefaultLookback), rightsizing.MaxLookback, "How far back to retrieve utilization from, at most 455 days (Example: 72h, 336h)")
+	cli.Float64FlagOnFlagSet(rightsizeCmd.Flags(), rightsizeHeadroom, nil, aws.Float64(rightsizing.DefaultHeadroom*100), "Percentage of capacity to add on top of the peak utilization")
+	cli.StringOptionsFlagOnFlagSet(rightsizeCmd.Flags(), rightsizeCrossCheck, nil, nil, fmt.Sprintf("Annotate which instance types match or deviate from the recommendation of a service (%s)", crossCheckComputeOptimizer), []string{crossCheckComputeOptimizer})
+	rightsizeCmd.MarkFlagsOneRequired(rightsizeInstanceID, rightsizeASGName)
+
+	cli.SubCommand(regionsList,
+		"List the regions enabled for the account",
+		"Lists the names and opt-in status of the regions enabled for the account, the names can be passed to --region",
+		fmt.Sprintf("%s %s --output one-line", binName, regionsList),
+		runFunc)
+	cli.SubCommand(zonesList,
+		"List the availability zones, Local Zones, and Wavelength Zones of a region",
+		"Lists the names, IDs, types, and opt-in status of all of the zones of the region, the names or IDs can be passed to --availability-zones",
+		fmt.Sprintf("%s %s --region us-west-2", binName, zonesList),
+		runFunc)
+
+	pricingCmd := cli.SubCommand(pricingCmdName,
+		"Look up the on-demand and spot prices of instance types",
+		"Prints the hourly on-demand price and the 30 day average hourly spot price in each availability zone of the instance types from the pricing caches without filtering, prices which are not cached are retrieved from the pricing APIs. --availability-zones limits the spot prices to those zones.",
+		fmt.Sprintf("%s %s m5.large c7g.xlarge --region us-east-1", binName, pricingCmdName),
+		runFunc)
+	pricingCmd.Use = pricingCmdName + " <instance-type>..."
+	pricingCmd.Args = cobra.MinimumNArgs(1)
+
+	compareCmd := cli.SubCommand(compare,
+		"Compare instance types side-by-side",
+		fmt.Sprintf("Prints a table with a row for each attribute of the --%s %s output, including the on-demand and spot prices, and a column for each instance type in the order they were passed. Filters passed as flags still apply, instance types which don't match them are left out. --%s selects another output format for the instance types.", output, outputs.TableWideFormat, output),
+		fmt.Sprintf("%s %s m5.2xlarge c6i.2xlarge r7g.2xlarge --region us-east-1", binName, compare),
+		runFunc)
+	compareCmd.Use = compare + " <instance-type>..."
+	compareCmd.Args = cobra.MinimumNArgs(1)
+
+	cli.SubCommand(refreshCmdName,
+		"Refresh the on-disk caches, to be run on a schedule",
+		fmt.Sprintf("Retrieves the instance types, the zones they are offered in, and the on-demand and spot prices of the region and saves them to --%s so that later invocations, such as the interactive output, start instantly. Requires --%s to be greater than 0. A lock file in --%s prevents concurrent refreshes from writing the same cache files, a refresh which finds the lock held exits without refreshing.", cacheDir, cacheTTL, cacheDir),
+		fmt.Sprintf("%s %s --region us-east-1 --%s 168", binName, refreshCmdName, cacheTTL),
+		runFunc)
+
+	upgradeCmd := cli.SubCommand(upgradeCmdName,
+		"Check for a newer release of "+binName,
+		"Retrieves the latest release from GitHub and prints the highlights of its release notes when it is newer than this binary, newer releases know about newer instance families. Binaries are upgraded with the package manager they were installed with.",
+		fmt.Sprintf("%s %s --%s", binName, upgradeCmdName, upgradeCheck),
+		runFunc)
+	cli.BoolFlagOnFlagSet(upgradeCmd.Flags(), upgradeCheck, nil, nil, "Check for a newer release without upgrading")
+	if err := upgradeCmd.MarkFlagRequired(upgradeCheck); err != nil {
+		log.Fatalf("Unable to register the %s sub-command: %v", upgradeCmdName, err)
+	}
 
 	// Configuration Flags - These will be grouped at the bottom of the help flags
 
-	cli.ConfigIntFlag(maxResults, nil, env.WithDefaultInt("EC2_INSTANCE_SELECTOR_MAX_RESULTS", 20), "The maximum number of instance types that match your criteria to return")
+	cli.ConfigIntFlag(maxResults, nil, env.WithDefaultInt(maxResultsEnvVar, 20), "The maximum number of instance types that match your criteria to return")
 	cli.ConfigStringFlag(profile, nil, nil, "AWS CLI profile to use for credentials and config", nil)
 	cli.ConfigStringFlag(region, cli.StringMe("r"), nil, "AWS Region to use for API requests (NOTE: if not passed in, uses AWS SDK default precedence)", nil)
-	cli.ConfigStringFlag(output, cli.StringMe("o"), nil, fmt.Sprintf("Specify the output format (%s)", strings.Join(cliOutputTypes, ", ")), nil)
-	cli.ConfigIntFlag(cacheTTL, nil, env.WithDefaultInt("EC2_INSTANCE_SELECTOR_CACHE_TTL", 0), "Cache TTLs in hours for pricing and instance type caches. Setting the cache to 0 will turn off caching and cleanup any on-disk caches.")
-	cli.ConfigPathFlag(cacheDir, nil, env.WithDefaultString("EC2_INSTANCE_SELECTOR_CACHE_DIR", "~/.ec2-instance-selector/"), "Directory to save the pricing and instance type caches")
+	cli.ConfigStringFlag(output, cli.StringMe("o"), nil, fmt.Sprintf("Specify the output format (%s)", strings.Join(outputDispatcher.Formats(), ", ")), func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		_, err := outputDispatcher.Format(*val.(*string))
+		return err
+	})
+	cli.ConfigIntFlag(cacheTTL, nil, env.WithDefaultInt(cacheTTLEnvVar, 0), "Cache TTLs in hours for pricing, instance type, and instance type offerings caches. Setting the cache to 0 will turn off caching and cleanup any on-disk caches.")
+	// the offerings cache TTL defaults to --cache-ttl when neither the flag nor its environment variable are set
+	var offeringsTTLDefault *int
+	if _, ok := os.LookupEnv(offeringsTTLEnvVar); ok {
+		offeringsTTLDefault = env.WithDefaultInt(offeringsTTLEnvVar, 0)
+	}
+	cli.ConfigIntFlag(offeringsTTL, nil, offeringsTTLDefault, fmt.Sprintf("Cache TTL in hours for the instance types offered in each region, zone, and outpost, defaults to --%s. Setting it to 0 turns off only the offerings cache.", cacheTTL))
+	cli.ConfigPathFlag(cacheDir, nil, env.WithDefaultString(cacheDirEnvVar, "~/.ec2-instance-selector/"), "Directory to save the pricing and instance type caches")
+	cli.ConfigBoolFlag(cacheReadOnly, nil, env.WithDefaultBool(cacheReadOnlyEnvVar, false), "Load the pricing and instance type caches from --cache-dir without saving or removing them, for caches shared from a read-only location (requires --cache-ttl greater than 0)")
+	cli.ConfigPathFlag(filtersFile, nil, nil, "YAML file of filters keyed by their Go field names (Example: VCpusRange: {LowerBound: 2, UpperBound: 4}) with an optional AnyOf list of filter groups to match any of, filters passed as flags take precedence")
+	cli.ConfigPathFlag(carbonData, nil, nil, "JSON file of instance type names to relative carbon scores which override the built-in estimates used by --sort-by carbon (Example: {\"m5.large\": 12.5})")
+	cli.ConfigPathFlag(deprecations, nil, nil, "JSON file of instance type names or families to the reason they are deprecated which is applied on top of the built-in previous generation families used by --exclude-deprecated, an empty reason removes a built-in deprecation (Example: {\"m4\": \"EOL 2026-06\"})")
+	cli.ConfigPathFlag(pricingFile, nil, nil, "CSV file with an InstanceType, OnDemandPricePerHour, and optional SpotPricePerHour header row of custom hourly prices, such as negotiated private pricing, which are used instead of the public prices for filtering, sorting, and output (Example: m5.large,0.0768,0.031)")
+	cli.ConfigFloat64Flag(priceDiscount, nil, nil, "Percentage discount, such as an EDP discount, applied to all public on-demand prices before filtering, sorting, and output (Example: 27)")
+	cli.ConfigStringFlag(scaleFrom, nil, nil, fmt.Sprintf("Instance type to scale up or down by --%s sizes within its family, sizes are ordered by vCPUs and then memory (Example: m5.xlarge)", scaleSteps), nil)
+	cli.ConfigIntFlag(scaleSteps, nil, cli.IntMe(1), fmt.Sprintf("Number of sizes to scale --%s by, scale down with a negative number passed as --%s=-1 (Example: +1)", scaleFrom, scaleSteps))
+	cli.ConfigBoolFlag(scaleEquivalents, nil, nil, fmt.Sprintf("Also retrieve the instance types of other families with the same vCPUs and memory as the scaled --%s instance type", scaleFrom))
+	cli.ConfigBoolFlag(siUnits, nil, nil, "Interpret byte quantity units without an i (MB, GB, TB, PB) as decimal units (i.e. 1 GB is 1,000,000,000 bytes) rather than binary units (i.e. 1 GiB is 1,073,741,824 bytes)")
 	cli.ConfigBoolFlag(verbose, cli.StringMe("v"), nil, "Verbose - will print out full instance specs")
-	cli.ConfigBoolFlag("debug", nil, nil, "Debug - prints debug log messages")
+	cli.ConfigBoolFlag(suggest, nil, nil, "Suggest which filters to relax, and by how much, when no instance types match")
+	cli.ConfigBoolFlag(stats, nil, nil, "Print how many instance types each filter excluded after the other filters were applied")
+	cli.ConfigBoolFlag(summary, nil, nil, fmt.Sprintf("Print the number of matches and the ranges of their vCPUs, memory, and prices after the table, for --%s %s or %s", output, outputs.TableFormat, outputs.TableWideFormat))
+	cli.ConfigBoolFlag(sageMakerNames, nil, nil, "Output the ml. prefixed SageMaker names of the instance types (Example: ml.m5.large), for use with --service sagemaker")
+	cli.ConfigBoolFlag(noColor, nil, aws.Bool(os.Getenv(noColorEnvVar) != ""), "Disable colors in all outputs, including the interactive output (defaults to true when the NO_COLOR env var is set)")
+	cli.ConfigBoolFlag(debug, nil, env.WithDefaultBool(debugEnvVar, false), "Debug - prints debug log messages")
+	cli.ConfigBoolFlag(allRegions, nil, nil, "Filter instance types in all of the regions enabled for the account instead of only --region, the table outputs display the region of each instance type and are the default output")
+	cli.ConfigBoolFlag(debugAWS, nil, nil, "Debug AWS - logs the request ID, duration, and attempts of each AWS API call and a count of calls per API (also enabled by --verbose)")
+	cli.ConfigIntFlag(spotDaysBack, nil, nil, fmt.Sprintf("Number of days of spot price history to average, 0 uses the latest price (defaults to the %s env var or 0)", spotPricingDaysBackEnvVar))
+	cli.ConfigIntFlag(maxAPICalls, nil, nil, "Maximum number of AWS API calls, paginated APIs count once per page. The calls beyond it are skipped, pricing which can not be retrieved is left out, and the skipped calls are reported (Example: 50)")
+	cli.ConfigDurationFlag(timeout, nil, env.WithDefaultDuration(timeoutEnvVar, 0), "Maximum duration of the command, including all of its AWS API requests, after which it exits with a timeout error and code 124 once the caches are saved. 0 disables the timeout (Example: 45s, 2m)")
 	cli.ConfigBoolFlag(help, cli.StringMe("h"), nil, "Help")
 	cli.ConfigBoolFlag(version, nil, nil, "Prints CLI version")
+	cli.ConfigBoolFlag(schema, nil, nil, fmt.Sprintf("Prints the JSON Schema of the instance types of the --%s and --%s %s outputs, which include their schemaVersion (%s)", verbose, output, outputs.NDJSONFormat, outputs.SchemaVersion))
 	cli.ConfigStringOptionsFlag(sortDirection, nil, cli.StringMe(sorter.SortAscending), fmt.Sprintf("Specify the direction to sort in (%s)", strings.Join(cliSortDirections, ", ")), cliSortDirections)
 	cli.ConfigStringFlag(sortBy, nil, cli.StringMe(instanceNamePath), "Specify the field to sort by. Quantity flags present in this CLI (memory, gpus, etc.) or a JSON path to the appropriate instance type field (Ex: \".MemoryInfo.SizeInMiB\") is acceptable.", nil)
+	cli.ConfigPathFlag(orderFile, nil, nil, fmt.Sprintf("File of newline-delimited instance type names in order of preference which are ordered first, the other instance types are ordered by --%s (Example: ./asg-override-order.txt)", sortBy))
+	cli.ConfigPathFlag(columnsFile, nil, nil, fmt.Sprintf("YAML file with a Columns mapping of custom column names to JSON paths of instance type fields like --%s accepts, which are displayed after the other columns of the %s and %s outputs (Example: Columns: {\"Max ENIs\": .NetworkInfo.MaximumNetworkInterfaces})", sortBy, outputs.TableWideFormat, outputs.InteractiveFormat))
+	cli.ConfigStringOptionsFlag(layout, nil, nil, fmt.Sprintf("Layout of the %s and %s outputs and of the %s command (%s, %s), defaults to %s. %s prints a block of lines for each instance type instead of a row, which fits in narrow terminals", outputs.TableFormat, outputs.TableWideFormat, compare, horizontalLayout, verticalLayout, horizontalLayout, verticalLayout), []string{horizontalLayout, verticalLayout})
+	cli.ConfigStringFlag(outputFile, nil, nil, fmt.Sprintf("File or S3 URI to write the output to instead of stdout, S3 objects are written with the loaded AWS config. The --%s is inferred from the extension when it is not passed (.json, .csv, .yaml, or .ndjson) (Example: s3://bucket/instance-types.json)", output), nil)
+	cli.ConfigStringOptionsFlag(outputFileMode, nil, nil, fmt.Sprintf("Whether --%s replaces the contents of the file or appends to it (%s, %s), defaults to %s. The CSV header is only written to empty files when appending", outputFile, overwriteOutputFile, appendOutputFile, overwriteOutputFile), []string{overwriteOutputFile, appendOutputFile})
+	cli.ConfigStringOptionsFlag(s3SSE, nil, nil, fmt.Sprintf("Server-side encryption of the S3 object written by --%s (%s), defaults to the default encryption of the bucket", outputFile, strings.Join(destination.ServerSideEncryptionAlgorithms(), ", ")), destination.ServerSideEncryptionAlgorithms())
+	cli.ConfigStringFlag(s3SSEKMSKeyID, nil, nil, fmt.Sprintf("KMS key ID, ARN, or alias to encrypt the S3 object written by --%s with, for --%s aws:kms or aws:kms:dsse", outputFile, s3SSE), nil)
+	cli.ConfigStringFlag(ssmParameter, nil, nil, fmt.Sprintf("Name of an SSM Parameter Store parameter to store the resulting instance types in as a StringList, overwriting its previous value, after they are printed (Example: /org/approved-instance-types). All of the matching instance types are stored, regardless of --%s", maxResults), validateSSMParameterName)
+	cli.ConfigBoolFlag(ssmDryRun, nil, nil, fmt.Sprintf("Print the AWS CLI put-parameter command which --%s would run instead of storing the parameter", ssmParameter))
+	cli.ConfigStringFlag(emitManifest, nil, nil, "File or S3 URI to write a JSON manifest of the selection to, with the version, region, filters, and cache file timestamps it was made with and a hash of the resulting instance types, so that it can be audited and reproduced later (Example: manifest.json)", nil)
+
+	// Flag Groups - printed together in the output of --help after the filter flags
+
+	cli.AddFlagGroup("Aggregate Flags", false, instanceTypeBase, flexible, service, scaleFrom, scaleSteps, scaleEquivalents)
+	cli.AddFlagGroup("Output Flags", false, output, layout, verbose, schema, maxResults, sortBy, sortDirection, orderFile, columnsFile, suggest, stats, sageMakerNames, outputFile, outputFileMode, s3SSE, s3SSEKMSKeyID, ssmParameter, ssmDryRun, emitManifest)
+	cli.AddFlagGroup("AWS Flags", true, profile, region, allRegions, debugAWS, maxAPICalls, timeout)
+	cli.AddFlagGroup("Caching Flags", true, cacheDir, cacheTTL, offeringsTTL, cacheReadOnly)
+	cli.SetFlagExample(region, "us-east-2")
+	cli.SetSIUnitsFlag(siUnits)
+
+	// Flag Relationships - combinations of flags which would otherwise be silently ignored
+
+	markFlagRelationships(&cli)
 
 	// Parses the user input with the registered flags and runs type specific validation on the user input
 	flags, err := cli.ParseAndValidateFlags()
@@ -246,11 +428,53 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		os.Exit(0)
 	}
 
+	if flags[schema] != nil {
+		jsonSchema, err := outputs.JSONSchema()
+		if err != nil {
+			fmt.Printf("An error occurred when generating the JSON Schema of the outputs: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonSchema))
+		os.Exit(0)
+	}
+
+	if aws.ToBool(cli.BoolMe(flags[noColor])) {
+		disableInteractiveColor()
+	}
+
 	if flags[service] != nil {
 		log.Println("--service eks is deprecated. EKS generally supports all instance types")
 	}
 
 	ctx := context.Background()
+	commandTimeout := *cli.DurationMe(flags[timeout])
+	if commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+	}
+	// the deadline of --timeout, which is not canceled by SIGINT and SIGTERM
+	deadlineCtx := ctx
+	// canceled by SIGINT and SIGTERM, see registerShutdown
+	ctx, cancelAPICalls := context.WithCancel(ctx)
+	defer cancelAPICalls()
+
+	// the upgrade check does not call AWS APIs, so it does not require AWS credentials
+	if cli.InvokedCommand() == upgradeCmdName {
+		if err := checkForUpgrade(ctx, upgrade.NewChecker()); err != nil {
+			fmt.Printf("An error occurred when checking for a newer release: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	spotPricingDaysBack := *env.WithDefaultInt(spotPricingDaysBackEnvVar, defaultSpotPricingDaysBack)
+	if daysBack := cli.IntMe(flags[spotDaysBack]); daysBack != nil {
+		if *daysBack < 0 {
+			fmt.Printf("--%s must be 0 or greater", spotDaysBack)
+			os.Exit(1)
+		}
+		spotPricingDaysBack = *daysBack
+	}
 	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithSharedConfigProfile(
 			aws.ToString(
@@ -269,57 +493,477 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	}
 
 	flags[region] = cfg.Region
+	// attribute the API calls of every client to the CLI unless an app id is configured (AWS_SDK_UA_APP_ID or sdk_ua_app_id)
+	if cfg.AppID == "" {
+		cfg.AppID = binName
+	}
+
+	var tracer *awsapi.Tracer
+	if aws.ToBool(cli.BoolMe(flags[debugAWS])) || flags[verbose] != nil {
+		tracer = awsapi.NewTracer(log.New(os.Stderr, "AWS ", log.LUTC|log.Ldate|log.Lmicroseconds))
+		cfg.APIOptions = append(cfg.APIOptions, tracer.AddMiddleware)
+	}
 
 	cacheTTLDuration := time.Hour * time.Duration(*cli.IntMe(flags[cacheTTL]))
-	instanceSelector, err := selector.NewWithCache(ctx, cfg, cacheTTLDuration, *cli.StringMe(flags[cacheDir]))
+	isCacheReadOnly := aws.ToBool(cli.BoolMe(flags[cacheReadOnly]))
+	if isCacheReadOnly && cacheTTLDuration <= 0 {
+		// a cache TTL of 0 removes the on-disk caches
+		log.Printf("--%s requires --%s to be greater than 0", cacheReadOnly, cacheTTL)
+		os.Exit(1)
+	}
+	var selectorOpts []selector.Option
+	if offeringsTTLHours := cli.IntMe(flags[offeringsTTL]); offeringsTTLHours != nil {
+		selectorOpts = append(selectorOpts, selector.WithOfferingsCacheTTL(time.Hour*time.Duration(*offeringsTTLHours)))
+	}
+	var apiCallBudget *awsapi.Budget
+	if maxCalls := cli.IntMe(flags[maxAPICalls]); maxCalls != nil {
+		if *maxCalls <= 0 {
+			fmt.Printf("--%s must be greater than 0", maxAPICalls)
+			os.Exit(1)
+		}
+		// shared by the selectors of every region and the other clients created from cfg, such as the CloudWatch, Auto
+		// Scaling, SNS, Compute Optimizer, SSM, and S3 clients
+		apiCallBudget = awsapi.NewBudget(*maxCalls)
+		cfg.APIOptions = append(cfg.APIOptions, apiCallBudget.AddMiddleware)
+	}
+	var carbonScores map[ec2types.InstanceType]float64
+	if carbonDataPath := cli.StringMe(flags[carbonData]); carbonDataPath != nil {
+		carbonScores, err = selector.LoadCarbonData(*carbonDataPath)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the carbon data file: %v", err)
+			os.Exit(1)
+		}
+	}
+	var orderPreferences []ec2types.InstanceType
+	if orderFilePath := cli.StringMe(flags[orderFile]); orderFilePath != nil {
+		orderPreferences, err = selector.LoadOrderPreferences(*orderFilePath)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the order preference file: %v", err)
+			os.Exit(1)
+		}
+	}
+	var customColumns []outputs.CustomColumn
+	if columnsFilePath := cli.StringMe(flags[columnsFile]); columnsFilePath != nil {
+		customColumns, err = outputs.LoadCustomColumns(*columnsFilePath)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the columns file: %v", err)
+			os.Exit(1)
+		}
+	}
+	var deprecatedInstanceTypes map[string]string
+	if deprecationsPath := cli.StringMe(flags[deprecations]); deprecationsPath != nil {
+		deprecatedInstanceTypes, err = selector.LoadDeprecations(*deprecationsPath)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the deprecations file: %v", err)
+			os.Exit(1)
+		}
+	}
+	var customPricing *ec2pricing.FilePricing
+	if pricingFilePath := cli.StringMe(flags[pricingFile]); pricingFilePath != nil {
+		customPricing, err = ec2pricing.LoadFilePricing(*pricingFilePath, nil)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the pricing file: %v", err)
+			os.Exit(1)
+		}
+	}
+	onDemandDiscountPercent := cli.Float64Me(flags[priceDiscount])
+	// the selectors of the regions filtered by --all-regions are created concurrently and saved by shutdown
+	var regionalSelectorsMu sync.Mutex
+	var regionalSelectors []*selector.Selector
+	newRegionalSelector := func(ctx context.Context, selectorRegion string) (*selector.Selector, error) {
+		regionalCfg := cfg.Copy()
+		regionalCfg.Region = selectorRegion
+		regionalSelector, err := selector.NewWithCache(ctx, regionalCfg, cacheTTLDuration, *cli.StringMe(flags[cacheDir]), selectorOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if onDemandDiscountPercent != nil {
+			regionalSelector.EC2Pricing, err = ec2pricing.NewDiscountedPricing(regionalSelector.EC2Pricing, *onDemandDiscountPercent)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --%s: %w", priceDiscount, err)
+			}
+		}
+		if customPricing != nil {
+			// prices missing from the pricing file are retrieved from the public pricing of the region, the prices of the
+			// pricing file are already negotiated so the discount only applies to the public prices
+			regionalSelector.EC2Pricing = ec2pricing.NewFilePricing(customPricing.OnDemandPrices, customPricing.SpotPrices, regionalSelector.EC2Pricing)
+		}
+		regionalSelector.SetCacheReadOnly(isCacheReadOnly)
+		regionalSelector.CarbonData = carbonScores
+		regionalSelector.Deprecations = deprecatedInstanceTypes
+		if aws.ToBool(cli.BoolMe(flags[debug])) {
+			debugLogger := log.New(os.Stdout, time.Now().UTC().Format(time.RFC3339)+" DEBUG ", 0)
+			regionalSelector.SetLogger(debugLogger)
+		}
+		regionalSelectorsMu.Lock()
+		defer regionalSelectorsMu.Unlock()
+		regionalSelectors = append(regionalSelectors, regionalSelector)
+		return regionalSelector, nil
+	}
+	var manifestCaches []manifest.Cache
+	if flags[emitManifest] != nil && cacheTTLDuration > 0 {
+		// the cache files are read before the selector loads them since expired caches are removed when they are loaded
+		manifestCaches = manifest.CacheFiles(*cli.StringMe(flags[cacheDir]), cfg.Region)
+	}
+	instanceSelector, err := newRegionalSelector(ctx, cfg.Region)
 	if err != nil {
 		fmt.Printf("An error occurred when initializing the ec2 selector: %v", err)
 		os.Exit(1)
 	}
-	if flags[debug] != nil {
-		debugLogger := log.New(os.Stdout, time.Now().UTC().Format(time.RFC3339)+" DEBUG ", 0)
-		instanceSelector.SetLogger(debugLogger)
+	if onDemandDiscountPercent != nil {
+		log.Printf("On-demand prices include the --%s discount of %g%%", priceDiscount, *onDemandDiscountPercent)
+	}
+	shutdown := sync.OnceFunc(func() {
+		for _, regionalSelector := range regionalSelectors {
+			// each cache which failed to save is logged separately, the others are still saved
+			for _, err := range multierr.Errors(regionalSelector.Save()) {
+				log.Printf("There was an error saving caches: %v", err)
+			}
+		}
+		if tracer != nil {
+			tracer.LogSummary()
+		}
+		if apiCallBudget != nil {
+			if skipped := apiCallBudget.SkippedSummary(); skipped != "" {
+				log.Printf("The --%s budget was spent, these API calls were skipped: %s", maxAPICalls, skipped)
+			}
+		}
+	})
+	interrupted := registerShutdown(cancelAPICalls, shutdown)
+	// the in-flight API calls fail once a signal cancels them or --timeout is exceeded, the caches are still saved before exiting
+	// an interrupted command exits with interruptedExitCode even if it finished its work after the signal
+	exitIfInterrupted := func() {
+		select {
+		case <-interrupted:
+			shutdown()
+			os.Exit(interruptedExitCode)
+		default:
+		}
+	}
+	exit := func(code int) {
+		exitIfInterrupted()
+		if code != watchChangedExitCode && errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			fmt.Printf("\nThe command did not finish within the --%s of %s", timeout, commandTimeout)
+			shutdown()
+			os.Exit(timedOutExitCode)
+		}
+		os.Exit(code)
+	}
+
+	switch cli.InvokedCommand() {
+	case regionsList:
+		regions, err := instanceSelector.Regions(ctx)
+		if err == nil {
+			err = printRows(regions, regionsList, cli.StringMe(flags[output]), []string{"Region", "Opt-In Status"}, func(region selector.Region) []string {
+				return []string{region.RegionName, region.OptInStatus}
+			})
+		}
+		if err != nil {
+			fmt.Printf("An error occurred when listing regions: %v", err)
+			exit(1)
+		}
+		exitIfInterrupted()
+		return
+	case zonesList:
+		zones, err := instanceSelector.Zones(ctx)
+		if err == nil {
+			err = printRows(zones, zonesList, cli.StringMe(flags[output]), []string{"Zone Name", "Zone ID", "Zone Type", "Opt-In Status"}, func(zone selector.Zone) []string {
+				return []string{zone.ZoneName, zone.ZoneID, zone.ZoneType, zone.OptInStatus}
+			})
+		}
+		if err != nil {
+			fmt.Printf("An error occurred when listing zones: %v", err)
+			exit(1)
+		}
+		exitIfInterrupted()
+		return
+	case refreshCmdName:
+		if cacheTTLDuration <= 0 || isCacheReadOnly {
+			fmt.Printf("The %s command requires --%s to be greater than 0 and --%s to be unset", refreshCmdName, cacheTTL, cacheReadOnly)
+			exit(1)
+		}
+		refreshed, err := refreshCaches(ctx, instanceSelector, *cli.StringMe(flags[cacheDir]), spotPricingDaysBack)
+		if err != nil {
+			fmt.Printf("An error occurred when refreshing the caches: %v", err)
+			exit(1)
+		}
+		if !refreshed {
+			log.Printf("Another refresh of %s is in progress, skipping this refresh", *cli.StringMe(flags[cacheDir]))
+		}
+		exitIfInterrupted()
+		return
+	case pricingCmdName:
+		instanceTypes := []ec2types.InstanceType{}
+		for _, instanceType := range cli.InvokedCommandArgs() {
+			instanceTypes = append(instanceTypes, ec2types.InstanceType(instanceType))
+		}
+		var availabilityZones []string
+		if zonesFilter := cli.FiltersMe(flags).AvailabilityZones; zonesFilter != nil {
+			availabilityZones = *zonesFilter
+		}
+		prices, err := instanceSelector.Prices(ctx, instanceTypes, availabilityZones)
+		if err == nil {
+			err = printRows(prices, pricingCmdName, cli.StringMe(flags[output]), []string{"Instance Type", "On-Demand Price/Hr", "Spot Price/Hr (30 Day Avg)"}, func(price selector.InstanceTypePrices) []string {
+				onDemandPrice := "-"
+				if price.OnDemandPricePerHour != nil {
+					onDemandPrice = "$" + strconv.FormatFloat(*price.OnDemandPricePerHour, 'f', -1, 64)
+				}
+				return []string{string(price.InstanceType), onDemandPrice, formatSpotPrices(price)}
+			})
+		}
+		if err != nil {
+			fmt.Printf("An error occurred when looking up prices: %v", err)
+			exit(1)
+		}
+		exitIfInterrupted()
+		return
+	}
+
+	// Filters are generated from the selector.Filters struct tags, the remaining fields are set from configuration and sub-command flags
+	filters := cli.FiltersMe(flags)
+	filters.Region = cli.StringMe(flags[region])
+	filters.MaxResults = cli.IntMe(flags[maxResults])
+	filters.AllowList = joinRegexes(filters.AllowList, cli.RegexMe(flags[allowListFile]))
+	filters.DenyList = joinRegexes(filters.DenyList, cli.RegexMe(flags[denyListFile]))
+	filters.LaunchTemplateID = cli.StringMe(flags[launchTemplateID])
+	filters.LaunchTemplateVersion = cli.StringMe(flags[launchTemplateVersion])
+	var compareInstanceTypes []ec2types.InstanceType
+	if cli.InvokedCommand() == compare {
+		instanceTypeNames := cli.InvokedCommandArgs()
+		for _, instanceType := range instanceTypeNames {
+			compareInstanceTypes = append(compareInstanceTypes, ec2types.InstanceType(instanceType))
+		}
+		filters.InstanceTypes = &instanceTypeNames
+	}
+	if filters.CPUArchitecture != nil && *filters.CPUArchitecture == selector.ArchitectureTypeAuto {
+		hostArchitecture, err := getHostArchitecture()
+		if err != nil {
+			fmt.Printf("An error occurred when detecting the CPU architecture: %v", err)
+			exit(1)
+		}
+		log.Printf("--cpu-architecture auto selected %s, the CPU architecture of this machine", hostArchitecture)
+		filters.CPUArchitecture = &hostArchitecture
+	}
+
+	// filter groups from the filters file are OR'd together, flags apply to every group
+	var filterGroups []selector.Filters
+	if filtersFilePath := cli.StringMe(flags[filtersFile]); filtersFilePath != nil {
+		fileFilterSet, err := selector.LoadFilterSetFile(*filtersFilePath)
+		if err != nil {
+			fmt.Printf("An error occurred when loading the filters file: %v", err)
+			exit(1)
+		}
+		if len(fileFilterSet.Groups) == 1 {
+			filters = filters.Merge(fileFilterSet.Groups[0])
+		} else {
+			if cli.InvokedCommand() == watch {
+				fmt.Printf("Filter groups (AnyOf) in the filters file are not supported by the %s command", watch)
+				exit(1)
+			}
+			for _, group := range fileFilterSet.Groups {
+				filterGroups = append(filterGroups, filters.Merge(group))
+			}
+			if filters.MaxResults == nil {
+				filters.MaxResults = fileFilterSet.MaxResults
+			}
+		}
+	}
+
+	var rightsizeRecommendation *rightsizing.Recommendation
+	if cli.InvokedCommand() == rightsize {
+		filters, rightsizeRecommendation, err = rightsizeFilters(ctx, cfg, filters, cli.StringMe(flags[rightsizeInstanceID]), cli.StringMe(flags[rightsizeASGName]), *cli.DurationMe(flags[rightsizeLookback]), *cli.Float64Me(flags[rightsizeHeadroom]))
+		if err != nil {
+			fmt.Printf("An error occurred when rightsizing: %v", err)
+			exit(1)
+		}
 	}
-	shutdown := func() {
-		if err := instanceSelector.Save(); err != nil {
-			log.Printf("There was an error saving pricing caches: %v", err)
+
+	if scaleFromInstanceType := cli.StringMe(flags[scaleFrom]); scaleFromInstanceType != nil {
+		filters, err = scaleFilters(ctx, instanceSelector, filters, ec2types.InstanceType(*scaleFromInstanceType), *cli.IntMe(flags[scaleSteps]), aws.ToBool(cli.BoolMe(flags[scaleEquivalents])))
+		if err != nil {
+			fmt.Printf("An error occurred when scaling %s: %v", *scaleFromInstanceType, err)
+			exit(1)
 		}
 	}
-	registerShutdown(shutdown)
+
+	if aws.ToBool(filters.HibernationSupported) || aws.ToBool(filters.HibernationStrict) {
+		log.Printf("Hibernation also requires an encrypted EBS root volume large enough to store the RAM of the instance and a supported AMI")
+	}
 
 	sortField := cli.StringMe(flags[sortBy])
 	lowercaseSortField := strings.ToLower(*sortField)
-	outputFlag := cli.StringMe(flags[output])
-	if outputFlag != nil && (*outputFlag == tableWideOutput || *outputFlag == bubbleTeaOutput) {
-		// If output type is `table-wide`, simply print both prices for better comparison,
+	var outputFormat *outputs.Format
+	if outputFlag := cli.StringMe(flags[output]); outputFlag != nil {
+		format, err := outputDispatcher.Format(*outputFlag)
+		if err != nil {
+			fmt.Printf("An error occurred when selecting the output format: %v", err)
+			exit(1)
+		}
+		outputFormat = &format
+	}
+	if cli.InvokedCommand() == compare && outputFormat == nil && flags[verbose] == nil {
+		outputFormat = &outputs.CompareTableFormat
+	}
+	if outputFormat != nil && outputFormat.Interactive && !interactiveOutputAvailable {
+		fmt.Printf("--%s %s is not available in this build of %s, it was built with the notui tag", output, outputFormat.Name, binName)
+		exit(1)
+	}
+	if outputFormat != nil && outputFormat.Interactive && !isInteractiveTerminal() {
+		// CI jobs and piped output can't run the interactive table
+		log.Printf("--%s %s requires a terminal, falling back to --%s %s", output, outputFormat.Name, output, outputs.TableWideFormat)
+		format, _ := outputDispatcher.Format(outputs.TableWideFormat)
+		outputFormat = &format
+	}
+	var outputDestination destination.Destination
+	isAppendingOutput := aws.ToString(cli.StringMe(flags[outputFileMode])) == appendOutputFile
+	if outputPath := cli.StringMe(flags[outputFile]); outputPath != nil {
+		if outputFormat != nil && outputFormat.Interactive {
+			fmt.Printf("--%s is not supported with --%s %s", outputFile, output, outputs.InteractiveFormat)
+			exit(1)
+		}
+		if outputFormat == nil && flags[verbose] == nil {
+			if format, ok := outputDispatcher.FormatForFile(*outputPath); ok {
+				outputFormat = &format
+			}
+		}
+		if isAppendingOutput && (flags[verbose] != nil || (outputFormat != nil && (outputFormat.Name == outputs.JSONFormat || outputFormat.Name == outputs.YAMLFormat))) {
+			// appending a second JSON array or YAML sequence would leave a file which can't be parsed
+			fmt.Printf("--%s %s is not supported by JSON or YAML outputs, use --%s %s to append one instance type per line", outputFileMode, appendOutputFile, output, outputs.NDJSONFormat)
+			exit(1)
+		}
+		outputDestination, err = newOutputDestination(cfg, *outputPath, isAppendingOutput, cli.StringMe(flags[s3SSE]), cli.StringMe(flags[s3SSEKMSKeyID]))
+		if err != nil {
+			fmt.Printf("An error occurred with --%s: %v", outputFile, err)
+			exit(1)
+		}
+	} else if flags[s3SSE] != nil || flags[s3SSEKMSKeyID] != nil || flags[outputFileMode] != nil {
+		fmt.Printf("--%s, --%s, and --%s require --%s", outputFileMode, s3SSE, s3SSEKMSKeyID, outputFile)
+		exit(1)
+	}
+	if aws.ToString(cli.StringMe(flags[layout])) == verticalLayout {
+		if outputFormat == nil && flags[verbose] == nil {
+			format, _ := outputDispatcher.Format(outputs.TableFormat)
+			outputFormat = &format
+		}
+		if outputFormat == nil || outputFormat.VerticalOutput == nil {
+			fmt.Printf("--%s %s is only supported by --%s %s or %s and the %s command", layout, verticalLayout, output, outputs.TableFormat, outputs.TableWideFormat, compare)
+			exit(1)
+		}
+		verticalFormat := *outputFormat
+		verticalFormat.Output = verticalFormat.VerticalOutput
+		outputFormat = &verticalFormat
+	}
+	ssmParameterName := cli.StringMe(flags[ssmParameter])
+	if ssmParameterName != nil && outputFormat != nil && outputFormat.Interactive {
+		fmt.Printf("--%s is not supported with --%s %s", ssmParameter, output, outputs.InteractiveFormat)
+		exit(1)
+	}
+	if aws.ToBool(cli.BoolMe(flags[ssmDryRun])) && ssmParameterName == nil {
+		fmt.Printf("--%s requires --%s", ssmDryRun, ssmParameter)
+		exit(1)
+	}
+	var manifestDestination destination.Destination
+	if manifestPath := cli.StringMe(flags[emitManifest]); manifestPath != nil {
+		if cli.InvokedCommand() == watch || cli.InvokedCommand() == rightsize {
+			fmt.Printf("--%s is not supported by the %s command", emitManifest, cli.InvokedCommand())
+			exit(1)
+		}
+		if outputFormat != nil && outputFormat.Interactive {
+			fmt.Printf("--%s is not supported with --%s %s", emitManifest, output, outputs.InteractiveFormat)
+			exit(1)
+		}
+		if aws.ToBool(cli.BoolMe(flags[allRegions])) {
+			fmt.Printf("--%s is not supported with --%s", emitManifest, allRegions)
+			exit(1)
+		}
+		manifestDestination, err = newOutputDestination(cfg, *manifestPath, false, nil, nil)
+		if err != nil {
+			fmt.Printf("An error occurred with --%s: %v", emitManifest, err)
+			exit(1)
+		}
+	}
+	isAllRegions := aws.ToBool(cli.BoolMe(flags[allRegions]))
+	if isAllRegions {
+		if cli.InvokedCommand() == watch || cli.InvokedCommand() == rightsize {
+			fmt.Printf("--%s is not supported by the %s command", allRegions, cli.InvokedCommand())
+			exit(1)
+		}
+		// the region of each instance type is displayed by the table outputs
+		if outputFormat == nil && flags[verbose] == nil {
+			format, _ := outputDispatcher.Format(outputs.TableFormat)
+			outputFormat = &format
+		}
+	}
+	isSummary := aws.ToBool(cli.BoolMe(flags[summary]))
+	if isSummary {
+		if outputFormat == nil && flags[verbose] == nil {
+			format, _ := outputDispatcher.Format(outputs.TableFormat)
+			outputFormat = &format
+		}
+		if outputFormat == nil || (outputFormat.Name != outputs.TableFormat && outputFormat.Name != outputs.TableWideFormat) {
+			fmt.Printf("--%s is only supported by --%s %s or %s", summary, output, outputs.TableFormat, outputs.TableWideFormat)
+			exit(1)
+		}
+		// the summary includes the price ranges of the matches
+		summaryFormat := *outputFormat
+		summaryFormat.RequiresPrices = true
+		outputFormat = &summaryFormat
+	}
+	if apiCallBudget != nil {
+		// retrieve the instance types before the prices so that the calls skipped once the budget is spent are pricing calls
+		if err := instanceSelector.HydrateCaches(ctx, selector.HydrateOptions{Caches: []string{selector.InstanceTypesCache}}, nil); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+	// the interactive output is displayed before the prices are retrieved unless the instance types are filtered or
+	// sorted by them, which requires the pricing caches to be hydrated first
+	isLazilyPriced := outputFormat != nil && outputFormat.Interactive && !isAllRegions && len(filterGroups) == 0 &&
+		!requiresPricing(filters, lowercaseSortField, nil)
+	if outputFormat != nil && outputFormat.RequiresPrices && !isLazilyPriced {
+		// If the output format displays both prices, fetch both for better comparison,
 		//   even if the actual filter is applied on any one of those based on usage class
 		// Save time by hydrating all caches in parallel
-		if err := hydrateCaches(ctx, *instanceSelector); err != nil {
+		if err := instanceSelector.HydrateCaches(ctx, selector.HydrateOptions{Caches: filterCaches, SpotPricingDaysBack: spotPricingDaysBack}, nil); err != nil {
 			log.Printf("%v", err)
 		}
 	} else {
 		// Else, if price filters are applied, only hydrate the respective cache as we don't have to print the prices
-		if flags[pricePerHour] != nil {
-			if flags[usageClass] == nil || *cli.StringMe(flags[usageClass]) == "on-demand" {
+		if hasPriceFilter(filters) {
+			if filters.UsageClass == nil || *filters.UsageClass == ec2types.UsageClassTypeOnDemand {
 				if instanceSelector.EC2Pricing.OnDemandCacheCount() == 0 {
 					if err := instanceSelector.EC2Pricing.RefreshOnDemandCache(ctx); err != nil {
 						log.Printf("There was a problem refreshing the on-demand pricing cache: %v", err)
 					}
 				}
-			} else {
+			} else if *filters.UsageClass == ec2types.UsageClassTypeSpot {
 				if instanceSelector.EC2Pricing.SpotCacheCount() == 0 {
-					if err := instanceSelector.EC2Pricing.RefreshSpotCache(ctx, spotPricingDaysBack); err != nil {
+					if err := instanceSelector.RefreshSpotCacheForFilters(ctx, filters, spotPricingDaysBack); err != nil {
 						log.Printf("There was a problem refreshing the spot pricing cache: %v", err)
 					}
 				}
 			}
+			// capacity-block prices are retrieved per instance type while filtering
+		}
+
+		// spot savings are derived from both the on-demand and spot prices
+		if filters.SpotSavings != nil || lowercaseSortField == sorter.SpotSavings {
+			if instanceSelector.EC2Pricing.OnDemandCacheCount() == 0 {
+				if err := instanceSelector.EC2Pricing.RefreshOnDemandCache(ctx); err != nil {
+					log.Printf("There was a problem refreshing the on-demand pricing cache: %v", err)
+				}
+			}
+			if instanceSelector.EC2Pricing.SpotCacheCount() == 0 {
+				if err := instanceSelector.RefreshSpotCacheForFilters(ctx, filters, spotPricingDaysBack); err != nil {
+					log.Printf("There was a problem refreshing the spot pricing cache: %v", err)
+				}
+			}
 		}
 
 		// refresh appropriate caches if sorting by either spot or on demand pricing
 		if strings.Contains(lowercaseSortField, "price") {
 			if strings.Contains(lowercaseSortField, "spot") {
 				if instanceSelector.EC2Pricing.SpotCacheCount() == 0 {
-					if err := instanceSelector.EC2Pricing.RefreshSpotCache(ctx, spotPricingDaysBack); err != nil {
+					if err := instanceSelector.RefreshSpotCacheForFilters(ctx, filters, spotPricingDaysBack); err != nil {
 						log.Printf("There was a problem refreshing the spot pricing cache: %v", err)
 					}
 				}
@@ -333,116 +977,22 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		}
 	}
 
-	var cpuArchitectureFilterValue *ec2types.ArchitectureType
-
-	if arch, ok := flags[cpuArchitecture].(*string); ok && arch != nil {
-		value := ec2types.ArchitectureType(*arch)
-		cpuArchitectureFilterValue = &value
-	}
-
-	var cpuManufacturerFilterValue *selector.CPUManufacturer
-
-	if cpuMan, ok := flags[cpuManufacturer].(*string); ok && cpuMan != nil {
-		value := selector.CPUManufacturer(*cpuMan)
-		cpuManufacturerFilterValue = &value
-	}
-
-	var virtualizationTypeFilterValue *ec2types.VirtualizationType
-
-	if virtType, ok := flags[virtualizationType].(*string); ok && virtType != nil {
-		value := ec2types.VirtualizationType(*virtType)
-		virtualizationTypeFilterValue = &value
-	}
-
-	var deviceTypeFilterValue *ec2types.RootDeviceType
-
-	if rootDev, ok := flags[rootDeviceType].(*string); ok && rootDev != nil {
-		value := ec2types.RootDeviceType(*rootDev)
-		deviceTypeFilterValue = &value
-	}
-
-	var usageClassFilterValue *ec2types.UsageClassType
-
-	if useClass, ok := flags[usageClass].(*string); ok && useClass != nil {
-		value := ec2types.UsageClassType(*useClass)
-		usageClassFilterValue = &value
-	}
-
-	var hypervisorFilterValue *ec2types.InstanceTypeHypervisor
-
-	if hype, ok := flags[hypervisor].(*string); ok && hype != nil {
-		value := ec2types.InstanceTypeHypervisor(*hype)
-		hypervisorFilterValue = &value
-	}
-
-	filters := selector.Filters{
-		VCpusRange:                       cli.Int32RangeMe(flags[vcpus]),
-		MemoryRange:                      cli.ByteQuantityRangeMe(flags[memory]),
-		VCpusToMemoryRatio:               cli.Float64Me(flags[vcpusToMemoryRatio]),
-		CPUArchitecture:                  cpuArchitectureFilterValue,
-		CPUManufacturer:                  cpuManufacturerFilterValue,
-		GpusRange:                        cli.Int32RangeMe(flags[gpus]),
-		GpuMemoryRange:                   cli.ByteQuantityRangeMe(flags[gpuMemoryTotal]),
-		GPUManufacturer:                  cli.StringMe(flags[gpuManufacturer]),
-		GPUModel:                         cli.StringMe(flags[gpuModel]),
-		InferenceAcceleratorsRange:       cli.IntRangeMe(flags[inferenceAccelerators]),
-		InferenceAcceleratorManufacturer: cli.StringMe(flags[inferenceAcceleratorManufacturer]),
-		InferenceAcceleratorModel:        cli.StringMe(flags[inferenceAcceleratorModel]),
-		PlacementGroupStrategy:           cli.StringMe(flags[placementGroupStrategy]),
-		UsageClass:                       usageClassFilterValue,
-		RootDeviceType:                   deviceTypeFilterValue,
-		EnaSupport:                       cli.BoolMe(flags[enaSupport]),
-		EfaSupport:                       cli.BoolMe(flags[efaSupport]),
-		HibernationSupported:             cli.BoolMe(flags[hibernationSupport]),
-		Hypervisor:                       hypervisorFilterValue,
-		BareMetal:                        cli.BoolMe(flags[baremetal]),
-		Fpga:                             cli.BoolMe(flags[fpgaSupport]),
-		Burstable:                        cli.BoolMe(flags[burstSupport]),
-		Region:                           cli.StringMe(flags[region]),
-		AvailabilityZones:                cli.StringSliceMe(flags[availabilityZones]),
-		CurrentGeneration:                cli.BoolMe(flags[currentGeneration]),
-		MaxResults:                       cli.IntMe(flags[maxResults]),
-		NetworkInterfaces:                cli.Int32RangeMe(flags[networkInterfaces]),
-		NetworkPerformance:               cli.IntRangeMe(flags[networkPerformance]),
-		NetworkEncryption:                cli.BoolMe(flags[networkEncryption]),
-		IPv6:                             cli.BoolMe(flags[ipv6]),
-		AllowList:                        cli.RegexMe(flags[allowList]),
-		DenyList:                         cli.RegexMe(flags[denyList]),
-		InstanceTypeBase:                 cli.StringMe(flags[instanceTypeBase]),
-		Flexible:                         cli.BoolMe(flags[flexible]),
-		Service:                          cli.StringMe(flags[service]),
-		VirtualizationType:               virtualizationTypeFilterValue,
-		PricePerHour:                     cli.Float64RangeMe(flags[pricePerHour]),
-		InstanceStorageRange:             cli.ByteQuantityRangeMe(flags[instanceStorage]),
-		DiskType:                         cli.StringMe(flags[diskType]),
-		DiskEncryption:                   cli.BoolMe(flags[diskEncryption]),
-		NVME:                             cli.BoolMe(flags[nvme]),
-		EBSOptimized:                     cli.BoolMe(flags[ebsOptimized]),
-		EBSOptimizedBaselineBandwidth:    cli.ByteQuantityRangeMe(flags[ebsOptimizedBaselineBandwidth]),
-		EBSOptimizedBaselineThroughput:   cli.ByteQuantityRangeMe(flags[ebsOptimizedBaselineThroughput]),
-		EBSOptimizedBaselineIOPS:         cli.IntRangeMe(flags[ebsOptimizedBaselineIOPS]),
-		FreeTier:                         cli.BoolMe(flags[freeTier]),
-		AutoRecovery:                     cli.BoolMe(flags[autoRecovery]),
-		DedicatedHosts:                   cli.BoolMe(flags[dedicatedHosts]),
-		Generation:                       cli.IntRangeMe(flags[generation]),
-	}
-
 	if flags[verbose] != nil {
 		resultsOutputFn = outputs.VerboseInstanceTypeOutput
 		transformedFilters, err := instanceSelector.AggregateFilterTransform(ctx, filters)
 		if err != nil {
 			fmt.Printf("An error occurred while transforming the aggregate filters")
-			os.Exit(1)
+			exit(1)
 		}
 		filtersJSON, err := filters.MarshalIndent("", "    ")
 		if err != nil {
 			fmt.Printf("An error occurred when printing filters due to --verbose being specified: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		transformedFiltersJSON, err := transformedFilters.MarshalIndent("", "    ")
 		if err != nil {
 			fmt.Printf("An error occurred when printing aggregate filters due to --verbose being specified: %v", err)
-			os.Exit(1)
+			exit(1)
 		}
 		log.Println("\n\n\"Filters\":", string(filtersJSON))
 		if string(transformedFiltersJSON) != string(filtersJSON) {
@@ -450,15 +1000,68 @@ Full docs can be found at github.com/aws/amazon-` + binName
 		} else {
 			log.Println("There were no transformations on the filters to display")
 		}
+		for i, group := range filterGroups {
+			groupJSON, err := group.MarshalIndent("", "    ")
+			if err != nil {
+				fmt.Printf("An error occurred when printing filter groups due to --verbose being specified: %v", err)
+				exit(1)
+			}
+			log.Printf("\n\n\"Filter Group %d\": %s", i+1, string(groupJSON))
+		}
+	}
+
+	if cli.InvokedCommand() == watch {
+		notifiers := []notify.Notifier{}
+		if webhookURL := cli.StringMe(flags[notifyWebhook]); webhookURL != nil {
+			notifiers = append(notifiers, notify.NewWebhook(*webhookURL))
+		}
+		if topicARN := cli.StringMe(flags[notifySNSTopic]); topicARN != nil {
+			// publish in the resolved region of the config, or in the topic's region if the ARN has one since it may be
+			// different from the region being watched
+			topic, _ := arn.Parse(*topicARN)
+			snsClient := sns.NewFromConfig(cfg, func(o *sns.Options) {
+				if topic.Region != "" {
+					o.Region = topic.Region
+				}
+			})
+			notifiers = append(notifiers, notify.NewSNS(snsClient, *topicARN))
+		}
+		changed, err := runWatch(ctx, *instanceSelector, filters, *cli.DurationMe(flags[watchInterval]), cli.StringMe(flags[watchStateFile]), aws.ToBool(cli.BoolMe(flags[exitOnChange])), notifiers)
+		shutdown()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Printf("An error occurred when watching instance types: %v", err)
+			exit(1)
+		}
+		if changed {
+			exit(watchChangedExitCode)
+		}
+		exitIfInterrupted()
+		return
 	}
 
 	// fetch instance types without truncating results
 	prevMaxResults := filters.MaxResults
 	filters.MaxResults = nil
-	instanceTypesDetails, err := instanceSelector.FilterVerbose(ctx, filters)
+	var instanceTypesDetails []*instancetypes.Details
+	if isAllRegions {
+		instanceTypesDetails, err = filterAllRegions(ctx, instanceSelector, filters, filterGroups, newRegionalSelector, requiresPricing(filters, lowercaseSortField, outputFormat), spotPricingDaysBack)
+	} else if len(filterGroups) > 0 {
+		instanceTypesDetails, err = instanceSelector.FilterGroupsVerbose(ctx, selector.FilterSet{Groups: filterGroups})
+	} else {
+		instanceTypesDetails, err = instanceSelector.FilterVerbose(ctx, filters)
+	}
 	if err != nil {
 		fmt.Printf("An error occurred when filtering instance types: %v", err)
-		os.Exit(1)
+		exit(1)
+	}
+	if aws.ToBool(cli.BoolMe(flags[stats])) {
+		if len(filterGroups) > 0 {
+			log.Printf("--%s is not supported with filter groups", stats)
+		} else if isAllRegions {
+			log.Printf("--%s is not supported with --%s", stats, allRegions)
+		} else {
+			printFilterStats(ctx, instanceSelector, filters)
+		}
 	}
 
 	// sort instance types
@@ -466,111 +1069,670 @@ Full docs can be found at github.com/aws/amazon-` + binName
 	instanceTypesDetails, err = sorter.Sort(instanceTypesDetails, *sortField, *sortDirection)
 	if err != nil {
 		fmt.Printf("Sorting error: %v", err)
-		os.Exit(1)
+		exit(1)
+	}
+	isPreviousGenerationIncluded := aws.ToBool(cli.BoolMe(flags[includePreviousGeneration]))
+	if isPreviousGenerationIncluded && *sortField == instanceNamePath {
+		instanceTypesDetails = sorter.PreviousGenerationLast(instanceTypesDetails)
+	}
+	if len(orderPreferences) > 0 {
+		instanceTypesDetails = sorter.PreferredFirst(instanceTypesDetails, orderPreferences)
+	}
+	if len(compareInstanceTypes) > 0 {
+		instanceTypesDetails = sorter.PreferredFirst(instanceTypesDetails, compareInstanceTypes)
+		logUnmatchedInstanceTypes(compareInstanceTypes, instanceTypesDetails)
+	}
+
+	if rightsizeRecommendation != nil && cli.StringMe(flags[rightsizeCrossCheck]) != nil {
+		if err := crossCheckRightsizing(ctx, rightsizing.NewComputeOptimizerClient(cfg), *rightsizeRecommendation, instanceTypesDetails); err != nil {
+			log.Printf("Unable to cross-check with AWS Compute Optimizer: %v", err)
+		}
+	}
+
+	// display the dedicated EBS columns in the wide outputs when they were filtered on
+	var extraColumns []string
+	if filters.EBSOptimizedBaselineBandwidth != nil || filters.EBSOptimizedBaselineThroughput != nil || filters.EBSOptimizedBaselineIOPS != nil {
+		extraColumns = outputs.EBSColumns
+	}
+	if isPreviousGenerationIncluded {
+		extraColumns = append(slices.Clip(extraColumns), outputs.PrevGenColumn)
+	}
+	// display the network cards and ENA queues in the wide outputs when they were filtered on
+	if filters.NetworkCards != nil || filters.ENAQueuesPerInterface != nil || filters.ENAQueues != nil {
+		extraColumns = append(slices.Clip(extraColumns), outputs.NetworkColumns...)
+	}
+	// display the normalization factor units in the wide outputs when they are sorted by
+	if lowercaseSortField == sorter.NFU {
+		extraColumns = append(slices.Clip(extraColumns), outputs.NFUColumn)
+	}
+	// display the monthly and annual prices in the wide outputs when they were filtered on or the hours per month were set
+	if filters.PricePerMonth != nil || filters.HoursPerMonth != nil {
+		extraColumns = append(slices.Clip(extraColumns), outputs.PricePerMonthColumns...)
+	}
+	if filters.PricePerYear != nil || filters.HoursPerMonth != nil {
+		extraColumns = append(slices.Clip(extraColumns), outputs.PricePerYearColumns...)
+	}
+
+	// list the availability zones of the region each instance type is offered in when they were not filtered on and the
+	// output displays them, --verbose prints the JSON output only when no --output is given
+	if (outputFormat == nil && flags[verbose] != nil) || (outputFormat != nil && outputFormat.RequiresZones) {
+		if err := instanceSelector.AddOfferedZones(ctx, instanceTypesDetails); err != nil {
+			log.Printf("Unable to retrieve the availability zones the instance types are offered in: %v", err)
+		}
+	}
+
+	if aws.ToBool(cli.BoolMe(flags[sageMakerNames])) {
+		for _, instanceTypeDetails := range instanceTypesDetails {
+			instanceTypeDetails.InstanceType = ec2types.InstanceType(selector.SageMakerInstanceTypeName(string(instanceTypeDetails.InstanceType)))
+		}
 	}
 
 	// handle output format
 	var itemsTruncated int
 	var instanceTypes []string
-	if outputFlag != nil && *outputFlag == bubbleTeaOutput {
-		p := tea.NewProgram(outputs.NewBubbleTeaModel(instanceTypesDetails), tea.WithMouseCellMotion())
-		if _, err := p.Run(); err != nil {
-			fmt.Printf("An error occurred when starting bubble tea: %v", err)
-			os.Exit(1)
+	// isStreamed is true when the output was already written as it was formatted
+	var isStreamed bool
+	// the SSM parameter stores all of the matches, not only those which are printed
+	allInstanceTypesDetails := instanceTypesDetails
+	if outputFormat != nil && outputFormat.Interactive {
+		if err := runInteractiveOutput(ctx, instanceSelector, filters, instanceTypesDetails, extraColumns, customColumns, isLazilyPriced, spotPricingDaysBack); err != nil {
+			fmt.Printf("An error occurred when running the interactive output: %v", err)
+			exit(1)
 		}
 
 		shutdown()
+		exitIfInterrupted()
 		return
 	} else {
 		// handle regular output modes
 
 		// truncate instance types based on user passed in maxResults
+		matchedInstanceTypesDetails := instanceTypesDetails
+		if outputFormat != nil && outputFormat.PerFamily {
+			instanceTypesDetails = outputs.FirstOfEachFamily(instanceTypesDetails)
+		}
 		instanceTypesDetails, itemsTruncated = truncateResults(prevMaxResults, instanceTypesDetails)
 		if len(instanceTypesDetails) == 0 {
 			log.Println("The criteria was too narrow and returned no valid instance types. Consider broadening your criteria so that more instance types are returned.")
-			os.Exit(1)
+			if aws.ToBool(cli.BoolMe(flags[suggest])) && len(filterGroups) == 0 && !isAllRegions {
+				printSuggestions(ctx, instanceSelector, filters)
+			}
+			exit(1)
 		}
 
-		// format instance types for output
-		outputFn := getOutputFn(outputFlag, selector.InstanceTypesOutputFn(resultsOutputFn))
-		instanceTypes = outputFn(instanceTypesDetails)
+		// format instance types for output, formats which can be streamed are written line by line to stdout and files
+		// rather than formatted in memory, S3 objects are put whole
+		_, isFileDestination := outputDestination.(*destination.File)
+		if outputFormat != nil && outputFormat.StreamOutput != nil && !isSummary && (outputDestination == nil || isFileDestination) {
+			if err := streamOutput(*outputFormat, outputDestination, instanceTypesDetails); err != nil {
+				fmt.Printf("An error occurred when writing the output: %v", err)
+				exit(1)
+			}
+			isStreamed = true
+		} else if outputFormat != nil {
+			instanceTypes = outputFormat.Output(instanceTypesDetails, extraColumns, customColumns)
+			if isSummary {
+				instanceTypes = append(instanceTypes, outputs.SummaryOutput(matchedInstanceTypesDetails, len(instanceTypesDetails))...)
+			}
+		} else {
+			instanceTypes = resultsOutputFn(instanceTypesDetails)
+		}
 	}
 
-	for _, instanceType := range instanceTypes {
-		fmt.Println(instanceType)
+	if isStreamed {
+		if outputDestination != nil {
+			log.Printf("Wrote the output of %d instance types to %s", len(instanceTypesDetails), outputDestination)
+		}
+	} else if outputDestination != nil {
+		if file, ok := outputDestination.(*destination.File); ok && file.Append && !file.IsEmpty() &&
+			outputFormat != nil && outputFormat.Name == outputs.CSVFormat && len(instanceTypes) > 0 {
+			// the file already starts with the header of the columns
+			instanceTypes = instanceTypes[1:]
+		}
+		outputData := strings.Builder{}
+		for _, instanceType := range instanceTypes {
+			outputData.WriteString(instanceType + "\n")
+		}
+		if err := outputDestination.Write(ctx, []byte(outputData.String())); err != nil {
+			fmt.Printf("An error occurred when writing the output: %v", err)
+			exit(1)
+		}
+		log.Printf("Wrote the output of %d instance types to %s", len(instanceTypesDetails), outputDestination)
+	} else {
+		for _, instanceType := range instanceTypes {
+			fmt.Println(instanceType)
+		}
+	}
+
+	if ssmParameterName != nil {
+		instanceTypeNames := []string{}
+		for _, instanceTypeDetails := range allInstanceTypesDetails {
+			instanceTypeNames = append(instanceTypeNames, string(instanceTypeDetails.InstanceType))
+		}
+		writer := ssmparameter.New(ssm.NewFromConfig(cfg), *ssmParameterName)
+		if aws.ToBool(cli.BoolMe(flags[ssmDryRun])) {
+			command, err := writer.PutCommand(instanceTypeNames)
+			if err != nil {
+				fmt.Printf("An error occurred when storing the instance types in SSM Parameter Store: %v", err)
+				exit(1)
+			}
+			log.Printf("Dry run, the instance types would be stored with: %s", command)
+		} else {
+			version, err := writer.Put(ctx, instanceTypeNames)
+			if err != nil {
+				fmt.Printf("An error occurred when storing the instance types in SSM Parameter Store: %v", err)
+				exit(1)
+			}
+			log.Printf("Stored %d instance types in SSM parameter %s (version %d)", len(instanceTypeNames), *ssmParameterName, version)
+		}
+	}
+
+	if manifestDestination != nil {
+		// the results were filtered without a maximum so that they could be sorted before they were truncated
+		manifestFilters := filters
+		manifestFilters.MaxResults = prevMaxResults
+		if err := writeManifest(ctx, manifestDestination, cfg.Region, manifestFilters, filterGroups, manifestCaches, instanceTypesDetails); err != nil {
+			fmt.Printf("An error occurred when writing the manifest: %v", err)
+			exit(1)
+		}
+		log.Printf("Wrote the manifest of the selection to %s", manifestDestination)
 	}
 
 	if itemsTruncated > 0 {
 		log.Printf("%d entries were truncated, increase --%s to see more", itemsTruncated, maxResults)
 	}
 	shutdown()
+	exitIfInterrupted()
 }
 
-func hydrateCaches(ctx context.Context, instanceSelector selector.Selector) (errs error) {
-	wg := &sync.WaitGroup{}
-	hydrateTasks := []func(*sync.WaitGroup) error{
-		func(waitGroup *sync.WaitGroup) error {
-			defer waitGroup.Done()
-			if instanceSelector.EC2Pricing.OnDemandCacheCount() == 0 {
-				if err := instanceSelector.EC2Pricing.RefreshOnDemandCache(ctx); err != nil {
-					return multierr.Append(errs, fmt.Errorf("there was a problem refreshing the on-demand pricing cache: %w", err))
-				}
-			}
-			return nil
-		},
-		func(waitGroup *sync.WaitGroup) error {
-			defer waitGroup.Done()
-			if instanceSelector.EC2Pricing.SpotCacheCount() == 0 {
-				if err := instanceSelector.EC2Pricing.RefreshSpotCache(ctx, spotPricingDaysBack); err != nil {
-					return multierr.Append(errs, fmt.Errorf("there was a problem refreshing the spot pricing cache: %w", err))
-				}
-			}
-			return nil
-		},
-		func(waitGroup *sync.WaitGroup) error {
-			defer waitGroup.Done()
-			if instanceSelector.InstanceTypesProvider.CacheCount() == 0 {
-				if _, err := instanceSelector.InstanceTypesProvider.Get(ctx, nil); err != nil {
-					return multierr.Append(errs, fmt.Errorf("there was a problem refreshing the instance types cache: %w", err))
-				}
+// streamOutput writes the instance types in the streamed output format to the file destination, or to stdout if there is
+// no destination.
+func streamOutput(format outputs.Format, outputDestination destination.Destination, instanceTypesDetails []*instancetypes.Details) error {
+	if outputDestination == nil {
+		return format.StreamOutput(os.Stdout, instanceTypesDetails)
+	}
+	file, ok := outputDestination.(*destination.File)
+	if !ok {
+		return fmt.Errorf("the output can not be streamed to %s", outputDestination)
+	}
+	return file.WriteStream(func(w io.Writer) error {
+		return format.StreamOutput(w, instanceTypesDetails)
+	})
+}
+
+// hostArchitectures are the EC2 architectures of the values of runtime.GOARCH which instance types are available for.
+var hostArchitectures = map[string]ec2types.ArchitectureType{
+	"amd64": ec2types.ArchitectureTypeX8664,
+	"arm64": ec2types.ArchitectureTypeArm64,
+	"386":   ec2types.ArchitectureTypeI386,
+}
+
+// getHostArchitecture returns the EC2 architecture of the machine running the CLI which --cpu-architecture auto selects,
+// i.e. arm64 on Graviton instances and Apple silicon. Macs are not mapped to the mac architectures since the instance
+// types are usually picked to build or run the same Linux binaries as the machine.
+func getHostArchitecture() (ec2types.ArchitectureType, error) {
+	architecture, ok := hostArchitectures[runtime.GOARCH]
+	if !ok {
+		return "", fmt.Errorf("no EC2 instance types have the %s architecture of this machine", runtime.GOARCH)
+	}
+	return architecture, nil
+}
+
+// markFlagRelationships registers the combinations of flags which would otherwise be silently ignored.
+func markFlagRelationships(cli *commandline.CommandLineInterface) {
+	cli.MarkFlagsMutuallyExclusive(instanceTypeBase, flexible)
+	cli.MarkFlagsMutuallyExclusive(verbose, output)
+	cli.MarkFlagConflicts(includePreviousGeneration, currentGeneration, "true")
+	cli.MarkFlagsMutuallyExclusive(includePreviousGeneration, excludeDeprecated)
+	cli.MarkFlagsMutuallyExclusive(rightsizeInstanceID, rightsizeASGName)
+	cli.MarkFlagRequires(spotDaysBack, usageClass, "spot")
+}
+
+// filterAllRegions filters instance types in every region enabled for the account with a selector of the region created by
+// newRegionalSelector, which hydrates the pricing caches of the region when requirePricing is true. Regions which could not
+// be filtered are logged.
+func filterAllRegions(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters, filterGroups []selector.Filters, newRegionalSelector selector.RegionalSelectorFn, requirePricing bool, spotPricingDaysBack int) ([]*instancetypes.Details, error) {
+	regions, err := instanceSelector.Regions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	regionNames := []string{}
+	for _, region := range regions {
+		regionNames = append(regionNames, region.RegionName)
+	}
+	filterSet := selector.FilterSet{Groups: filterGroups}
+	if len(filterSet.Groups) == 0 {
+		filterSet.Groups = []selector.Filters{filters}
+	}
+	instanceTypesDetails, err := selector.FilterRegionsVerbose(ctx, regionNames, func(ctx context.Context, region string) (*selector.Selector, error) {
+		regionalSelector, err := newRegionalSelector(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		if requirePricing {
+			if err := regionalSelector.HydrateCaches(ctx, selector.HydrateOptions{Caches: filterCaches, SpotPricingDaysBack: spotPricingDaysBack}, nil); err != nil {
+				log.Printf("%s: %v", region, err)
 			}
-			return nil
-		},
+		}
+		return regionalSelector, nil
+	}, filterSet)
+	if err != nil {
+		if errors.Is(err, selector.ErrInvalidFilterCombination) || len(instanceTypesDetails) == 0 {
+			return nil, err
+		}
+		for _, regionErr := range multierr.Errors(err) {
+			log.Printf("Unable to filter instance types in %v", regionErr)
+		}
+	}
+	return instanceTypesDetails, nil
+}
+
+// requiresPricing returns true when the instance types are filtered, sorted or displayed by price.
+func requiresPricing(filters selector.Filters, lowercaseSortField string, outputFormat *outputs.Format) bool {
+	return hasPriceFilter(filters) || filters.SpotSavings != nil || strings.Contains(lowercaseSortField, "price") ||
+		lowercaseSortField == sorter.SpotSavings || (outputFormat != nil && outputFormat.RequiresPrices)
+}
+
+// hasPriceFilter returns true when the instance types are filtered by their hourly, monthly or annual price.
+func hasPriceFilter(filters selector.Filters) bool {
+	return filters.PricePerHour != nil || filters.PricePerMonth != nil || filters.PricePerYear != nil
+}
+
+// rightsizeFilters merges the vCPU and memory ranges recommended from the CloudWatch utilization of an instance or
+// Auto Scaling group into filters, ranges which are already set in filters take precedence.
+func rightsizeFilters(ctx context.Context, cfg aws.Config, filters selector.Filters, instanceID *string, asgName *string, lookback time.Duration, headroomPercent float64) (selector.Filters, *rightsizing.Recommendation, error) {
+	if headroomPercent < 0 {
+		return filters, nil, fmt.Errorf("--%s must not be negative", rightsizeHeadroom)
+	}
+	rightsizer := rightsizing.New(cfg)
+	rightsizer.Lookback = lookback
+	rightsizer.Headroom = headroomPercent / 100
+	var recommendation *rightsizing.Recommendation
+	var err error
+	if instanceID != nil {
+		recommendation, err = rightsizer.ForInstance(ctx, *instanceID)
+	} else {
+		recommendation, err = rightsizer.ForAutoScalingGroup(ctx, *asgName)
+	}
+	if err != nil {
+		return filters, nil, err
 	}
-	wg.Add(len(hydrateTasks))
-	for _, task := range hydrateTasks {
-		go func() {
-			if err := task(wg); err != nil {
-				log.Printf("Hydrate task error: %v", err)
+	memoryUtilization := "not published by the CloudWatch agent, memory is not filtered"
+	if recommendation.MemoryUtilization != nil {
+		memoryUtilization = fmt.Sprintf("%.1f%% of %s, filtering %s - %s", *recommendation.MemoryUtilization, bytequantity.FromMiB(uint64(recommendation.MemoryMiB)).StringGiB(), recommendation.MemoryRange.LowerBound.StringGiB(), recommendation.MemoryRange.UpperBound.StringGiB())
+	}
+	log.Printf("Rightsizing %s: peak p95 CPU utilization %.1f%% of %d vCPUs, filtering %d - %d vCPUs; peak p95 memory utilization %s", recommendation.InstanceType, recommendation.CPUUtilization, recommendation.VCpus, recommendation.VCpusRange.LowerBound, recommendation.VCpusRange.UpperBound, memoryUtilization)
+	return filters.Merge(recommendation.Filters()), recommendation, nil
+}
+
+// scaleFilters restricts filters to the instance type which is steps sizes from instanceType within its family and,
+// when includeEquivalents is set, the instance types of other families with the same vCPUs and memory. If the instance
+// types filter is set, only the scaled instance types which it contains are kept.
+func scaleFilters(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters, instanceType ec2types.InstanceType, steps int, includeEquivalents bool) (selector.Filters, error) {
+	catalog, err := instanceSelector.Catalog(ctx)
+	if err != nil {
+		return filters, err
+	}
+	scaledInstanceType, err := catalog.ScaleInstanceType(instanceType, steps)
+	if err != nil {
+		return filters, err
+	}
+	instanceTypes := []string{string(scaledInstanceType.InstanceType)}
+	if includeEquivalents {
+		equivalents, err := catalog.EquivalentInstanceTypes(scaledInstanceType.InstanceType)
+		if err != nil {
+			return filters, err
+		}
+		for _, equivalent := range equivalents {
+			instanceTypes = append(instanceTypes, string(equivalent.InstanceType))
+		}
+	}
+	log.Printf("Scaling %s by %+d sizes to %s", instanceType, steps, scaledInstanceType.InstanceType)
+	if filters.InstanceTypes != nil {
+		// only the scaled instance types which are also in the instance types filter are selected
+		instanceTypes = slices.DeleteFunc(instanceTypes, func(scaledInstanceType string) bool {
+			return !slices.Contains(*filters.InstanceTypes, scaledInstanceType)
+		})
+	}
+	filters.InstanceTypes = &instanceTypes
+	return filters, nil
+}
+
+// crossCheckRightsizing annotates the instance types which AWS Compute Optimizer recommends for the rightsized resource
+// with their rank and logs the recommended instance types which deviate from the selection criteria.
+func crossCheckRightsizing(ctx context.Context, computeOptimizer rightsizing.ComputeOptimizerAPI, recommendation rightsizing.Recommendation, instanceTypesDetails []*instancetypes.Details) error {
+	coRecommendation, err := computeOptimizer.GetRecommendation(ctx, recommendation.ResourceARN)
+	if err != nil {
+		return err
+	}
+	deviations := rightsizing.CrossCheck(instanceTypesDetails, *coRecommendation)
+	log.Printf("AWS Compute Optimizer finding for %s: %s, recommended instance types: %s", coRecommendation.CurrentInstanceType, coRecommendation.Finding, formatComputeOptimizerOptions(coRecommendation.Options))
+	if len(deviations) == 0 {
+		log.Printf("All %d recommended instance types match the selection criteria", len(coRecommendation.Options))
+		return nil
+	}
+	log.Printf("%d of %d recommended instance types match the selection criteria, deviating: %s", len(coRecommendation.Options)-len(deviations), len(coRecommendation.Options), formatComputeOptimizerOptions(deviations))
+	return nil
+}
+
+// formatComputeOptimizerOptions formats Compute Optimizer options as a comma separated list of instance types and ranks.
+func formatComputeOptimizerOptions(options []rightsizing.ComputeOptimizerOption) string {
+	if len(options) == 0 {
+		return "none"
+	}
+	formatted := []string{}
+	for _, option := range options {
+		formatted = append(formatted, fmt.Sprintf("%s (rank %d)", option.InstanceType, option.Rank))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// printRows prints the rows as a table of their fields, the names of the rows (the first field) on one line with
+// --output one-line, or one JSON object per line with --output ndjson.
+func printRows[T any](rows []T, command string, outputFlag *string, header []string, fields func(T) []string) error {
+	switch aws.ToString(outputFlag) {
+	case "", outputs.TableFormat, outputs.TableWideFormat:
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(fields(row), "\t"))
+		}
+		return w.Flush()
+	case outputs.OneLineFormat:
+		names := []string{}
+		for _, row := range rows {
+			names = append(names, fields(row)[0])
+		}
+		fmt.Println(strings.Join(names, ","))
+	case outputs.NDJSONFormat:
+		for _, row := range rows {
+			rowJSON, err := json.Marshal(row)
+			if err != nil {
+				return err
 			}
-		}()
+			fmt.Println(string(rowJSON))
+		}
+	default:
+		return fmt.Errorf("--%s %s is not supported by the %s command, use %s, %s, or %s", output, *outputFlag, command, outputs.TableFormat, outputs.OneLineFormat, outputs.NDJSONFormat)
+	}
+	return nil
+}
+
+// logUnmatchedInstanceTypes logs the instance types which were passed to the compare command and are not in the results,
+// because they don't exist in the region or don't match the filters.
+func logUnmatchedInstanceTypes(instanceTypes []ec2types.InstanceType, instanceTypesDetails []*instancetypes.Details) {
+	matched := map[ec2types.InstanceType]bool{}
+	for _, instanceTypeDetails := range instanceTypesDetails {
+		matched[instanceTypeDetails.InstanceType] = true
+	}
+	unmatched := []string{}
+	for _, instanceType := range instanceTypes {
+		if !matched[instanceType] {
+			unmatched = append(unmatched, string(instanceType))
+		}
+	}
+	if len(unmatched) > 0 {
+		log.Printf("These instance types are not offered in the region or don't match the filters: %s", strings.Join(unmatched, ", "))
 	}
-	wg.Wait()
-	return errs
 }
 
-func getOutputFn(outputFlag *string, currentFn selector.InstanceTypesOutputFn) selector.InstanceTypesOutputFn {
-	outputFn := selector.InstanceTypesOutputFn(currentFn)
-	if outputFlag != nil {
-		switch *outputFlag {
-		case tableWideOutput:
-			return selector.InstanceTypesOutputFn(outputs.TableOutputWide)
-		case tableOutput:
-			return selector.InstanceTypesOutputFn(outputs.TableOutputShort)
-		case oneLine:
-			return selector.InstanceTypesOutputFn(outputs.OneLineOutput)
+// formatSpotPrices formats the spot prices of an instance type with their availability zones (Example: $0.0351 (us-east-1a)).
+func formatSpotPrices(prices selector.InstanceTypePrices) string {
+	if len(prices.SpotPricesPerHour) == 0 {
+		return "-"
+	}
+	formatted := []string{}
+	for _, zone := range prices.SpotZones() {
+		formatted = append(formatted, fmt.Sprintf("$%s (%s)", strconv.FormatFloat(prices.SpotPricesPerHour[zone], 'f', -1, 64), zone))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// checkForUpgrade prints whether a newer release is available and the highlights of its release notes.
+func checkForUpgrade(ctx context.Context, checker *upgrade.Checker) error {
+	result, err := checker.Check(ctx, versionID)
+	if errors.Is(err, upgrade.ErrUnversionedBuild) {
+		// development builds are not versioned so they cannot be compared with releases
+		fmt.Printf("The latest release of %s is %s, this is an unversioned build %s: %s\n", binName, result.Latest.TagName, versionID, result.Latest.HTMLURL)
+		return nil
+	} else if errors.Is(err, upgrade.ErrUnknownLatestVersion) {
+		fmt.Printf("Unable to tell whether the latest release %s of %s is newer than %s: %s\n", result.Latest.TagName, binName, versionID, result.Latest.HTMLURL)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !result.UpgradeAvailable {
+		fmt.Printf("%s %s is up to date\n", binName, versionID)
+		return nil
+	}
+	fmt.Printf("%s %s is available, this is %s: %s\n", binName, result.Latest.TagName, versionID, result.Latest.HTMLURL)
+	if highlights := result.Latest.Highlights(maxUpgradeHighlights); len(highlights) > 0 {
+		fmt.Println("Highlights:")
+		for _, highlight := range highlights {
+			fmt.Printf("  - %s\n", highlight)
 		}
 	}
-	return outputFn
+	fmt.Printf("Upgrade with \"brew upgrade %s\" or download the binary from the release page\n", binName)
+	return nil
+}
+
+// printFilterStats logs how many instance types each of the active filters excluded.
+func printFilterStats(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters) {
+	filterStats, err := instanceSelector.FilterStats(ctx, filters)
+	if err != nil {
+		log.Printf("Unable to collect filter statistics: %v", err)
+		return
+	}
+	log.Printf("%d of %d instance types matched all of the filters", filterStats.Matches, filterStats.Candidates)
+	for _, filterStat := range filterStats.Filters {
+		log.Printf("--%s excluded %d instance types after the other filters (%d in total)", filterStat.Flag, filterStat.Excluded, filterStat.ExcludedTotal)
+	}
 }
 
-func registerShutdown(shutdown func()) {
+// printSuggestions logs the filters which can be relaxed so that instance types match.
+func printSuggestions(ctx context.Context, instanceSelector *selector.Selector, filters selector.Filters) {
+	suggestions, err := instanceSelector.SuggestWiderCriteria(ctx, filters)
+	if err != nil {
+		log.Printf("Unable to suggest wider criteria: %v", err)
+		return
+	}
+	if len(suggestions) == 0 {
+		log.Println("No single filter can be relaxed to match an instance type.")
+		return
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	for _, suggestion := range suggestions {
+		log.Printf("Suggestion: %s", suggestion)
+	}
+}
+
+// registerShutdown cancels the in-flight API calls, such as paginated describes and pricing refreshes, when the process
+// receives SIGINT or SIGTERM and closes the returned channel so that the command saves the caches and exits once its calls
+// return. shutdown is called and the process exits if the command does not return within shutdownGracePeriod.
+func registerShutdown(cancel context.CancelFunc, shutdown func()) <-chan struct{} {
+	interrupted := make(chan struct{})
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigs
+		close(interrupted)
+		cancel()
+		time.Sleep(shutdownGracePeriod)
 		shutdown()
+		os.Exit(interruptedExitCode)
 	}()
+	return interrupted
+}
+
+// refreshCaches refreshes every cache of the selector and saves them to cacheDir while holding the refresh lock file of
+// cacheDir. false is returned without refreshing if another refresh holds the lock.
+func refreshCaches(ctx context.Context, instanceSelector *selector.Selector, cacheDir string, spotPricingDaysBack int) (bool, error) {
+	lock, err := filelock.TryAcquire(filepath.Join(cacheDir, refreshLockFile), refreshLockStaleAfter)
+	if errors.Is(err, filelock.ErrLocked) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := lock.Release(); err != nil {
+			log.Printf("%v", err)
+		}
+	}()
+	err = instanceSelector.HydrateCaches(ctx, selector.HydrateOptions{SpotPricingDaysBack: spotPricingDaysBack, Refresh: true}, func(progress selector.HydrateProgress) {
+		switch progress.Event {
+		case selector.HydrateCompleted:
+			fmt.Printf("Refreshed the %s cache with %d entries in %s\n", progress.Cache, progress.Count, progress.Elapsed.Round(time.Millisecond))
+		case selector.HydrateFailed:
+			fmt.Printf("Failed to refresh the %s cache after %s\n", progress.Cache, progress.Elapsed.Round(time.Millisecond))
+		}
+	})
+	return true, multierr.Append(err, instanceSelector.Save())
+}
+
+// errWatchChanged stops a watch when --exit-on-change is set and the matching instance types changed.
+var errWatchChanged = errors.New("matching instance types changed")
+
+// runWatch re-runs the filters every interval and prints the instance types added (+) and removed (-) between runs.
+// If a state file is passed in, the previous run is loaded from and each run is saved to it.
+// Changes are sent to each notifier, a failed notification is logged and does not stop the watch.
+// true is returned if exitOnChange is set and the matching instance types changed.
+func runWatch(ctx context.Context, instanceSelector selector.Selector, filters selector.Filters, interval time.Duration, stateFile *string, exitOnChange bool, notifiers []notify.Notifier) (bool, error) {
+	var previous []string
+	if stateFile != nil {
+		stateBytes, err := os.ReadFile(*stateFile)
+		if err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("unable to read watch state file %s: %w", *stateFile, err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(stateBytes, &previous); err != nil {
+				return false, fmt.Errorf("unable to parse watch state file %s: %w", *stateFile, err)
+			}
+		}
+	}
+	log.Printf("Watching for changes to the instance types matching your criteria every %s", interval)
+	err := instanceSelector.Watch(ctx, filters, interval, previous, func(instanceTypes []string, diff selector.InstanceTypesDiff) error {
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+		for _, instanceType := range diff.Added {
+			fmt.Printf("%s + %s\n", timestamp, instanceType)
+		}
+		for _, instanceType := range diff.Removed {
+			fmt.Printf("%s - %s\n", timestamp, instanceType)
+		}
+		if !diff.Changed() {
+			log.Printf("%s %d instance types match your criteria, no changes since the previous run", timestamp, len(instanceTypes))
+		} else if err := notify.NotifyAll(ctx, notifiers, notify.NewNotification(aws.ToString(filters.Region), diff)); err != nil {
+			log.Printf("There was a problem sending notifications: %v", err)
+		}
+		if stateFile != nil {
+			stateBytes, err := json.Marshal(instanceTypes)
+			if err != nil {
+				return err
+			}
+			if err := filelock.WriteFile(*stateFile, stateBytes, 0o600); err != nil {
+				return fmt.Errorf("unable to save watch state file %s: %w", *stateFile, err)
+			}
+		}
+		if exitOnChange && diff.Changed() {
+			return errWatchChanged
+		}
+		return nil
+	})
+	if errors.Is(err, errWatchChanged) {
+		return true, nil
+	}
+	return false, err
+}
+
+func validateWebhookURL(val interface{}) error {
+	if val == nil {
+		return nil
+	}
+	webhookURL, err := url.ParseRequestURI(*val.(*string))
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") {
+		return fmt.Errorf("error --%s must be an http or https URL", notifyWebhook)
+	}
+	return nil
+}
+
+// writeManifest writes the manifest of the instance types which were selected with the filters to the destination.
+func writeManifest(ctx context.Context, manifestDestination destination.Destination, region string, filters selector.Filters, filterGroups []selector.Filters, caches []manifest.Cache, instanceTypesDetails []*instancetypes.Details) error {
+	instanceTypeNames := []string{}
+	for _, instanceTypeDetails := range instanceTypesDetails {
+		instanceTypeNames = append(instanceTypeNames, string(instanceTypeDetails.InstanceType))
+	}
+	selectionManifest, err := manifest.New(versionID, os.Args[1:], region, filters, filterGroups, caches, instanceTypeNames)
+	if err != nil {
+		return err
+	}
+	manifestJSON, err := selectionManifest.MarshalIndent("", "    ")
+	if err != nil {
+		return fmt.Errorf("unable to convert the manifest to JSON: %w", err)
+	}
+	return manifestDestination.Write(ctx, append(manifestJSON, '\n'))
+}
+
+// newOutputDestination returns the destination of --output-file, an S3 object written with the AWS config if the path
+// is an S3 URI and otherwise a local file.
+func newOutputDestination(cfg aws.Config, outputPath string, appendToFile bool, sse *string, kmsKeyID *string) (destination.Destination, error) {
+	if !destination.IsS3URI(outputPath) {
+		if sse != nil || kmsKeyID != nil {
+			return nil, fmt.Errorf("--%s and --%s are only supported for S3 URIs", s3SSE, s3SSEKMSKeyID)
+		}
+		file, err := destination.NewFile(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		file.Append = appendToFile
+		return file, nil
+	}
+	if appendToFile {
+		return nil, fmt.Errorf("--%s %s is not supported for S3 URIs, objects are always replaced", outputFileMode, appendOutputFile)
+	}
+	return destination.NewS3(s3.NewFromConfig(cfg), outputPath, destination.S3Options{
+		ServerSideEncryption: aws.ToString(sse),
+		KMSKeyID:             aws.ToString(kmsKeyID),
+	})
+}
+
+func validateSSMParameterName(val interface{}) error {
+	if val == nil {
+		return nil
+	}
+	if err := ssmparameter.ValidateName(*val.(*string)); err != nil {
+		return fmt.Errorf("error --%s: %w", ssmParameter, err)
+	}
+	return nil
+}
+
+func validateSNSTopicARN(val interface{}) error {
+	if val == nil {
+		return nil
+	}
+	topicARN, err := arn.Parse(*val.(*string))
+	if err != nil || topicARN.Service != "sns" {
+		return fmt.Errorf("error --%s must be an SNS topic ARN (Example: arn:aws:sns:us-east-1:123456789012:my-topic)", notifySNSTopic)
+	}
+	return nil
+}
+
+// joinRegexes combines regexes into a single regex which matches if any of the regexes match.
+// nil regexes are skipped and nil is returned if all regexes are nil.
+func joinRegexes(regexes ...*regexp.Regexp) *regexp.Regexp {
+	var joined *regexp.Regexp
+	patterns := []string{}
+	for _, regex := range regexes {
+		if regex != nil {
+			joined = regex
+			patterns = append(patterns, fmt.Sprintf("(?:%s)", regex.String()))
+		}
+	}
+	if len(patterns) <= 1 {
+		return joined
+	}
+	return regexp.MustCompile(strings.Join(patterns, "|"))
 }
 
 func truncateResults(maxResults *int, instanceTypeInfoSlice []*instancetypes.Details) ([]*instancetypes.Details, int) {
@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/spf13/cobra"
+
+	commandline "github.com/aws/amazon-ec2-instance-selector/v3/pkg/cli"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/destination"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Helpers
+
+// parseFlags parses the args with the filter flags, the other flags the tests relate them to, and the flag relationships of the CLI registered.
+func parseFlags(t *testing.T, args ...string) error {
+	t.Helper()
+	cli := commandline.New(binName, "test short usage", "test long usage", "test examples", func(cmd *cobra.Command, args []string) {})
+	h.Ok(t, cli.FilterFlags())
+	cli.BoolFlag(includePreviousGeneration, nil, nil, "test include previous generation")
+	cli.ConfigIntFlag(spotDaysBack, nil, nil, "test spot days back")
+	markFlagRelationships(&cli)
+	os.Args = append([]string{binName}, args...)
+	_, err := cli.ParseAndValidateFlags()
+	return err
+}
+
+// describeInstanceTypesEC2 describes a fixed set of instance types.
+type describeInstanceTypesEC2 struct {
+	ec2.DescribeInstanceTypesAPIClient
+	InstanceTypes []ec2types.InstanceTypeInfo
+}
+
+func (m describeInstanceTypesEC2) DescribeInstanceTypes(_ context.Context, _ *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	return &ec2.DescribeInstanceTypesOutput{InstanceTypes: m.InstanceTypes}, nil
+}
+
+// newInstanceTypeInfo returns the info of an instance type with the vCPUs and memory in MiB.
+func newInstanceTypeInfo(instanceType ec2types.InstanceType, vcpus int32, memoryMiB int64) ec2types.InstanceTypeInfo {
+	return ec2types.InstanceTypeInfo{
+		InstanceType: instanceType,
+		VCpuInfo:     &ec2types.VCpuInfo{DefaultVCpus: aws.Int32(vcpus)},
+		MemoryInfo:   &ec2types.MemoryInfo{SizeInMiB: aws.Int64(memoryMiB)},
+	}
+}
+
+// Tests
+
+func TestFlagRelationships_BaseInstanceTypeAndFlexible(t *testing.T) {
+	h.Ok(t, parseFlags(t, "--base-instance-type", "m5.large"))
+	h.Ok(t, parseFlags(t, "--flexible"))
+
+	err := parseFlags(t, "--base-instance-type", "m5.large", "--flexible")
+	h.Nok(t, err)
+	h.Equals(t, "error: --base-instance-type and --flexible cannot be used together", err.Error())
+}
+
+func TestFlagRelationships_IncludePreviousGenerationAndCurrentGeneration(t *testing.T) {
+	h.Ok(t, parseFlags(t, "--include-previous-generation", "--current-generation=false"))
+
+	err := parseFlags(t, "--include-previous-generation", "--current-generation")
+	h.Nok(t, err)
+	h.Equals(t, "error: --include-previous-generation cannot be used with --current-generation true", err.Error())
+}
+
+func TestFlagRelationships_SpotDaysBack(t *testing.T) {
+	h.Ok(t, parseFlags(t, "--spot-days-back", "7", "--usage-class", "spot"))
+
+	err := parseFlags(t, "--spot-days-back", "7")
+	h.Nok(t, err)
+	h.Equals(t, "error: --spot-days-back requires --usage-class spot", err.Error())
+
+	err = parseFlags(t, "--spot-days-back", "7", "--usage-class", "on-demand")
+	h.Nok(t, err)
+	h.Equals(t, "error: --spot-days-back requires --usage-class spot, got on-demand", err.Error())
+}
+
+func TestScaleFilters(t *testing.T) {
+	ctx := context.Background()
+	instanceSelector := &selector.Selector{InstanceTypesProvider: instancetypes.NewProvider("us-east-1", describeInstanceTypesEC2{
+		InstanceTypes: []ec2types.InstanceTypeInfo{
+			newInstanceTypeInfo(ec2types.InstanceTypeM5Large, 2, 8192),
+			newInstanceTypeInfo(ec2types.InstanceTypeM5Xlarge, 4, 16384),
+			newInstanceTypeInfo(ec2types.InstanceTypeM6iXlarge, 4, 16384),
+		},
+	})}
+	filters, err := scaleFilters(ctx, instanceSelector, selector.Filters{}, ec2types.InstanceTypeM5Large, 1, true)
+	h.Ok(t, err)
+	h.Equals(t, []string{"m5.xlarge", "m6i.xlarge"}, *filters.InstanceTypes)
+
+	// the scaled instance types are intersected with the instance types filter
+	filters, err = scaleFilters(ctx, instanceSelector, selector.Filters{InstanceTypes: &[]string{"m6i.xlarge", "c5.xlarge"}}, ec2types.InstanceTypeM5Large, 1, true)
+	h.Ok(t, err)
+	h.Equals(t, []string{"m6i.xlarge"}, *filters.InstanceTypes)
+}
+
+func TestStreamOutput(t *testing.T) {
+	format, err := outputs.NewDispatcher().Format(outputs.NDJSONFormat)
+	h.Ok(t, err)
+	instanceTypesDetails := []*instancetypes.Details{{InstanceTypeInfo: newInstanceTypeInfo(ec2types.InstanceTypeM5Large, 2, 8192)}}
+	file, err := destination.NewFile(filepath.Join(t.TempDir(), "instance-types.ndjson"))
+	h.Ok(t, err)
+	h.Ok(t, streamOutput(format, file, instanceTypesDetails))
+	data, err := os.ReadFile(file.Path)
+	h.Ok(t, err)
+	h.Equals(t, strings.Join(outputs.NDJSONOutput(instanceTypesDetails), "\n")+"\n", string(data))
+}
+
+func TestGetHostArchitecture(t *testing.T) {
+	expected := map[string]ec2types.ArchitectureType{"amd64": ec2types.ArchitectureTypeX8664, "arm64": ec2types.ArchitectureTypeArm64}
+	hostArchitecture, err := getHostArchitecture()
+	if architecture, ok := expected[runtime.GOARCH]; ok {
+		h.Ok(t, err)
+		h.Equals(t, architecture, hostArchitecture)
+	}
+}
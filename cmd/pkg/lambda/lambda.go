@@ -0,0 +1,187 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lambda serves instance type selections from AWS Lambda functions behind API Gateway. The filters are read
+// from the body of the request and the matching instance types are returned as JSON. The selector is created on the
+// first request with in-memory caches which are reused by the warm invocations of the function, and the package does
+// not depend on the interactive output so that the function's binary stays small.
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+// appID identifies the API calls of the handler in the user agent.
+const appID = "ec2-instance-selector-lambda"
+
+// defaultOfferingsCacheTTL is how long the instance types offered in each location are cached between invocations.
+const defaultOfferingsCacheTTL = 6 * time.Hour
+
+// Response is the body of successful responses.
+type Response struct {
+	InstanceTypes []string `json:"instanceTypes"`
+}
+
+// ErrorResponse is the body of failed responses.
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// NewSelectorFn creates the selector which serves the requests.
+type NewSelectorFn func(ctx context.Context) (*selector.Selector, error)
+
+// Option configures a Handler created by New.
+type Option func(*Handler)
+
+// WithSelector serves the requests with the selector rather than creating one on the first request.
+func WithSelector(instanceSelector *selector.Selector) Option {
+	return func(h *Handler) {
+		h.selector = instanceSelector
+	}
+}
+
+// WithLogger logs the errors which are responded to with a 500 to logger rather than to the standard logger, whose
+// output Lambda sends to CloudWatch Logs.
+func WithLogger(logger *log.Logger) Option {
+	return func(h *Handler) {
+		h.logger = logger
+	}
+}
+
+// WithNewSelectorFn creates the selector on the first request with newSelectorFn rather than with the default AWS config
+// and in-memory caches.
+func WithNewSelectorFn(newSelectorFn NewSelectorFn) Option {
+	return func(h *Handler) {
+		h.newSelectorFn = newSelectorFn
+	}
+}
+
+// Handler handles API Gateway proxy requests, it is safe for concurrent use.
+type Handler struct {
+	newSelectorFn NewSelectorFn
+	selector      *selector.Selector
+	logger        *log.Logger
+	mu            sync.Mutex
+}
+
+// New creates a Handler which creates its selector on the first request, so that functions which are initialized but
+// never invoked do not load the AWS config.
+func New(opts ...Option) *Handler {
+	h := &Handler{newSelectorFn: newInMemorySelector, logger: log.Default()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// newInMemorySelector creates a selector from the default AWS config of the function, it never writes to the filesystem
+// since the filesystem of Lambda functions is read-only.
+func newInMemorySelector(ctx context.Context) (*selector.Selector, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the AWS config: %w", err)
+	}
+	return selector.NewInMemory(ctx, cfg, selector.WithAppID(appID), selector.WithOfferingsCacheTTL(defaultOfferingsCacheTTL))
+}
+
+// getSelector returns the selector of the handler, creating it if this is the first request. A selector which failed
+// to be created is created again by the next request.
+func (h *Handler) getSelector(ctx context.Context) (*selector.Selector, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.selector != nil {
+		return h.selector, nil
+	}
+	instanceSelector, err := h.newSelectorFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.selector = instanceSelector
+	return instanceSelector, nil
+}
+
+// Handle selects the instance types matching the filters in the body of the request. The body is the JSON or YAML of
+// the filters in the format of --filters-file, including AnyOf filter groups (Example: {"VCpusRange": {"LowerBound": 2,
+// "UpperBound": 4}}), an empty body matches every instance type. Invalid filters are responded to with a 400 which
+// explains why they are invalid. Failures to retrieve the instance types are logged and responded to with a 500 whose
+// message does not include the error, errors are only returned if the response can't be created.
+func (h *Handler) Handle(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	filterSet := &selector.FilterSet{}
+	if request.Body != "" {
+		if err := yaml.Unmarshal([]byte(request.Body), filterSet); err != nil {
+			return newErrorResponse(http.StatusBadRequest, fmt.Errorf("invalid filters: %w", err))
+		}
+	}
+	instanceSelector, err := h.getSelector(ctx)
+	if err != nil {
+		return h.newInternalErrorResponse(err)
+	}
+	var instanceTypes []string
+	if len(filterSet.Groups) > 1 {
+		instanceTypes, err = instanceSelector.FilterGroups(ctx, *filterSet)
+	} else {
+		filters := selector.Filters{}
+		if len(filterSet.Groups) == 1 {
+			filters = filterSet.Groups[0]
+		}
+		if filters.MaxResults == nil {
+			filters.MaxResults = filterSet.MaxResults
+		}
+		instanceTypes, err = instanceSelector.Filter(ctx, filters)
+	}
+	if errors.Is(err, selector.ErrInvalidFilterCombination) || errors.Is(err, selector.ErrNotFound) || errors.Is(err, selector.ErrRegionResolution) {
+		return newErrorResponse(http.StatusBadRequest, err)
+	}
+	if err != nil {
+		return h.newInternalErrorResponse(err)
+	}
+	if instanceTypes == nil {
+		instanceTypes = []string{}
+	}
+	return newResponse(http.StatusOK, Response{InstanceTypes: instanceTypes})
+}
+
+// newInternalErrorResponse logs err and responds with a generic 500, the error may include details of the AWS account
+// such as the ARN of the function's role which should not be returned to the callers of the API.
+func (h *Handler) newInternalErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
+	h.logger.Printf("Unable to select instance types: %v", err)
+	return newErrorResponse(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+}
+
+func newErrorResponse(statusCode int, err error) (events.APIGatewayProxyResponse, error) {
+	return newResponse(statusCode, ErrorResponse{Message: err.Error()})
+}
+
+func newResponse(statusCode int, body interface{}) (events.APIGatewayProxyResponse, error) {
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("unable to convert the response to JSON: %w", err)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(bodyJSON),
+	}, nil
+}
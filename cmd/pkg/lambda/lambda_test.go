@@ -0,0 +1,151 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambda_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/cmd/pkg/lambda"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+const describeInstanceTypesFile = "../../../test/static/DescribeInstanceTypes/25_instances.json"
+
+// Mocks
+
+type mockedEC2 struct {
+	describeInstanceTypesResp ec2.DescribeInstanceTypesOutput
+}
+
+func (m mockedEC2) DescribeInstanceTypes(_ context.Context, _ *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	return &m.describeInstanceTypesResp, nil
+}
+
+func newSelector(ctx context.Context) (*selector.Selector, error) {
+	mockFile, err := os.ReadFile(describeInstanceTypesFile)
+	if err != nil {
+		return nil, err
+	}
+	ec2Mock := mockedEC2{}
+	if err := json.Unmarshal(mockFile, &ec2Mock.describeInstanceTypesResp); err != nil {
+		return nil, err
+	}
+	return selector.NewInMemory(ctx, aws.Config{Region: "us-east-1"},
+		selector.WithInstanceTypesProvider(instancetypes.NewProvider("us-east-1", ec2Mock)),
+		selector.WithPricing(ec2pricing.NewFilePricing(nil, nil, nil)))
+}
+
+func handle(t *testing.T, handler *lambda.Handler, body string) (int, map[string]interface{}) {
+	response, err := handler.Handle(context.Background(), events.APIGatewayProxyRequest{Body: body})
+	h.Ok(t, err)
+	h.Equals(t, "application/json", response.Headers["Content-Type"])
+	responseBody := map[string]interface{}{}
+	h.Ok(t, json.Unmarshal([]byte(response.Body), &responseBody))
+	return response.StatusCode, responseBody
+}
+
+// Tests
+
+func TestHandle(t *testing.T) {
+	handler := lambda.New(lambda.WithNewSelectorFn(newSelector))
+	statusCode, body := handle(t, handler, `{"VCpusRange": {"LowerBound": 1, "UpperBound": 2}}`)
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, []interface{}{"a1.large", "a1.medium", "c1.medium", "c3.large", "c4.large", "c5.large"}, body["instanceTypes"])
+
+	statusCode, body = handle(t, handler, "VCpusRange: {LowerBound: 48, UpperBound: 72}")
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, []interface{}{"c5.12xlarge", "c5.18xlarge"}, body["instanceTypes"])
+
+	statusCode, body = handle(t, handler, "")
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, 25, len(body["instanceTypes"].([]interface{})))
+
+	statusCode, body = handle(t, handler, `{"VCpusRange": {"LowerBound": 128, "UpperBound": 128}}`)
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, []interface{}{}, body["instanceTypes"])
+}
+
+func TestHandle_FilterGroups(t *testing.T) {
+	handler := lambda.New(lambda.WithNewSelectorFn(newSelector))
+	statusCode, body := handle(t, handler, `{"AnyOf": [{"VCpusRange": {"LowerBound": 1, "UpperBound": 1}}, {"VCpusRange": {"LowerBound": 96, "UpperBound": 96}}]}`)
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, []interface{}{"a1.medium", "c5.24xlarge"}, body["instanceTypes"])
+}
+
+func TestHandle_InvalidFilters(t *testing.T) {
+	handler := lambda.New(lambda.WithNewSelectorFn(newSelector))
+	statusCode, body := handle(t, handler, `{"VCpusRange": "many"}`)
+	h.Equals(t, http.StatusBadRequest, statusCode)
+	h.Assert(t, body["message"] != "", "The response should explain the invalid filters")
+
+	statusCode, _ = handle(t, handler, `["m5.large"]`)
+	h.Equals(t, http.StatusBadRequest, statusCode)
+}
+
+func TestHandle_MaxResults(t *testing.T) {
+	handler := lambda.New(lambda.WithNewSelectorFn(newSelector))
+	// the MaxResults of the filter set applies to a single filter group which does not set it
+	statusCode, body := handle(t, handler, `{"MaxResults": 2, "AnyOf": [{"VCpusRange": {"LowerBound": 1, "UpperBound": 2}}]}`)
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, []interface{}{"a1.large", "a1.medium"}, body["instanceTypes"])
+}
+
+func TestHandle_LazySelector(t *testing.T) {
+	calls := 0
+	logs := &bytes.Buffer{}
+	handler := lambda.New(lambda.WithLogger(log.New(logs, "", 0)), lambda.WithNewSelectorFn(func(ctx context.Context) (*selector.Selector, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("unable to load the AWS config")
+		}
+		return newSelector(ctx)
+	}))
+	h.Equals(t, 0, calls)
+
+	// internal errors are logged rather than returned
+	statusCode, body := handle(t, handler, "")
+	h.Equals(t, http.StatusInternalServerError, statusCode)
+	h.Equals(t, http.StatusText(http.StatusInternalServerError), body["message"])
+	h.Equals(t, "Unable to select instance types: unable to load the AWS config\n", logs.String())
+
+	// the selector is created again after a failure and then reused
+	statusCode, _ = handle(t, handler, "")
+	h.Equals(t, http.StatusOK, statusCode)
+	statusCode, _ = handle(t, handler, "")
+	h.Equals(t, http.StatusOK, statusCode)
+	h.Equals(t, 2, calls)
+}
+
+func TestWithSelector(t *testing.T) {
+	instanceSelector, err := newSelector(context.Background())
+	h.Ok(t, err)
+	handler := lambda.New(lambda.WithSelector(instanceSelector), lambda.WithNewSelectorFn(func(_ context.Context) (*selector.Selector, error) {
+		return nil, errors.New("the selector passed in should be used")
+	}))
+	statusCode, _ := handle(t, handler, "")
+	h.Equals(t, http.StatusOK, statusCode)
+}
@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+)
+
+const (
+	// controls.
+	exportControls = "Controls: enter - write file (.json for verbose JSON, otherwise CSV) • esc - return to table"
+)
+
+// exportModel holds the state for the export view.
+type exportModel struct {
+	// text input for the path of the exported file
+	exportTextInput textinput.Model
+}
+
+// initExportModel initializes and returns a new exportModel.
+func initExportModel() *exportModel {
+	exportTextInput := textinput.New()
+	exportTextInput.Prompt = "Export to: "
+	exportTextInput.PromptStyle = lipgloss.NewStyle().Bold(true)
+	exportTextInput.Placeholder = "instance-types.csv"
+
+	return &exportModel{
+		exportTextInput: exportTextInput,
+	}
+}
+
+// update updates the state of the exportModel.
+func (m exportModel) update(msg tea.Msg) (exportModel, tea.Cmd) {
+	var cmd tea.Cmd
+	m.exportTextInput, cmd = m.exportTextInput.Update(msg)
+	return m, cmd
+}
+
+// view returns a string representing the export view.
+func (m exportModel) view() string {
+	outputStr := strings.Builder{}
+
+	outputStr.WriteString(m.exportTextInput.View())
+	outputStr.WriteString("\n\n")
+	outputStr.WriteString(controlsStyle.Render(exportControls))
+	outputStr.WriteString("\n")
+
+	return outputStr.String()
+}
+
+// exportVisibleRows writes the rows currently visible in the table, after filtering, sorting and trimming,
+// to the file at path. Files with a .json extension contain the verbose JSON of the instance types and
+// any other file contains the displayed columns as CSV. The number of exported instance types is returned.
+func (m tableModel) exportVisibleRows(path string) (int, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return 0, fmt.Errorf("unable to expand export path %s: %w", path, err)
+	}
+	rows := m.table.GetVisibleRows()
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(expandedPath), ".json") {
+		instanceTypes := []*instancetypes.Details{}
+		for _, row := range rows {
+			currInstance, ok := row.Data[instanceTypeKey].(*instancetypes.Details)
+			if !ok {
+				continue
+			}
+			instanceTypes = append(instanceTypes, currInstance)
+		}
+		data = []byte("[]")
+		if verboseOutput := outputs.VerboseInstanceTypeOutput(instanceTypes); len(verboseOutput) != 0 {
+			data = []byte(verboseOutput[0])
+		}
+	} else {
+		csvData := strings.Builder{}
+		csvWriter := csv.NewWriter(&csvData)
+		if err := csvWriter.Write(m.columnHeaders); err != nil {
+			return 0, fmt.Errorf("unable to write CSV header: %w", err)
+		}
+		for _, row := range rows {
+			record := []string{}
+			for _, header := range m.columnHeaders {
+				record = append(record, fmt.Sprintf("%v", row.Data[header]))
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return 0, fmt.Errorf("unable to write CSV row: %w", err)
+			}
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return 0, fmt.Errorf("unable to write CSV: %w", err)
+		}
+		data = []byte(csvData.String())
+	}
+
+	if err := os.WriteFile(expandedPath, data, 0o600); err != nil {
+		return 0, fmt.Errorf("unable to write export file %s: %w", expandedPath, err)
+	}
+	return len(rows), nil
+}
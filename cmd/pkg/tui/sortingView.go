@@ -10,7 +10,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package outputs
+package tui
 
 import (
 	"fmt"
@@ -147,8 +147,14 @@ func createListItems() *[]list.Item {
 		sorter.Memory,
 		sorter.GPUMemoryTotal,
 		sorter.NetworkInterfaces,
+		sorter.NetworkCards,
+		sorter.ENAQueues,
+		sorter.NFU,
 		sorter.SpotPrice,
 		sorter.ODPrice,
+		sorter.CapacityBlockPrice,
+		sorter.BaselineCPU,
+		sorter.Carbon,
 		sorter.InstanceStorage,
 		sorter.EBSOptimizedBaselineBandwidth,
 		sorter.EBSOptimizedBaselineThroughput,
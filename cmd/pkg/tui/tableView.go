@@ -10,20 +10,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package outputs
+package tui
 
 import (
 	"fmt"
-	"reflect"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
 )
 
@@ -33,8 +34,9 @@ const (
 	headerPadding          = 2
 
 	// controls.
-	tableControls = "Controls: ↑/↓ - up/down • ←/→  - left/right • shift + ←/→ - pg up/down • e - expand • f - filter • t - trim toggle • space - select • s - sort • q - quit"
-	ellipses      = "..."
+	tableControls            = "Controls: ↑/↓ - up/down • ←/→  - left/right • shift + ←/→ - pg up/down • e - expand • f - filter • t - trim toggle • space - select • s - sort • w - write to file • q - quit"
+	confirmSelectionControls = " • enter - confirm selection"
+	ellipses                 = "..."
 
 	jsonPathError = "INVALID JSON PATH"
 )
@@ -58,6 +60,24 @@ type tableModel struct {
 	originalRows []table.Row
 
 	canSelectRows bool
+
+	// shows whether the selection can be confirmed with enter
+	canConfirmSelection bool
+
+	// the headers of the displayed columns in display order
+	columnHeaders []string
+
+	// message displayed below the table, such as the result of an export
+	statusMessage string
+
+	// the columns which are displayed, kept to recalculate the rows when the prices are updated
+	extraColumns  []string
+	customColumns []outputs.CustomColumn
+
+	// shows whether the prices are still being retrieved and their progress
+	isLoadingPrices bool
+	pricesStatus    string
+	pricesSpinner   spinner.Model
 }
 
 var customBorder = table.Border{
@@ -82,16 +102,23 @@ var customBorder = table.Border{
 
 // initTableModel initializes and returns a new tableModel based on the given
 // instance type details.
-func initTableModel(instanceTypes []*instancetypes.Details) *tableModel {
-	table := createTable(instanceTypes)
+func initTableModel(instanceTypes []*instancetypes.Details, extraColumns []string, customColumns []outputs.CustomColumn) *tableModel {
+	// calculate and fetch all column data from instance types
+	columnHeaders, columnsData := outputs.WideColumns(instanceTypes, extraColumns, customColumns)
+	columns := *createColumns(columnHeaders, columnsData)
+	table := createTable(columns, columnsData, instanceTypes)
 
 	return &tableModel{
 		table:           table,
+		columnHeaders:   columnHeaders,
 		tableWidth:      initialDimensionVal,
 		filterTextInput: createFilterTextInput(),
 		isTrimmed:       false,
 		originalRows:    table.GetVisibleRows(),
 		canSelectRows:   true,
+		extraColumns:    extraColumns,
+		customColumns:   customColumns,
+		pricesSpinner:   spinner.New(spinner.WithSpinner(spinner.Dot)),
 	}
 }
 
@@ -105,22 +132,16 @@ func createFilterTextInput() textinput.Model {
 }
 
 // createRows creates a row for each instance type in the passed in list.
-func createRows(columnsData []*wideColumnsData, instanceTypes []*instancetypes.Details) *[]table.Row {
+func createRows(columnsData []map[string]interface{}, instanceTypes []*instancetypes.Details) *[]table.Row {
 	rows := []table.Row{}
 
 	// create a row for each instance type
 	for i, data := range columnsData {
 		rowData := table.RowData{}
 
-		// create a new row by iterating through the column data
-		// struct and using struct tags as column keys
-		structType := reflect.TypeOf(*data)
-		structValue := reflect.ValueOf(*data)
-		for i := 0; i < structType.NumField(); i++ {
-			currField := structType.Field(i)
-			columnName := currField.Tag.Get(columnTag)
-			colValue := structValue.Field(i)
-			rowData[columnName] = getUnderlyingValue(colValue)
+		// create a new row using the column headers as column keys
+		for columnName, value := range data {
+			rowData[columnName] = value
 		}
 
 		// add instance type as metaData
@@ -138,28 +159,14 @@ func createRows(columnsData []*wideColumnsData, instanceTypes []*instancetypes.D
 }
 
 // maxColWidth finds the maximum width element in the given column.
-func maxColWidth(columnsData []*wideColumnsData, columnHeader string) int {
+func maxColWidth(columnsData []map[string]interface{}, columnHeader string) int {
 	// default max width is the width of the header itself with padding
 	maxWidth := len(columnHeader) + headerPadding
 
 	for _, data := range columnsData {
-		// get data at given column
-		structType := reflect.TypeOf(*data)
-		structValue := reflect.ValueOf(*data)
-		var underlyingValue interface{}
-		for i := 0; i < structType.NumField(); i++ {
-			currField := structType.Field(i)
-			columnName := currField.Tag.Get(columnTag)
-			if columnName == columnHeader {
-				colValue := structValue.Field(i)
-				underlyingValue = getUnderlyingValue(colValue)
-				break
-			}
-		}
-
 		// see if the width of the current column element exceeds
 		// the previous max width
-		currWidth := len(fmt.Sprintf("%v", underlyingValue))
+		currWidth := len(fmt.Sprintf("%v", data[columnHeader]))
 		if currWidth > maxWidth {
 			maxWidth = currWidth
 		}
@@ -168,16 +175,11 @@ func maxColWidth(columnsData []*wideColumnsData, columnHeader string) int {
 	return maxWidth
 }
 
-// createColumns creates columns based on the tags in the wideColumnsData
-// struct.
-func createColumns(columnsData []*wideColumnsData) *[]table.Column {
+// createColumns creates a column for each of the displayed column headers.
+func createColumns(columnHeaders []string, columnsData []map[string]interface{}) *[]table.Column {
 	columns := []table.Column{}
 
-	// iterate through wideColumnsData struct and create a new column for each field tag
-	columnDataStruct := wideColumnsData{}
-	structType := reflect.TypeOf(columnDataStruct)
-	for i := 0; i < structType.NumField(); i++ {
-		columnHeader := structType.Field(i).Tag.Get(columnTag)
+	for _, columnHeader := range columnHeaders {
 		newCol := table.NewColumn(columnHeader, columnHeader, maxColWidth(columnsData, columnHeader)).
 			WithFiltered(true)
 
@@ -215,11 +217,8 @@ func createTableKeyMap() *table.KeyMap {
 
 // createTable creates an intractable table which contains information about all of
 // the given instance types.
-func createTable(instanceTypes []*instancetypes.Details) table.Model {
-	// calculate and fetch all column data from instance types
-	columnsData := getWideColumnsData(instanceTypes)
-
-	newTable := table.New(*createColumns(columnsData)).
+func createTable(columns []table.Column, columnsData []map[string]interface{}, instanceTypes []*instancetypes.Details) table.Model {
+	newTable := table.New(columns).
 		WithRows(*createRows(columnsData, instanceTypes)).
 		WithKeyMap(*createTableKeyMap()).
 		WithPageSize(initialDimensionVal).
@@ -266,7 +265,11 @@ func (m tableModel) resizeView(msg tea.WindowSizeMsg) tableModel {
 
 // updateFooter updates the page and controls string in the table footer.
 func (m tableModel) updateFooter() tableModel {
-	controlsStr := tableControls
+	controls := tableControls
+	if m.canConfirmSelection {
+		controls += confirmSelectionControls
+	}
+	controlsStr := controls
 
 	// prevent controls text from wrapping to avoid table misprints
 	pageStr := fmt.Sprintf("Page: %d/%d | ", m.table.CurrentPage(), m.table.MaxPages())
@@ -274,10 +277,10 @@ func (m tableModel) updateFooter() tableModel {
 		controlsWidth := m.tableWidth - len(ellipses) - len(pageStr) - 2
 		if controlsWidth < 0 {
 			controlsWidth = 0
-		} else if controlsWidth > len(tableControls) {
-			controlsWidth = len(tableControls)
+		} else if controlsWidth > len(controls) {
+			controlsWidth = len(controls)
 		}
-		controlsStr = tableControls[0:controlsWidth] + ellipses
+		controlsStr = controls[0:controlsWidth] + ellipses
 	}
 
 	renderedControls := controlsStyle.Render(controlsStr)
@@ -374,9 +377,100 @@ func (m tableModel) view() string {
 		outputStr.WriteString("\n")
 	}
 
+	if m.isLoadingPrices {
+		outputStr.WriteString(controlsStyle.Render(m.pricesSpinner.View() + m.pricesStatus))
+		outputStr.WriteString("\n")
+	} else if m.pricesStatus != "" {
+		outputStr.WriteString(controlsStyle.Render(m.pricesStatus))
+		outputStr.WriteString("\n")
+	}
+
+	if m.statusMessage != "" {
+		outputStr.WriteString(controlsStyle.Render(m.statusMessage))
+		outputStr.WriteString("\n")
+	}
+
 	return outputStr.String()
 }
 
+// updatePrices replaces the instance types and column values of the rows with the priced instance types of the
+// PricesMsg, keeping which rows are selected, trimmed, filtered and their order.
+func (m tableModel) updatePrices(msg PricesMsg) tableModel {
+	m.pricesStatus = msg.Status
+	m.isLoadingPrices = !msg.Done
+	if len(msg.InstanceTypes) == 0 {
+		return m
+	}
+
+	_, columnsData := outputs.WideColumns(msg.InstanceTypes, m.extraColumns, m.customColumns)
+	pricedRows := map[string]table.Row{}
+	for _, row := range *createRows(columnsData, msg.InstanceTypes) {
+		pricedInstance, ok := row.Data[instanceTypeKey].(*instancetypes.Details)
+		if !ok {
+			continue
+		}
+		pricedRows[string(pricedInstance.InstanceType)] = row
+	}
+	updateRows := func(rows []table.Row) []table.Row {
+		updatedRows := []table.Row{}
+		for _, row := range rows {
+			currInstance, ok := row.Data[instanceTypeKey].(*instancetypes.Details)
+			if !ok {
+				updatedRows = append(updatedRows, row)
+				continue
+			}
+			pricedRow, ok := pricedRows[string(currInstance.InstanceType)]
+			if !ok {
+				updatedRows = append(updatedRows, row)
+				continue
+			}
+			isSelected, _ := row.Data[selectedKey].(bool)
+			rowData := table.RowData{}
+			for key, value := range pricedRow.Data {
+				rowData[key] = value
+			}
+			rowData[selectedKey] = isSelected
+			updatedRows = append(updatedRows, table.NewRow(rowData).Selected(isSelected))
+		}
+		return updatedRows
+	}
+
+	rows := updateRows(m.getUnfilteredRows())
+	m.table = m.table.WithRows(rows)
+	if m.isTrimmed {
+		m.originalRows = updateRows(m.originalRows)
+		rows = m.originalRows
+	}
+
+	// the prices may be wider than the placeholders they replace
+	m.table = m.table.WithColumns(*createColumns(m.columnHeaders, m.getColumnsData(rows)))
+
+	return m
+}
+
+// getColumnsData returns the column values of the rows keyed by column header.
+func (m tableModel) getColumnsData(rows []table.Row) []map[string]interface{} {
+	columnsData := []map[string]interface{}{}
+	for _, row := range rows {
+		data := map[string]interface{}{}
+		for _, columnHeader := range m.columnHeaders {
+			data[columnHeader] = row.Data[columnHeader]
+		}
+		columnsData = append(columnsData, data)
+	}
+	return columnsData
+}
+
+// updatePricesSpinner advances the spinner displayed while the prices are retrieved.
+func (m tableModel) updatePricesSpinner(msg spinner.TickMsg) (tableModel, tea.Cmd) {
+	if !m.isLoadingPrices {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.pricesSpinner, cmd = m.pricesSpinner.Update(msg)
+	return m, cmd
+}
+
 // sortTable sorts the table based on the sorting direction and sorting filter.
 func (m tableModel) sortTable(sortFilter string, sortDirection string) (tableModel, error) {
 	instanceTypes, rowMap := m.getInstanceTypeFromRows()
@@ -435,6 +529,26 @@ func (m tableModel) getInstanceTypeFromRows() ([]*instancetypes.Details, map[str
 	return instanceTypes, rowMap
 }
 
+// selectedInstanceTypes returns the instance types of the selected rows in display order, or the instance type
+// of the highlighted row when no rows are selected.
+func (m tableModel) selectedInstanceTypes() []*instancetypes.Details {
+	instanceTypes, rowMap := m.getInstanceTypeFromRows()
+	selected := []*instancetypes.Details{}
+	for _, instanceType := range instanceTypes {
+		if isSelected, ok := rowMap[string(instanceType.InstanceType)].Data[selectedKey].(bool); ok && isSelected {
+			selected = append(selected, instanceType)
+		}
+	}
+	if len(selected) != 0 {
+		return selected
+	}
+
+	if highlighted, ok := m.table.HighlightedRow().Data[instanceTypeKey].(*instancetypes.Details); ok {
+		selected = append(selected, highlighted)
+	}
+	return selected
+}
+
 // getUnfilteredRows gets the rows in the given table model without any filtering applied.
 func (m tableModel) getUnfilteredRows() []table.Row {
 	m.table = m.table.Filtered(false)
@@ -0,0 +1,447 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tui is the interactive output of ec2-instance-selector, a bubble tea model which displays instance types in
+// a table that can be filtered, sorted, expanded and exported. The Model can be run on its own with Run or embedded in
+// other bubble tea programs, which receive a QuitMsg when the user quits and a SelectionMsg when the user confirms the
+// instance types they selected with WithSelections.
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
+)
+
+const (
+	// can't get terminal dimensions on startup, so use this.
+	initialDimensionVal = 30
+
+	instanceTypeKey = "instance type"
+	selectedKey     = "selected"
+)
+
+const (
+	// table states.
+	stateTable   = "table"
+	stateVerbose = "verbose"
+	stateSorting = "sorting"
+	stateExport  = "export"
+)
+
+var controlsStyle = lipgloss.NewStyle().Faint(true)
+
+// Model is used to hold the state of the bubble tea TUI. It implements tea.Model so that it can be embedded in the model
+// of another bubble tea program, it never quits the program itself, see Run to run it on its own.
+type Model struct {
+	// holds the output currentState of the model
+	currentState string
+
+	// the model for the table view
+	tableModel tableModel
+
+	// holds state for the verbose view
+	verboseModel verboseModel
+
+	// holds the state for the sorting view
+	sortingModel sortingModel
+
+	// holds the state for the export view
+	exportModel exportModel
+
+	// whether the selected instance types can be confirmed with enter
+	canConfirmSelection bool
+
+	// receives the prices of the instance types, nil when they are not retrieved after the table is displayed
+	prices <-chan PricesMsg
+}
+
+// SelectionMsg is emitted by a Model created WithSelections when the user confirms the selected instance types with enter.
+type SelectionMsg struct {
+	// InstanceTypes are the selected instance types in display order, or the highlighted instance type when none are selected
+	InstanceTypes []*instancetypes.Details
+}
+
+// QuitMsg is emitted by a Model when the user presses q or ctrl+c, the program embedding the Model decides whether to quit.
+type QuitMsg struct{}
+
+// PricesMsg updates the prices of the instance types displayed by a Model created WithPrices.
+type PricesMsg struct {
+	// InstanceTypes are the displayed instance types with their prices, the rows of instance types which are not in it
+	// are not changed
+	InstanceTypes []*instancetypes.Details
+	// Status describes the progress of retrieving the prices, it is displayed below the table with a spinner until Done
+	Status string
+	// Done is true for the last message, the Status of the last message is only displayed if it is not empty (Ex: an error)
+	Done bool
+}
+
+// options are the settings of a Model which can be changed with an Option.
+type options struct {
+	extraColumns  []string
+	customColumns []outputs.CustomColumn
+	filter        string
+	sortField     string
+	sortDirection string
+	selections    bool
+	prices        <-chan PricesMsg
+}
+
+// Option changes the initial state of the Model created by New.
+type Option func(*options)
+
+// WithColumns displays the passed in optional columns (i.e. outputs.EBSColumns) of the wide table output.
+func WithColumns(extraColumns ...string) Option {
+	return func(o *options) {
+		o.extraColumns = append(o.extraColumns, extraColumns...)
+	}
+}
+
+// WithCustomColumns displays the custom columns after the other columns.
+func WithCustomColumns(customColumns ...outputs.CustomColumn) Option {
+	return func(o *options) {
+		o.customColumns = append(o.customColumns, customColumns...)
+	}
+}
+
+// WithFilter filters the rows of the table with the filter text like the user typing it after pressing f.
+func WithFilter(filter string) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithSort sorts the table by the sort field, a json path or shorthand accepted by sorter.Sort, in the sort
+// direction (sorter.SortAscending or sorter.SortDescending).
+func WithSort(sortField string, sortDirection string) Option {
+	return func(o *options) {
+		o.sortField = sortField
+		o.sortDirection = sortDirection
+	}
+}
+
+// WithSelections lets the user confirm their selection with enter, which emits a SelectionMsg of the selected instance
+// types, or of the highlighted instance type when none are selected.
+func WithSelections() Option {
+	return func(o *options) {
+		o.selections = true
+	}
+}
+
+// WithPrices displays the table before the prices of the instance types are retrieved and updates the rows with the
+// prices of each PricesMsg received on the channel, so that the table does not wait on the pricing caches to hydrate.
+// The channel should be closed after the last message, which is treated like a PricesMsg that is Done.
+func WithPrices(prices <-chan PricesMsg) Option {
+	return func(o *options) {
+		o.prices = prices
+	}
+}
+
+// New initializes a new Model which represents a stylized table to display the instance types.
+// An error is returned when the sort field of WithSort is invalid.
+func New(instanceTypes []*instancetypes.Details, opts ...Option) (Model, error) {
+	o := options{sortDirection: sorter.SortAscending}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := Model{
+		currentState: stateTable,
+		tableModel:   *initTableModel(instanceTypes, o.extraColumns, o.customColumns),
+		verboseModel: *initVerboseModel(),
+		sortingModel: *initSortingModel(instanceTypes),
+		exportModel:  *initExportModel(),
+		prices:       o.prices,
+	}
+	m.canConfirmSelection = o.selections
+	m.tableModel.canConfirmSelection = o.selections
+	m.tableModel.isLoadingPrices = o.prices != nil
+
+	if o.sortField != "" {
+		var err error
+		m.tableModel, err = m.tableModel.sortTable(o.sortField, o.sortDirection)
+		if err != nil {
+			return Model{}, fmt.Errorf("unable to sort the interactive output by %s: %w", o.sortField, err)
+		}
+		m.sortingModel.isDescending = o.sortDirection == sorter.SortDescending || o.sortDirection == sorter.SortDesc
+	}
+	if o.filter != "" {
+		m.tableModel.filterTextInput.SetValue(o.filter)
+		m.tableModel.table = m.tableModel.table.WithFilterInput(m.tableModel.filterTextInput)
+	}
+	m.tableModel = m.tableModel.updateFooter()
+
+	return m, nil
+}
+
+// IsInteractiveTerminal returns true when both stdin and stdout are terminals, which the interactive output requires
+// to read key presses and draw the table.
+func IsInteractiveTerminal() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// DisableColor renders the styles of the interactive output without colors.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Init is used by bubble tea to initialize a bubble tea table.
+func (m Model) Init() tea.Cmd {
+	if m.prices == nil {
+		return nil
+	}
+	return tea.Batch(m.tableModel.pricesSpinner.Tick, m.waitForPrices())
+}
+
+// waitForPrices returns a command which waits for the next PricesMsg.
+func (m Model) waitForPrices() tea.Cmd {
+	prices := m.prices
+	return func() tea.Msg {
+		msg, ok := <-prices
+		if !ok {
+			return PricesMsg{Done: true}
+		}
+		return msg
+	}
+}
+
+// Update is used by bubble tea to update the state of the bubble
+// tea model based on user input.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PricesMsg:
+		m.tableModel = m.tableModel.updatePrices(msg)
+		if msg.Done {
+			return m, nil
+		}
+		return m, m.waitForPrices()
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.tableModel, cmd = m.tableModel.updatePricesSpinner(msg)
+		return m, cmd
+	case tea.KeyMsg:
+		// don't listen for input if currently typing into text field
+		if m.tableModel.filterTextInput.Focused() {
+			break
+		} else if m.sortingModel.sortTextInput.Focused() {
+			// see if we should sort and switch states to table
+			if m.currentState == stateSorting && msg.String() == "enter" {
+				jsonPath := m.sortingModel.sortTextInput.Value()
+
+				sortDirection := sorter.SortAscending
+				if m.sortingModel.isDescending {
+					sortDirection = sorter.SortDescending
+				}
+
+				var err error
+				m.tableModel, err = m.tableModel.sortTable(jsonPath, sortDirection)
+				if err != nil {
+					m.sortingModel.sortTextInput.SetValue(jsonPathError)
+					break
+				}
+
+				m.currentState = stateTable
+
+				m.sortingModel.sortTextInput.Blur()
+			}
+
+			break
+		} else if m.exportModel.exportTextInput.Focused() {
+			switch msg.String() {
+			case "enter":
+				// write the visible rows and switch states to table
+				path := m.exportModel.exportTextInput.Value()
+				if path == "" {
+					break
+				}
+				exported, err := m.tableModel.exportVisibleRows(path)
+				if err != nil {
+					m.tableModel.statusMessage = err.Error()
+				} else {
+					m.tableModel.statusMessage = fmt.Sprintf("Exported %d instance types to %s", exported, path)
+				}
+				m.exportModel.exportTextInput.Blur()
+				m.currentState = stateTable
+			case "esc":
+				m.exportModel.exportTextInput.Blur()
+				m.currentState = stateTable
+			}
+
+			break
+		}
+
+		// check for quit or change in state
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, func() tea.Msg { return QuitMsg{} }
+		case "e":
+			// switch from table state to verbose state
+			if m.currentState == stateTable {
+				// get focused instance type
+				focusedRow := m.tableModel.table.HighlightedRow()
+				focusedInstance, ok := focusedRow.Data[instanceTypeKey].(*instancetypes.Details)
+				if !ok {
+					break
+				}
+
+				// set content of view
+				m.verboseModel.focusedInstanceName = focusedInstance.InstanceType
+				m.verboseModel.viewport.SetContent(outputs.VerboseInstanceTypeOutput([]*instancetypes.Details{focusedInstance})[0])
+
+				// move viewport to top of printout
+				m.verboseModel.viewport.SetYOffset(0)
+
+				// switch from table state to verbose state
+				m.currentState = stateVerbose
+			}
+		case "s":
+			// switch from table view to sorting view
+			if m.currentState == stateTable {
+				m.currentState = stateSorting
+			}
+		case "w":
+			// switch from table view to export view
+			if m.currentState == stateTable {
+				m.tableModel.statusMessage = ""
+				m.exportModel.exportTextInput.Focus()
+				m.currentState = stateExport
+				return m, textinput.Blink
+			}
+		case "enter":
+			// confirm the selection of the table
+			if m.currentState == stateTable && m.canConfirmSelection {
+				selected := m.tableModel.selectedInstanceTypes()
+				if len(selected) == 0 {
+					break
+				}
+				return m, m.confirmSelection(selected)
+			}
+
+			// sort and switch states to table
+			if m.currentState == stateSorting {
+				sortFilter := string(m.sortingModel.shorthandList.SelectedItem().(item))
+
+				sortDirection := sorter.SortAscending
+				if m.sortingModel.isDescending {
+					sortDirection = sorter.SortDescending
+				}
+
+				var err error
+				m.tableModel, err = m.tableModel.sortTable(sortFilter, sortDirection)
+				if err != nil {
+					m.sortingModel.sortTextInput.SetValue("INVALID SHORTHAND VALUE")
+					break
+				}
+
+				m.currentState = stateTable
+
+				m.sortingModel.sortTextInput.Blur()
+			}
+		case "esc":
+			// switch from sorting state or verbose state to table state
+			if m.currentState == stateSorting || m.currentState == stateVerbose {
+				m.currentState = stateTable
+			}
+		}
+	case tea.WindowSizeMsg:
+		// handle screen resizing
+		m.tableModel = m.tableModel.resizeView(msg)
+		m.verboseModel = m.verboseModel.resizeView(msg)
+		m.sortingModel = m.sortingModel.resizeView(msg)
+	}
+
+	var cmd tea.Cmd
+	// update currently active state
+	switch m.currentState {
+	case stateTable:
+		m.tableModel, cmd = m.tableModel.update(msg)
+	case stateVerbose:
+		m.verboseModel, cmd = m.verboseModel.update(msg)
+	case stateSorting:
+		m.sortingModel, cmd = m.sortingModel.update(msg)
+	case stateExport:
+		m.exportModel, cmd = m.exportModel.update(msg)
+	}
+
+	return m, cmd
+}
+
+// confirmSelection returns a command which emits the SelectionMsg of the selected instance types.
+func (m Model) confirmSelection(selected []*instancetypes.Details) tea.Cmd {
+	return func() tea.Msg {
+		return SelectionMsg{InstanceTypes: selected}
+	}
+}
+
+// View is used by bubble tea to render the bubble tea model.
+func (m Model) View() string {
+	switch m.currentState {
+	case stateTable:
+		return m.tableModel.view()
+	case stateVerbose:
+		return m.verboseModel.view()
+	case stateSorting:
+		return m.sortingModel.view()
+	case stateExport:
+		return m.exportModel.view()
+	}
+
+	return ""
+}
+
+// Run runs the Model as a standalone bubble tea program with the program options until the user quits or confirms a
+// selection, and returns the confirmed instance types, nil if the user quit without confirming a selection.
+func Run(m Model, opts ...tea.ProgramOption) ([]*instancetypes.Details, error) {
+	finalModel, err := tea.NewProgram(standaloneModel{Model: m}, opts...).Run()
+	if err != nil {
+		return nil, err
+	}
+	return finalModel.(standaloneModel).selected, nil
+}
+
+// standaloneModel quits the program on the QuitMsg and SelectionMsg of the Model it wraps.
+type standaloneModel struct {
+	Model
+	selected []*instancetypes.Details
+}
+
+// Update is used by bubble tea to update the wrapped Model and quit once the user quits or confirms a selection.
+func (s standaloneModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case QuitMsg:
+		return s, tea.Quit
+	case SelectionMsg:
+		s.selected = msg.InstanceTypes
+		return s, tea.Quit
+	}
+	model, cmd := s.Model.Update(msg)
+	s.Model = model.(Model)
+	if _, ok := msg.(tea.WindowSizeMsg); ok {
+		// resizing causes misprints of the table without clearing the screen (https://github.com/Evertras/bubble-table/issues/121)
+		cmd = tea.Batch(tea.ClearScreen, cmd)
+	}
+	return s, cmd
+}
@@ -0,0 +1,362 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+	"github.com/muesli/termenv"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+const (
+	mockFilesPath = "../../../test/static"
+)
+
+// helpers
+
+// getInstanceTypeDetails unmarshalls the json file in the given testing folder
+// and returns a list of instance type details.
+func getInstanceTypeDetails(t *testing.T, file string) []*instancetypes.Details {
+	folder := "FilterVerbose"
+	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, folder, file)
+	mockFile, err := os.ReadFile(mockFilename)
+	h.Assert(t, err == nil, "Error reading mock file "+mockFilename)
+
+	instanceTypes := []*instancetypes.Details{}
+	err = json.Unmarshal(mockFile, &instanceTypes)
+	h.Assert(t, err == nil, fmt.Sprintf("Error parsing mock json file contents %s. Error: %v", mockFilename, err))
+	return instanceTypes
+}
+
+// newModel creates a Model of the instance types with the options.
+func newModel(t *testing.T, instanceTypes []*instancetypes.Details, opts ...Option) Model {
+	model, err := New(instanceTypes, opts...)
+	h.Ok(t, err)
+	return model
+}
+
+// getRowsInstances reformats the given table rows into a list of instance type names.
+func getRowsInstances(rows []table.Row) string {
+	instances := []string{}
+
+	for _, row := range rows {
+		instances = append(instances, fmt.Sprintf("%v", row.Data["Instance Type"]))
+	}
+
+	return strings.Join(instances, ", ")
+}
+
+// tests
+
+func TestNew_Hypervisor(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+
+	// test non nil Hypervisor
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+	expectedHypervisor := "xen"
+	actualHypervisor := rows[0].Data["Hypervisor"]
+
+	h.Assert(t, actualHypervisor == expectedHypervisor, fmt.Sprintf("Hypervisor should be %s but instead is %s", expectedHypervisor, actualHypervisor))
+}
+
+func TestNew_CPUArchitectures(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+
+	actualGPUArchitectures := "x86_64"
+	expectedGPUArchitectures := rows[0].Data["CPU Arch"]
+
+	h.Assert(t, actualGPUArchitectures == expectedGPUArchitectures, "CPU architecture should be (%s), but actually (%s)", expectedGPUArchitectures, actualGPUArchitectures)
+}
+
+func TestNew_GPU(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+
+	// test GPU count
+	expectedGPUCount := "4"
+	actualGPUCount := fmt.Sprintf("%v", rows[0].Data["GPUs"])
+
+	h.Assert(t, expectedGPUCount == actualGPUCount, "GPU count should be %s, but is actually %s", expectedGPUCount, actualGPUCount)
+
+	// test GPU memory
+	expectedGPUMemory := "32"
+	actualGPUMemory := rows[0].Data["GPU Mem (GiB)"]
+
+	h.Assert(t, expectedGPUMemory == actualGPUMemory, "GPU memory should be %s, but is actually %s", expectedGPUMemory, actualGPUMemory)
+
+	// test GPU info
+	expectedGPUInfo := "NVIDIA M60"
+	actualGPUInfo := rows[0].Data["GPU Info"]
+
+	h.Assert(t, expectedGPUInfo == actualGPUInfo, "GPU info should be (%s), but is actually (%s)", expectedGPUInfo, actualGPUInfo)
+}
+
+func TestNew_CustomColumns(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+	model := newModel(t, instanceTypes, WithCustomColumns(outputs.CustomColumn{Name: "GPU Name", Path: ".GpuInfo.Gpus[0].Name"}))
+	rows := model.tableModel.table.GetVisibleRows()
+
+	h.Equals(t, "GPU Name", model.tableModel.columnHeaders[len(model.tableModel.columnHeaders)-1])
+	h.Equals(t, "M60", rows[0].Data["GPU Name"])
+}
+
+func TestNew_ODPricing(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+
+	// test non nil OD price
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+	expectedODPrice := "$4.56"
+	actualODPrice := fmt.Sprintf("%v", rows[0].Data["On-Demand Price/Hr"])
+
+	h.Assert(t, actualODPrice == expectedODPrice, "Actual OD price should be %s, but is actually %s", expectedODPrice, actualODPrice)
+
+	// test nil OD price
+	instanceTypes[0].OndemandPricePerHour = nil
+	model = newModel(t, instanceTypes)
+	rows = model.tableModel.table.GetVisibleRows()
+	expectedODPrice = "-Not Fetched-"
+	actualODPrice = fmt.Sprintf("%v", rows[0].Data["On-Demand Price/Hr"])
+
+	h.Assert(t, actualODPrice == expectedODPrice, "Actual OD price should be %s, but is actually %s", expectedODPrice, actualODPrice)
+}
+
+func TestNew_SpotPricing(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+
+	// test non nil spot price
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+	expectedODPrice := "$1.368"
+	actualODPrice := fmt.Sprintf("%v", rows[0].Data["Spot Price/Hr"])
+
+	h.Assert(t, actualODPrice == expectedODPrice, "Actual spot price should be %s, but is actually %s", expectedODPrice, actualODPrice)
+
+	// test nil spot price
+	instanceTypes[0].SpotPrice = nil
+	model = newModel(t, instanceTypes)
+	rows = model.tableModel.table.GetVisibleRows()
+	expectedODPrice = "-Not Fetched-"
+	actualODPrice = fmt.Sprintf("%v", rows[0].Data["Spot Price/Hr"])
+
+	h.Assert(t, actualODPrice == expectedODPrice, "Actual spot price should be %s, but is actually %s", expectedODPrice, actualODPrice)
+}
+
+func TestNew_Rows(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes)
+	rows := model.tableModel.table.GetVisibleRows()
+
+	h.Assert(t, len(rows) == len(instanceTypes), "Number of rows should be %d, but is actually %d", len(instanceTypes), len(rows))
+
+	// test that order of instance types is retained
+	for i := range instanceTypes {
+		currInstanceName := instanceTypes[i].InstanceType
+		currRowName := rows[i].Data["Instance Type"]
+
+		h.Assert(t, string(currInstanceName) == currRowName, "Rows should be in following order: %s. Actual order: [%s]", outputs.OneLineOutput(instanceTypes), getRowsInstances(rows))
+	}
+}
+
+// exportWithKeys exports the visible rows of the model to path by sending the export keys.
+func exportWithKeys(model Model, path string) Model {
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(path)})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updatedModel.(Model)
+}
+
+func TestModel_ExportCSV(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes)
+	model.tableModel.filterTextInput.SetValue("a1.4xlarge")
+	model.tableModel.table = model.tableModel.table.WithFilterInput(model.tableModel.filterTextInput)
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	model = exportWithKeys(model, path)
+	h.Equals(t, stateTable, model.currentState)
+	h.Equals(t, fmt.Sprintf("Exported 1 instance types to %s", path), model.tableModel.statusMessage)
+
+	exportFile, err := os.Open(path)
+	h.Ok(t, err)
+	defer exportFile.Close()
+	records, err := csv.NewReader(exportFile).ReadAll()
+	h.Ok(t, err)
+	h.Equals(t, 2, len(records))
+	h.Equals(t, model.tableModel.columnHeaders, records[0])
+	h.Equals(t, "Instance Type", records[0][0])
+	h.Equals(t, "a1.4xlarge", records[1][0])
+}
+
+func TestModel_ExportJSON(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes)
+	var err error
+	model.tableModel, err = model.tableModel.sortTable(sorter.VCPUs, sorter.SortDescending)
+	h.Ok(t, err)
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	model = exportWithKeys(model, path)
+	h.Equals(t, stateTable, model.currentState)
+
+	exportBytes, err := os.ReadFile(path)
+	h.Ok(t, err)
+	exported := []*instancetypes.Details{}
+	h.Ok(t, json.Unmarshal(exportBytes, &exported))
+	h.Equals(t, 3, len(exported))
+	h.Equals(t, "a1.4xlarge", string(exported[0].InstanceType))
+	h.Equals(t, "a1.large", string(exported[2].InstanceType))
+}
+
+func TestModel_ExportCancel(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes)
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	h.Equals(t, stateExport, updatedModel.(Model).currentState)
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	h.Equals(t, stateTable, updatedModel.(Model).currentState)
+	h.Equals(t, "", updatedModel.(Model).tableModel.statusMessage)
+}
+
+func TestNew_WithFilter(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes, WithFilter("a1.4xlarge"))
+	rows := model.tableModel.table.GetVisibleRows()
+	h.Equals(t, "a1.4xlarge", getRowsInstances(rows))
+	h.Equals(t, "a1.4xlarge", model.tableModel.filterTextInput.Value())
+}
+
+func TestNew_WithSort(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes, WithSort(sorter.VCPUs, sorter.SortDescending))
+	rows := model.tableModel.table.GetVisibleRows()
+	h.Equals(t, "a1.4xlarge", rows[0].Data["Instance Type"])
+	h.Equals(t, "a1.large", rows[2].Data["Instance Type"])
+	h.Assert(t, model.sortingModel.isDescending, "The sorting view should show the descending direction")
+
+	_, err := New(instanceTypes, WithSort("fdsafdsafdjskalfjlsf #@", sorter.SortAscending))
+	h.Nok(t, err)
+}
+
+func TestModel_WithSelections(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes, WithSelections())
+	model.tableModel.tableWidth = len(tableControls) * 2
+	h.Assert(t, strings.Contains(model.tableModel.updateFooter().table.View(), "confirm selection"), "The controls should include confirm selection")
+
+	// without selected rows the highlighted row is confirmed
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	h.Assert(t, cmd != nil, "Enter should return a command which confirms the selection")
+	selection, ok := cmd().(SelectionMsg)
+	h.Assert(t, ok, "Enter should emit a SelectionMsg")
+	h.Equals(t, 1, len(selection.InstanceTypes))
+	h.Equals(t, instanceTypes[0].InstanceType, selection.InstanceTypes[0].InstanceType)
+
+	// selected rows are confirmed in display order
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updatedModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	_, cmd = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	selection = cmd().(SelectionMsg)
+	h.Equals(t, 2, len(selection.InstanceTypes))
+	h.Equals(t, instanceTypes[1].InstanceType, selection.InstanceTypes[0].InstanceType)
+	h.Equals(t, instanceTypes[2].InstanceType, selection.InstanceTypes[1].InstanceType)
+}
+
+func TestModel_Quit(t *testing.T) {
+	model := newModel(t, getInstanceTypeDetails(t, "3_instances.json"))
+	// the model leaves quitting to the program embedding it
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	h.Equals(t, QuitMsg{}, cmd())
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	h.Equals(t, QuitMsg{}, cmd())
+}
+
+func TestStandaloneModel(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := standaloneModel{Model: newModel(t, instanceTypes, WithSelections())}
+	_, cmd := model.Update(QuitMsg{})
+	h.Equals(t, tea.QuitMsg{}, cmd())
+
+	updatedModel, cmd := model.Update(SelectionMsg{InstanceTypes: instanceTypes[:1]})
+	h.Equals(t, tea.QuitMsg{}, cmd())
+	h.Equals(t, instanceTypes[:1], updatedModel.(standaloneModel).selected)
+}
+
+func TestModel_WithoutSelections(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	model := newModel(t, instanceTypes)
+	model.tableModel.tableWidth = len(tableControls) * 2
+	h.Assert(t, !strings.Contains(model.tableModel.updateFooter().table.View(), "confirm selection"), "The controls should not include confirm selection")
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	h.Equals(t, stateTable, updatedModel.(Model).currentState)
+}
+
+func TestModel_WithPrices(t *testing.T) {
+	pricedInstanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+	instanceTypes := getInstanceTypeDetails(t, "g3_16xlarge.json")
+	instanceTypes[0].OndemandPricePerHour = nil
+	prices := make(chan PricesMsg, 1)
+	model := newModel(t, instanceTypes, WithPrices(prices))
+	h.Assert(t, model.Init() != nil, "Init should wait for the prices")
+	h.Equals(t, "-Not Fetched-", model.tableModel.table.GetVisibleRows()[0].Data["On-Demand Price/Hr"])
+
+	// keep the selection when the prices are updated
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	updatedModel, cmd := updatedModel.Update(PricesMsg{Status: "Retrieving on-demand prices"})
+	h.Assert(t, cmd != nil, "Should wait for the next prices")
+	h.Assert(t, strings.Contains(updatedModel.View(), "Retrieving on-demand prices"), "Should display the status of the prices")
+
+	updatedModel, cmd = updatedModel.Update(PricesMsg{InstanceTypes: pricedInstanceTypes, Done: true})
+	h.Assert(t, cmd == nil, "Should not wait for prices once they are done")
+	model = updatedModel.(Model)
+	rows := model.tableModel.table.GetVisibleRows()
+	h.Equals(t, "$4.56", rows[0].Data["On-Demand Price/Hr"])
+	h.Equals(t, pricedInstanceTypes[0], rows[0].Data[instanceTypeKey])
+	h.Equals(t, true, rows[0].Data[selectedKey])
+	h.Assert(t, !strings.Contains(model.View(), "Retrieving"), "Should not display the status once the prices are done")
+
+	// closing the channel is the last message
+	close(prices)
+	h.Equals(t, PricesMsg{Done: true}, model.waitForPrices()())
+}
+
+func TestDisableColor(t *testing.T) {
+	colorProfile := lipgloss.ColorProfile()
+	defer lipgloss.SetColorProfile(colorProfile)
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	DisableColor()
+	h.Equals(t, termenv.Ascii, lipgloss.ColorProfile())
+	rendered := descendingStyle.Render("test")
+	h.Assert(t, !strings.Contains(rendered, "\x1b["), "Should render without ANSI color codes, got %q", rendered)
+}
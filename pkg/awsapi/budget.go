@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const budgetMiddlewareID = "InstanceSelectorBudget"
+
+// ErrBudgetExceeded is returned by the AWS API calls which are not sent because the Budget is spent.
+var ErrBudgetExceeded = errors.New("AWS API call budget exceeded")
+
+// Budget caps the number of AWS API calls made by the SDK clients it is added to. Calls beyond the budget fail with
+// ErrBudgetExceeded without being sent and are counted per operation so that what was skipped can be reported.
+// Paginated operations are counted once per page and retries are not counted.
+type Budget struct {
+	maxCalls int
+	calls    int
+	skipped  map[string]int
+	sync.Mutex
+}
+
+// NewBudget creates a Budget which allows maxCalls AWS API calls.
+func NewBudget(maxCalls int) *Budget {
+	return &Budget{
+		maxCalls: maxCalls,
+		skipped:  map[string]int{},
+	}
+}
+
+// AddMiddleware adds the budget to an SDK client's middleware stack.
+// It can be appended to aws.Config.APIOptions to share the budget between every client created from the config.
+func (b *Budget) AddMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(budgetMiddlewareID, b.handleInitialize), middleware.After)
+}
+
+func (b *Budget) handleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	operation := fmt.Sprintf("%s.%s", awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx))
+	b.Lock()
+	if b.calls >= b.maxCalls {
+		b.skipped[operation]++
+		b.Unlock()
+		return middleware.InitializeOutput{}, middleware.Metadata{}, fmt.Errorf("%s was not called: %w after %d calls", operation, ErrBudgetExceeded, b.maxCalls)
+	}
+	b.calls++
+	b.Unlock()
+	return next.HandleInitialize(ctx, in)
+}
+
+// Remaining returns the number of calls which can still be made.
+func (b *Budget) Remaining() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.maxCalls - b.calls
+}
+
+// Skipped returns the number of calls which were not made per operation because the budget was spent (Example: Pricing.GetProducts).
+func (b *Budget) Skipped() map[string]int {
+	b.Lock()
+	defer b.Unlock()
+	skipped := make(map[string]int, len(b.skipped))
+	for operation, count := range b.skipped {
+		skipped[operation] = count
+	}
+	return skipped
+}
+
+// SkippedSummary describes the calls which were skipped per operation sorted by operation (Example: EC2.DescribeSpotPriceHistory=3),
+// it is empty if no calls were skipped.
+func (b *Budget) SkippedSummary() string {
+	skipped := b.Skipped()
+	operations := make([]string, 0, len(skipped))
+	for operation, count := range skipped {
+		operations = append(operations, fmt.Sprintf("%s=%d", operation, count))
+	}
+	sort.Strings(operations)
+	return strings.Join(operations, " ")
+}
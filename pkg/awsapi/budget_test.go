@@ -0,0 +1,55 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestBudget(t *testing.T) {
+	budget := awsapi.NewBudget(2)
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		HTTPClient: mockedHTTPClient{
+			statusCode: http.StatusOK,
+			body:       `<DescribeAvailabilityZonesResponse><availabilityZoneInfo/></DescribeAvailabilityZonesResponse>`,
+		},
+		Retryer:    func() aws.Retryer { return aws.NopRetryer{} },
+		APIOptions: []func(*middleware.Stack) error{budget.AddMiddleware},
+	}
+	ec2Client := ec2.NewFromConfig(cfg)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+		h.Ok(t, err)
+	}
+	h.Equals(t, 0, budget.Remaining())
+	h.Equals(t, "", budget.SkippedSummary())
+
+	_, err := ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+	h.Assert(t, errors.Is(err, awsapi.ErrBudgetExceeded), "Should not call the API once the budget is spent, got: %v", err)
+	h.Equals(t, map[string]int{"EC2.DescribeAvailabilityZones": 1}, budget.Skipped())
+	h.Equals(t, "EC2.DescribeAvailabilityZones=1", budget.SkippedSummary())
+}
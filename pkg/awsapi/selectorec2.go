@@ -22,5 +22,15 @@ import (
 type SelectorInterface interface {
 	ec2.DescribeInstanceTypeOfferingsAPIClient
 	ec2.DescribeInstanceTypesAPIClient
+	ec2.DescribeLaunchTemplateVersionsAPIClient
+	ec2.DescribeImagesAPIClient
+	ec2.DescribeCapacityBlockOfferingsAPIClient
 	DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+	DescribePlacementGroups(ctx context.Context, params *ec2.DescribePlacementGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribePlacementGroupsOutput, error)
+}
+
+// RegionsDescriber is implemented by the SelectorInterfaces which can describe the regions enabled for the account, such as
+// the EC2 client. It is separate from SelectorInterface so that existing implementations of SelectorInterface do not need to implement it.
+type RegionsDescriber interface {
+	DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
 }
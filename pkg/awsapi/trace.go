@@ -0,0 +1,104 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const tracerMiddlewareID = "InstanceSelectorTracer"
+
+// Tracer logs the request ID, duration, and attempts of each AWS API call and counts the calls made per operation.
+// Paginated operations are counted once per page.
+type Tracer struct {
+	logger *log.Logger
+	calls  map[string]int
+	sync.Mutex
+}
+
+// NewTracer creates a Tracer which logs each AWS API call to the passed in logger.
+func NewTracer(logger *log.Logger) *Tracer {
+	return &Tracer{
+		logger: logger,
+		calls:  map[string]int{},
+	}
+}
+
+// AddMiddleware adds the tracer to an SDK client's middleware stack.
+// It can be appended to aws.Config.APIOptions to trace every client created from the config.
+func (t *Tracer) AddMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(tracerMiddlewareID, t.handleInitialize), middleware.After)
+}
+
+func (t *Tracer) handleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	start := time.Now()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	operation := fmt.Sprintf("%s.%s", awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx))
+
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	var responseErr *awshttp.ResponseError
+	if requestID == "" && errors.As(err, &responseErr) {
+		requestID = responseErr.ServiceRequestID()
+	}
+	attempts := 1
+	if attemptResults, ok := retry.GetAttemptResults(metadata); ok && len(attemptResults.Results) > 0 {
+		attempts = len(attemptResults.Results)
+	}
+
+	t.Lock()
+	t.calls[operation]++
+	t.Unlock()
+	if err != nil {
+		t.logger.Printf("%s request-id=%s duration=%s attempts=%d error=%q", operation, requestID, time.Since(start), attempts, err)
+	} else {
+		t.logger.Printf("%s request-id=%s duration=%s attempts=%d", operation, requestID, time.Since(start), attempts)
+	}
+	return out, metadata, err
+}
+
+// Calls returns the number of calls made per operation (Example: EC2.DescribeInstanceTypes).
+func (t *Tracer) Calls() map[string]int {
+	t.Lock()
+	defer t.Unlock()
+	calls := make(map[string]int, len(t.calls))
+	for operation, count := range t.calls {
+		calls[operation] = count
+	}
+	return calls
+}
+
+// LogSummary logs the number of calls made per operation.
+func (t *Tracer) LogSummary() {
+	calls := t.Calls()
+	if len(calls) == 0 {
+		return
+	}
+	operations := make([]string, 0, len(calls))
+	for operation, count := range calls {
+		operations = append(operations, fmt.Sprintf("%s=%d", operation, count))
+	}
+	sort.Strings(operations)
+	t.logger.Printf("API calls: %s", strings.Join(operations, " "))
+}
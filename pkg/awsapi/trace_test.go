@@ -0,0 +1,90 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awsapi_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocks
+
+type mockedHTTPClient struct {
+	statusCode int
+	body       string
+}
+
+func (m mockedHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: m.statusCode,
+		Header:     http.Header{"X-Amzn-Requestid": []string{"11111111-2222-3333-4444-555555555555"}},
+		Body:       io.NopCloser(strings.NewReader(m.body)),
+	}, nil
+}
+
+func getTracedEC2Client(tracer *awsapi.Tracer, httpClient mockedHTTPClient) *ec2.Client {
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		HTTPClient:  httpClient,
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+	}
+	cfg.APIOptions = append(cfg.APIOptions, tracer.AddMiddleware)
+	return ec2.NewFromConfig(cfg)
+}
+
+// Tests
+
+func TestTracer(t *testing.T) {
+	logs := &bytes.Buffer{}
+	tracer := awsapi.NewTracer(log.New(logs, "", 0))
+	ec2Client := getTracedEC2Client(tracer, mockedHTTPClient{
+		statusCode: http.StatusOK,
+		body:       `<DescribeAvailabilityZonesResponse><availabilityZoneInfo/></DescribeAvailabilityZonesResponse>`,
+	})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+		h.Ok(t, err)
+	}
+	h.Equals(t, map[string]int{"EC2.DescribeAvailabilityZones": 2}, tracer.Calls())
+	h.Assert(t, strings.Contains(logs.String(), "EC2.DescribeAvailabilityZones request-id=11111111-2222-3333-4444-555555555555"), "Should log the request ID, got: %s", logs.String())
+
+	tracer.LogSummary()
+	h.Assert(t, strings.Contains(logs.String(), "API calls: EC2.DescribeAvailabilityZones=2"), "Should log a summary of calls, got: %s", logs.String())
+}
+
+func TestTracer_Error(t *testing.T) {
+	logs := &bytes.Buffer{}
+	tracer := awsapi.NewTracer(log.New(logs, "", 0))
+	ec2Client := getTracedEC2Client(tracer, mockedHTTPClient{
+		statusCode: http.StatusBadRequest,
+		body:       `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>11111111-2222-3333-4444-555555555555</RequestID></Response>`,
+	})
+	_, err := ec2Client.DescribeAvailabilityZones(context.Background(), &ec2.DescribeAvailabilityZonesInput{})
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(logs.String(), "request-id=11111111-2222-3333-4444-555555555555"), "Should log the request ID of failed calls, got: %s", logs.String())
+	h.Assert(t, strings.Contains(logs.String(), "RequestLimitExceeded"), "Should log the error of failed calls, got: %s", logs.String())
+}
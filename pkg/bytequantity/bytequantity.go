@@ -18,18 +18,36 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 const (
-	/// Examples:          1mb, 1 gb, 1.0tb, 1mib, 2g, 2.001 t.
-	byteQuantityRegex = `^([0-9]+\.?[0-9]{0,3})[ ]?(mi?b?|gi?b?|ti?b?)?$`
+	/// Examples:          1mb, 1 gb, 1.0tb, 1mib, 2g, 2.001 t, 1,024 MiB, 1.5 PiB.
+	byteQuantityRegex = `^((?:[0-9]{1,3}(?:,[0-9]{3})+|[0-9]+)\.?[0-9]{0,3})\s*(mi?b?|gi?b?|ti?b?|pi?b?)?$`
 	mib               = "MiB"
 	gib               = "GiB"
 	tib               = "TiB"
+	pib               = "PiB"
 	gbConvert         = 1 << 10
 	tbConvert         = gbConvert << 10
-	maxGiB            = math.MaxUint64 / gbConvert
-	maxTiB            = math.MaxUint64 / tbConvert
+	pbConvert         = tbConvert << 10
+	// bytesPerMiB converts decimal (SI) units, which are a power of 10 bytes, to MiB
+	bytesPerMiB = 1 << 20
+	// maxQuantity is the smallest float64 which does not fit in a uint64 (2^64)
+	maxQuantity float64 = math.MaxUint64
+)
+
+var (
+	bqRegexp = regexp.MustCompile(byteQuantityRegex)
+
+	// siBytes are the number of bytes in each decimal (SI) unit keyed by the first letter of the unit
+	siBytes = map[string]float64{
+		"m": 1e6,
+		"g": 1e9,
+		"t": 1e12,
+		"p": 1e15,
+	}
 )
 
 // ByteQuantity is a data type representing a byte quantity.
@@ -39,35 +57,44 @@ type ByteQuantity struct {
 
 // ParseToByteQuantity parses a string representation of a byte quantity to a ByteQuantity type.
 // A unit can be appended such as 16 GiB. If no unit is appended, GiB is assumed.
+// Thousands may be separated by commas such as 1,024 MiB.
+// Units are binary whether or not they contain an i, 1 GB is 1 GiB.
 func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
-	bqRegexp := regexp.MustCompile(byteQuantityRegex)
-	matches := bqRegexp.FindStringSubmatch(strings.ToLower(byteQuantityStr))
+	return parseToByteQuantity(byteQuantityStr, false)
+}
+
+// ParseToByteQuantitySI parses a string representation of a byte quantity to a ByteQuantity type like ParseToByteQuantity
+// except units without an i are decimal (SI) units, 1 GB is 1,000,000,000 bytes while 1 GiB is 1,073,741,824 bytes.
+// If no unit is appended, GiB is assumed. Decimal quantities are rounded to the nearest MiB.
+func ParseToByteQuantitySI(byteQuantityStr string) (ByteQuantity, error) {
+	return parseToByteQuantity(byteQuantityStr, true)
+}
+
+func parseToByteQuantity(byteQuantityStr string, siUnits bool) (ByteQuantity, error) {
+	matches := bqRegexp.FindStringSubmatch(strings.ToLower(strings.TrimSpace(byteQuantityStr)))
 	if len(matches) < 2 {
 		return ByteQuantity{}, fmt.Errorf("%s is not a valid byte quantity", byteQuantityStr)
 	}
 
-	quantityStr := matches[1]
+	quantityStr := strings.ReplaceAll(matches[1], ",", "")
 	unit := gib
 	if len(matches) > 2 && matches[2] != "" {
 		unit = matches[2]
 	}
+	if siUnits && !strings.Contains(unit, "i") {
+		return parseSIByteQuantity(quantityStr, unit)
+	}
 	quantity := uint64(0)
 	switch strings.ToLower(string(unit[0])) {
 	// mib
 	case "m":
-		inputDecSplit := strings.Split(quantityStr, ".")
-		if len(inputDecSplit) == 2 {
-			d, err := strconv.Atoi(inputDecSplit[1])
-			if err != nil {
-				return ByteQuantity{}, err
-			}
-			if d != 0 {
-				return ByteQuantity{}, fmt.Errorf("cannot accept floating point MB value, only integers are accepted")
-			}
+		integer, decimal, _ := strings.Cut(quantityStr, ".")
+		if strings.Trim(decimal, "0") != "" {
+			return ByteQuantity{}, fmt.Errorf("cannot accept floating point MB value, only integers are accepted")
 		}
 		// need error here so that this quantity doesn't bind in the local scope
 		var err error
-		quantity, err = strconv.ParseUint(inputDecSplit[0], 10, 64)
+		quantity, err = strconv.ParseUint(integer, 10, 64)
 		if err != nil {
 			return ByteQuantity{}, err
 		}
@@ -77,7 +104,7 @@ func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
 		if err != nil {
 			return ByteQuantity{}, err
 		}
-		if quantityDec > maxGiB {
+		if quantityDec*gbConvert >= maxQuantity {
 			return ByteQuantity{}, fmt.Errorf("error GiB value is too large")
 		}
 		quantity = uint64(quantityDec * gbConvert)
@@ -87,10 +114,20 @@ func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
 		if err != nil {
 			return ByteQuantity{}, err
 		}
-		if quantityDec > maxTiB {
+		if quantityDec*tbConvert >= maxQuantity {
 			return ByteQuantity{}, fmt.Errorf("error TiB value is too large")
 		}
 		quantity = uint64(quantityDec * tbConvert)
+	// pib
+	case "p":
+		quantityDec, err := strconv.ParseFloat(quantityStr, 64)
+		if err != nil {
+			return ByteQuantity{}, err
+		}
+		if quantityDec*pbConvert >= maxQuantity {
+			return ByteQuantity{}, fmt.Errorf("error PiB value is too large")
+		}
+		quantity = uint64(quantityDec * pbConvert)
 	default:
 		return ByteQuantity{}, fmt.Errorf("error unit %s is not supported", unit)
 	}
@@ -100,6 +137,32 @@ func ParseToByteQuantity(byteQuantityStr string) (ByteQuantity, error) {
 	}, nil
 }
 
+// parseSIByteQuantity converts a quantity of a decimal (SI) unit like GB to a ByteQuantity rounded to the nearest MiB.
+func parseSIByteQuantity(quantityStr string, unit string) (ByteQuantity, error) {
+	unitBytes, ok := siBytes[unit[:1]]
+	if !ok {
+		return ByteQuantity{}, fmt.Errorf("error unit %s is not supported", unit)
+	}
+	quantityDec, err := strconv.ParseFloat(quantityStr, 64)
+	if err != nil {
+		return ByteQuantity{}, err
+	}
+	quantityMiB := math.Round(quantityDec * unitBytes / bytesPerMiB)
+	if quantityMiB >= maxQuantity {
+		return ByteQuantity{}, fmt.Errorf("error %s value is too large", strings.ToUpper(unit))
+	}
+	return ByteQuantity{
+		Quantity: uint64(quantityMiB),
+	}, nil
+}
+
+// FromPiB returns a byte quantity of the passed in pebibytes quantity.
+func FromPiB(pib uint64) ByteQuantity {
+	return ByteQuantity{
+		Quantity: pib * pbConvert,
+	}
+}
+
 // FromTiB returns a byte quantity of the passed in tebibytes quantity.
 func FromTiB(tib uint64) ByteQuantity {
 	return ByteQuantity{
@@ -122,8 +185,9 @@ func FromMiB(mib uint64) ByteQuantity {
 }
 
 // StringMiB returns a byte quantity in a mebibytes string representation.
+// The quantity is formatted as an integer so that quantities larger than a float64 can represent exactly round trip.
 func (bq ByteQuantity) StringMiB() string {
-	return fmt.Sprintf("%.0f %s", bq.MiB(), mib)
+	return fmt.Sprintf("%d %s", bq.Quantity, mib)
 }
 
 // StringGiB returns a byte quantity in a gibibytes string representation.
@@ -136,6 +200,11 @@ func (bq ByteQuantity) StringTiB() string {
 	return fmt.Sprintf("%.3f %s", bq.TiB(), tib)
 }
 
+// StringPiB returns a byte quantity in a pebibytes string representation.
+func (bq ByteQuantity) StringPiB() string {
+	return fmt.Sprintf("%.3f %s", bq.PiB(), pib)
+}
+
 // MiB returns a byte quantity in mebibytes.
 func (bq ByteQuantity) MiB() float64 {
 	return float64(bq.Quantity)
@@ -150,3 +219,27 @@ func (bq ByteQuantity) GiB() float64 {
 func (bq ByteQuantity) TiB() float64 {
 	return float64(bq.Quantity) * 1 / tbConvert
 }
+
+// PiB returns a byte quantity in pebibytes.
+func (bq ByteQuantity) PiB() float64 {
+	return float64(bq.Quantity) * 1 / pbConvert
+}
+
+// MarshalYAML returns a byte quantity in a mebibytes string representation so that it round trips through UnmarshalYAML.
+func (bq ByteQuantity) MarshalYAML() (interface{}, error) {
+	return bq.StringMiB(), nil
+}
+
+// UnmarshalYAML parses a string representation of a byte quantity such as 16 GiB.
+// If no unit is appended, GiB is assumed.
+func (bq *ByteQuantity) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("byte quantity must be a string (Example: 16 GiB)")
+	}
+	parsed, err := ParseToByteQuantity(value.Value)
+	if err != nil {
+		return err
+	}
+	*bq = parsed
+	return nil
+}
@@ -15,7 +15,11 @@ package bytequantity_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
+	"testing/quick"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
@@ -73,6 +77,135 @@ func TestParseToByteQuantity(t *testing.T) {
 	h.Nok(t, err)
 }
 
+func TestParseToByteQuantity_PiB(t *testing.T) {
+	for _, testQuantity := range []string{"2pb", "2 pb", "2.0 pb", "2p", "2pib", "2 P", "2.000 PiB", "2,048 TiB"} {
+		expectationVal := uint64(2 << 30)
+		bq, err := bytequantity.ParseToByteQuantity(testQuantity)
+		h.Ok(t, err)
+		h.Assert(t, bq.Quantity == expectationVal, "quantity should have been %d, got %d instead on string %s", expectationVal, bq.Quantity, testQuantity)
+	}
+	bq, err := bytequantity.ParseToByteQuantity("1.5 PiB")
+	h.Ok(t, err)
+	h.Equals(t, bytequantity.FromTiB(1536), bq)
+
+	// 2^34 PiB is 2^64 MiB which does not fit in a uint64
+	_, err = bytequantity.ParseToByteQuantity("17179869184 pib")
+	h.Nok(t, err)
+}
+
+func TestParseToByteQuantitySI(t *testing.T) {
+	for testQuantity, expectationVal := range map[string]uint64{
+		"500 gb":   476837,
+		"500 GB":   476837,
+		"500 GiB":  512000,
+		"500 g":    476837,
+		"500":      512000,
+		"1000 mb":  954,
+		"1000 MiB": 1000,
+		"1.5 MB":   1,
+		"2 tb":     1907349,
+		"2 TiB":    2097152,
+		"1 pb":     953674316,
+		"1 PiB":    1073741824,
+		"1,000 GB": 953674,
+	} {
+		bq, err := bytequantity.ParseToByteQuantitySI(testQuantity)
+		h.Ok(t, err)
+		h.Assert(t, bq.Quantity == expectationVal, "quantity should have been %d, got %d instead on string %s", expectationVal, bq.Quantity, testQuantity)
+	}
+
+	// binary units are not affected
+	bq, err := bytequantity.ParseToByteQuantitySI("16 GiB")
+	h.Ok(t, err)
+	h.Equals(t, bytequantity.FromGiB(16), bq)
+
+	_, err = bytequantity.ParseToByteQuantitySI("1 NS")
+	h.Nok(t, err)
+	_, err = bytequantity.ParseToByteQuantitySI("20000000000000000 pb")
+	h.Nok(t, err)
+}
+
+func TestParseToByteQuantity_Separators(t *testing.T) {
+	for _, testQuantity := range []string{"1,024 MiB", "1,024MiB", "1,024  mib", " 1,024 MiB ", "1,024.000 MiB", "1 GiB", "1,024"} {
+		expectationVal := uint64(1024)
+		if testQuantity == "1,024" {
+			expectationVal = 1024 * 1024
+		}
+		bq, err := bytequantity.ParseToByteQuantity(testQuantity)
+		h.Ok(t, err)
+		h.Assert(t, bq.Quantity == expectationVal, "quantity should have been %d, got %d instead on string %s", expectationVal, bq.Quantity, testQuantity)
+	}
+
+	bq, err := bytequantity.ParseToByteQuantity("1,048,576 MiB")
+	h.Ok(t, err)
+	h.Equals(t, bytequantity.FromTiB(1), bq)
+
+	// Commas must separate groups of 3 digits
+	for _, testQuantity := range []string{"1,02 MiB", "10,24 MiB", ",1024 MiB", "1024, MiB", "1,,024 MiB", "1.024,5 GiB"} {
+		_, err := bytequantity.ParseToByteQuantity(testQuantity)
+		h.Nok(t, err)
+	}
+}
+
+func TestParseToByteQuantity_Overflow(t *testing.T) {
+	bq, err := bytequantity.ParseToByteQuantity(fmt.Sprintf("%d mib", uint64(math.MaxUint64)))
+	h.Ok(t, err)
+	h.Equals(t, uint64(math.MaxUint64), bq.Quantity)
+
+	// 2^54 GiB and 2^44 TiB are 2^64 MiB which does not fit in a uint64
+	_, err = bytequantity.ParseToByteQuantity("18014398509481984 gib")
+	h.Nok(t, err)
+	_, err = bytequantity.ParseToByteQuantity("17592186044416 tib")
+	h.Nok(t, err)
+
+	bq, err = bytequantity.ParseToByteQuantity("17592186044415 tib")
+	h.Ok(t, err)
+	h.Equals(t, bytequantity.FromTiB(17592186044415), bq)
+}
+
+func TestByteQuantity_RoundTrip(t *testing.T) {
+	// formatting any quantity in MiB and parsing it returns the same quantity
+	roundTripMiB := func(quantity uint64) bool {
+		bq, err := bytequantity.ParseToByteQuantity(bytequantity.FromMiB(quantity).StringMiB())
+		return err == nil && bq.Quantity == quantity
+	}
+	h.Ok(t, quick.Check(roundTripMiB, nil))
+
+	// whole GiB and TiB quantities round trip through their 3 decimal place string representations
+	roundTripGiB := func(gib uint32) bool {
+		bq, err := bytequantity.ParseToByteQuantity(bytequantity.FromGiB(uint64(gib)).StringGiB())
+		return err == nil && bq == bytequantity.FromGiB(uint64(gib))
+	}
+	h.Ok(t, quick.Check(roundTripGiB, nil))
+	roundTripTiB := func(tib uint32) bool {
+		bq, err := bytequantity.ParseToByteQuantity(bytequantity.FromTiB(uint64(tib)).StringTiB())
+		return err == nil && bq == bytequantity.FromTiB(uint64(tib))
+	}
+	h.Ok(t, quick.Check(roundTripTiB, nil))
+}
+
+func FuzzParseToByteQuantity(f *testing.F) {
+	for _, seed := range []string{"10mb", "4", "4.000 GiB", "109 T", "1,024 MiB", "1.001 gb", "109.0001", "18446744073709551616 mib", "18014398509481984 gib", "1 NS", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, byteQuantityStr string) {
+		// SI units are rounded to the nearest MiB but must not panic on any input
+		_, _ = bytequantity.ParseToByteQuantitySI(byteQuantityStr)
+		bq, err := bytequantity.ParseToByteQuantity(byteQuantityStr)
+		if err != nil {
+			return
+		}
+		// any successfully parsed quantity round trips through its MiB string representation
+		roundTrip, err := bytequantity.ParseToByteQuantity(bq.StringMiB())
+		if err != nil {
+			t.Fatalf("unable to parse %q formatted from %q: %v", bq.StringMiB(), byteQuantityStr, err)
+		}
+		if roundTrip != bq {
+			t.Fatalf("%q parsed to %d MiB but its string representation %q parsed to %d MiB", byteQuantityStr, bq.Quantity, bq.StringMiB(), roundTrip.Quantity)
+		}
+	})
+}
+
 func TestStringGiB(t *testing.T) {
 	expectedVal := "0.098 GiB"
 	testVal := uint64(100)
@@ -107,6 +240,12 @@ func TestStringMiB(t *testing.T) {
 	testVal = uint64(2)
 	bq = bytequantity.ByteQuantity{Quantity: testVal}
 	h.Assert(t, bq.StringMiB() == expectedVal, "%d MiB should equal %s, instead got %s", testVal, expectedVal, bq.StringMiB())
+
+	// quantities larger than a float64 can represent exactly are not rounded
+	expectedVal = "18446744073709551615 MiB"
+	testVal = uint64(math.MaxUint64)
+	bq = bytequantity.ByteQuantity{Quantity: testVal}
+	h.Assert(t, bq.StringMiB() == expectedVal, "%d MiB should equal %s, instead got %s", testVal, expectedVal, bq.StringMiB())
 }
 
 func TestFromMiB(t *testing.T) {
@@ -122,9 +261,32 @@ func TestFromGiB(t *testing.T) {
 	h.Assert(t, bq.GiB() == expectedVal, "%d GiB should equal %d, instead got %s", expectedVal, expectedVal, bq.StringGiB())
 }
 
+func TestFromPiB(t *testing.T) {
+	expectedVal := float64(1.0)
+	testVal := uint64(1)
+	bq := bytequantity.FromPiB(testVal)
+	h.Assert(t, bq.PiB() == expectedVal, "%d PiB should equal %d, instead got %s", expectedVal, expectedVal, bq.StringPiB())
+	h.Equals(t, "1.000 PiB", bq.StringPiB())
+}
+
 func TestFromTiB(t *testing.T) {
 	expectedVal := float64(1.0)
 	testVal := uint64(1)
 	bq := bytequantity.FromTiB(testVal)
 	h.Assert(t, bq.TiB() == expectedVal, "%d TiB should equal %d, instead got %s", expectedVal, expectedVal, bq.StringTiB())
 }
+
+func TestByteQuantityYAML(t *testing.T) {
+	out, err := yaml.Marshal(bytequantity.FromGiB(4))
+	h.Ok(t, err)
+	h.Equals(t, "4096 MiB\n", string(out))
+
+	bq := bytequantity.ByteQuantity{}
+	h.Ok(t, yaml.Unmarshal(out, &bq))
+	h.Equals(t, bytequantity.FromGiB(4), bq)
+	h.Ok(t, yaml.Unmarshal([]byte("2"), &bq))
+	h.Equals(t, bytequantity.FromGiB(2), bq)
+
+	h.Nok(t, yaml.Unmarshal([]byte("lots"), &bq))
+	h.Nok(t, yaml.Unmarshal([]byte("Quantity: 2"), &bq))
+}
@@ -18,7 +18,9 @@ import (
 	"math"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -39,28 +41,46 @@ func New(binaryName string, shortUsage string, longUsage, examples string, run r
 		Run:     run,
 	}
 	return CommandLineInterface{
-		Command:     cmd,
-		Flags:       map[string]interface{}{},
-		nilDefaults: map[string]bool{},
-		rangeFlags:  map[string]bool{},
-		validators:  map[string]validator{},
-		processors:  map[string]processor{},
-		suiteFlags:  pflag.NewFlagSet("suite", pflag.ExitOnError),
+		Command:      cmd,
+		Flags:        map[string]interface{}{},
+		nilDefaults:  map[string]bool{},
+		rangeFlags:   map[string]bool{},
+		validators:   map[string]validator{},
+		processors:   map[string]processor{},
+		suiteFlags:   pflag.NewFlagSet("suite", pflag.ExitOnError),
+		flagExamples: map[string]string{},
 	}
 }
 
 // ParseFlags will parse flags registered in this instance of CLI from os.Args.
 func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
+	cl.shareFlagsWithSubCommands()
 	cl.setUsageTemplate()
 	// Remove Suite Flags so that args only include Config and Filter Flags
-	cl.Command.SetArgs(removeIntersectingArgs(cl.suiteFlags))
+	// The first arg is the binary name which cobra does not expect
+	args := removeIntersectingArgs(cl.suiteFlags)
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	cl.Command.SetArgs(args)
 	// This parses Config and Filter flags only
-	if err := cl.Command.Execute(); err != nil {
+	invokedCommand, err := cl.Command.ExecuteC()
+	if err != nil {
 		return nil, err
 	}
+	cl.invokedCommand = invokedCommand
+	// sub-command groups are not runnable so cobra printed their usage as if --help had been passed
+	if !invokedCommand.Runnable() {
+		if helpFlag := invokedCommand.Flags().Lookup("help"); helpFlag != nil {
+			if err := helpFlag.Value.Set("true"); err != nil {
+				return nil, err
+			}
+			helpFlag.Changed = true
+		}
+	}
 
-	// Remove Config and Filter flags so that only suite flags are parsed
-	if err := cl.suiteFlags.Parse(removeIntersectingArgs(cl.Command.Flags())); err != nil {
+	// Remove Config, Filter, and Sub-Command flags so that only suite flags are parsed
+	if err := cl.suiteFlags.Parse(removeIntersectingArgs(cl.nonSuiteFlags())); err != nil {
 		return nil, err
 	}
 
@@ -77,6 +97,89 @@ func (cl *CommandLineInterface) ParseFlags() (map[string]interface{}, error) {
 	return cl.Flags, nil
 }
 
+// SubCommand creates and registers a sub-command which accepts all of the filter and config flags of the root command.
+// Flags specific to the sub-command can be registered on the returned command's flag set.
+// Space separated names (i.e. "regions list") register the last name as a sub-command of a group of sub-commands which
+// only prints its usage when invoked on its own.
+func (cl *CommandLineInterface) SubCommand(name string, shortUsage string, longUsage string, examples string, run runFunc) *cobra.Command {
+	names := strings.Fields(name)
+	parent := cl.Command
+	for _, groupName := range names[:len(names)-1] {
+		group := findSubCommand(parent, groupName)
+		if group == nil {
+			group = &cobra.Command{Use: groupName, Short: fmt.Sprintf("%s sub-commands", groupName)}
+			parent.AddCommand(group)
+		}
+		parent = group
+	}
+	subCommand := &cobra.Command{
+		Use:     names[len(names)-1],
+		Short:   shortUsage,
+		Long:    longUsage,
+		Example: examples,
+		Run:     run,
+	}
+	parent.AddCommand(subCommand)
+	cl.Command.CompletionOptions.DisableDefaultCmd = true
+	return subCommand
+}
+
+// InvokedCommand returns the name of the command or sub-command which was invoked when the flags were parsed.
+// Sub-commands of a group are returned with the group name (i.e. "regions list").
+func (cl *CommandLineInterface) InvokedCommand() string {
+	if cl.invokedCommand == nil || cl.invokedCommand == cl.Command {
+		return cl.Command.Name()
+	}
+	return strings.TrimPrefix(cl.invokedCommand.CommandPath(), cl.Command.Name()+" ")
+}
+
+// InvokedCommandArgs returns the positional arguments of the command or sub-command which was invoked when the flags were parsed.
+func (cl *CommandLineInterface) InvokedCommandArgs() []string {
+	if cl.invokedCommand == nil {
+		return nil
+	}
+	return cl.invokedCommand.Flags().Args()
+}
+
+// findSubCommand returns the direct sub-command of the command with the name or nil if there is none.
+func findSubCommand(command *cobra.Command, name string) *cobra.Command {
+	for _, subCommand := range command.Commands() {
+		if subCommand.Name() == name {
+			return subCommand
+		}
+	}
+	return nil
+}
+
+// allSubCommands returns the sub-commands of the command including the sub-commands of groups.
+func allSubCommands(command *cobra.Command) []*cobra.Command {
+	subCommands := []*cobra.Command{}
+	for _, subCommand := range command.Commands() {
+		subCommands = append(subCommands, subCommand)
+		subCommands = append(subCommands, allSubCommands(subCommand)...)
+	}
+	return subCommands
+}
+
+// shareFlagsWithSubCommands adds the root command's filter flags to each sub-command so that they can be parsed
+// no matter which command is invoked.
+func (cl *CommandLineInterface) shareFlagsWithSubCommands() {
+	for _, subCommand := range allSubCommands(cl.Command) {
+		subCommand.Flags().AddFlagSet(cl.Command.Flags())
+	}
+}
+
+// nonSuiteFlags returns a flag set containing the filter, config, and sub-command flags.
+func (cl *CommandLineInterface) nonSuiteFlags() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet("non-suite", pflag.ContinueOnError)
+	flagSet.AddFlagSet(cl.Command.Flags())
+	flagSet.AddFlagSet(cl.Command.PersistentFlags())
+	for _, subCommand := range allSubCommands(cl.Command) {
+		flagSet.AddFlagSet(subCommand.Flags())
+	}
+	return flagSet
+}
+
 // ParseAndValidateFlags will parse flags registered in this instance of CLI from os.Args
 // and then perform validation.
 func (cl *CommandLineInterface) ParseAndValidateFlags() (map[string]interface{}, error) {
@@ -107,7 +210,8 @@ func (cl *CommandLineInterface) ProcessFlags() error {
 	return nil
 }
 
-// ValidateFlags iterates through any registered validators and executes them.
+// ValidateFlags iterates through any registered validators and executes them
+// and then checks the registered mutually exclusive and dependent flag relationships.
 func (cl *CommandLineInterface) ValidateFlags() error {
 	for flagName, validationFn := range cl.validators {
 		if validationFn == nil {
@@ -118,9 +222,104 @@ func (cl *CommandLineInterface) ValidateFlags() error {
 			return err
 		}
 	}
+	return cl.ValidateFlagRelationships()
+}
+
+// MarkFlagsMutuallyExclusive registers a set of flags of which at most one can be set.
+// Flags with a default value are always considered set.
+func (cl *CommandLineInterface) MarkFlagsMutuallyExclusive(flagNames ...string) {
+	cl.exclusiveFlags = append(cl.exclusiveFlags, flagNames)
+}
+
+// MarkFlagRequires registers that flagName can only be set when requiredFlag is set.
+// If requiredValues are passed in, requiredFlag must also be set to one of the values.
+func (cl *CommandLineInterface) MarkFlagRequires(flagName string, requiredFlag string, requiredValues ...string) {
+	cl.flagDependencies = append(cl.flagDependencies, flagDependency{
+		flagName:       flagName,
+		requiredFlag:   requiredFlag,
+		requiredValues: requiredValues,
+	})
+}
+
+// MarkFlagConflicts registers that flagName can not be set together with conflictingFlag.
+// If conflictingValues are passed in, flagName only conflicts with conflictingFlag set to one of the values.
+func (cl *CommandLineInterface) MarkFlagConflicts(flagName string, conflictingFlag string, conflictingValues ...string) {
+	cl.flagConflicts = append(cl.flagConflicts, flagConflict{
+		flagName:          flagName,
+		conflictingFlag:   conflictingFlag,
+		conflictingValues: conflictingValues,
+	})
+}
+
+// ValidateFlagRelationships returns an error if more than one of a set of mutually exclusive flags is set,
+// if a flag is set together with a conflicting flag (and value), or if a flag is set without the flag (and value) it requires.
+func (cl *CommandLineInterface) ValidateFlagRelationships() error {
+	for _, flagNames := range cl.exclusiveFlags {
+		setFlags := []string{}
+		for _, flagName := range flagNames {
+			if cl.isFlagSet(flagName) {
+				setFlags = append(setFlags, "--"+flagName)
+			}
+		}
+		if len(setFlags) > 1 {
+			return fmt.Errorf("error: %s cannot be used together", strings.Join(setFlags, " and "))
+		}
+	}
+	for _, conflict := range cl.flagConflicts {
+		if !cl.isFlagSet(conflict.flagName) || !cl.isFlagSet(conflict.conflictingFlag) {
+			continue
+		}
+		if len(conflict.conflictingValues) == 0 {
+			return fmt.Errorf("error: --%s and --%s cannot be used together", conflict.flagName, conflict.conflictingFlag)
+		}
+		value := flagValueString(cl.Flags[conflict.conflictingFlag])
+		if slices.Contains(conflict.conflictingValues, value) {
+			return fmt.Errorf("error: --%s cannot be used with --%s %s", conflict.flagName, conflict.conflictingFlag, value)
+		}
+	}
+	for _, dependency := range cl.flagDependencies {
+		if !cl.isFlagSet(dependency.flagName) {
+			continue
+		}
+		if !cl.isFlagSet(dependency.requiredFlag) {
+			if len(dependency.requiredValues) == 0 {
+				return fmt.Errorf("error: --%s requires --%s to be set", dependency.flagName, dependency.requiredFlag)
+			}
+			return fmt.Errorf("error: --%s requires --%s %s", dependency.flagName, dependency.requiredFlag, strings.Join(dependency.requiredValues, " or "))
+		}
+		if len(dependency.requiredValues) == 0 {
+			continue
+		}
+		value := flagValueString(cl.Flags[dependency.requiredFlag])
+		if !slices.Contains(dependency.requiredValues, value) {
+			return fmt.Errorf("error: --%s requires --%s %s, got %s", dependency.flagName, dependency.requiredFlag, strings.Join(dependency.requiredValues, " or "), value)
+		}
+	}
 	return nil
 }
 
+// isFlagSet returns true if the flag has a value, untouched flags without a default value are nil.
+func (cl *CommandLineInterface) isFlagSet(flagName string) bool {
+	value, ok := cl.Flags[flagName]
+	if !ok || value == nil {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	return rv.Kind() != reflect.Ptr || !rv.IsNil()
+}
+
+// flagValueString returns the string representation of a flag value, dereferencing pointers.
+func flagValueString(value interface{}) string {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
 func removeIntersectingArgs(flagSet *pflag.FlagSet) []string {
 	newArgs := []string{}
 	skipNext := false
@@ -149,29 +348,129 @@ func shorthandLookup(flagSet *pflag.FlagSet, arg string) *pflag.Flag {
 	return nil
 }
 
-func (cl *CommandLineInterface) setUsageTemplate() {
-	transformedUsage := usageTemplate
-	suiteFlagCount := 0
-	cl.suiteFlags.VisitAll(func(*pflag.Flag) {
-		suiteFlagCount++
+// AddFlagGroup registers a named group of flags which are printed together in the output of --help.
+// Groups are printed in the order they are registered, between the ungrouped filter flags and the ungrouped suite and config flags.
+// The min and max helper flags of range flags are added to the group of the range flag.
+func (cl *CommandLineInterface) AddFlagGroup(title string, sorted bool, flagNames ...string) {
+	cl.flagGroups = append(cl.flagGroups, flagGroup{
+		title:     title,
+		sorted:    sorted,
+		flagNames: flagNames,
 	})
-	if suiteFlagCount > 0 {
-		transformedUsage = fmt.Sprintf(transformedUsage, "\n\nSuite Flags:\n"+cl.suiteFlags.FlagUsages()+"\n")
-	} else {
-		transformedUsage = fmt.Sprintf(transformedUsage, "")
+}
+
+// SetFlagExample registers an example value which is appended to the flag's description in the output of --help.
+func (cl *CommandLineInterface) SetFlagExample(name string, example string) {
+	cl.flagExamples[name] = example
+}
+
+// SetSIUnitsFlag registers a bool flag which, when set, parses byte quantity flags with decimal (SI) units so that
+// 1 GB is 1,000,000,000 bytes rather than 1 GiB.
+func (cl *CommandLineInterface) SetSIUnitsFlag(name string) {
+	cl.siUnitsFlag = name
+}
+
+// parseByteQuantity parses a byte quantity flag value with decimal (SI) units if the SI units flag is set.
+func (cl *CommandLineInterface) parseByteQuantity(byteQuantityStr string) (bytequantity.ByteQuantity, error) {
+	if siUnits := cl.BoolMe(cl.Flags[cl.siUnitsFlag]); siUnits != nil && *siUnits {
+		return bytequantity.ParseToByteQuantitySI(byteQuantityStr)
+	}
+	return bytequantity.ParseToByteQuantity(byteQuantityStr)
+}
+
+func (cl *CommandLineInterface) setUsageTemplate() {
+	commands := append([]*cobra.Command{cl.Command}, allSubCommands(cl.Command)...)
+	for _, command := range commands {
+		command.SetUsageTemplate(fmt.Sprintf(usageTemplate, cl.flagGroupUsages(command)))
 	}
-	cl.Command.SetUsageTemplate(transformedUsage)
 	cl.suiteFlags.Usage = func() {}
 	cl.Command.Flags().Usage = func() {}
 }
 
+// flagGroupUsages returns the usage sections of the command's flags with the ungrouped filter flags first,
+// followed by the registered flag groups and the ungrouped suite and config flags.
+func (cl *CommandLineInterface) flagGroupUsages(command *cobra.Command) string {
+	grouped := map[string]bool{}
+	for _, group := range cl.flagGroups {
+		for _, flagName := range group.flagNames {
+			for _, name := range cl.withRangeHelpers(flagName) {
+				grouped[name] = true
+			}
+		}
+	}
+	ungrouped := func(title string, flagSets ...*pflag.FlagSet) flagGroup {
+		group := flagGroup{title: title, sorted: true}
+		for _, flagSet := range flagSets {
+			flagSet.VisitAll(func(f *pflag.Flag) {
+				if !grouped[f.Name] {
+					group.flagNames = append(group.flagNames, f.Name)
+				}
+			})
+		}
+		return group
+	}
+	groups := []flagGroup{ungrouped("Filter Flags", command.LocalNonPersistentFlags())}
+	groups = append(groups, cl.flagGroups...)
+	groups = append(groups,
+		ungrouped("Suite Flags", cl.suiteFlags),
+		ungrouped("Global Flags", command.PersistentFlags(), command.InheritedFlags()))
+
+	usages := strings.Builder{}
+	for _, group := range groups {
+		flagSet := pflag.NewFlagSet(group.title, pflag.ContinueOnError)
+		flagSet.SortFlags = group.sorted
+		for _, flagName := range group.flagNames {
+			for _, name := range cl.withRangeHelpers(flagName) {
+				if f := lookupFlag(name, command.Flags(), command.InheritedFlags(), cl.suiteFlags); f != nil && flagSet.Lookup(name) == nil {
+					flagSet.AddFlag(cl.withExample(f))
+				}
+			}
+		}
+		if !flagSet.HasAvailableFlags() {
+			continue
+		}
+		// flag usages are inserted into the usage template so template actions have to be escaped
+		flagUsages := strings.ReplaceAll(strings.TrimRight(flagSet.FlagUsages(), " \n"), "{{", `{{"{{"}}`)
+		usages.WriteString(fmt.Sprintf("\n\n%s:\n%s", group.title, flagUsages))
+	}
+	return usages.String()
+}
+
+// withRangeHelpers returns the flag name and the names of its min and max helper flags if it is a range flag.
+func (cl *CommandLineInterface) withRangeHelpers(flagName string) []string {
+	if !cl.rangeFlags[flagName] {
+		return []string{flagName}
+	}
+	return []string{flagName, fmt.Sprintf("%s-%s", flagName, "min"), fmt.Sprintf("%s-%s", flagName, "max")}
+}
+
+// withExample returns a copy of the flag with its registered example appended to the description.
+func (cl *CommandLineInterface) withExample(f *pflag.Flag) *pflag.Flag {
+	example, ok := cl.flagExamples[f.Name]
+	if !ok {
+		return f
+	}
+	flagWithExample := *f
+	flagWithExample.Usage = fmt.Sprintf("%s (Example: %s)", f.Usage, example)
+	return &flagWithExample
+}
+
+func lookupFlag(name string, flagSets ...*pflag.FlagSet) *pflag.Flag {
+	for _, flagSet := range flagSets {
+		if f := flagSet.Lookup(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
 // SetUntouchedFlagValuesToNil iterates through all flags and sets their value to nil if they were not specifically set by the user
 // This allows for a specified value, a negative value (like false or empty string), or an unspecified (nil) entry.
 func (cl *CommandLineInterface) SetUntouchedFlagValuesToNil() error {
 	defaultHandlerErrMsg := "Unable to find a default value handler for %v, marking as no default value. This could be an error"
 	defaultHandlerFlags := []string{}
 
-	cl.Command.Flags().VisitAll(func(f *pflag.Flag) {
+	cl.nonSuiteFlags().VisitAll(func(f *pflag.Flag) {
 		if !f.Changed {
 			// If nilDefaults entry for flag is set to false, do not change default
 			if val := cl.nilDefaults[f.Name]; !val {
@@ -202,10 +501,18 @@ func (cl *CommandLineInterface) SetUntouchedFlagValuesToNil() error {
 				if reflect.ValueOf(*v).IsZero() {
 					cl.Flags[f.Name] = nil
 				}
+			case *time.Duration:
+				if *v == 0 {
+					cl.Flags[f.Name] = nil
+				}
 			case *[]string:
 				if reflect.ValueOf(v).IsZero() {
 					cl.Flags[f.Name] = nil
 				}
+			case *[]int32:
+				if len(*v) == 0 {
+					cl.Flags[f.Name] = nil
+				}
 			default:
 				defaultHandlerFlags = append(defaultHandlerFlags, f.Name)
 				cl.Flags[f.Name] = nil
@@ -16,8 +16,12 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -66,6 +70,125 @@ func TestValidateFlags(t *testing.T) {
 	h.Nok(t, err)
 }
 
+func TestParseAndValidateFlags_MutuallyExclusive(t *testing.T) {
+	cli := getTestCLI()
+	cli.StringFlag("base", nil, nil, "Test Base", nil)
+	cli.BoolFlag("flexible", nil, nil, "Test Flexible")
+	cli.MarkFlagsMutuallyExclusive("base", "flexible")
+	os.Args = []string{"", "--base", "m5.large"}
+	_, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+
+	cli = getTestCLI()
+	cli.StringFlag("base", nil, nil, "Test Base", nil)
+	cli.BoolFlag("flexible", nil, nil, "Test Flexible")
+	cli.MarkFlagsMutuallyExclusive("base", "flexible")
+	os.Args = []string{"", "--base", "m5.large", "--flexible"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --base and --flexible cannot be used together", err.Error())
+}
+
+func TestParseAndValidateFlags_Conflicts(t *testing.T) {
+	newCLI := func() cli.CommandLineInterface {
+		cli := getTestCLI()
+		cli.BoolFlag("include-previous", nil, nil, "Test Include Previous")
+		cli.BoolFlag("current", nil, nil, "Test Current")
+		cli.MarkFlagConflicts("include-previous", "current", "true")
+		return cli
+	}
+	cli := newCLI()
+	os.Args = []string{"", "--include-previous", "--current=false"}
+	_, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+
+	cli = newCLI()
+	os.Args = []string{"", "--include-previous", "--current"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --include-previous cannot be used with --current true", err.Error())
+
+	cli = getTestCLI()
+	cli.StringFlag("base", nil, nil, "Test Base", nil)
+	cli.BoolFlag("flexible", nil, nil, "Test Flexible")
+	cli.MarkFlagConflicts("base", "flexible")
+	os.Args = []string{"", "--base", "m5.large", "--flexible=false"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --base and --flexible cannot be used together", err.Error())
+}
+
+func TestParseAndValidateFlags_Requires(t *testing.T) {
+	newCLI := func() cli.CommandLineInterface {
+		cli := getTestCLI()
+		cli.IntFlag("days-back", nil, nil, "Test Days Back")
+		cli.StringOptionsFlag("usage-class", nil, nil, "Test Usage Class", []string{"spot", "on-demand"})
+		cli.MarkFlagRequires("days-back", "usage-class", "spot")
+		return cli
+	}
+	cli := newCLI()
+	os.Args = []string{"", "--days-back", "5", "--usage-class", "spot"}
+	_, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+
+	cli = newCLI()
+	os.Args = []string{"", "--usage-class", "on-demand"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+
+	cli = newCLI()
+	os.Args = []string{"", "--days-back", "5"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --days-back requires --usage-class spot", err.Error())
+
+	cli = newCLI()
+	os.Args = []string{"", "--days-back", "5", "--usage-class", "on-demand"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --days-back requires --usage-class spot, got on-demand", err.Error())
+
+	cli = getTestCLI()
+	cli.IntFlag("days-back", nil, nil, "Test Days Back")
+	cli.StringFlag("region", nil, nil, "Test Region", nil)
+	cli.MarkFlagRequires("days-back", "region")
+	os.Args = []string{"", "--days-back", "5"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+	h.Equals(t, "error: --days-back requires --region to be set", err.Error())
+}
+
+func TestParseFlags_FlagGroups(t *testing.T) {
+	cli := getTestCLI()
+	cli.IntMinMaxRangeFlags("vcpus", nil, nil, "Test vcpus")
+	cli.StringFlag("gpu-model", nil, nil, "Test GPU Model", nil)
+	cli.SuiteBoolFlag("flexible", nil, nil, "Test Flexible")
+	cli.ConfigStringFlag("region", nil, nil, "Test Region", nil)
+	cli.ConfigStringFlag("output", nil, nil, "Test Output", nil)
+	cli.ConfigBoolFlag("verbose", nil, nil, "Test Verbose")
+	cli.ConfigBoolFlag("debug", nil, nil, "Test Debug")
+	cli.AddFlagGroup("Compute Flags", true, "vcpus")
+	cli.AddFlagGroup("Output Flags", false, "verbose", "output")
+	cli.AddFlagGroup("AWS Flags", true, "region")
+	cli.SetFlagExample("region", "us-east-2")
+	usage := &strings.Builder{}
+	cli.Command.SetOut(usage)
+	os.Args = []string{"", "--help"}
+	_, err := cli.ParseFlags()
+	h.Ok(t, err)
+
+	sections := regexp.MustCompile(`(?m)^([A-Za-z ]+):$`).FindAllStringSubmatch(usage.String(), -1)
+	titles := []string{}
+	for _, section := range sections {
+		titles = append(titles, section[1])
+	}
+	h.Equals(t, []string{"Usage", "Examples", "Filter Flags", "Compute Flags", "Output Flags", "AWS Flags", "Suite Flags", "Global Flags"}, titles)
+	h.Assert(t, regexp.MustCompile(`Compute Flags:\n\s+--vcpus int .*\n\s+--vcpus-max int .*\n\s+--vcpus-min int `).MatchString(usage.String()), "vcpus range helper flags should be grouped with --vcpus:\n%s", usage.String())
+	h.Assert(t, regexp.MustCompile(`Output Flags:\n\s+--verbose .*\n\s+--output string `).MatchString(usage.String()), "Output flags should be in registration order:\n%s", usage.String())
+	h.Assert(t, strings.Contains(usage.String(), "Test Region (Example: us-east-2)"), "--region should include its example:\n%s", usage.String())
+	h.Assert(t, regexp.MustCompile(`Global Flags:\n\s+--debug\s`).MatchString(usage.String()), "--debug should be an ungrouped global flag:\n%s", usage.String())
+}
+
 func TestParseAndValidateFlags_Ratio(t *testing.T) {
 	// Nil validator should succeed validation
 	cli := getTestCLI()
@@ -103,6 +226,24 @@ func TestParseAndValidateFlags_StringOptions(t *testing.T) {
 	h.Nok(t, err)
 }
 
+func TestParseAndValidateFlags_StringSliceOptions(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-string-slice-opts-flag"
+	opts := []string{"opt1", "opt2", "opt3"}
+	cli.StringSliceOptionsFlagOnFlagSet(cli.Command.Flags(), flagName, nil, nil, "Test String Slice Options", opts)
+	os.Args = []string{"", "--" + flagName, "opt1,opt3"}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	h.Equals(t, []string{"opt1", "opt3"}, *flags[flagName].(*[]string))
+
+	// every value must be an option
+	cli = getTestCLI()
+	cli.StringSliceOptionsFlagOnFlagSet(cli.Command.Flags(), flagName, nil, nil, "Test String Slice Options w/ validation failure", opts)
+	os.Args = []string{"", "--" + flagName, "opt1,opt55"}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+}
+
 func TestParseFlags(t *testing.T) {
 	cli := getTestCLI()
 	flagName := "test-flag"
@@ -311,6 +452,32 @@ func TestParseAndValidateFlags_ByteQuantityRange(t *testing.T) {
 	h.Ok(t, err)
 }
 
+func TestParseAndValidateFlags_SIUnits(t *testing.T) {
+	flagName := "test-flag"
+	siUnitsFlag := "si-units"
+
+	// byte quantity units are binary unless the SI units flag is set
+	cli := getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	cli.ConfigBoolFlag(siUnitsFlag, nil, nil, "Test SI Units")
+	cli.SetSIUnitsFlag(siUnitsFlag)
+	os.Args = []string{"ec2-instance-selector", "--" + flagName, "500 GB"}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	h.Equals(t, bytequantity.FromGiB(500), flags[flagName].(*selector.ByteQuantityRangeFilter).LowerBound)
+
+	cli = getTestCLI()
+	cli.ByteQuantityMinMaxRangeFlags(flagName, nil, nil, "Test")
+	cli.ConfigBoolFlag(siUnitsFlag, nil, nil, "Test SI Units")
+	cli.SetSIUnitsFlag(siUnitsFlag)
+	os.Args = []string{"ec2-instance-selector", "--" + flagName + "-min", "500 GB", "--" + flagName + "-max", "500 GiB", "--" + siUnitsFlag}
+	flags, err = cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	byteQuantityRange := flags[flagName].(*selector.ByteQuantityRangeFilter)
+	h.Equals(t, bytequantity.FromMiB(476837), byteQuantityRange.LowerBound)
+	h.Equals(t, bytequantity.FromGiB(500), byteQuantityRange.UpperBound)
+}
+
 func TestParseFlags_RootErr(t *testing.T) {
 	cli := getTestCLI()
 	os.Args = []string{"ec2-instance-selector", "--test", "test"}
@@ -350,6 +517,88 @@ func TestParseFlags_ConfigFlags(t *testing.T) {
 	h.Assert(t, *flagOutput == true, "Config Flag %s should have been parsed", flagArg)
 }
 
+func TestParseFlags_SubCommand(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-flag"
+	subFlagName := "test-sub-flag"
+	cli.StringFlag(flagName, nil, nil, "Test Filter Flag", nil)
+	subCommand := cli.SubCommand("sub", "sub short usage", "sub long usage", "sub examples", func(cmd *cobra.Command, args []string) {})
+	cli.StringFlagOnFlagSet(subCommand.Flags(), subFlagName, nil, nil, "Test Sub-Command Flag", nil, nil)
+	os.Args = []string{"ec2-instance-selector", "sub", "--" + flagName, "test", "--" + subFlagName, "sub-test"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "sub", cli.InvokedCommand())
+	h.Equals(t, "test", *flags[flagName].(*string))
+	h.Equals(t, "sub-test", *flags[subFlagName].(*string))
+
+	cli = getTestCLI()
+	cli.StringFlag(flagName, nil, nil, "Test Filter Flag", nil)
+	subCommand = cli.SubCommand("sub", "sub short usage", "sub long usage", "sub examples", func(cmd *cobra.Command, args []string) {})
+	cli.StringFlagOnFlagSet(subCommand.Flags(), subFlagName, nil, nil, "Test Sub-Command Flag", nil, nil)
+	os.Args = []string{"ec2-instance-selector", "--" + flagName, "test"}
+	flags, err = cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "test", cli.InvokedCommand())
+	h.Assert(t, flags[subFlagName] == nil, "Sub-Command Flag %s should be nil when the sub-command is not invoked", subFlagName)
+}
+
+func TestParseFlags_SubCommandArgs(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-flag"
+	cli.StringFlag(flagName, nil, nil, "Test Filter Flag", nil)
+	subCommand := cli.SubCommand("sub", "sub short usage", "sub long usage", "sub examples", func(cmd *cobra.Command, args []string) {})
+	subCommand.Args = cobra.MinimumNArgs(1)
+	os.Args = []string{"ec2-instance-selector", "sub", "m5.large", "--" + flagName, "test", "c7g.xlarge"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "sub", cli.InvokedCommand())
+	h.Equals(t, []string{"m5.large", "c7g.xlarge"}, cli.InvokedCommandArgs())
+	h.Equals(t, "test", *flags[flagName].(*string))
+}
+
+func TestParseFlags_SubCommandGroup(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-flag"
+	cli.StringFlag(flagName, nil, nil, "Test Filter Flag", nil)
+	cli.SubCommand("group list", "list short usage", "list long usage", "list examples", func(cmd *cobra.Command, args []string) {})
+	cli.SubCommand("group show", "show short usage", "show long usage", "show examples", func(cmd *cobra.Command, args []string) {})
+	h.Equals(t, 1, len(cli.Command.Commands()))
+	os.Args = []string{"ec2-instance-selector", "group", "list", "--" + flagName, "test"}
+	flags, err := cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "group list", cli.InvokedCommand())
+	h.Equals(t, "test", *flags[flagName].(*string))
+
+	cli = getTestCLI()
+	cli.BoolFlag("help", nil, nil, "Help")
+	cli.SubCommand("group list", "list short usage", "list long usage", "list examples", func(cmd *cobra.Command, args []string) {})
+	os.Args = []string{"ec2-instance-selector", "group"}
+	flags, err = cli.ParseFlags()
+	h.Ok(t, err)
+	h.Equals(t, "group", cli.InvokedCommand())
+	h.Assert(t, flags["help"] != nil, "help should be set when a sub-command group is invoked on its own")
+}
+
+func TestParseFlags_Duration(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-duration"
+	defaultFlagName := "test-default-duration"
+	cli.DurationFlagOnFlagSet(cli.Command.Flags(), flagName, nil, nil, "Test Duration")
+	cli.DurationFlagOnFlagSet(cli.Command.Flags(), defaultFlagName, nil, cli.DurationMe(time.Hour), "Test Default Duration")
+	os.Args = []string{"ec2-instance-selector", "--" + flagName, "30m"}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	h.Equals(t, 30*time.Minute, *cli.DurationMe(flags[flagName]))
+	h.Equals(t, time.Hour, *cli.DurationMe(flags[defaultFlagName]))
+
+	cli = getTestCLI()
+	cli.DurationFlagOnFlagSet(cli.Command.Flags(), flagName, nil, nil, "Test Duration")
+	os.Args = []string{"ec2-instance-selector"}
+	flags, err = cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	h.Assert(t, flags[flagName] == nil, "%s should be nil when it is not passed in", flagName)
+}
+
 func TestParseFlags_AllTypes(t *testing.T) {
 	cli := getTestCLI()
 	flagName := "test-flag"
@@ -461,6 +710,16 @@ func TestParseAndValidateRegexFlag(t *testing.T) {
 	_, err = cli.ParseAndValidateFlags()
 	h.Ok(t, err)
 
+	// globs must match the whole instance type name
+	cli = getTestCLI()
+	cli.RegexFlag(flagName, nil, nil, "Test with validation")
+	os.Args = []string{"ec2-instance-selector", flagArg, "m5*.large"}
+	flags, err = cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	regex := cli.RegexMe(flags[flagName])
+	h.Assert(t, regex.MatchString("m5dn.large"), "Should match an instance type matching the glob")
+	h.Assert(t, !regex.MatchString("xm5.large"), "Should match whole instance type names only")
+
 	cli = getTestCLI()
 	cli.RegexFlag(flagName, nil, nil, "Test with validation")
 	os.Args = []string{"ec2-instance-selector", flagArg, "(("}
@@ -468,6 +727,39 @@ func TestParseAndValidateRegexFlag(t *testing.T) {
 	h.Nok(t, err)
 }
 
+func TestParseAndValidateRegexFileFlag(t *testing.T) {
+	flagName := "test-regex-file-flag"
+	flagArg := fmt.Sprintf("--%s", flagName)
+	regexFile := filepath.Join(t.TempDir(), "instance-types.txt")
+	h.Ok(t, os.WriteFile(regexFile, []byte("# allowed instance types\nm5.large\n\nc5\\..*\nr?.large\n"), 0o600))
+
+	cli := getTestCLI()
+	cli.RegexFileFlag(flagName, nil, nil, "Test with validation")
+	os.Args = []string{"ec2-instance-selector", flagArg, regexFile}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+	regex := cli.RegexMe(flags[flagName])
+	h.Assert(t, regex != nil, "%s should have been processed into a regex", flagArg)
+	h.Assert(t, regex.MatchString("m5.large"), "Should match an instance type name in the file")
+	h.Assert(t, regex.MatchString("c5.xlarge"), "Should match an instance type pattern in the file")
+	h.Assert(t, !regex.MatchString("m5.xlarge"), "Should match whole instance type names only")
+	h.Assert(t, regex.MatchString("r5.large"), "Should match an instance type glob in the file")
+	h.Assert(t, !regex.MatchString("r5a.large"), "Should match whole instance type names of globs only")
+
+	h.Ok(t, os.WriteFile(regexFile, []byte("m5.large\n((\n"), 0o600))
+	cli = getTestCLI()
+	cli.RegexFileFlag(flagName, nil, nil, "Test with validation")
+	os.Args = []string{"ec2-instance-selector", flagArg, regexFile}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+
+	cli = getTestCLI()
+	cli.RegexFileFlag(flagName, nil, nil, "Test with validation")
+	os.Args = []string{"ec2-instance-selector", flagArg, filepath.Join(t.TempDir(), "does-not-exist.txt")}
+	_, err = cli.ParseAndValidateFlags()
+	h.Nok(t, err)
+}
+
 func TestParseAndValidateByteQuantityFlag(t *testing.T) {
 	flagName := "test-bq-flag"
 	flagArg := fmt.Sprintf("--%s", flagName)
@@ -0,0 +1,162 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+// Struct tags of the selector.Filters fields which are used to generate flags.
+const (
+	// flagTag is the name of the flag which sets the field
+	flagTag = "flag"
+	// shorthandTag is the single letter shorthand of the flag
+	shorthandTag = "short"
+	// descriptionTag is the description of the flag printed in the output of --help
+	descriptionTag = "description"
+	// optionsTag is a comma separated list of the values accepted by a string flag
+	optionsTag = "options"
+	// flagKindTag overrides the kind of flag which is otherwise derived from the field type
+	flagKindTag = "flagKind"
+	// flagSetTag is the flag set the flag is registered on, filter flags are registered on the root command by default
+	flagSetTag = "flagSet"
+
+	flagKindRatio = "ratio"
+	flagSetSuite  = "suite"
+)
+
+var (
+	int32RangeFilterType        = reflect.TypeOf(selector.Int32RangeFilter{})
+	intRangeFilterType          = reflect.TypeOf(selector.IntRangeFilter{})
+	float64RangeFilterType      = reflect.TypeOf(selector.Float64RangeFilter{})
+	byteQuantityRangeFilterType = reflect.TypeOf(selector.ByteQuantityRangeFilter{})
+	regexpType                  = reflect.TypeOf(regexp.Regexp{})
+)
+
+// FilterFlags creates and registers a flag for each selector.Filters field with a flag struct tag.
+// The kind of flag is derived from the field type, string and string slice fields with an options struct tag only accept those values.
+func (cl *CommandLineInterface) FilterFlags() error {
+	filtersType := reflect.TypeOf(selector.Filters{})
+	for i := 0; i < filtersType.NumField(); i++ {
+		field := filtersType.Field(i)
+		name := field.Tag.Get(flagTag)
+		if name == "" {
+			continue
+		}
+		var shorthand *string
+		if short := field.Tag.Get(shorthandTag); short != "" {
+			shorthand = &short
+		}
+		description := field.Tag.Get(descriptionTag)
+		flagSet := cl.Command.Flags()
+		if field.Tag.Get(flagSetTag) == flagSetSuite {
+			flagSet = cl.suiteFlags
+		}
+
+		fieldType := field.Type.Elem()
+		switch {
+		case field.Tag.Get(flagKindTag) == flagKindRatio:
+			cl.RatioFlag(name, shorthand, nil, description)
+		case fieldType == int32RangeFilterType:
+			cl.Int32MinMaxRangeFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType == intRangeFilterType:
+			cl.IntMinMaxRangeFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType == float64RangeFilterType:
+			cl.Float64MinMaxRangeFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType == byteQuantityRangeFilterType:
+			cl.ByteQuantityMinMaxRangeFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType == regexpType:
+			cl.RegexFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType.Kind() == reflect.Bool:
+			cl.BoolFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType.Kind() == reflect.Int:
+			cl.IntFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType.Kind() == reflect.Float64:
+			cl.Float64FlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		case fieldType.Kind() == reflect.String:
+			if options := field.Tag.Get(optionsTag); options != "" {
+				cl.StringOptionsFlagOnFlagSet(flagSet, name, shorthand, nil, description, strings.Split(options, ","))
+			} else {
+				cl.StringFlagOnFlagSet(flagSet, name, shorthand, nil, description, nil, nil)
+			}
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.String:
+			if options := field.Tag.Get(optionsTag); options != "" {
+				cl.StringSliceOptionsFlagOnFlagSet(flagSet, name, shorthand, nil, description, strings.Split(options, ","))
+			} else {
+				cl.StringSliceFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+			}
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Int32:
+			cl.Int32SliceFlagOnFlagSet(flagSet, name, shorthand, nil, description)
+		default:
+			return fmt.Errorf("unable to create a flag for the %s filter of type %s", field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+// FiltersMe returns selector.Filters with each field which has a flag struct tag set to the value of its flag.
+// Fields of flags which were not set are nil.
+func (cl *CommandLineInterface) FiltersMe(flags map[string]interface{}) selector.Filters {
+	filters := selector.Filters{}
+	filtersValue := reflect.ValueOf(&filters).Elem()
+	filtersType := filtersValue.Type()
+	for i := 0; i < filtersType.NumField(); i++ {
+		field := filtersType.Field(i)
+		name := field.Tag.Get(flagTag)
+		if name == "" || flags[name] == nil {
+			continue
+		}
+		var value interface{}
+		fieldType := field.Type.Elem()
+		switch {
+		case fieldType == int32RangeFilterType:
+			value = cl.Int32RangeMe(flags[name])
+		case fieldType == intRangeFilterType:
+			value = cl.IntRangeMe(flags[name])
+		case fieldType == float64RangeFilterType:
+			value = cl.Float64RangeMe(flags[name])
+		case fieldType == byteQuantityRangeFilterType:
+			value = cl.ByteQuantityRangeMe(flags[name])
+		case fieldType == regexpType:
+			value = cl.RegexMe(flags[name])
+		case fieldType.Kind() == reflect.Bool:
+			value = cl.BoolMe(flags[name])
+		case fieldType.Kind() == reflect.Int:
+			value = cl.IntMe(flags[name])
+		case fieldType.Kind() == reflect.Float64:
+			value = cl.Float64Me(flags[name])
+		case fieldType.Kind() == reflect.String:
+			// string enums like ec2types.ArchitectureType are converted from the flag's string value
+			if stringValue := cl.StringMe(flags[name]); stringValue != nil {
+				enumValue := reflect.New(fieldType)
+				enumValue.Elem().SetString(*stringValue)
+				filtersValue.Field(i).Set(enumValue)
+			}
+			continue
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Int32:
+			value = cl.Int32SliceMe(flags[name])
+		case fieldType.Kind() == reflect.Slice:
+			value = cl.StringSliceMe(flags[name])
+		}
+		if rv := reflect.ValueOf(value); rv.IsValid() && !rv.IsNil() {
+			// named slice types like selector.NotFilter are converted from the flag's []string or []int32 value
+			filtersValue.Field(i).Set(rv.Convert(field.Type))
+		}
+	}
+	return filters
+}
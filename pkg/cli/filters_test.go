@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestFilterFlags(t *testing.T) {
+	cli := getTestCLI()
+	h.Ok(t, cli.FilterFlags())
+	filtersType := reflect.TypeOf(selector.Filters{})
+	for i := 0; i < filtersType.NumField(); i++ {
+		field := filtersType.Field(i)
+		if name := field.Tag.Get("flag"); name != "" {
+			_, ok := cli.Flags[name]
+			h.Assert(t, ok, "Should contain the %s flag of the %s filter", name, field.Name)
+		}
+	}
+	_, ok := cli.Flags["region"]
+	h.Assert(t, !ok, "Should not contain a flag for the untagged Region filter")
+}
+
+func TestFiltersMe(t *testing.T) {
+	cli := getTestCLI()
+	h.Ok(t, cli.FilterFlags())
+	os.Args = []string{"ec2-instance-selector", "--vcpus", "2", "--memory-min", "4gb", "--cpu-architecture", "arm64", "--cpu-manufacturer", "aws", "--ipv6", "--availability-zones", "us-east-2a,us-east-2b", "--allow-list", "^m6g", "--flexible", "--gpu-manufacturer-not", "nvidia,amd", "--vcpus-list", "2,4,8"}
+	flags, err := cli.ParseAndValidateFlags()
+	h.Ok(t, err)
+
+	filters := cli.FiltersMe(flags)
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2}, *filters.VCpusRange)
+	h.Equals(t, bytequantity.FromGiB(4), filters.MemoryRange.LowerBound)
+	h.Equals(t, ec2types.ArchitectureTypeArm64, *filters.CPUArchitecture)
+	h.Equals(t, selector.CPUManufacturerAWS, *filters.CPUManufacturer)
+	h.Assert(t, *filters.IPv6, "IPv6 filter should be true")
+	h.Equals(t, []string{"us-east-2a", "us-east-2b"}, *filters.AvailabilityZones)
+	h.Equals(t, "^m6g", filters.AllowList.String())
+	h.Assert(t, *filters.Flexible, "Flexible suite filter should be true")
+	h.Equals(t, selector.NotFilter{"nvidia", "amd"}, *filters.GPUManufacturerNot)
+	h.Equals(t, selector.Int32SetFilter{2, 4, 8}, *filters.VCpusList)
+	h.Assert(t, filters.GpusList == nil, "Filters of list flags which were not set should be nil")
+	h.Assert(t, filters.GpusRange == nil, "Filters of flags which were not set should be nil")
+	h.Assert(t, filters.UsageClass == nil, "Filters of string flags which were not set should be nil")
+}
@@ -16,14 +16,18 @@ package cli
 import (
 	"fmt"
 	"math"
+	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/pflag"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
 )
 
 const (
@@ -110,6 +114,12 @@ func (cl *CommandLineInterface) RegexFlag(name string, shorthand *string, defaul
 	cl.RegexFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
 }
 
+// RegexFileFlag creates and registers a flag accepting a path to a file of newline-delimited regular expressions
+// and validates that each line is a valid regex.
+func (cl *CommandLineInterface) RegexFileFlag(name string, shorthand *string, defaultValue *string, description string) {
+	cl.RegexFileFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
+}
+
 // PathFlag creates and registers a flag accepting a string representing a path and validates that it is a valid path.
 func (cl *CommandLineInterface) PathFlag(name string, shorthand *string, defaultValue *string, description string) {
 	cl.PathFlagOnFlagSet(cl.Command.Flags(), name, shorthand, defaultValue, description)
@@ -149,6 +159,18 @@ func (cl *CommandLineInterface) ConfigIntFlag(name string, shorthand *string, de
 	cl.IntFlagOnFlagSet(cl.Command.PersistentFlags(), name, shorthand, defaultValue, description)
 }
 
+// ConfigFloat64Flag creates and registers a flag accepting a Float64 for configuration purposes.
+// Config flags will be grouped at the bottom in the output of --help.
+func (cl *CommandLineInterface) ConfigFloat64Flag(name string, shorthand *string, defaultValue *float64, description string) {
+	cl.Float64FlagOnFlagSet(cl.Command.PersistentFlags(), name, shorthand, defaultValue, description)
+}
+
+// ConfigDurationFlag creates and registers a flag accepting a duration (Example: 45s, 2m) for configuration purposes.
+// Config flags will be grouped at the bottom in the output of --help.
+func (cl *CommandLineInterface) ConfigDurationFlag(name string, shorthand *string, defaultValue *time.Duration, description string) {
+	cl.DurationFlagOnFlagSet(cl.Command.PersistentFlags(), name, shorthand, defaultValue, description)
+}
+
 // ConfigBoolFlag creates and registers a flag accepting a boolean for configuration purposes.
 // Config flags will be grouped at the bottom in the output of --help.
 func (cl *CommandLineInterface) ConfigBoolFlag(name string, shorthand *string, defaultValue *bool, description string) {
@@ -291,7 +313,7 @@ func (cl *CommandLineInterface) ByteQuantityFlagOnFlagSet(flagSet *pflag.FlagSet
 		}
 		switch byteQuantityInput := val.(type) {
 		case *string:
-			bq, err := bytequantity.ParseToByteQuantity(*byteQuantityInput)
+			bq, err := cl.parseByteQuantity(*byteQuantityInput)
 			if err != nil {
 				return fmt.Errorf("%s Can't parse byte quantity %s", invalidInputMsg, *byteQuantityInput)
 			}
@@ -362,6 +384,35 @@ func (cl *CommandLineInterface) Float64FlagOnFlagSet(flagSet *pflag.FlagSet, nam
 	cl.Flags[name] = flagSet.Float64(name, *defaultValue, description)
 }
 
+// DurationFlagOnFlagSet creates and registers a flag accepting a duration (Example: 24h, 30m).
+func (cl *CommandLineInterface) DurationFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *time.Duration, description string) {
+	if defaultValue == nil {
+		cl.nilDefaults[name] = true
+		defaultValue = cl.DurationMe(time.Duration(0))
+	}
+	if shorthand != nil {
+		cl.Flags[name] = flagSet.DurationP(name, string(*shorthand), *defaultValue, description)
+		return
+	}
+	cl.Flags[name] = flagSet.Duration(name, *defaultValue, description)
+}
+
+// PositiveDurationFlagOnFlagSet creates and registers a flag accepting a duration (Example: 24h, 30m) which must be greater
+// than 0 and at most maxValue.
+func (cl *CommandLineInterface) PositiveDurationFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *time.Duration, maxValue time.Duration, description string) {
+	cl.DurationFlagOnFlagSet(flagSet, name, shorthand, defaultValue, description)
+	cl.validators[name] = func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		duration := *val.(*time.Duration)
+		if duration <= 0 || duration > maxValue {
+			return fmt.Errorf("invalid input for --%s. It must be greater than 0 and at most %s, got %s", name, maxValue, duration)
+		}
+		return nil
+	}
+}
+
 // StringFlagOnFlagSet creates and registers a flag accepting a string and a validator function.
 // The validator function is provided so that more complex flags can be created from a string input.
 func (cl *CommandLineInterface) StringFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *string, description string, processorFn processor, validationFn validator) {
@@ -408,7 +459,39 @@ func (cl *CommandLineInterface) StringSliceFlagOnFlagSet(flagSet *pflag.FlagSet,
 	cl.Flags[name] = flagSet.StringSlice(name, defaultValue, description)
 }
 
+// StringSliceOptionsFlagOnFlagSet creates and registers a flag accepting a comma separated list of valid options.
+// The validOpts slice of strings will be used to validate each value of the list.
+func (cl *CommandLineInterface) StringSliceOptionsFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue []string, description string, validOpts []string) {
+	cl.StringSliceFlagOnFlagSet(flagSet, name, shorthand, defaultValue, description)
+	cl.validators[name] = func(val interface{}) error {
+		values, ok := val.(*[]string)
+		if !ok || values == nil {
+			return nil
+		}
+		for _, value := range *values {
+			if !slices.Contains(validOpts, value) {
+				return fmt.Errorf("error %s must be a list of: %s", name, strings.Join(validOpts, ", "))
+			}
+		}
+		return nil
+	}
+}
+
+// Int32SliceFlagOnFlagSet creates and registers a flag accepting a comma separated list of int32s.
+func (cl *CommandLineInterface) Int32SliceFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue []int32, description string) {
+	if defaultValue == nil {
+		cl.nilDefaults[name] = true
+		defaultValue = []int32{}
+	}
+	if shorthand != nil {
+		cl.Flags[name] = flagSet.Int32SliceP(name, string(*shorthand), defaultValue, description)
+		return
+	}
+	cl.Flags[name] = flagSet.Int32Slice(name, defaultValue, description)
+}
+
 // RegexFlagOnFlagSet creates and registers a flag accepting a string slice of regular expressions.
+// Instance type globs like m5*.large are translated to a regex which must match the whole instance type name.
 func (cl *CommandLineInterface) RegexFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *string, description string) {
 	invalidInputMsg := fmt.Sprintf("Invalid regex input for --%s.", name)
 	regexProcessor := func(val interface{}) error {
@@ -417,7 +500,7 @@ func (cl *CommandLineInterface) RegexFlagOnFlagSet(flagSet *pflag.FlagSet, name
 		}
 		switch v := val.(type) {
 		case *string:
-			regexVal, err := regexp.Compile(*v)
+			regexVal, err := selector.CompileInstanceTypePattern(*v)
 			if err != nil {
 				return fmt.Errorf("%s Unable to compile the regex", invalidInputMsg)
 			}
@@ -444,6 +527,67 @@ func (cl *CommandLineInterface) RegexFlagOnFlagSet(flagSet *pflag.FlagSet, name
 	cl.StringFlagOnFlagSet(flagSet, name, shorthand, defaultValue, description, regexProcessor, regexValidator)
 }
 
+// RegexFileFlagOnFlagSet creates and registers a flag accepting a path to a file of newline-delimited regular expressions.
+// Each line is an instance type name, glob, or pattern which must match the whole instance type name.
+// Blank lines and lines starting with # are ignored.
+// The lines are combined into a single regular expression which matches if any line matches.
+func (cl *CommandLineInterface) RegexFileFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *string, description string) {
+	invalidInputMsg := fmt.Sprintf("Invalid regex file input for --%s.", name)
+	regexFileProcessor := func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		switch v := val.(type) {
+		case *string:
+			path, err := homedir.Expand(*v)
+			if err != nil {
+				return fmt.Errorf("%s Unable to expand path", invalidInputMsg)
+			}
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s Unable to read the file: %w", invalidInputMsg, err)
+			}
+			patterns := []string{}
+			for i, line := range strings.Split(string(contents), "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				lineRegex, err := selector.CompileInstanceTypePattern(line)
+				if err != nil {
+					return fmt.Errorf("%s Unable to compile the regex on line %d", invalidInputMsg, i+1)
+				}
+				patterns = append(patterns, fmt.Sprintf("^(?:%s)$", lineRegex.String()))
+			}
+			if len(patterns) == 0 {
+				return fmt.Errorf("%s The file does not contain any instance types", invalidInputMsg)
+			}
+			regexVal, err := regexp.Compile(strings.Join(patterns, "|"))
+			if err != nil {
+				return fmt.Errorf("%s Unable to compile the regex", invalidInputMsg)
+			}
+			cl.Flags[name] = regexVal
+		case *regexp.Regexp:
+			return nil
+		default:
+			return fmt.Errorf("%s Input type is unsupported", invalidInputMsg)
+		}
+		return nil
+	}
+	regexFileValidator := func(val interface{}) error {
+		if val == nil {
+			return nil
+		}
+		switch val.(type) {
+		case *regexp.Regexp:
+			return nil
+		default:
+			return fmt.Errorf("%s Processing failed", invalidInputMsg)
+		}
+	}
+	cl.StringFlagOnFlagSet(flagSet, name, shorthand, defaultValue, description, regexFileProcessor, regexFileValidator)
+}
+
 // PathFlagOnFlagSet creates and registers a flag accepting a string as a path.
 func (cl *CommandLineInterface) PathFlagOnFlagSet(flagSet *pflag.FlagSet, name string, shorthand *string, defaultValue *string, description string) {
 	invalidInputMsg := fmt.Sprintf("Invalid path input for --%s. ", name)
@@ -15,6 +15,7 @@ package cli_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
@@ -54,6 +55,52 @@ func TestIntFlag(t *testing.T) {
 	}
 }
 
+func TestConfigFloat64Flag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-float64"
+	cli.ConfigFloat64Flag(flagName, cli.StringMe("t"), nil, "Test Float64")
+	_, ok := cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+
+	cli = getTestCLI()
+	cli.ConfigFloat64Flag(flagName, nil, cli.Float64Me(27.5), "Test Float64")
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag w/ no shorthand")
+	h.Equals(t, 27.5, *cli.Float64Me(cli.Flags[flagName]))
+}
+
+func TestConfigDurationFlag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-duration"
+	cli.ConfigDurationFlag(flagName, cli.StringMe("t"), nil, "Test Duration")
+	_, ok := cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+
+	cli = getTestCLI()
+	cli.ConfigDurationFlag(flagName, nil, cli.DurationMe(45*time.Second), "Test Duration")
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag w/ no shorthand")
+	h.Equals(t, 45*time.Second, *cli.DurationMe(cli.Flags[flagName]))
+}
+
+func TestPositiveDurationFlag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-duration"
+	cli.PositiveDurationFlagOnFlagSet(cli.Command.Flags(), flagName, nil, cli.DurationMe(time.Hour), 24*time.Hour, "Test Duration")
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Ok(t, cli.ValidateFlags())
+
+	for _, invalid := range []time.Duration{0, -time.Hour, 25 * time.Hour} {
+		cli.Flags[flagName] = cli.DurationMe(invalid)
+		err := cli.ValidateFlags()
+		h.Nok(t, err)
+		h.Equals(t, fmt.Sprintf("invalid input for --test-duration. It must be greater than 0 and at most 24h0m0s, got %s", invalid), err.Error())
+	}
+
+	cli.Flags[flagName] = cli.DurationMe(24 * time.Hour)
+	h.Ok(t, cli.ValidateFlags())
+}
+
 func TestStringFlag(t *testing.T) {
 	cli := getTestCLI()
 	for _, flagFn := range []func(string, *string, *string, string, func(interface{}) error){cli.StringFlag, cli.ConfigStringFlag, cli.SuiteStringFlag} {
@@ -201,6 +248,15 @@ func TestRegexFlag(t *testing.T) {
 	}
 }
 
+func TestRegexFileFlag(t *testing.T) {
+	cli := getTestCLI()
+	flagName := "test-regex-file"
+	cli.RegexFileFlag(flagName, cli.StringMe("t"), nil, "Test Regex File")
+	_, ok := cli.Flags[flagName]
+	h.Assert(t, len(cli.Flags) == 1, "Should contain 1 flag")
+	h.Assert(t, ok, "Should contain %s flag", flagName)
+}
+
 func TestFloat64MinMaxRangeFlags(t *testing.T) {
 	cli := getTestCLI()
 	flagName := "test-float64-min-max-range"
@@ -16,6 +16,7 @@ package cli
 import (
 	"log"
 	"regexp"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -37,13 +38,7 @@ Examples:
 {{.Example}}{{end}}{{if .HasAvailableSubCommands}}
 
 Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
-
-Filter Flags:
-{{.LocalNonPersistentFlags.FlagUsages | trimTrailingWhitespaces}}
-%s
-Global Flags:
-{{.PersistentFlags.FlagUsages | trimTrailingWhitespaces}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableFlags}}%s
 
 {{end}}`
 )
@@ -54,15 +49,45 @@ type validator = func(val interface{}) error
 // processor defines the function for providing mutating processing on a flag.
 type processor = func(val interface{}) error
 
+// flagDependency defines a flag which can only be set when another flag is set, optionally to one of a set of values.
+type flagDependency struct {
+	flagName       string
+	requiredFlag   string
+	requiredValues []string
+}
+
+// flagConflict defines a flag which can not be set together with another flag, or only with the other flag set to one of a set of values.
+type flagConflict struct {
+	flagName          string
+	conflictingFlag   string
+	conflictingValues []string
+}
+
+// flagGroup defines a named group of flags printed together in the output of --help.
+type flagGroup struct {
+	title     string
+	sorted    bool
+	flagNames []string
+}
+
 // CommandLineInterface is a type to group CLI funcs and state.
 type CommandLineInterface struct {
-	Command     *cobra.Command
-	Flags       map[string]interface{}
-	nilDefaults map[string]bool
-	rangeFlags  map[string]bool
-	validators  map[string]validator
-	processors  map[string]processor
-	suiteFlags  *pflag.FlagSet
+	Command          *cobra.Command
+	Flags            map[string]interface{}
+	nilDefaults      map[string]bool
+	rangeFlags       map[string]bool
+	validators       map[string]validator
+	processors       map[string]processor
+	suiteFlags       *pflag.FlagSet
+	exclusiveFlags   [][]string
+	flagDependencies []flagDependency
+	flagConflicts    []flagConflict
+	flagGroups       []flagGroup
+	flagExamples     map[string]string
+	// siUnitsFlag is the name of the bool flag which parses byte quantity flags with decimal (SI) units when set
+	siUnitsFlag string
+	// invokedCommand is the command or sub-command executed when parsing flags
+	invokedCommand *cobra.Command
 }
 
 // Float64Me takes an interface and returns a pointer to a float64 value
@@ -82,6 +107,23 @@ func (*CommandLineInterface) Float64Me(i interface{}) *float64 {
 	}
 }
 
+// DurationMe takes an interface and returns a pointer to a time.Duration value
+// If the underlying interface kind is not time.Duration or *time.Duration then nil is returned.
+func (*CommandLineInterface) DurationMe(i interface{}) *time.Duration {
+	if i == nil {
+		return nil
+	}
+	switch v := i.(type) {
+	case *time.Duration:
+		return v
+	case time.Duration:
+		return &v
+	default:
+		log.Printf("%s cannot be converted to a duration", i)
+		return nil
+	}
+}
+
 // IntMe takes an interface and returns a pointer to an int value
 // If the underlying interface kind is not int or *int then nil is returned.
 func (*CommandLineInterface) IntMe(i interface{}) *int {
@@ -247,6 +289,23 @@ func (*CommandLineInterface) StringSliceMe(i interface{}) *[]string {
 	}
 }
 
+// Int32SliceMe takes an interface and returns a pointer to an int32 slice
+// If the underlying interface kind is not []int32 or *[]int32 then nil is returned.
+func (*CommandLineInterface) Int32SliceMe(i interface{}) *[]int32 {
+	if i == nil {
+		return nil
+	}
+	switch v := i.(type) {
+	case *[]int32:
+		return v
+	case []int32:
+		return &v
+	default:
+		log.Printf("%s cannot be converted to an int32 list", i)
+		return nil
+	}
+}
+
 // RegexMe takes an interface and returns a pointer to a regex
 // If the underlying interface kind is not regexp.Regexp or *regexp.Regexp then nil is returned.
 func (*CommandLineInterface) RegexMe(i interface{}) *regexp.Regexp {
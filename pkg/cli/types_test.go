@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
@@ -94,6 +95,19 @@ func TestFloat64Me(t *testing.T) {
 	h.Assert(t, val == nil, "Should return nil if nil is passed in")
 }
 
+func TestDurationMe(t *testing.T) {
+	cli := getTestCLI()
+	dVal := 24 * time.Hour
+	val := cli.DurationMe(dVal)
+	h.Assert(t, *val == dVal, "Should return %s from passed in duration value", dVal)
+	val = cli.DurationMe(&dVal)
+	h.Assert(t, *val == dVal, "Should return %s from passed in duration pointer", dVal)
+	val = cli.DurationMe(true)
+	h.Assert(t, val == nil, "Should return nil from other data type passed in")
+	val = cli.DurationMe(nil)
+	h.Assert(t, val == nil, "Should return nil if nil is passed in")
+}
+
 func TestIntRangeMe(t *testing.T) {
 	cli := getTestCLI()
 	intRangeVal := selector.IntRangeFilter{LowerBound: 1, UpperBound: 2}
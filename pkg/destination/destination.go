@@ -0,0 +1,211 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package destination writes outputs to local files or S3 objects rather than stdout, so that scheduled jobs can store
+// their reports without wrapper scripts.
+package destination
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/mitchellh/go-homedir"
+)
+
+// s3Scheme is the prefix of S3 URIs (Example: s3://bucket/key).
+const s3Scheme = "s3://"
+
+// defaultContentType is the content type of objects whose key does not have a known extension.
+const defaultContentType = "text/plain; charset=utf-8"
+
+// Destination is where an output is written to.
+type Destination interface {
+	Write(ctx context.Context, data []byte) error
+	// String returns the path or URI of the destination
+	String() string
+}
+
+// IsS3URI returns true if the destination is an S3 URI (Example: s3://bucket/key).
+func IsS3URI(destination string) bool {
+	return strings.HasPrefix(destination, s3Scheme)
+}
+
+// ParseS3URI returns the bucket and key of an S3 URI (Example: s3://bucket/reports/instance-types.json).
+func ParseS3URI(uri string) (string, string, error) {
+	if !IsS3URI(uri) {
+		return "", "", fmt.Errorf("%s is not an S3 URI (Example: s3://bucket/key)", uri)
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(uri, s3Scheme), "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("%s must include the bucket and key of the object to write (Example: s3://bucket/key)", uri)
+	}
+	return bucket, key, nil
+}
+
+// File writes outputs to a local file, replacing its previous contents unless Append is true.
+type File struct {
+	Path string
+	// Append adds the outputs to the end of the file rather than replacing its contents, the file is created if it does
+	// not exist
+	Append bool
+}
+
+// NewFile creates a File destination for the passed in path, ~ is expanded to the home directory.
+func NewFile(filePath string) (*File, error) {
+	expandedPath, err := homedir.Expand(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand output file path %s: %w", filePath, err)
+	}
+	return &File{Path: expandedPath}, nil
+}
+
+// Write writes the data to the file.
+func (f File) Write(_ context.Context, data []byte) error {
+	if !f.Append {
+		if err := os.WriteFile(f.Path, data, 0o600); err != nil {
+			return fmt.Errorf("unable to write output file %s: %w", f.Path, err)
+		}
+		return nil
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to append to output file %s: %w", f.Path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to append to output file %s: %w", f.Path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to append to output file %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// WriteStream opens the file once and passes it to write, so that outputs can be written as they are produced rather
+// than held in memory.
+func (f File) WriteStream(write func(w io.Writer) error) error {
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if f.Append {
+		flag = os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	}
+	file, err := os.OpenFile(f.Path, flag, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to write output file %s: %w", f.Path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write output file %s: %w", f.Path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write output file %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// IsEmpty returns true if the file does not exist or has no contents yet.
+func (f File) IsEmpty() bool {
+	info, err := os.Stat(f.Path)
+	return err != nil || info.Size() == 0
+}
+
+func (f File) String() string {
+	return f.Path
+}
+
+// PutObjectAPI is the subset of the S3 client used to write objects.
+type PutObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Options are the server-side encryption settings of the objects written by an S3 destination.
+type S3Options struct {
+	// ServerSideEncryption is the server-side encryption algorithm (AES256, aws:kms, or aws:kms:dsse), the
+	// default encryption of the bucket is used if it is empty
+	ServerSideEncryption string
+	// KMSKeyID is the KMS key used by the aws:kms and aws:kms:dsse algorithms, the AWS managed key is used if it is empty
+	KMSKeyID string
+}
+
+// S3 writes outputs to an S3 object, replacing its previous contents.
+type S3 struct {
+	Bucket  string
+	Key     string
+	Options S3Options
+	client  PutObjectAPI
+}
+
+// NewS3 creates an S3 destination for the passed in S3 URI (Example: s3://bucket/key).
+func NewS3(client PutObjectAPI, uri string, opts S3Options) (*S3, error) {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ServerSideEncryption != "" && !slices.Contains(ServerSideEncryptionAlgorithms(), opts.ServerSideEncryption) {
+		return nil, fmt.Errorf("invalid server-side encryption %s (valid options: %s)", opts.ServerSideEncryption, strings.Join(ServerSideEncryptionAlgorithms(), ", "))
+	}
+	if opts.KMSKeyID != "" && opts.ServerSideEncryption != string(s3types.ServerSideEncryptionAwsKms) && opts.ServerSideEncryption != string(s3types.ServerSideEncryptionAwsKmsDsse) {
+		return nil, fmt.Errorf("a KMS key can only be used with the %s or %s server-side encryption", s3types.ServerSideEncryptionAwsKms, s3types.ServerSideEncryptionAwsKmsDsse)
+	}
+	return &S3{
+		Bucket:  bucket,
+		Key:     key,
+		Options: opts,
+		client:  client,
+	}, nil
+}
+
+// ServerSideEncryptionAlgorithms returns the server-side encryption algorithms of S3 objects.
+func ServerSideEncryptionAlgorithms() []string {
+	algorithms := []string{}
+	for _, algorithm := range s3types.ServerSideEncryption("").Values() {
+		algorithms = append(algorithms, string(algorithm))
+	}
+	return algorithms
+}
+
+// Write puts the data in the S3 object, the content type is inferred from the extension of the key.
+func (s S3) Write(ctx context.Context, data []byte) error {
+	contentType := mime.TypeByExtension(path.Ext(s.Key))
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(s.Key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	}
+	if s.Options.ServerSideEncryption != "" {
+		input.ServerSideEncryption = s3types.ServerSideEncryption(s.Options.ServerSideEncryption)
+	}
+	if s.Options.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.Options.KMSKeyID)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("unable to write output to %s: %w", s, err)
+	}
+	return nil
+}
+
+func (s S3) String() string {
+	return s3Scheme + s.Bucket + "/" + s.Key
+}
@@ -0,0 +1,164 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destination_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/destination"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocks
+
+type mockedS3 struct {
+	putObjectInputs []*s3.PutObjectInput
+	putObjectBodies []string
+	putObjectErr    error
+}
+
+func (m *mockedS3) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.putObjectInputs = append(m.putObjectInputs, params)
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.putObjectBodies = append(m.putObjectBodies, string(body))
+	return &s3.PutObjectOutput{}, m.putObjectErr
+}
+
+// Tests
+
+func TestParseS3URI(t *testing.T) {
+	bucket, key, err := destination.ParseS3URI("s3://reports/ec2/instance-types.json")
+	h.Ok(t, err)
+	h.Equals(t, "reports", bucket)
+	h.Equals(t, "ec2/instance-types.json", key)
+
+	for _, uri := range []string{"reports/instance-types.json", "s3://reports", "s3://reports/", "s3:///instance-types.json", "s3://reports/ec2/"} {
+		_, _, err := destination.ParseS3URI(uri)
+		h.Nok(t, err)
+	}
+}
+
+func TestFile_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance-types.txt")
+	file, err := destination.NewFile(path)
+	h.Ok(t, err)
+	h.Equals(t, path, file.String())
+	h.Ok(t, file.Write(context.Background(), []byte("m5.large\n")))
+	h.Ok(t, file.Write(context.Background(), []byte("c5.large\n")))
+	data, err := os.ReadFile(path)
+	h.Ok(t, err)
+	h.Equals(t, "c5.large\n", string(data))
+
+	file, err = destination.NewFile(filepath.Join(t.TempDir(), "missing", "instance-types.txt"))
+	h.Ok(t, err)
+	h.Nok(t, file.Write(context.Background(), []byte("m5.large\n")))
+}
+
+func TestFile_WriteAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance-types.txt")
+	file, err := destination.NewFile(path)
+	h.Ok(t, err)
+	file.Append = true
+	h.Assert(t, file.IsEmpty(), "A file which does not exist should be empty")
+	h.Ok(t, file.Write(context.Background(), []byte("m5.large\n")))
+	h.Assert(t, !file.IsEmpty(), "The file should not be empty once written to")
+	h.Ok(t, file.Write(context.Background(), []byte("c5.large\n")))
+	data, err := os.ReadFile(path)
+	h.Ok(t, err)
+	h.Equals(t, "m5.large\nc5.large\n", string(data))
+
+	file, err = destination.NewFile(filepath.Join(t.TempDir(), "missing", "instance-types.txt"))
+	h.Ok(t, err)
+	file.Append = true
+	h.Nok(t, file.Write(context.Background(), []byte("m5.large\n")))
+}
+
+func TestFile_WriteStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance-types.txt")
+	file, err := destination.NewFile(path)
+	h.Ok(t, err)
+	writeLines := func(lines ...string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			for _, line := range lines {
+				if _, err := io.WriteString(w, line+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	h.Ok(t, file.WriteStream(writeLines("m5.large", "m5.xlarge")))
+	h.Ok(t, file.WriteStream(writeLines("c5.large")))
+	data, err := os.ReadFile(path)
+	h.Ok(t, err)
+	h.Equals(t, "c5.large\n", string(data))
+
+	file.Append = true
+	h.Ok(t, file.WriteStream(writeLines("r5.large")))
+	data, err = os.ReadFile(path)
+	h.Ok(t, err)
+	h.Equals(t, "c5.large\nr5.large\n", string(data))
+
+	h.Nok(t, file.WriteStream(func(w io.Writer) error { return errors.New("write failed") }))
+}
+
+func TestS3_Write(t *testing.T) {
+	client := &mockedS3{}
+	s3Destination, err := destination.NewS3(client, "s3://reports/ec2/instance-types.json", destination.S3Options{})
+	h.Ok(t, err)
+	h.Equals(t, "s3://reports/ec2/instance-types.json", s3Destination.String())
+	h.Ok(t, s3Destination.Write(context.Background(), []byte("[]\n")))
+	h.Equals(t, 1, len(client.putObjectInputs))
+	input := client.putObjectInputs[0]
+	h.Equals(t, "reports", aws.ToString(input.Bucket))
+	h.Equals(t, "ec2/instance-types.json", aws.ToString(input.Key))
+	h.Equals(t, "application/json", aws.ToString(input.ContentType))
+	h.Equals(t, s3types.ServerSideEncryption(""), input.ServerSideEncryption)
+	h.Assert(t, input.SSEKMSKeyId == nil, "Should not set a KMS key")
+	h.Equals(t, "[]\n", client.putObjectBodies[0])
+
+	s3Destination, err = destination.NewS3(client, "s3://reports/instance-types", destination.S3Options{ServerSideEncryption: "aws:kms", KMSKeyID: "alias/reports"})
+	h.Ok(t, err)
+	h.Ok(t, s3Destination.Write(context.Background(), []byte("m5.large\n")))
+	input = client.putObjectInputs[1]
+	h.Equals(t, "text/plain; charset=utf-8", aws.ToString(input.ContentType))
+	h.Equals(t, s3types.ServerSideEncryptionAwsKms, input.ServerSideEncryption)
+	h.Equals(t, "alias/reports", aws.ToString(input.SSEKMSKeyId))
+
+	client.putObjectErr = errors.New("AccessDenied")
+	err = s3Destination.Write(context.Background(), []byte("m5.large\n"))
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "s3://reports/instance-types"), "The error should name the object, got %v", err)
+}
+
+func TestNewS3_InvalidOptions(t *testing.T) {
+	_, err := destination.NewS3(&mockedS3{}, "s3://reports/instance-types", destination.S3Options{ServerSideEncryption: "rot13"})
+	h.Nok(t, err)
+	_, err = destination.NewS3(&mockedS3{}, "s3://reports/instance-types", destination.S3Options{ServerSideEncryption: "AES256", KMSKeyID: "alias/reports"})
+	h.Nok(t, err)
+	_, err = destination.NewS3(&mockedS3{}, "reports/instance-types", destination.S3Options{})
+	h.Nok(t, err)
+}
@@ -0,0 +1,88 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// DiscountedPricing is an EC2PricingIface which applies a flat discount, such as an EDP discount, to the on-demand prices
+// of the embedded EC2PricingIface. Spot prices are not discounted.
+type DiscountedPricing struct {
+	EC2PricingIface
+	// OnDemandDiscountPercent is subtracted from the on-demand prices (Example: 27 for a 27% discount)
+	OnDemandDiscountPercent float64
+}
+
+// OnDemandDiscounter is implemented by the EC2PricingIfaces which discount on-demand prices so the discounted prices can be
+// told apart from the public prices.
+type OnDemandDiscounter interface {
+	// GetOnDemandDiscountPercent returns the discount applied to the on-demand price of the instance type, 0 if it is not discounted
+	GetOnDemandDiscountPercent(instanceType ec2types.InstanceType) float64
+}
+
+// NewDiscountedPricing creates a DiscountedPricing which discounts the on-demand prices of pricing by discountPercent.
+func NewDiscountedPricing(pricing EC2PricingIface, discountPercent float64) (*DiscountedPricing, error) {
+	if discountPercent < 0 || discountPercent >= 100 {
+		return nil, fmt.Errorf("the on-demand discount must be at least 0%% and less than 100%%, got %g%%", discountPercent)
+	}
+	return &DiscountedPricing{
+		EC2PricingIface:         pricing,
+		OnDemandDiscountPercent: discountPercent,
+	}, nil
+}
+
+// GetOnDemandInstanceTypeCost retrieves the on-demand hourly cost for the specified instance type with the discount applied.
+func (p *DiscountedPricing) GetOnDemandInstanceTypeCost(ctx context.Context, instanceType ec2types.InstanceType) (float64, error) {
+	price, err := p.EC2PricingIface.GetOnDemandInstanceTypeCost(ctx, instanceType)
+	if err != nil {
+		return price, err
+	}
+	return price * (100 - p.OnDemandDiscountPercent) / 100, nil
+}
+
+// GetOnDemandDiscountPercent returns the discount applied to the on-demand price of the instance type.
+func (p *DiscountedPricing) GetOnDemandDiscountPercent(instanceType ec2types.InstanceType) float64 {
+	return p.OnDemandDiscountPercent
+}
+
+// GetSpotInstanceTypeNDayAvgCostByZone retrieves the spot hourly costs of each AZ from the embedded EC2PricingIface, they are not discounted.
+func (p *DiscountedPricing) GetSpotInstanceTypeNDayAvgCostByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error) {
+	zonalPricer, ok := p.EC2PricingIface.(ZonalSpotPricer)
+	if !ok {
+		return nil, fmt.Errorf("the pricing source does not retrieve spot prices by availability zone for instance type %s", instanceType)
+	}
+	return zonalPricer.GetSpotInstanceTypeNDayAvgCostByZone(ctx, instanceType, days)
+}
+
+// GetSpotInstanceTypeLastPriceChange returns the time of the newest spot price of the instance type from the embedded EC2PricingIface.
+func (p *DiscountedPricing) GetSpotInstanceTypeLastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (time.Time, bool) {
+	if changeTimer, ok := p.EC2PricingIface.(SpotPriceChangeTimer); ok {
+		return changeTimer.GetSpotInstanceTypeLastPriceChange(instanceType, availabilityZones)
+	}
+	return time.Time{}, false
+}
+
+// SaveOnDemand saves the on-demand pricing caches of the embedded EC2PricingIface, or all of its caches if it does not implement CacheSaver.
+func (p *DiscountedPricing) SaveOnDemand() error {
+	return saveOnDemand(p.EC2PricingIface)
+}
+
+// SaveSpot saves the spot pricing cache of the embedded EC2PricingIface if it implements CacheSaver.
+func (p *DiscountedPricing) SaveSpot() error {
+	return saveSpot(p.EC2PricingIface)
+}
@@ -16,6 +16,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -33,10 +35,15 @@ const (
 var DefaultSpotDaysBack = 30
 
 // EC2Pricing is the public struct to interface with AWS pricing APIs.
+// ODPricing and SpotPricing hold the caches of the region the EC2Pricing was created for.
+// On-demand pricing caches of additional regions are created on demand with ODPricingForRegion.
 type EC2Pricing struct {
-	ODPricing   *OnDemandPricing
-	SpotPricing *SpotPricing
-	logger      *log.Logger
+	ODPricing         *OnDemandPricing
+	SpotPricing       *SpotPricing
+	logger            *log.Logger
+	regionalODPricing map[string]*OnDemandPricing
+	regionalODMutex   sync.Mutex
+	readOnly          bool
 }
 
 // EC2PricingIface is the EC2Pricing interface mainly used to mock out ec2pricing during testing.
@@ -45,10 +52,32 @@ type EC2PricingIface interface {
 	GetSpotInstanceTypeNDayAvgCost(ctx context.Context, instanceType ec2types.InstanceType, availabilityZones []string, days int) (float64, error)
 	RefreshOnDemandCache(ctx context.Context) error
 	RefreshSpotCache(ctx context.Context, days int) error
+	RefreshSpotCacheFor(ctx context.Context, days int, instanceTypes []ec2types.InstanceType, availabilityZones []string) error
 	OnDemandCacheCount() int
 	SpotCacheCount() int
 	Save() error
 	SetLogger(*log.Logger)
+	SetCacheReadOnly(readOnly bool)
+}
+
+// CacheSaver is implemented by the EC2PricingIfaces which save the on-demand and the spot pricing caches separately, so
+// that the cache which failed to save can be told apart. Save saves both.
+type CacheSaver interface {
+	SaveOnDemand() error
+	SaveSpot() error
+}
+
+// ZonalSpotPricer is implemented by the EC2PricingIfaces which can retrieve the spot prices of each availability zone.
+// It is separate from EC2PricingIface so that existing implementations of EC2PricingIface do not need to implement it.
+type ZonalSpotPricer interface {
+	GetSpotInstanceTypeNDayAvgCostByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error)
+}
+
+// SpotPriceChangeTimer is implemented by the EC2PricingIfaces which can tell when the spot price of an instance type last changed.
+type SpotPriceChangeTimer interface {
+	// GetSpotInstanceTypeLastPriceChange returns the time of the newest spot price of the instance type in the availability zones,
+	// or in every zone if none are passed in. ok is false if it is unknown.
+	GetSpotInstanceTypeLastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (lastChange time.Time, ok bool)
 }
 
 // use us-east-1 since pricing only has endpoints in us-east-1 and ap-south-1
@@ -81,10 +110,63 @@ func NewWithCache(ctx context.Context, cfg aws.Config, ttl time.Duration, cacheD
 	}, nil
 }
 
+// NewInMemory creates an instance of instance-selector EC2Pricing whose on-demand and spot pricing caches, including those
+// of the regions of ODPricingForRegion, are only kept in memory. No cache files are read, written, or removed.
+func NewInMemory(cfg aws.Config) *EC2Pricing {
+	return &EC2Pricing{
+		ODPricing:   newODPricing(pricing.NewFromConfig(cfg, modifyPricingRegion), cfg.Region, 0, ""),
+		SpotPricing: newSpotPricing(ec2.NewFromConfig(cfg), cfg.Region, 0, ""),
+	}
+}
+
 func (p *EC2Pricing) SetLogger(logger *log.Logger) {
 	p.logger = logger
 	p.ODPricing.SetLogger(logger)
 	p.SpotPricing.SetLogger(logger)
+	p.regionalODMutex.Lock()
+	defer p.regionalODMutex.Unlock()
+	for _, odPricing := range p.regionalODPricing {
+		odPricing.SetLogger(logger)
+	}
+}
+
+// ODPricingForRegion returns the on-demand pricing cache for the passed in region.
+// Caches for regions other than the default region are loaded from the cache directory, or created, on first use
+// and share the default cache's pricing client, TTL, and cache directory. Each region is persisted to its own cache file.
+func (p *EC2Pricing) ODPricingForRegion(ctx context.Context, region string) (*OnDemandPricing, error) {
+	if region == "" || region == p.ODPricing.Region {
+		return p.ODPricing, nil
+	}
+	p.regionalODMutex.Lock()
+	defer p.regionalODMutex.Unlock()
+	if odPricing, ok := p.regionalODPricing[region]; ok {
+		return odPricing, nil
+	}
+	odPricing, err := LoadODCacheOrNew(ctx, p.ODPricing.pricingClient, region, p.ODPricing.FullRefreshTTL, p.ODPricing.DirectoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize the OD pricing cache for region %s: %w", region, err)
+	}
+	if p.logger != nil {
+		odPricing.SetLogger(p.logger)
+	}
+	odPricing.ReadOnly = p.readOnly
+	if p.regionalODPricing == nil {
+		p.regionalODPricing = map[string]*OnDemandPricing{}
+	}
+	p.regionalODPricing[region] = odPricing
+	return odPricing, nil
+}
+
+// OnDemandRegions returns the regions which currently have an on-demand pricing cache, including the default region.
+func (p *EC2Pricing) OnDemandRegions() []string {
+	regions := []string{p.ODPricing.Region}
+	p.regionalODMutex.Lock()
+	defer p.regionalODMutex.Unlock()
+	for region := range p.regionalODPricing {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions[1:])
+	return regions
 }
 
 // OnDemandCacheCount returns the number of items in the OD cache.
@@ -119,11 +201,41 @@ func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCost(ctx context.Context, instanc
 	return costs[0], nil
 }
 
+// GetSpotInstanceTypeNDayAvgCostByZone retrieves the spot price history from the past N days and averages the price of
+// each AZ in the current AWSSession's region which the instance type has spot prices in.
+func (p *EC2Pricing) GetSpotInstanceTypeNDayAvgCostByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error) {
+	return p.SpotPricing.GetByZone(ctx, instanceType, days)
+}
+
+// GetSpotInstanceTypeLastPriceChange returns the time of the newest cached spot price of the instance type in the availability zones.
+func (p *EC2Pricing) GetSpotInstanceTypeLastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (time.Time, bool) {
+	return p.SpotPricing.LastPriceChange(instanceType, availabilityZones)
+}
+
 // GetOnDemandInstanceTypeCost retrieves the on-demand hourly cost for the specified instance type.
 func (p *EC2Pricing) GetOnDemandInstanceTypeCost(ctx context.Context, instanceType ec2types.InstanceType) (float64, error) {
 	return p.ODPricing.Get(ctx, instanceType)
 }
 
+// GetOnDemandInstanceTypeCostForRegion retrieves the on-demand hourly cost for the specified instance type in the passed in region.
+func (p *EC2Pricing) GetOnDemandInstanceTypeCostForRegion(ctx context.Context, region string, instanceType ec2types.InstanceType) (float64, error) {
+	odPricing, err := p.ODPricingForRegion(ctx, region)
+	if err != nil {
+		return 0, err
+	}
+	return odPricing.Get(ctx, instanceType)
+}
+
+// RefreshOnDemandCacheForRegion makes a bulk request to the pricing api to retrieve all instance type pricing in the passed in region
+// and stores them in the region's local cache.
+func (p *EC2Pricing) RefreshOnDemandCacheForRegion(ctx context.Context, region string) error {
+	odPricing, err := p.ODPricingForRegion(ctx, region)
+	if err != nil {
+		return err
+	}
+	return odPricing.Refresh(ctx)
+}
+
 // RefreshOnDemandCache makes a bulk request to the pricing api to retrieve all instance type pricing and stores them in a local cache.
 func (p *EC2Pricing) RefreshOnDemandCache(ctx context.Context) error {
 	return p.ODPricing.Refresh(ctx)
@@ -134,6 +246,50 @@ func (p *EC2Pricing) RefreshSpotCache(ctx context.Context, days int) error {
 	return p.SpotPricing.Refresh(ctx, days)
 }
 
+// RefreshSpotCacheFor retrieves the spot instance type pricing of only the passed in instance types and availability zones
+// and stores them in the local cache. Empty instanceTypes and availabilityZones are not used to scope the request.
+func (p *EC2Pricing) RefreshSpotCacheFor(ctx context.Context, days int, instanceTypes []ec2types.InstanceType, availabilityZones []string) error {
+	return p.SpotPricing.RefreshFor(ctx, days, instanceTypes, availabilityZones)
+}
+
+// SetCacheReadOnly prevents the on-demand and spot pricing caches from being saved to or removed from the cache directory.
+// This allows sharing caches from read-only locations such as a container image or a mounted volume.
+func (p *EC2Pricing) SetCacheReadOnly(readOnly bool) {
+	p.readOnly = readOnly
+	p.ODPricing.ReadOnly = readOnly
+	p.SpotPricing.ReadOnly = readOnly
+	p.regionalODMutex.Lock()
+	defer p.regionalODMutex.Unlock()
+	for _, odPricing := range p.regionalODPricing {
+		odPricing.ReadOnly = readOnly
+	}
+}
+
+// Save saves the on-demand and spot pricing caches.
 func (p *EC2Pricing) Save() error {
-	return multierr.Append(p.ODPricing.Save(), p.SpotPricing.Save())
+	return multierr.Append(p.SaveOnDemand(), p.SaveSpot())
+}
+
+// SaveOnDemand saves the on-demand pricing caches of every region.
+func (p *EC2Pricing) SaveOnDemand() error {
+	errs := saveError("on-demand", p.ODPricing.Region, p.ODPricing.Save())
+	p.regionalODMutex.Lock()
+	defer p.regionalODMutex.Unlock()
+	for _, odPricing := range p.regionalODPricing {
+		errs = multierr.Append(errs, saveError("on-demand", odPricing.Region, odPricing.Save()))
+	}
+	return errs
+}
+
+// SaveSpot saves the spot pricing cache.
+func (p *EC2Pricing) SaveSpot() error {
+	return saveError("spot", p.SpotPricing.Region, p.SpotPricing.Save())
+}
+
+// saveError identifies which of the pricing caches failed to save since they are saved together.
+func saveError(pricingCache string, region string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s pricing cache of %s: %w", pricingCache, region, err)
 }
@@ -16,9 +16,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
@@ -76,6 +81,18 @@ func setupOdMock(t *testing.T, api string, file string) mockedPricing {
 	return mockedPricing{}
 }
 
+// recordingSpotEC2 returns a fixed spot price history and records the start time of each request.
+type recordingSpotEC2 struct {
+	ec2.DescribeSpotPriceHistoryAPIClient
+	SpotPriceHistory []ec2types.SpotPrice
+	StartTimes       []time.Time
+}
+
+func (m *recordingSpotEC2) DescribeSpotPriceHistory(_ context.Context, input *ec2.DescribeSpotPriceHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	m.StartTimes = append(m.StartTimes, *input.StartTime)
+	return &ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: m.SpotPriceHistory}, nil
+}
+
 func setupEc2Mock(t *testing.T, api string, file string) mockedSpotEC2 {
 	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, api, file)
 	mockFile, err := os.ReadFile(mockFilename)
@@ -131,6 +148,31 @@ func TestGetSpotInstanceTypeNDayAvgCost(t *testing.T) {
 	h.Equals(t, float64(0.041486231229302666), price)
 }
 
+func TestGetSpotInstanceTypeLastPriceChange(t *testing.T) {
+	ctx := context.Background()
+	lastChange := time.Date(2021, time.February, 9, 1, 40, 10, 0, time.UTC)
+	ec2Mock := &recordingSpotEC2{SpotPriceHistory: []ec2types.SpotPrice{
+		{InstanceType: ec2types.InstanceTypeM5Large, AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.04"), Timestamp: aws.Time(lastChange.Add(-time.Hour))},
+		{InstanceType: ec2types.InstanceTypeM5Large, AvailabilityZone: aws.String("us-east-1b"), SpotPrice: aws.String("0.05"), Timestamp: aws.Time(lastChange)},
+	}}
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SpotPricing: lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, ec2Mock, "us-east-1", 0, "", 30)),
+	}
+	_, ok := ec2pricingClient.GetSpotInstanceTypeLastPriceChange(ec2types.InstanceTypeM5Large, nil)
+	h.Assert(t, !ok, "The last spot price change should be unknown before the spot prices are fetched")
+
+	_, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(ctx, ec2types.InstanceTypeM5Large, []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	timestamp, ok := ec2pricingClient.GetSpotInstanceTypeLastPriceChange(ec2types.InstanceTypeM5Large, nil)
+	h.Assert(t, ok, "The last spot price change should be known")
+	h.Equals(t, lastChange, timestamp)
+	timestamp, ok = ec2pricingClient.GetSpotInstanceTypeLastPriceChange(ec2types.InstanceTypeM5Large, []string{"us-east-1a"})
+	h.Assert(t, ok, "The last spot price change in us-east-1a should be known")
+	h.Equals(t, lastChange.Add(-time.Hour), timestamp)
+	_, ok = ec2pricingClient.GetSpotInstanceTypeLastPriceChange(ec2types.InstanceTypeM5Large, []string{"us-east-1c"})
+	h.Assert(t, !ok, "The last spot price change in us-east-1c should be unknown")
+}
+
 func TestRefreshSpotCache(t *testing.T) {
 	ec2Mock := setupEc2Mock(t, describeSpotPriceHistory, "m5_large.json")
 	ctx := context.Background()
@@ -144,3 +186,196 @@ func TestRefreshSpotCache(t *testing.T) {
 	h.Ok(t, err)
 	h.Equals(t, float64(0.041486231229302666), price)
 }
+
+func TestRefreshSpotCacheFor(t *testing.T) {
+	ec2Mock := setupEc2Mock(t, describeSpotPriceHistory, "m5_large.json")
+	ctx := context.Background()
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		SpotPricing: lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, ec2Mock, "us-east-1", 0, "", 30)),
+	}
+	err := ec2pricingClient.RefreshSpotCacheFor(ctx, 30, []ec2types.InstanceType{ec2types.InstanceTypeM5Large}, []string{"us-east-1a"})
+	h.Ok(t, err)
+
+	price, err := ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(ctx, ec2types.InstanceTypeM5Large, []string{"us-east-1a"}, 30)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.041486231229302666), price)
+
+	// instance types outside of the refresh scope are not fetched
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(ctx, ec2types.InstanceTypeT3Micro, []string{"us-east-1a"}, 30)
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "not included"), "Should not fetch spot pricing outside of the refresh scope, got %v", err)
+
+	// a full refresh removes the scope
+	err = ec2pricingClient.RefreshSpotCache(ctx, 30)
+	h.Ok(t, err)
+	_, err = ec2pricingClient.GetSpotInstanceTypeNDayAvgCost(ctx, ec2types.InstanceTypeT3Micro, []string{"us-east-1a"}, 30)
+	h.Assert(t, err == nil || !strings.Contains(err.Error(), "not included"), "Should fetch spot pricing after a full refresh, got %v", err)
+}
+
+func TestRefreshSpotCache_Incremental(t *testing.T) {
+	now := time.Now().UTC()
+	ec2Mock := &recordingSpotEC2{
+		SpotPriceHistory: []ec2types.SpotPrice{
+			{InstanceType: ec2types.InstanceTypeM5Large, AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.04"), Timestamp: aws.Time(now.Add(-2 * time.Hour))},
+			{InstanceType: ec2types.InstanceTypeM5Large, AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.05"), Timestamp: aws.Time(now.Add(-1 * time.Hour))},
+		},
+	}
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	spotPricing := lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, ec2Mock, "us-east-1", time.Hour, cacheDir, 30))
+	h.Ok(t, spotPricing.Refresh(ctx, 30))
+	h.Assert(t, ec2Mock.StartTimes[0].Before(now.Add(-29*24*time.Hour)), "Should fetch the full lookback window when the cache is empty, got %s", ec2Mock.StartTimes[0])
+	price, err := spotPricing.Get(ctx, ec2types.InstanceTypeM5Large, "us-east-1a", 30)
+	h.Ok(t, err)
+	h.Equals(t, 0.04, price)
+
+	// a refresh from the saved cache only fetches the history since the newest entry and does not duplicate entries
+	spotPricing = lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, ec2Mock, "us-east-1", time.Hour, cacheDir, 30))
+	h.Ok(t, spotPricing.Refresh(ctx, 30))
+	h.Equals(t, 2, len(ec2Mock.StartTimes))
+	h.Assert(t, ec2Mock.StartTimes[1].Equal(now.Add(-1*time.Hour)), "Should fetch the history since the newest cached entry, got %s", ec2Mock.StartTimes[1])
+	price, err = spotPricing.Get(ctx, ec2types.InstanceTypeM5Large, "us-east-1a", 30)
+	h.Ok(t, err)
+	h.Equals(t, 0.04, price)
+}
+
+func TestGetOndemandInstanceTypeCostForRegion(t *testing.T) {
+	pricingMock := setupOdMock(t, getProducts, "m5_large.json")
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		ODPricing:   lo.Must(ec2pricing.LoadODCacheOrNew(ctx, pricingMock, "us-east-1", time.Hour, cacheDir)),
+		SpotPricing: lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, mockedSpotEC2{}, "us-east-1", 0, "", 30)),
+	}
+	price, err := ec2pricingClient.GetOnDemandInstanceTypeCostForRegion(ctx, "us-west-2", ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	h.Equals(t, []string{"us-east-1", "us-west-2"}, ec2pricingClient.OnDemandRegions())
+	h.Equals(t, 0, ec2pricingClient.OnDemandCacheCount())
+
+	usWest2, err := ec2pricingClient.ODPricingForRegion(ctx, "us-west-2")
+	h.Ok(t, err)
+	h.Equals(t, 1, usWest2.Count())
+	usEast1, err := ec2pricingClient.ODPricingForRegion(ctx, "")
+	h.Ok(t, err)
+	h.Assert(t, usEast1 == ec2pricingClient.ODPricing, "empty region should return the default region's cache")
+
+	h.Ok(t, ec2pricingClient.Save())
+	_, err = os.Stat(filepath.Join(cacheDir, "us-west-2-"+ec2pricing.ODCacheFileName))
+	h.Ok(t, err)
+}
+
+func TestSetCacheReadOnly(t *testing.T) {
+	pricingMock := setupOdMock(t, getProducts, "m5_large.json")
+	ec2Mock := setupEc2Mock(t, describeSpotPriceHistory, "m5_large.json")
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	ec2pricingClient := ec2pricing.EC2Pricing{
+		ODPricing:   lo.Must(ec2pricing.LoadODCacheOrNew(ctx, pricingMock, "us-east-1", time.Hour, cacheDir)),
+		SpotPricing: lo.Must(ec2pricing.LoadSpotCacheOrNew(ctx, ec2Mock, "us-east-1", time.Hour, cacheDir, 30)),
+	}
+	ec2pricingClient.SetCacheReadOnly(true)
+	h.Ok(t, ec2pricingClient.RefreshOnDemandCache(ctx))
+	h.Ok(t, ec2pricingClient.RefreshSpotCache(ctx, 30))
+	_, err := ec2pricingClient.GetOnDemandInstanceTypeCostForRegion(ctx, "us-west-2", ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Ok(t, ec2pricingClient.Save())
+	h.Ok(t, ec2pricingClient.ODPricing.Clear())
+	files, err := os.ReadDir(cacheDir)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(files))
+
+	ec2pricingClient.SetCacheReadOnly(false)
+	h.Ok(t, ec2pricingClient.RefreshOnDemandCache(ctx))
+	h.Ok(t, ec2pricingClient.Save())
+	_, err = os.Stat(filepath.Join(cacheDir, "us-east-1-"+ec2pricing.ODCacheFileName))
+	h.Ok(t, err)
+}
+
+func TestLoadFilePricing(t *testing.T) {
+	pricingFile := filepath.Join(t.TempDir(), "pricing.csv")
+	err := os.WriteFile(pricingFile, []byte("InstanceType,OnDemandPricePerHour,SpotPricePerHour\nm5.large,0.0768,0.031\nc5.large,,0.029\n"), 0o600)
+	h.Ok(t, err)
+	ctx := context.Background()
+	filePricing, err := ec2pricing.LoadFilePricing(pricingFile, nil)
+	h.Ok(t, err)
+	h.Equals(t, 1, filePricing.OnDemandCacheCount())
+	h.Equals(t, 2, filePricing.SpotCacheCount())
+
+	price, err := filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Equals(t, 0.0768, price)
+	price, err = filePricing.GetSpotInstanceTypeNDayAvgCost(ctx, ec2types.InstanceTypeC5Large, nil, 30)
+	h.Ok(t, err)
+	h.Equals(t, 0.029, price)
+	_, err = filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeC5Large)
+	h.Nok(t, err)
+}
+
+func TestLoadFilePricing_Invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"missing instance type column": "OnDemandPricePerHour\n0.0768\n",
+		"invalid price":                "InstanceType,OnDemandPricePerHour\nm5.large,cheap\n",
+		"negative price":               "InstanceType,OnDemandPricePerHour\nm5.large,-1\n",
+	} {
+		pricingFile := filepath.Join(t.TempDir(), "pricing.csv")
+		h.Ok(t, os.WriteFile(pricingFile, []byte(contents), 0o600))
+		_, err := ec2pricing.LoadFilePricing(pricingFile, nil)
+		h.Assert(t, err != nil, "Should fail to load a pricing file with a "+name)
+	}
+}
+
+func TestFilePricing_Fallback(t *testing.T) {
+	pricingMock := setupOdMock(t, getProducts, "m5_large.json")
+	ctx := context.Background()
+	fallback := &ec2pricing.EC2Pricing{
+		ODPricing: lo.Must(ec2pricing.LoadODCacheOrNew(ctx, pricingMock, "us-east-1", 0, "")),
+	}
+	filePricing := ec2pricing.NewFilePricing(map[ec2types.InstanceType]float64{ec2types.InstanceTypeC5Large: 0.05}, nil, fallback)
+	h.Ok(t, filePricing.RefreshOnDemandCache(ctx))
+	h.Equals(t, fallback.OnDemandCacheCount(), filePricing.OnDemandCacheCount())
+
+	price, err := filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+	price, err = filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeC5Large)
+	h.Ok(t, err)
+	h.Equals(t, 0.05, price)
+}
+
+func TestDiscountedPricing(t *testing.T) {
+	pricingMock := setupOdMock(t, getProducts, "m5_large.json")
+	ctx := context.Background()
+	discountedPricing, err := ec2pricing.NewDiscountedPricing(&ec2pricing.EC2Pricing{
+		ODPricing: lo.Must(ec2pricing.LoadODCacheOrNew(ctx, pricingMock, "us-east-1", 0, "")),
+	}, 25)
+	h.Ok(t, err)
+	price, err := discountedPricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.072) < 1e-9, "Should discount the on-demand price of 0.096 by 25%%, got %v", price)
+
+	_, err = ec2pricing.NewDiscountedPricing(discountedPricing, 100)
+	h.Nok(t, err)
+	_, err = ec2pricing.NewDiscountedPricing(discountedPricing, -5)
+	h.Nok(t, err)
+}
+
+func TestDiscountedPricing_FilePricesNotDiscounted(t *testing.T) {
+	pricingMock := setupOdMock(t, getProducts, "m5_large.json")
+	ctx := context.Background()
+	discountedPricing, err := ec2pricing.NewDiscountedPricing(&ec2pricing.EC2Pricing{
+		ODPricing: lo.Must(ec2pricing.LoadODCacheOrNew(ctx, pricingMock, "us-east-1", 0, "")),
+	}, 25)
+	h.Ok(t, err)
+	filePricing := ec2pricing.NewFilePricing(map[ec2types.InstanceType]float64{ec2types.InstanceTypeC5Large: 0.05}, nil, discountedPricing)
+
+	price, err := filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeC5Large)
+	h.Ok(t, err)
+	h.Equals(t, 0.05, price)
+	h.Equals(t, float64(0), filePricing.GetOnDemandDiscountPercent(ec2types.InstanceTypeC5Large))
+
+	price, err = filePricing.GetOnDemandInstanceTypeCost(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Assert(t, math.Abs(price-0.072) < 1e-9, "Should discount the on-demand price of 0.096 by 25%%, got %v", price)
+	h.Equals(t, float64(25), filePricing.GetOnDemandDiscountPercent(ec2types.InstanceTypeM5Large))
+}
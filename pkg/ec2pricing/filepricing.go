@@ -0,0 +1,274 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ec2pricing
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	instanceTypeColumn         = "InstanceType"
+	onDemandPricePerHourColumn = "OnDemandPricePerHour"
+	spotPricePerHourColumn     = "SpotPricePerHour"
+)
+
+// FilePricing is an EC2PricingIface which returns custom hourly prices, such as negotiated private pricing, and falls back to
+// another EC2PricingIface for the instance types and usage classes it does not have a price for.
+// The caches of the fallback are refreshed and saved as usual, the custom prices are never cached.
+type FilePricing struct {
+	OnDemandPrices map[ec2types.InstanceType]float64
+	// SpotPrices apply to every availability zone of the region
+	SpotPrices map[ec2types.InstanceType]float64
+	// Fallback retrieves the prices which are not custom, it is optional
+	Fallback EC2PricingIface
+}
+
+// NewFilePricing creates a FilePricing which returns the custom on-demand and spot prices and falls back to fallback, which can be nil.
+func NewFilePricing(onDemandPrices map[ec2types.InstanceType]float64, spotPrices map[ec2types.InstanceType]float64, fallback EC2PricingIface) *FilePricing {
+	return &FilePricing{
+		OnDemandPrices: onDemandPrices,
+		SpotPrices:     spotPrices,
+		Fallback:       fallback,
+	}
+}
+
+// LoadFilePricing creates a FilePricing from a CSV file which has a header row of InstanceType, OnDemandPricePerHour, and
+// optionally SpotPricePerHour columns (Example: m5.large,0.0768,0.031). Empty prices fall back to fallback, which can be nil.
+func LoadFilePricing(path string, fallback EC2PricingIface) (*FilePricing, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand pricing file path %s: %w", path, err)
+	}
+	pricingFile, err := os.Open(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pricing file %s: %w", expandedPath, err)
+	}
+	defer pricingFile.Close()
+	onDemandPrices, spotPrices, err := parsePricingCSV(pricingFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse pricing file %s: %w", expandedPath, err)
+	}
+	return NewFilePricing(onDemandPrices, spotPrices, fallback), nil
+}
+
+// parsePricingCSV returns the on-demand and spot prices keyed by instance type of the pricing CSV.
+func parsePricingCSV(r io.Reader) (map[ec2types.InstanceType]float64, map[ec2types.InstanceType]float64, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the header row: %w", err)
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	instanceTypeIndex, ok := columns[instanceTypeColumn]
+	if !ok {
+		return nil, nil, fmt.Errorf("the header row is missing the %s column", instanceTypeColumn)
+	}
+	onDemandPrices := map[ec2types.InstanceType]float64{}
+	spotPrices := map[ec2types.InstanceType]float64{}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		instanceType := ec2types.InstanceType(strings.TrimSpace(record[instanceTypeIndex]))
+		for column, prices := range map[string]map[ec2types.InstanceType]float64{onDemandPricePerHourColumn: onDemandPrices, spotPricePerHourColumn: spotPrices} {
+			index, ok := columns[column]
+			if !ok || strings.TrimSpace(record[index]) == "" {
+				continue
+			}
+			price, err := strconv.ParseFloat(strings.TrimSpace(record[index]), 64)
+			if err != nil || price < 0 {
+				return nil, nil, fmt.Errorf("invalid %s %q of instance type %s", column, record[index], instanceType)
+			}
+			prices[instanceType] = price
+		}
+	}
+	return onDemandPrices, spotPrices, nil
+}
+
+// GetOnDemandInstanceTypeCost returns the custom on-demand hourly cost of the instance type or the cost retrieved by the fallback.
+func (p *FilePricing) GetOnDemandInstanceTypeCost(ctx context.Context, instanceType ec2types.InstanceType) (float64, error) {
+	if price, ok := p.OnDemandPrices[instanceType]; ok {
+		return price, nil
+	}
+	if p.Fallback == nil {
+		return -1, fmt.Errorf("no on-demand price for instance type %s in the pricing file", instanceType)
+	}
+	return p.Fallback.GetOnDemandInstanceTypeCost(ctx, instanceType)
+}
+
+// GetOnDemandDiscountPercent returns the discount the fallback applied to the on-demand price of the instance type, custom
+// on-demand prices are never discounted.
+func (p *FilePricing) GetOnDemandDiscountPercent(instanceType ec2types.InstanceType) float64 {
+	if _, ok := p.OnDemandPrices[instanceType]; ok {
+		return 0
+	}
+	if discounter, ok := p.Fallback.(OnDemandDiscounter); ok {
+		return discounter.GetOnDemandDiscountPercent(instanceType)
+	}
+	return 0
+}
+
+// GetSpotInstanceTypeNDayAvgCost returns the custom spot hourly cost of the instance type or the N day average cost retrieved by the fallback.
+func (p *FilePricing) GetSpotInstanceTypeNDayAvgCost(ctx context.Context, instanceType ec2types.InstanceType, availabilityZones []string, days int) (float64, error) {
+	if price, ok := p.SpotPrices[instanceType]; ok {
+		return price, nil
+	}
+	if p.Fallback == nil {
+		return -1, fmt.Errorf("no spot price for instance type %s in the pricing file", instanceType)
+	}
+	return p.Fallback.GetSpotInstanceTypeNDayAvgCost(ctx, instanceType, availabilityZones, days)
+}
+
+// GetSpotInstanceTypeNDayAvgCostByZone returns the spot hourly costs of each AZ retrieved by the fallback, the custom spot
+// cost of the instance type replaces the cost of every AZ.
+func (p *FilePricing) GetSpotInstanceTypeNDayAvgCostByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error) {
+	zonalPricer, ok := p.Fallback.(ZonalSpotPricer)
+	if !ok {
+		return nil, fmt.Errorf("spot prices by availability zone of instance type %s require a fallback pricing source which retrieves them", instanceType)
+	}
+	zonePrices, err := zonalPricer.GetSpotInstanceTypeNDayAvgCostByZone(ctx, instanceType, days)
+	if err != nil {
+		return nil, err
+	}
+	if price, ok := p.SpotPrices[instanceType]; ok {
+		for zone := range zonePrices {
+			zonePrices[zone] = price
+		}
+	}
+	return zonePrices, nil
+}
+
+// GetSpotInstanceTypeLastPriceChange returns the time of the newest spot price of the instance type retrieved by the fallback,
+// it is unknown for custom spot prices.
+func (p *FilePricing) GetSpotInstanceTypeLastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (time.Time, bool) {
+	if _, ok := p.SpotPrices[instanceType]; ok {
+		return time.Time{}, false
+	}
+	if changeTimer, ok := p.Fallback.(SpotPriceChangeTimer); ok {
+		return changeTimer.GetSpotInstanceTypeLastPriceChange(instanceType, availabilityZones)
+	}
+	return time.Time{}, false
+}
+
+// RefreshOnDemandCache refreshes the on-demand pricing cache of the fallback.
+func (p *FilePricing) RefreshOnDemandCache(ctx context.Context) error {
+	if p.Fallback == nil {
+		return nil
+	}
+	return p.Fallback.RefreshOnDemandCache(ctx)
+}
+
+// RefreshSpotCache refreshes the spot pricing cache of the fallback.
+func (p *FilePricing) RefreshSpotCache(ctx context.Context, days int) error {
+	if p.Fallback == nil {
+		return nil
+	}
+	return p.Fallback.RefreshSpotCache(ctx, days)
+}
+
+// RefreshSpotCacheFor refreshes the spot pricing cache of the fallback for the instance types and availability zones.
+func (p *FilePricing) RefreshSpotCacheFor(ctx context.Context, days int, instanceTypes []ec2types.InstanceType, availabilityZones []string) error {
+	if p.Fallback == nil {
+		return nil
+	}
+	return p.Fallback.RefreshSpotCacheFor(ctx, days, instanceTypes, availabilityZones)
+}
+
+// OnDemandCacheCount returns the number of items in the on-demand cache of the fallback, or the number of custom
+// on-demand prices without a fallback, so that the fallback cache is still refreshed when it is empty.
+func (p *FilePricing) OnDemandCacheCount() int {
+	if p.Fallback == nil {
+		return len(p.OnDemandPrices)
+	}
+	return p.Fallback.OnDemandCacheCount()
+}
+
+// SpotCacheCount returns the number of items in the spot cache of the fallback, or the number of custom spot prices
+// without a fallback, so that the fallback cache is still refreshed when it is empty.
+func (p *FilePricing) SpotCacheCount() int {
+	if p.Fallback == nil {
+		return len(p.SpotPrices)
+	}
+	return p.Fallback.SpotCacheCount()
+}
+
+// Save saves the pricing caches of the fallback.
+func (p *FilePricing) Save() error {
+	if p.Fallback == nil {
+		return nil
+	}
+	return p.Fallback.Save()
+}
+
+// SaveOnDemand saves the on-demand pricing caches of the fallback, or all of its caches if it does not implement CacheSaver.
+func (p *FilePricing) SaveOnDemand() error {
+	return saveOnDemand(p.Fallback)
+}
+
+// SaveSpot saves the spot pricing cache of the fallback if it implements CacheSaver.
+func (p *FilePricing) SaveSpot() error {
+	return saveSpot(p.Fallback)
+}
+
+// saveOnDemand saves the on-demand pricing caches of pricing, or all of its caches if it does not implement CacheSaver.
+func saveOnDemand(pricing EC2PricingIface) error {
+	if saver, ok := pricing.(CacheSaver); ok {
+		return saver.SaveOnDemand()
+	}
+	if pricing == nil {
+		return nil
+	}
+	return pricing.Save()
+}
+
+// saveSpot saves the spot pricing cache of pricing if it implements CacheSaver, otherwise it is saved by saveOnDemand.
+func saveSpot(pricing EC2PricingIface) error {
+	if saver, ok := pricing.(CacheSaver); ok {
+		return saver.SaveSpot()
+	}
+	return nil
+}
+
+// SetLogger sets the logger of the fallback.
+func (p *FilePricing) SetLogger(logger *log.Logger) {
+	if p.Fallback != nil {
+		p.Fallback.SetLogger(logger)
+	}
+}
+
+// SetCacheReadOnly sets whether the pricing caches of the fallback are saved.
+func (p *FilePricing) SetCacheReadOnly(readOnly bool) {
+	if p.Fallback != nil {
+		p.Fallback.SetCacheReadOnly(readOnly)
+	}
+}
@@ -31,6 +31,8 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/patrickmn/go-cache"
 	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
 )
 
 const (
@@ -41,9 +43,11 @@ type OnDemandPricing struct {
 	Region         string
 	FullRefreshTTL time.Duration
 	DirectoryPath  string
-	cache          *cache.Cache
-	pricingClient  pricing.GetProductsAPIClient
-	logger         *log.Logger
+	// ReadOnly prevents the cache file in DirectoryPath from being saved or removed
+	ReadOnly      bool
+	cache         *cache.Cache
+	pricingClient pricing.GetProductsAPIClient
+	logger        *log.Logger
 	sync.RWMutex
 }
 
@@ -84,19 +88,24 @@ type PriceDimensionInfo struct {
 	PricePerUnit map[string]string `json:"pricePerUnit"`
 }
 
-func LoadODCacheOrNew(ctx context.Context, pricingClient pricing.GetProductsAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string) (*OnDemandPricing, error) {
-	expandedDirPath, err := homedir.Expand(directoryPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load on-demand pricing cache directory %s: %w", expandedDirPath, err)
-	}
-	odPricing := &OnDemandPricing{
+// newODPricing creates an on-demand pricing cache without loading the cache file.
+func newODPricing(pricingClient pricing.GetProductsAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string) *OnDemandPricing {
+	return &OnDemandPricing{
 		Region:         region,
 		FullRefreshTTL: fullRefreshTTL,
-		DirectoryPath:  expandedDirPath,
+		DirectoryPath:  directoryPath,
 		pricingClient:  pricingClient,
 		cache:          cache.New(fullRefreshTTL, fullRefreshTTL),
 		logger:         log.New(io.Discard, "", 0),
 	}
+}
+
+func LoadODCacheOrNew(ctx context.Context, pricingClient pricing.GetProductsAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string) (*OnDemandPricing, error) {
+	expandedDirPath, err := homedir.Expand(directoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load on-demand pricing cache directory %s: %w", expandedDirPath, err)
+	}
+	odPricing := newODPricing(pricingClient, region, fullRefreshTTL, expandedDirPath)
 	if fullRefreshTTL <= 0 {
 		if err := odPricing.Clear(); err != nil {
 			return nil, fmt.Errorf("unable to clear od pricing cache due to ttl <= 0 %w", err)
@@ -106,7 +115,8 @@ func LoadODCacheOrNew(ctx context.Context, pricingClient pricing.GetProductsAPIC
 	// Start the cache refresh job
 	go odPricing.odCacheRefreshJob(ctx)
 	odCache, err := loadODCacheFrom(fullRefreshTTL, region, expandedDirPath)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
+	// a cache file which is locked by another process is a cache miss
+	if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, filelock.ErrLocked) {
 		return nil, fmt.Errorf("an on-demand pricing cache file could not be loaded: %v", err)
 	}
 	if err != nil {
@@ -117,7 +127,7 @@ func LoadODCacheOrNew(ctx context.Context, pricingClient pricing.GetProductsAPIC
 }
 
 func loadODCacheFrom(itemTTL time.Duration, region string, expandedDirPath string) (*cache.Cache, error) {
-	cacheBytes, err := os.ReadFile(getODCacheFilePath(region, expandedDirPath))
+	cacheBytes, err := filelock.ReadFile(getODCacheFilePath(region, expandedDirPath))
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +195,9 @@ func (c *OnDemandPricing) Count() int {
 	return c.cache.ItemCount()
 }
 
+// Save persists the on-demand pricing cache to DirectoryPath if caching is configured, nothing is written without a DirectoryPath.
 func (c *OnDemandPricing) Save() error {
-	if c.FullRefreshTTL == 0 || c.Count() == 0 {
+	if c.ReadOnly || c.FullRefreshTTL == 0 || c.DirectoryPath == "" || c.Count() == 0 {
 		return nil
 	}
 	cacheBytes, err := json.Marshal(c.cache.Items())
@@ -196,13 +207,17 @@ func (c *OnDemandPricing) Save() error {
 	if err := os.Mkdir(c.DirectoryPath, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
 		return err
 	}
-	return os.WriteFile(getODCacheFilePath(c.Region, c.DirectoryPath), cacheBytes, 0600)
+	cacheFilePath := getODCacheFilePath(c.Region, c.DirectoryPath)
+	return filelock.WriteFile(cacheFilePath, cacheBytes, 0600)
 }
 
 func (c *OnDemandPricing) Clear() error {
 	c.Lock()
 	defer c.Unlock()
 	c.cache.Flush()
+	if c.ReadOnly || c.DirectoryPath == "" {
+		return nil
+	}
 	if err := os.Remove(getODCacheFilePath(c.Region, c.DirectoryPath)); err != nil && !os.IsNotExist(err) {
 		return err
 	}
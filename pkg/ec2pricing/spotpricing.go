@@ -13,6 +13,7 @@
 package ec2pricing
 
 import (
+	"bytes"
 	"context"
 	"encoding/gob"
 	"errors"
@@ -22,16 +23,20 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/mitchellh/go-homedir"
 	"github.com/patrickmn/go-cache"
 	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
 )
 
 const (
@@ -42,9 +47,16 @@ type SpotPricing struct {
 	Region         string
 	FullRefreshTTL time.Duration
 	DirectoryPath  string
-	cache          *cache.Cache
-	ec2Client      ec2.DescribeSpotPriceHistoryAPIClient
-	logger         *log.Logger
+	// ReadOnly prevents the cache file in DirectoryPath from being saved or removed
+	ReadOnly  bool
+	cache     *cache.Cache
+	ec2Client ec2.DescribeSpotPriceHistoryAPIClient
+	logger    *log.Logger
+	// staleEntries holds the expired spot price history loaded from the cache file, which is merged into the next refresh
+	// so that only the history since the newest entries is fetched
+	staleEntries map[string][]*spotPricingEntry
+	// scopedInstanceTypes holds the instance types of the last scoped refresh, it is nil after a full refresh
+	scopedInstanceTypes map[ec2types.InstanceType]bool
 	sync.RWMutex
 }
 
@@ -54,19 +66,24 @@ type spotPricingEntry struct {
 	Zone      string
 }
 
-func LoadSpotCacheOrNew(ctx context.Context, ec2Client ec2.DescribeSpotPriceHistoryAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string, days int) (*SpotPricing, error) {
-	expandedDirPath, err := homedir.Expand(directoryPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load spot pricing cache directory %s: %w", expandedDirPath, err)
-	}
-	spotPricing := &SpotPricing{
+// newSpotPricing creates a spot pricing cache without loading the cache file.
+func newSpotPricing(ec2Client ec2.DescribeSpotPriceHistoryAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string) *SpotPricing {
+	return &SpotPricing{
 		Region:         region,
 		FullRefreshTTL: fullRefreshTTL,
-		DirectoryPath:  expandedDirPath,
+		DirectoryPath:  directoryPath,
 		ec2Client:      ec2Client,
 		cache:          cache.New(fullRefreshTTL, fullRefreshTTL),
 		logger:         log.New(io.Discard, "", 0),
 	}
+}
+
+func LoadSpotCacheOrNew(ctx context.Context, ec2Client ec2.DescribeSpotPriceHistoryAPIClient, region string, fullRefreshTTL time.Duration, directoryPath string, days int) (*SpotPricing, error) {
+	expandedDirPath, err := homedir.Expand(directoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load spot pricing cache directory %s: %w", expandedDirPath, err)
+	}
+	spotPricing := newSpotPricing(ec2Client, region, fullRefreshTTL, expandedDirPath)
 	if fullRefreshTTL <= 0 {
 		if err := spotPricing.Clear(); err != nil {
 			return nil, err
@@ -76,30 +93,41 @@ func LoadSpotCacheOrNew(ctx context.Context, ec2Client ec2.DescribeSpotPriceHist
 	gob.Register([]*spotPricingEntry{})
 	// Start the cache refresh job
 	go spotPricing.spotCacheRefreshJob(ctx, days)
-	spotCache, err := loadSpotCacheFrom(fullRefreshTTL, region, expandedDirPath)
-	if err != nil && !os.IsNotExist(err) {
+	spotCache, staleEntries, err := loadSpotCacheFrom(fullRefreshTTL, region, expandedDirPath)
+	// a cache file which is locked by another process is a cache miss
+	if err != nil && !os.IsNotExist(err) && !errors.Is(err, filelock.ErrLocked) {
 		return nil, fmt.Errorf("a spot pricing cache file could not be loaded: %w", err)
 	}
 	if err != nil {
 		spotCache = cache.New(0, 0)
 	}
 	spotPricing.cache = spotCache
+	spotPricing.staleEntries = staleEntries
 	return spotPricing, nil
 }
 
-func loadSpotCacheFrom(itemTTL time.Duration, region string, expandedDirPath string) (*cache.Cache, error) {
-	file, err := os.Open(getSpotCacheFilePath(region, expandedDirPath))
+// loadSpotCacheFrom loads the spot pricing cache file and returns the unexpired items as a cache
+// and the entries of the expired items separately.
+func loadSpotCacheFrom(itemTTL time.Duration, region string, expandedDirPath string) (*cache.Cache, map[string][]*spotPricingEntry, error) {
+	cacheBytes, err := filelock.ReadFile(getSpotCacheFilePath(region, expandedDirPath))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	decoder := gob.NewDecoder(file)
-	spotTimeSeries := &map[string]cache.Item{}
-	if err := decoder.Decode(spotTimeSeries); err != nil {
-		return nil, err
+	decoder := gob.NewDecoder(bytes.NewReader(cacheBytes))
+	spotTimeSeries := map[string]cache.Item{}
+	if err := decoder.Decode(&spotTimeSeries); err != nil {
+		return nil, nil, err
+	}
+	staleEntries := map[string][]*spotPricingEntry{}
+	for instanceType, item := range spotTimeSeries {
+		if item.Expired() {
+			if entries, ok := item.Object.([]*spotPricingEntry); ok {
+				staleEntries[instanceType] = entries
+			}
+			delete(spotTimeSeries, instanceType)
+		}
 	}
-	c := cache.NewFrom(itemTTL, itemTTL, *spotTimeSeries)
-	c.DeleteExpired()
-	return c, nil
+	return cache.NewFrom(itemTTL, itemTTL, spotTimeSeries), staleEntries, nil
 }
 
 func getSpotCacheFilePath(region string, directoryPath string) string {
@@ -123,15 +151,43 @@ func (c *SpotPricing) SetLogger(logger *log.Logger) {
 }
 
 func (c *SpotPricing) Refresh(ctx context.Context, days int) error {
+	return c.RefreshFor(ctx, days, nil, nil)
+}
+
+// RefreshFor refreshes the cache with the spot price history of only the passed in instance types and availability zones.
+// Empty instanceTypes and availabilityZones refresh all instance types in all zones of the region.
+// Only the history since the newest cached entries is fetched and merged with the cached history,
+// entries older than the lookback window of days are expired.
+// Scoped refreshes are not saved to disk, and spot prices of instance types outside the scope are not fetched until the next full refresh.
+func (c *SpotPricing) RefreshFor(ctx context.Context, days int, instanceTypes []ec2types.InstanceType, availabilityZones []string) error {
 	c.Lock()
 	defer c.Unlock()
-	spotInstanceTypeCosts, err := c.fetchSpotPricingTimeSeries(ctx, "", days)
+	endTime := time.Now().UTC()
+	windowStartTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
+	cachedEntries := c.cachedEntries(instanceTypes)
+	startTime := incrementalStartTime(cachedEntries, instanceTypes, availabilityZones, windowStartTime)
+	spotInstanceTypeCosts, err := c.fetchSpotPricingTimeSeriesBetween(ctx, instanceTypes, availabilityZones, startTime, endTime)
 	if err != nil {
 		return fmt.Errorf("there was a problem refreshing the spot instance type pricing cache: %v", err)
 	}
+	if startTime.After(windowStartTime) {
+		c.logger.Printf("Fetched spot pricing since %s and merged it with the cached spot pricing", startTime.Format(time.RFC3339))
+	}
+	for instanceType, entries := range cachedEntries {
+		spotInstanceTypeCosts[instanceType] = mergeSpotPricingEntries(entries, spotInstanceTypeCosts[instanceType], windowStartTime)
+	}
 	for instanceTypeAndZone, cost := range spotInstanceTypeCosts {
 		c.cache.SetDefault(instanceTypeAndZone, cost)
+		delete(c.staleEntries, instanceTypeAndZone)
+	}
+	if len(instanceTypes) != 0 || len(availabilityZones) != 0 {
+		c.scopedInstanceTypes = map[ec2types.InstanceType]bool{}
+		for _, instanceType := range instanceTypes {
+			c.scopedInstanceTypes[instanceType] = true
+		}
+		return nil
 	}
+	c.scopedInstanceTypes = nil
 	if err := c.Save(); err != nil {
 		return fmt.Errorf("unable to save the refreshed spot instance type pricing cache file: %v", err)
 	}
@@ -145,10 +201,13 @@ func (c *SpotPricing) Get(ctx context.Context, instanceType ec2types.InstanceTyp
 			ok = false
 		}
 	}
+	if !ok && c.isOutOfRefreshScope(instanceType) {
+		return -1, fmt.Errorf("spot pricing for %s was not included in the last spot pricing cache refresh", instanceType)
+	}
 	if !ok {
 		c.RLock()
 		defer c.RUnlock()
-		zonalSpotPricing, err := c.fetchSpotPricingTimeSeries(ctx, instanceType, days)
+		zonalSpotPricing, err := c.fetchSpotPricingTimeSeries(ctx, []ec2types.InstanceType{instanceType}, nil, days)
 		if err != nil {
 			return -1, fmt.Errorf("there was a problem fetching spot instance type pricing for %s: %v", instanceType, err)
 		}
@@ -164,6 +223,129 @@ func (c *SpotPricing) Get(ctx context.Context, instanceType ec2types.InstanceTyp
 	return c.calculateSpotAggregate(c.filterOn(zone, entries.([]*spotPricingEntry))), nil
 }
 
+// GetByZone returns the average spot price of the instance type over the past N days keyed by availability zone name.
+// The spot price history of the instance type is fetched if it is not cached.
+func (c *SpotPricing) GetByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error) {
+	if _, err := c.Get(ctx, instanceType, "", days); err != nil {
+		return nil, err
+	}
+	entries, ok := c.cache.Get(string(instanceType))
+	if !ok {
+		return nil, fmt.Errorf("unable to get spot pricing for %s for %d days back", instanceType, days)
+	}
+	zonePrices := map[string]float64{}
+	for _, entry := range entries.([]*spotPricingEntry) {
+		if _, ok := zonePrices[entry.Zone]; !ok {
+			zonePrices[entry.Zone] = c.calculateSpotAggregate(c.filterOn(entry.Zone, entries.([]*spotPricingEntry)))
+		}
+	}
+	return zonePrices, nil
+}
+
+// LastPriceChange returns the time of the newest cached spot price of the instance type in the availability zones, or in
+// every zone if none are passed in. ok is false if no spot price of the instance type in the zones is cached.
+func (c *SpotPricing) LastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (lastChange time.Time, ok bool) {
+	entries, found := c.cache.Get(string(instanceType))
+	if !found {
+		return time.Time{}, false
+	}
+	for zone, timestamp := range newestEntryTimestamps(entries.([]*spotPricingEntry)) {
+		if len(availabilityZones) != 0 && !slices.Contains(availabilityZones, zone) {
+			continue
+		}
+		if !ok || timestamp.After(lastChange) {
+			lastChange, ok = timestamp, true
+		}
+	}
+	return lastChange, ok
+}
+
+// cachedEntries returns the cached and stale spot price history of the passed in instance types, or of all instance types if none are passed in.
+func (c *SpotPricing) cachedEntries(instanceTypes []ec2types.InstanceType) map[string][]*spotPricingEntry {
+	entries := map[string][]*spotPricingEntry{}
+	for instanceType, item := range c.cache.Items() {
+		entries[instanceType] = item.Object.([]*spotPricingEntry)
+	}
+	for instanceType, staleEntries := range c.staleEntries {
+		if _, ok := entries[instanceType]; !ok {
+			entries[instanceType] = staleEntries
+		}
+	}
+	if len(instanceTypes) == 0 {
+		return entries
+	}
+	scopedEntries := map[string][]*spotPricingEntry{}
+	for _, instanceType := range instanceTypes {
+		if instanceTypeEntries, ok := entries[string(instanceType)]; ok {
+			scopedEntries[string(instanceType)] = instanceTypeEntries
+		}
+	}
+	return scopedEntries
+}
+
+// incrementalStartTime returns the oldest of the newest cached entry timestamps of each instance type and zone in the refresh,
+// so that no history is missed when only fetching the history since the cached entries.
+// windowStartTime is returned if there are no cached entries, if any instance type in the refresh has no cached entries,
+// or if the returned time would be before it.
+func incrementalStartTime(cachedEntries map[string][]*spotPricingEntry, instanceTypes []ec2types.InstanceType, availabilityZones []string, windowStartTime time.Time) time.Time {
+	if len(cachedEntries) == 0 || len(instanceTypes) > len(cachedEntries) {
+		return windowStartTime
+	}
+	var startTime *time.Time
+	for _, entries := range cachedEntries {
+		for zone, newest := range newestEntryTimestamps(entries) {
+			if len(availabilityZones) != 0 && !slices.Contains(availabilityZones, zone) {
+				continue
+			}
+			if startTime == nil || newest.Before(*startTime) {
+				startTime = &newest
+			}
+		}
+	}
+	if startTime == nil || startTime.Before(windowStartTime) {
+		return windowStartTime
+	}
+	return *startTime
+}
+
+// newestEntryTimestamps returns the timestamp of the newest entry in each zone.
+func newestEntryTimestamps(entries []*spotPricingEntry) map[string]time.Time {
+	newest := map[string]time.Time{}
+	for _, entry := range entries {
+		if timestamp, ok := newest[entry.Zone]; !ok || entry.Timestamp.After(timestamp) {
+			newest[entry.Zone] = entry.Timestamp
+		}
+	}
+	return newest
+}
+
+// mergeSpotPricingEntries appends the fetched entries which are newer than the cached entries of their zone to the cached entries
+// and drops entries older than windowStartTime, except for the newest entry of each zone since that price is still in effect.
+func mergeSpotPricingEntries(cachedEntries []*spotPricingEntry, fetchedEntries []*spotPricingEntry, windowStartTime time.Time) []*spotPricingEntry {
+	cachedNewest := newestEntryTimestamps(cachedEntries)
+	merged := append([]*spotPricingEntry{}, cachedEntries...)
+	for _, entry := range fetchedEntries {
+		if timestamp, ok := cachedNewest[entry.Zone]; !ok || entry.Timestamp.After(timestamp) {
+			merged = append(merged, entry)
+		}
+	}
+	newest := newestEntryTimestamps(merged)
+	unexpired := []*spotPricingEntry{}
+	for _, entry := range merged {
+		if !entry.Timestamp.Before(windowStartTime) || entry.Timestamp.Equal(newest[entry.Zone]) {
+			unexpired = append(unexpired, entry)
+		}
+	}
+	return unexpired
+}
+
+// isOutOfRefreshScope returns true if the last refresh was scoped to instance types which did not include instanceType.
+func (c *SpotPricing) isOutOfRefreshScope(instanceType ec2types.InstanceType) bool {
+	c.RLock()
+	defer c.RUnlock()
+	return len(c.scopedInstanceTypes) != 0 && !c.scopedInstanceTypes[instanceType]
+}
+
 func (c *SpotPricing) contains(zone string, entries []*spotPricingEntry) bool {
 	for _, entry := range entries {
 		if entry.Zone == zone {
@@ -216,26 +398,37 @@ func (c *SpotPricing) Count() int {
 	return c.cache.ItemCount()
 }
 
+// Save persists the spot pricing cache to DirectoryPath if caching is configured, nothing is written without a DirectoryPath.
 func (c *SpotPricing) Save() error {
-	if c.FullRefreshTTL <= 0 || c.Count() == 0 {
+	if c.ReadOnly || c.FullRefreshTTL <= 0 || c.DirectoryPath == "" || c.Count() == 0 {
 		return nil
 	}
 	if err := os.Mkdir(c.DirectoryPath, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
 		return err
 	}
-	file, err := os.Create(getSpotCacheFilePath(c.Region, c.DirectoryPath))
-	if err != nil {
+	items := c.cache.Items()
+	// keep stale entries which have not been refreshed yet so that the next refresh can still be incremental
+	for instanceType, entries := range c.staleEntries {
+		if _, ok := items[instanceType]; !ok {
+			items[instanceType] = cache.Item{Object: entries, Expiration: time.Now().Add(-time.Second).UnixNano()}
+		}
+	}
+	cacheBytes := new(bytes.Buffer)
+	if err := gob.NewEncoder(cacheBytes).Encode(items); err != nil {
 		return err
 	}
-	defer file.Close()
-	encoder := gob.NewEncoder(file)
-	return encoder.Encode(c.cache.Items())
+	cacheFilePath := getSpotCacheFilePath(c.Region, c.DirectoryPath)
+	return filelock.WriteFile(cacheFilePath, cacheBytes.Bytes(), 0600)
 }
 
 func (c *SpotPricing) Clear() error {
 	c.Lock()
 	defer c.Unlock()
 	c.cache.Flush()
+	c.staleEntries = nil
+	if c.ReadOnly || c.DirectoryPath == "" {
+		return nil
+	}
 	if err := os.Remove(getSpotCacheFilePath(c.Region, c.DirectoryPath)); err != nil && !os.IsNotExist(err) {
 		return err
 	}
@@ -243,23 +436,34 @@ func (c *SpotPricing) Clear() error {
 }
 
 // fetchSpotPricingTimeSeries makes a bulk request to the ec2 api to retrieve all spot instance type pricing for the past n days
-// If instanceType is empty, it will fetch for all instance types.
-func (c *SpotPricing) fetchSpotPricingTimeSeries(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string][]*spotPricingEntry, error) {
+// If instanceTypes is empty, it will fetch for all instance types and if availabilityZones is empty, it will fetch for all zones.
+func (c *SpotPricing) fetchSpotPricingTimeSeries(ctx context.Context, instanceTypes []ec2types.InstanceType, availabilityZones []string, days int) (map[string][]*spotPricingEntry, error) {
+	endTime := time.Now().UTC()
+	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
+	return c.fetchSpotPricingTimeSeriesBetween(ctx, instanceTypes, availabilityZones, startTime, endTime)
+}
+
+// fetchSpotPricingTimeSeriesBetween retrieves the spot instance type pricing between startTime and endTime.
+func (c *SpotPricing) fetchSpotPricingTimeSeriesBetween(ctx context.Context, instanceTypes []ec2types.InstanceType, availabilityZones []string, startTime time.Time, endTime time.Time) (map[string][]*spotPricingEntry, error) {
 	start := time.Now()
 	calls := 0
 	defer func() {
 		c.logger.Printf("Took %s and %d calls to collect Spot pricing", time.Since(start), calls)
 	}()
 	spotTimeSeries := map[string][]*spotPricingEntry{}
-	endTime := time.Now().UTC()
-	startTime := endTime.Add(time.Hour * time.Duration(24*-1*days))
 	spotPriceHistInput := ec2.DescribeSpotPriceHistoryInput{
 		ProductDescriptions: []string{productDescription},
 		StartTime:           &startTime,
 		EndTime:             &endTime,
+		InstanceTypes:       instanceTypes,
 	}
-	if instanceType != "" {
-		spotPriceHistInput.InstanceTypes = append(spotPriceHistInput.InstanceTypes, instanceType)
+	if len(availabilityZones) != 0 {
+		spotPriceHistInput.Filters = []ec2types.Filter{
+			{
+				Name:   aws.String("availability-zone"),
+				Values: availabilityZones,
+			},
+		}
 	}
 	var processingErr error
 
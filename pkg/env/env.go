@@ -17,6 +17,7 @@ package env
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // WithDefaultInt returns the int value of the supplied environment variable or, if not present,
@@ -42,3 +43,46 @@ func WithDefaultString(key string, def string) *string {
 	}
 	return &val
 }
+
+// WithDefaultBool returns the bool value of the supplied environment variable or, if not present,
+// the supplied default value. If the bool conversion fails, returns the default.
+func WithDefaultBool(key string, def bool) *bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return &def
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return &def
+	}
+	return &b
+}
+
+// WithDefaultFloat64 returns the float64 value of the supplied environment variable or, if not present,
+// the supplied default value. If the float64 conversion fails, returns the default.
+func WithDefaultFloat64(key string, def float64) *float64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return &def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return &def
+	}
+	return &f
+}
+
+// WithDefaultDuration returns the time.Duration value of the supplied environment variable or, if not present,
+// the supplied default value. The value must be parseable by time.ParseDuration (Example: 90s, 1h30m).
+// If the duration conversion fails, returns the default.
+func WithDefaultDuration(key string, def time.Duration) *time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return &def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return &def
+	}
+	return &d
+}
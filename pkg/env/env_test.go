@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/env"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+const testEnvVar = "EC2_INSTANCE_SELECTOR_TEST_ENV"
+
+func TestWithDefaultInt(t *testing.T) {
+	h.Equals(t, 5, *env.WithDefaultInt(testEnvVar, 5))
+	t.Setenv(testEnvVar, "10")
+	h.Equals(t, 10, *env.WithDefaultInt(testEnvVar, 5))
+	t.Setenv(testEnvVar, "ten")
+	h.Equals(t, 5, *env.WithDefaultInt(testEnvVar, 5))
+}
+
+func TestWithDefaultString(t *testing.T) {
+	h.Equals(t, "default", *env.WithDefaultString(testEnvVar, "default"))
+	t.Setenv(testEnvVar, "value")
+	h.Equals(t, "value", *env.WithDefaultString(testEnvVar, "default"))
+}
+
+func TestWithDefaultBool(t *testing.T) {
+	h.Equals(t, false, *env.WithDefaultBool(testEnvVar, false))
+	t.Setenv(testEnvVar, "true")
+	h.Equals(t, true, *env.WithDefaultBool(testEnvVar, false))
+	t.Setenv(testEnvVar, "yes please")
+	h.Equals(t, false, *env.WithDefaultBool(testEnvVar, false))
+}
+
+func TestWithDefaultFloat64(t *testing.T) {
+	h.Equals(t, 0.5, *env.WithDefaultFloat64(testEnvVar, 0.5))
+	t.Setenv(testEnvVar, "1.25")
+	h.Equals(t, 1.25, *env.WithDefaultFloat64(testEnvVar, 0.5))
+	t.Setenv(testEnvVar, "one")
+	h.Equals(t, 0.5, *env.WithDefaultFloat64(testEnvVar, 0.5))
+}
+
+func TestWithDefaultDuration(t *testing.T) {
+	h.Equals(t, time.Minute, *env.WithDefaultDuration(testEnvVar, time.Minute))
+	t.Setenv(testEnvVar, "1h30m")
+	h.Equals(t, 90*time.Minute, *env.WithDefaultDuration(testEnvVar, time.Minute))
+	t.Setenv(testEnvVar, "90")
+	h.Equals(t, time.Minute, *env.WithDefaultDuration(testEnvVar, time.Minute))
+}
@@ -0,0 +1,190 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filelock provides advisory locks backed by lock files so that processes sharing a cache directory, such as
+// concurrent CLI invocations on a CI runner, do not write the same cache files at the same time.
+// Lock files are created exclusively which works on every platform, unlike flock.
+package filelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// ErrLocked is returned by TryAcquire when the lock is held by another process.
+var ErrLocked = errors.New("locked by another process")
+
+// retryInterval is how often Acquire retries to create the lock file while it is held.
+var retryInterval = 50 * time.Millisecond
+
+const (
+	// fileLockTimeout is how long the lock of a file is waited for while another process reads or writes the file
+	fileLockTimeout = 10 * time.Second
+	// fileLockStaleAfter is how long the lock of a file is held before it is assumed to be left behind by a crashed
+	// process, reading and writing a cache file takes well under a second
+	fileLockStaleAfter = time.Minute
+)
+
+// Lock is an acquired lock which is held until it is released.
+type Lock struct {
+	path string
+}
+
+// TryAcquire acquires the lock file at path without waiting and returns ErrLocked if it is held. Lock files which were
+// not modified within staleAfter are assumed to be left behind by a process which crashed and are taken over.
+func TryAcquire(path string, staleAfter time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create the directory of lock file %s: %w", path, err)
+	}
+	lockFile, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) <= staleAfter {
+			return nil, fmt.Errorf("lock file %s is %w", path, ErrLocked)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unable to remove stale lock file %s: %w", path, err)
+		}
+		lockFile, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			// another process took over the stale lock first
+			return nil, fmt.Errorf("lock file %s is %w", path, ErrLocked)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create lock file %s: %w", path, err)
+	}
+	defer lockFile.Close()
+	// the PID helps to find the process holding the lock, it is not used to detect stale locks
+	if _, err := lockFile.WriteString(strconv.Itoa(os.Getpid())); err != nil {
+		return nil, fmt.Errorf("unable to write lock file %s: %w", path, err)
+	}
+	return &Lock{path: path}, nil
+}
+
+// Acquire waits until the lock file at path is acquired or ctx is done, see TryAcquire for how stale locks are handled.
+func Acquire(ctx context.Context, path string, staleAfter time.Duration) (*Lock, error) {
+	for {
+		lock, err := TryAcquire(path, staleAfter)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// Release releases the lock by removing its lock file.
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove lock file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// lockFilePath returns the path of the lock file of the file at path.
+func lockFilePath(path string) string {
+	return path + ".lock"
+}
+
+// WithFileLock calls fn while holding the lock of the file at path, the lock file is path with a .lock suffix. Processes
+// which read and write the file with WithFileLock and ReadFile don't see each other's partial writes.
+func WithFileLock(path string, fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fileLockTimeout)
+	defer cancel()
+	lock, err := Acquire(ctx, lockFilePath(path), fileLockStaleAfter)
+	if err != nil {
+		return err
+	}
+	return multierr.Append(fn(), lock.Release())
+}
+
+// WriteFile replaces the file at path with data while holding its lock, see WithFileLock. The data is written and synced
+// to a temporary file in the same directory which is then renamed to path, so that the file is never left truncated or
+// partially written if the process is killed or the disk fills up while it is written.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return WithFileLock(path, func() error {
+		return writeFileAtomic(path, data, perm, time.Time{})
+	})
+}
+
+// WriteFileModTime is WriteFile which also sets the modification time of the file to modTime before it replaces the file
+// at path, so that other processes never see the file with the modification time of the write.
+func WriteFileModTime(path string, data []byte, perm os.FileMode, modTime time.Time) error {
+	return WithFileLock(path, func() error {
+		return writeFileAtomic(path, data, perm, modTime)
+	})
+}
+
+// writeFileAtomic writes data to path through a temporary file, modTime is only set if it is not zero.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, modTime time.Time) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("unable to create a temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmpFile.Name()
+	// the temporary file no longer exists once it is renamed to path
+	defer os.Remove(tmpPath)
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil && !modTime.IsZero() {
+		err = os.Chtimes(tmpPath, time.Now(), modTime)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("unable to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFile reads the file at path while holding its lock, see WithFileLock. Errors reading the file are returned as is so
+// that they can be checked with os.IsNotExist. The file is read without the lock if the lock file can not be created, such
+// as for caches shared from a read-only directory. An error wrapping ErrLocked is returned if another process still holds
+// the lock after waiting for it, which cache readers treat as a cache miss.
+func ReadFile(path string) ([]byte, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	var data []byte
+	var readErr error
+	err := WithFileLock(path, func() error {
+		data, readErr = os.ReadFile(path)
+		return nil
+	})
+	if errors.Is(err, ErrLocked) {
+		return nil, err
+	}
+	if err != nil {
+		return os.ReadFile(path)
+	}
+	return data, readErr
+}
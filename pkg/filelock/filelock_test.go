@@ -0,0 +1,130 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filelock_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestTryAcquire(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "cache", "refresh.lock")
+	lock, err := filelock.TryAcquire(lockPath, time.Hour)
+	h.Ok(t, err)
+
+	_, err = filelock.TryAcquire(lockPath, time.Hour)
+	h.Assert(t, errors.Is(err, filelock.ErrLocked), "Should not acquire a held lock")
+
+	h.Ok(t, lock.Release())
+	_, err = os.Stat(lockPath)
+	h.Assert(t, os.IsNotExist(err), "Should remove the lock file when the lock is released")
+	lock, err = filelock.TryAcquire(lockPath, time.Hour)
+	h.Ok(t, err)
+	h.Ok(t, lock.Release())
+	h.Ok(t, lock.Release())
+}
+
+func TestTryAcquire_Stale(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "refresh.lock")
+	h.Ok(t, os.WriteFile(lockPath, []byte("12345"), 0o600))
+	modTime := time.Now().Add(-2 * time.Hour)
+	h.Ok(t, os.Chtimes(lockPath, modTime, modTime))
+
+	lock, err := filelock.TryAcquire(lockPath, time.Hour)
+	h.Ok(t, err)
+	h.Ok(t, lock.Release())
+}
+
+func TestAcquire(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "refresh.lock")
+	lock, err := filelock.TryAcquire(lockPath, time.Hour)
+	h.Ok(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = filelock.Acquire(ctx, lockPath, time.Hour)
+	h.Assert(t, errors.Is(err, context.DeadlineExceeded), "Should stop waiting for the lock when the context is done")
+
+	released := make(chan error, 1)
+	held := lock
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		released <- held.Release()
+	}()
+	lock, err = filelock.Acquire(context.Background(), lockPath, time.Hour)
+	h.Ok(t, err)
+	h.Ok(t, <-released)
+	h.Ok(t, lock.Release())
+}
+
+func TestWithFileLock(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "us-east-1-ec2-instance-types.json")
+	err := filelock.WithFileLock(cacheFile, func() error {
+		_, err := filelock.TryAcquire(cacheFile+".lock", time.Hour)
+		h.Assert(t, errors.Is(err, filelock.ErrLocked), "Should hold the lock of the file while fn is called")
+		return os.WriteFile(cacheFile, []byte("{}"), 0o600)
+	})
+	h.Ok(t, err)
+	_, err = os.Stat(cacheFile + ".lock")
+	h.Assert(t, os.IsNotExist(err), "Should release the lock of the file after fn returns")
+
+	writeErr := errors.New("disk full")
+	err = filelock.WithFileLock(cacheFile, func() error { return writeErr })
+	h.Assert(t, errors.Is(err, writeErr), "Should return the error of fn")
+}
+
+func TestReadFile(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "us-east-1-ec2-instance-types.json")
+	_, err := filelock.ReadFile(cacheFile)
+	h.Assert(t, os.IsNotExist(err), fmt.Sprintf("Should return a not exist error for a missing file, got %v", err))
+
+	h.Ok(t, os.WriteFile(cacheFile, []byte("{}"), 0o600))
+	data, err := filelock.ReadFile(cacheFile)
+	h.Ok(t, err)
+	h.Equals(t, "{}", string(data))
+	_, err = os.Stat(cacheFile + ".lock")
+	h.Assert(t, os.IsNotExist(err), "Should release the lock of the file after reading it")
+}
+
+func TestWriteFile(t *testing.T) {
+	cacheDir := t.TempDir()
+	cacheFile := filepath.Join(cacheDir, "us-east-1-ec2-instance-types.json")
+	h.Ok(t, os.WriteFile(cacheFile, []byte(`{"previous": true}`), 0o644))
+
+	h.Ok(t, filelock.WriteFile(cacheFile, []byte("{}"), 0o600))
+	data, err := os.ReadFile(cacheFile)
+	h.Ok(t, err)
+	h.Equals(t, "{}", string(data))
+	entries, err := os.ReadDir(cacheDir)
+	h.Ok(t, err)
+	// neither the temporary file nor the lock file are left behind
+	h.Equals(t, 1, len(entries))
+}
+
+func TestWriteFileModTime(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "us-east-1-ec2-instance-types.json")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	h.Ok(t, filelock.WriteFileModTime(cacheFile, []byte("{}"), 0o600, modTime))
+	info, err := os.Stat(cacheFile)
+	h.Ok(t, err)
+	h.Assert(t, info.ModTime().Equal(modTime), "Should set the modification time to %v, got %v", modTime, info.ModTime())
+}
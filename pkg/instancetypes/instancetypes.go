@@ -21,29 +21,113 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/mitchellh/go-homedir"
 	"github.com/patrickmn/go-cache"
+	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
 )
 
 var CacheFileName = "ec2-instance-types.json"
 
+// MonthsPerYear is the number of months which annual prices are derived from the monthly prices of Details with.
+const MonthsPerYear = 12
+
+const (
+	// describeInstanceTypesMaxResults is the largest page size accepted by DescribeInstanceTypes
+	describeInstanceTypesMaxResults = 100
+	// describeInstanceTypeOfferingsMaxResults is the largest page size accepted by DescribeInstanceTypeOfferings
+	describeInstanceTypeOfferingsMaxResults = 1000
+	// maxInstanceTypesPerRequest is the most instance types which can be passed to a single DescribeInstanceTypes request
+	maxInstanceTypesPerRequest = 100
+	// maxConcurrentRequests limits the DescribeInstanceTypes requests in flight to avoid throttling
+	maxConcurrentRequests = 5
+)
+
 // Details hold all the information on an ec2 instance type.
 type Details struct {
 	ec2types.InstanceTypeInfo
 	OndemandPricePerHour *float64
 	SpotPrice            *float64
+	// SpotSavings is the percentage the spot price saves compared to the on-demand price
+	// It is only populated when both the on-demand and spot prices are fetched
+	SpotSavings *float64 `json:",omitempty"`
+	// OndemandPricePerMonth and SpotPricePerMonth are the hourly prices multiplied by the hours per month
+	// They are only populated when the respective hourly price is fetched
+	OndemandPricePerMonth *float64 `json:",omitempty"`
+	SpotPricePerMonth     *float64 `json:",omitempty"`
+	// CapacityBlockPricePerHour is the lowest hourly price of the Capacity Block for ML offerings currently available
+	// It is only populated when filtering on the capacity-block usage class
+	CapacityBlockPricePerHour *float64 `json:",omitempty"`
+	// BaselineCPUUtilization is the percentage of each vCPU a burstable instance type can use without spending CPU credits,
+	// 100 for instance types which are not burstable
+	BaselineCPUUtilization *float64 `json:",omitempty"`
+	// CPUCreditsPerHour is the number of CPU credits a burstable instance type earns per hour
+	CPUCreditsPerHour *float64 `json:",omitempty"`
+	// CarbonScore is a relative estimate of the carbon intensity of an instance type, lower is better
+	CarbonScore *float64 `json:",omitempty"`
+	// NitroGeneration is the generation of the Nitro cards the instance type is built on, 0 for Xen instance types
+	NitroGeneration *int `json:",omitempty"`
+	// NormalizationFactor is the normalization factor units (NFU) of the instance size which Reserved Instances are
+	// applied in (i.e. 4 for large and 8 for xlarge), nil for sizes without a normalization factor
+	NormalizationFactor *float64 `json:",omitempty"`
+	// EnaQueuesPerInterface and EnaQueues are the maximum number of ENA queues of each network interface and across all of
+	// the network interfaces, they are only populated for instance types in the bundled ENA queues dataset
+	EnaQueuesPerInterface *int32 `json:",omitempty"`
+	EnaQueues             *int32 `json:",omitempty"`
+	// Deprecation is the reason the instance type is deprecated, nil if it is not deprecated
+	Deprecation *string `json:",omitempty"`
+	// Annotations are advisory conditions of the instance type such as being of a previous generation or missing a price
+	// They are populated by the selector, see the selector.Annotation constants
+	Annotations []string `json:",omitempty"`
+	// ComputeOptimizerRank is the rank of the instance type in the AWS Compute Optimizer recommendation options
+	// It is only populated when cross-checking a rightsizing recommendation and the instance type is one of the options
+	ComputeOptimizerRank *int32 `json:",omitempty"`
+	// Region is the region the instance type was filtered in
+	// It is only populated when filtering across regions with selector.FilterRegionsVerbose
+	Region string `json:",omitempty"`
+	// AvailabilityZones are the filtered availability zones the instance type is offered in
+	// It is populated when filtering on availability zones or with all of the zones of the region by Selector.AddOfferedZones
+	AvailabilityZones []AvailabilityZone `json:",omitempty"`
+}
+
+// AvailabilityZone holds the name and ID of an availability zone.
+// Zone names are mapped to different zones in each account while zone IDs are the same across accounts.
+type AvailabilityZone struct {
+	ZoneName string
+	ZoneID   string `json:"ZoneId"`
+}
+
+func (z AvailabilityZone) String() string {
+	return fmt.Sprintf("%s (%s)", z.ZoneName, z.ZoneID)
+}
+
+// ProviderIface is the instance types Provider interface used to back Selector with alternative providers and to mock out
+// instance types during testing.
+type ProviderIface interface {
+	Get(ctx context.Context, instanceTypes []ec2types.InstanceType) ([]*Details, error)
+	CacheCount() int
+	Save() error
+	SetLogger(*log.Logger)
+	SetCacheReadOnly(readOnly bool)
 }
 
 type Provider struct {
-	Region          string
-	DirectoryPath   string
-	FullRefreshTTL  time.Duration
+	Region         string
+	DirectoryPath  string
+	FullRefreshTTL time.Duration
+	// ReadOnly prevents the cache file in DirectoryPath from being saved or removed
+	ReadOnly        bool
 	lastFullRefresh *time.Time
 	ec2Client       ec2.DescribeInstanceTypesAPIClient
+	offeringsClient ec2.DescribeInstanceTypeOfferingsAPIClient
 	cache           *cache.Cache
 	logger          *log.Logger
 }
@@ -74,7 +158,8 @@ func LoadFromOrNew(directoryPath string, region string, ttl time.Duration, ec2Cl
 		return provider, nil
 	}
 	itCache, err := loadFrom(ttl, region, expandedDirPath)
-	if err != nil && !os.IsNotExist(err) {
+	// a cache file which is locked by another process is a cache miss
+	if err != nil && !os.IsNotExist(err) && !errors.Is(err, filelock.ErrLocked) {
 		return nil, fmt.Errorf("unable to load instance-type cache from %s: %w", expandedDirPath, err)
 	}
 	if err != nil {
@@ -91,7 +176,7 @@ func LoadFromOrNew(directoryPath string, region string, ttl time.Duration, ec2Cl
 
 func loadFrom(ttl time.Duration, region string, expandedDirPath string) (*cache.Cache, error) {
 	itemTTL := ttl + time.Second
-	cacheBytes, err := os.ReadFile(getCacheFilePath(region, expandedDirPath))
+	cacheBytes, err := filelock.ReadFile(getCacheFilePath(region, expandedDirPath))
 	if err != nil {
 		return nil, err
 	}
@@ -110,27 +195,36 @@ func (p *Provider) SetLogger(logger *log.Logger) {
 	p.logger = logger
 }
 
+// SetCacheReadOnly prevents the cache file from being saved to or removed from DirectoryPath.
+func (p *Provider) SetCacheReadOnly(readOnly bool) {
+	p.ReadOnly = readOnly
+}
+
+// SetOfferingsClient enables full refreshes to look up the instance types offered in the region first and then
+// describe them in concurrent shards rather than paginating through every instance type sequentially.
+func (p *Provider) SetOfferingsClient(offeringsClient ec2.DescribeInstanceTypeOfferingsAPIClient) {
+	p.offeringsClient = offeringsClient
+}
+
 func (p *Provider) Get(ctx context.Context, instanceTypes []ec2types.InstanceType) ([]*Details, error) {
 	p.logger.Printf("Getting instance types %v", instanceTypes)
 	start := time.Now()
-	calls := 0
+	var calls atomic.Int32
 	defer func() {
-		p.logger.Printf("Took %s and %d calls to collect Instance Types", time.Since(start), calls)
+		p.logger.Printf("Took %s and %d calls to collect Instance Types", time.Since(start), calls.Load())
 	}()
 	instanceTypeDetails := []*Details{}
-	describeInstanceTypeOpts := &ec2.DescribeInstanceTypesInput{}
+	uncachedInstanceTypes := []ec2types.InstanceType{}
 	if len(instanceTypes) != 0 {
 		for _, it := range instanceTypes {
 			if cachedIT, ok := p.cache.Get(string(it)); ok {
 				instanceTypeDetails = append(instanceTypeDetails, cachedIT.(*Details))
 			} else {
-				// need to reassign, so we're not sharing the loop iterators memory space
-				instanceType := it
-				describeInstanceTypeOpts.InstanceTypes = append(describeInstanceTypeOpts.InstanceTypes, instanceType)
+				uncachedInstanceTypes = append(uncachedInstanceTypes, it)
 			}
 		}
 		// if we were able to retrieve all from cache, return here, else continue to do a remote lookup
-		if len(describeInstanceTypeOpts.InstanceTypes) == 0 {
+		if len(uncachedInstanceTypes) == 0 {
 			return instanceTypeDetails, nil
 		}
 	} else if p.lastFullRefresh != nil && !p.isFullRefreshNeeded() {
@@ -138,21 +232,28 @@ func (p *Provider) Get(ctx context.Context, instanceTypes []ec2types.InstanceTyp
 			instanceTypeDetails = append(instanceTypeDetails, item.Object.(*Details))
 		}
 		return instanceTypeDetails, nil
-	}
-
-	s := ec2.NewDescribeInstanceTypesPaginator(p.ec2Client, describeInstanceTypeOpts)
-
-	for s.HasMorePages() {
-		calls++
-		instanceTypeOutput, err := s.NextPage(ctx)
+	} else if p.offeringsClient != nil {
+		offeredInstanceTypes, err := p.getOfferedInstanceTypes(ctx, &calls)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get next instance types page, %w", err)
-		}
-		for _, instanceTypeInfo := range instanceTypeOutput.InstanceTypes {
-			itDetails := &Details{InstanceTypeInfo: instanceTypeInfo}
-			instanceTypeDetails = append(instanceTypeDetails, itDetails)
-			p.cache.SetDefault(string(instanceTypeInfo.InstanceType), itDetails)
+			p.logger.Printf("Unable to retrieve the instance types offered in %s, paginating all instance types instead: %v", p.Region, err)
 		}
+		uncachedInstanceTypes = offeredInstanceTypes
+	}
+
+	var describedInstanceTypes []ec2types.InstanceTypeInfo
+	var err error
+	if len(uncachedInstanceTypes) != 0 {
+		describedInstanceTypes, err = p.describeInstanceTypeShards(ctx, uncachedInstanceTypes, &calls)
+	} else {
+		describedInstanceTypes, err = p.describeAllInstanceTypes(ctx, &calls)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, instanceTypeInfo := range describedInstanceTypes {
+		itDetails := &Details{InstanceTypeInfo: instanceTypeInfo}
+		instanceTypeDetails = append(instanceTypeDetails, itDetails)
+		p.cache.SetDefault(string(instanceTypeInfo.InstanceType), itDetails)
 	}
 
 	if len(instanceTypes) == 0 {
@@ -165,12 +266,90 @@ func (p *Provider) Get(ctx context.Context, instanceTypes []ec2types.InstanceTyp
 	return instanceTypeDetails, nil
 }
 
+// describeAllInstanceTypes sequentially paginates through every instance type in the region using the largest page size.
+func (p *Provider) describeAllInstanceTypes(ctx context.Context, calls *atomic.Int32) ([]ec2types.InstanceTypeInfo, error) {
+	instanceTypes := []ec2types.InstanceTypeInfo{}
+	s := ec2.NewDescribeInstanceTypesPaginator(p.ec2Client, &ec2.DescribeInstanceTypesInput{
+		MaxResults: aws.Int32(describeInstanceTypesMaxResults),
+	})
+	for s.HasMorePages() {
+		calls.Add(1)
+		instanceTypeOutput, err := s.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next instance types page, %w", err)
+		}
+		instanceTypes = append(instanceTypes, instanceTypeOutput.InstanceTypes...)
+	}
+	return instanceTypes, nil
+}
+
+// describeInstanceTypeShards describes the passed in instance types in concurrent requests of up to
+// maxInstanceTypesPerRequest instance types each.
+func (p *Provider) describeInstanceTypeShards(ctx context.Context, instanceTypes []ec2types.InstanceType, calls *atomic.Int32) ([]ec2types.InstanceTypeInfo, error) {
+	var mu sync.Mutex
+	var errs error
+	describedInstanceTypes := []ec2types.InstanceTypeInfo{}
+	wg := sync.WaitGroup{}
+	semaphore := make(chan struct{}, maxConcurrentRequests)
+	for shardStart := 0; shardStart < len(instanceTypes); shardStart += maxInstanceTypesPerRequest {
+		shard := instanceTypes[shardStart:min(shardStart+maxInstanceTypesPerRequest, len(instanceTypes))]
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			shardInstanceTypes := []ec2types.InstanceTypeInfo{}
+			s := ec2.NewDescribeInstanceTypesPaginator(p.ec2Client, &ec2.DescribeInstanceTypesInput{InstanceTypes: shard})
+			for s.HasMorePages() {
+				calls.Add(1)
+				instanceTypeOutput, err := s.NextPage(ctx)
+				if err != nil {
+					mu.Lock()
+					errs = multierr.Append(errs, fmt.Errorf("failed to get next instance types page, %w", err))
+					mu.Unlock()
+					return
+				}
+				shardInstanceTypes = append(shardInstanceTypes, instanceTypeOutput.InstanceTypes...)
+			}
+			mu.Lock()
+			describedInstanceTypes = append(describedInstanceTypes, shardInstanceTypes...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if errs != nil {
+		return nil, errs
+	}
+	return describedInstanceTypes, nil
+}
+
+// getOfferedInstanceTypes returns the names of all instance types offered in the region.
+func (p *Provider) getOfferedInstanceTypes(ctx context.Context, calls *atomic.Int32) ([]ec2types.InstanceType, error) {
+	offeredInstanceTypes := []ec2types.InstanceType{}
+	s := ec2.NewDescribeInstanceTypeOfferingsPaginator(p.offeringsClient, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: ec2types.LocationTypeRegion,
+		MaxResults:   aws.Int32(describeInstanceTypeOfferingsMaxResults),
+	})
+	for s.HasMorePages() {
+		calls.Add(1)
+		offeringsOutput, err := s.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, offering := range offeringsOutput.InstanceTypeOfferings {
+			offeredInstanceTypes = append(offeredInstanceTypes, offering.InstanceType)
+		}
+	}
+	return offeredInstanceTypes, nil
+}
+
 func (p *Provider) isFullRefreshNeeded() bool {
 	return time.Since(*p.lastFullRefresh) > p.FullRefreshTTL
 }
 
+// Save persists the instance types to DirectoryPath if caching is configured. Nothing is written without a DirectoryPath.
 func (p *Provider) Save() error {
-	if p.FullRefreshTTL <= 0 || p.cache.ItemCount() == 0 {
+	if p.ReadOnly || p.FullRefreshTTL <= 0 || p.DirectoryPath == "" || p.cache.ItemCount() == 0 {
 		return nil
 	}
 	cacheBytes, err := json.Marshal(p.cache.Items())
@@ -180,11 +359,14 @@ func (p *Provider) Save() error {
 	if err := os.Mkdir(p.DirectoryPath, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
 		return err
 	}
-	return os.WriteFile(getCacheFilePath(p.Region, p.DirectoryPath), cacheBytes, 0600)
+	return filelock.WriteFile(getCacheFilePath(p.Region, p.DirectoryPath), cacheBytes, 0600)
 }
 
 func (p *Provider) Clear() error {
 	p.cache.Flush()
+	if p.ReadOnly || p.DirectoryPath == "" {
+		return nil
+	}
 	if err := os.Remove(getCacheFilePath(p.Region, p.DirectoryPath)); err != nil && !os.IsNotExist(err) {
 		return err
 	}
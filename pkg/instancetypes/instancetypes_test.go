@@ -0,0 +1,188 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancetypes_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocking helpers
+
+// mockedEC2 serves DescribeInstanceTypes from a fixed list of instance type names,
+// either in pages of MaxResults or filtered to the requested instance types.
+type mockedEC2 struct {
+	instanceTypes []ec2types.InstanceType
+	offeringsErr  error
+	mu            sync.Mutex
+	requests      []*ec2.DescribeInstanceTypesInput
+}
+
+func newMockedEC2(count int) *mockedEC2 {
+	m := &mockedEC2{}
+	for i := 0; i < count; i++ {
+		m.instanceTypes = append(m.instanceTypes, ec2types.InstanceType(fmt.Sprintf("m%d.large", i)))
+	}
+	return m
+}
+
+func (m *mockedEC2) DescribeInstanceTypes(_ context.Context, input *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	m.mu.Lock()
+	m.requests = append(m.requests, input)
+	m.mu.Unlock()
+	if len(input.InstanceTypes) > 100 {
+		return nil, errors.New("too many instance types requested")
+	}
+	output := &ec2.DescribeInstanceTypesOutput{}
+	if len(input.InstanceTypes) != 0 {
+		for _, it := range input.InstanceTypes {
+			output.InstanceTypes = append(output.InstanceTypes, ec2types.InstanceTypeInfo{InstanceType: it})
+		}
+		return output, nil
+	}
+	start := 0
+	if input.NextToken != nil {
+		fmt.Sscanf(*input.NextToken, "%d", &start)
+	}
+	end := min(start+int(aws.ToInt32(input.MaxResults)), len(m.instanceTypes))
+	for _, it := range m.instanceTypes[start:end] {
+		output.InstanceTypes = append(output.InstanceTypes, ec2types.InstanceTypeInfo{InstanceType: it})
+	}
+	if end < len(m.instanceTypes) {
+		output.NextToken = aws.String(fmt.Sprint(end))
+	}
+	return output, nil
+}
+
+func (m *mockedEC2) DescribeInstanceTypeOfferings(_ context.Context, _ *ec2.DescribeInstanceTypeOfferingsInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	if m.offeringsErr != nil {
+		return nil, m.offeringsErr
+	}
+	output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+	for _, it := range m.instanceTypes {
+		output.InstanceTypeOfferings = append(output.InstanceTypeOfferings, ec2types.InstanceTypeOffering{InstanceType: it})
+	}
+	return output, nil
+}
+
+func instanceTypeNames(details []*instancetypes.Details) []string {
+	names := []string{}
+	for _, it := range details {
+		names = append(names, string(it.InstanceType))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tests
+
+func TestGet_FullRefreshPaginated(t *testing.T) {
+	ec2Mock := newMockedEC2(250)
+	provider := instancetypes.NewProvider("us-east-1", ec2Mock)
+	details, err := provider.Get(context.Background(), nil)
+	h.Ok(t, err)
+	h.Equals(t, 250, len(details))
+	h.Equals(t, 3, len(ec2Mock.requests))
+	for _, request := range ec2Mock.requests {
+		h.Equals(t, int32(100), aws.ToInt32(request.MaxResults))
+	}
+}
+
+func TestGet_FullRefreshSharded(t *testing.T) {
+	ec2Mock := newMockedEC2(950)
+	provider := instancetypes.NewProvider("us-east-1", ec2Mock)
+	provider.SetOfferingsClient(ec2Mock)
+	details, err := provider.Get(context.Background(), nil)
+	h.Ok(t, err)
+	h.Equals(t, 950, len(details))
+	h.Equals(t, 10, len(ec2Mock.requests))
+	for _, request := range ec2Mock.requests {
+		h.Assert(t, len(request.InstanceTypes) > 0 && len(request.InstanceTypes) <= 100, "expected between 1 and 100 instance types per request, got %d", len(request.InstanceTypes))
+	}
+	expected := []string{}
+	for _, it := range ec2Mock.instanceTypes {
+		expected = append(expected, string(it))
+	}
+	sort.Strings(expected)
+	h.Equals(t, expected, instanceTypeNames(details))
+}
+
+func TestGet_FullRefreshOfferingsError(t *testing.T) {
+	ec2Mock := newMockedEC2(150)
+	ec2Mock.offeringsErr = errors.New("access denied")
+	provider := instancetypes.NewProvider("us-east-1", ec2Mock)
+	provider.SetOfferingsClient(ec2Mock)
+	details, err := provider.Get(context.Background(), nil)
+	h.Ok(t, err)
+	h.Equals(t, 150, len(details))
+	h.Equals(t, 2, len(ec2Mock.requests))
+}
+
+func TestGet_ManyInstanceTypes(t *testing.T) {
+	ec2Mock := newMockedEC2(250)
+	provider := instancetypes.NewProvider("us-east-1", ec2Mock)
+	details, err := provider.Get(context.Background(), ec2Mock.instanceTypes)
+	h.Ok(t, err)
+	h.Equals(t, 250, len(details))
+	h.Equals(t, 3, len(ec2Mock.requests))
+
+	// a second lookup is served from the cache
+	details, err = provider.Get(context.Background(), ec2Mock.instanceTypes[:10])
+	h.Ok(t, err)
+	h.Equals(t, 10, len(details))
+	h.Equals(t, 3, len(ec2Mock.requests))
+}
+
+// getBenchmarkProvider returns a provider of more instance types than a region offers which caches them in a temporary directory.
+func getBenchmarkProvider(b *testing.B) *instancetypes.Provider {
+	provider, err := instancetypes.LoadFromOrNew(b.TempDir(), "us-east-1", time.Hour, newMockedEC2(1000))
+	h.Ok(b, err)
+	// LoadFromOrNew does not set the TTL which Save requires
+	provider.FullRefreshTTL = time.Hour
+	_, err = provider.Get(context.Background(), nil)
+	h.Ok(b, err)
+	return provider
+}
+
+func BenchmarkSave(b *testing.B) {
+	provider := getBenchmarkProvider(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Ok(b, provider.Save())
+	}
+}
+
+func BenchmarkLoadFromOrNew(b *testing.B) {
+	provider := getBenchmarkProvider(b)
+	h.Ok(b, provider.Save())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		loadedProvider, err := instancetypes.LoadFromOrNew(provider.DirectoryPath, provider.Region, time.Hour, newMockedEC2(0))
+		h.Ok(b, err)
+		h.Equals(b, 1000, loadedProvider.CacheCount())
+	}
+}
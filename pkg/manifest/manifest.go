@@ -0,0 +1,157 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifest records how a selection of instance types was made, the version, region, filters, and cache data it
+// was made with, along with a hash of its results, so that the selection can be audited and reproduced later.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/offerings"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+// SchemaVersion is the version of the fields of the manifest, it is bumped when a field is removed, renamed, or changes type.
+const SchemaVersion = "1"
+
+// hashPrefix is the algorithm prefix of result hashes.
+const hashPrefix = "sha256:"
+
+// cacheFileNames are the file names of each cache within the cache directory, they are prefixed with the region.
+var cacheFileNames = map[string]string{
+	selector.InstanceTypesCache:   instancetypes.CacheFileName,
+	selector.OfferingsCache:       offerings.CacheFileName,
+	selector.OnDemandPricingCache: ec2pricing.ODCacheFileName,
+	selector.SpotPricingCache:     ec2pricing.SpotCacheFileName,
+}
+
+// Cache is the state of a cache file when the selection started.
+type Cache struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// ModifiedAt is the modification time of the file, it is not set if the file did not exist so the data was
+	// retrieved from AWS during the selection
+	ModifiedAt *time.Time `json:"modifiedAt,omitempty"`
+}
+
+// Manifest describes a selection of instance types.
+type Manifest struct {
+	SchemaVersion string    `json:"schemaVersion"`
+	ToolVersion   string    `json:"toolVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// Args are the command line arguments the selection was made with
+	Args   []string `json:"args"`
+	Region string   `json:"region"`
+	// Filters are the filters which were set, keyed by field name
+	Filters map[string]json.RawMessage `json:"filters"`
+	// FilterGroups are the filters which were set in each filter group, an instance type matches if it matches any group
+	FilterGroups []map[string]json.RawMessage `json:"filterGroups,omitempty"`
+	Caches       []Cache                      `json:"caches"`
+	// InstanceTypes are the instance types of the results in the order they were output
+	InstanceTypes []string `json:"instanceTypes"`
+	// ResultHash is the hash of InstanceTypes, a selection which is reproduced has the same hash
+	ResultHash string `json:"resultHash"`
+}
+
+// New creates a manifest of the instance types which were selected with the filters.
+func New(toolVersion string, args []string, region string, filters selector.Filters, filterGroups []selector.Filters, caches []Cache, instanceTypes []string) (*Manifest, error) {
+	setFilters, err := SetFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	groups := []map[string]json.RawMessage{}
+	for _, group := range filterGroups {
+		setGroupFilters, err := SetFilters(group)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, setGroupFilters)
+	}
+	if args == nil {
+		args = []string{}
+	}
+	if instanceTypes == nil {
+		instanceTypes = []string{}
+	}
+	if caches == nil {
+		caches = []Cache{}
+	}
+	return &Manifest{
+		SchemaVersion: SchemaVersion,
+		ToolVersion:   toolVersion,
+		CreatedAt:     time.Now().UTC(),
+		Args:          args,
+		Region:        region,
+		Filters:       setFilters,
+		FilterGroups:  groups,
+		Caches:        caches,
+		InstanceTypes: instanceTypes,
+		ResultHash:    ResultHash(instanceTypes),
+	}, nil
+}
+
+// SetFilters returns the JSON of the filters which are set, keyed by field name.
+func SetFilters(filters selector.Filters) (map[string]json.RawMessage, error) {
+	filtersJSON, err := filters.MarshalIndent("", "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert the filters to JSON: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(filtersJSON, &fields); err != nil {
+		return nil, fmt.Errorf("unable to convert the filters to JSON: %w", err)
+	}
+	for name, value := range fields {
+		if string(value) == "null" {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// ResultHash returns the SHA-256 hash of the instance type names, one per line in order (Example: sha256:9f86d0...).
+func ResultHash(instanceTypes []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(instanceTypes, "\n")))
+	return hashPrefix + hex.EncodeToString(hash[:])
+}
+
+// CacheFiles returns the state of the cache files of the region in the cache directory, they should be read before
+// the selector is created since caches which expired or are disabled are removed when they are loaded.
+func CacheFiles(cacheDir string, region string) []Cache {
+	caches := []Cache{}
+	for _, name := range []string{selector.InstanceTypesCache, selector.OfferingsCache, selector.OnDemandPricingCache, selector.SpotPricingCache} {
+		cache := Cache{
+			Name: name,
+			Path: filepath.Join(cacheDir, fmt.Sprintf("%s-%s", region, cacheFileNames[name])),
+		}
+		if info, err := os.Stat(cache.Path); err == nil {
+			modTime := info.ModTime().UTC()
+			cache.ModifiedAt = &modTime
+		}
+		caches = append(caches, cache)
+	}
+	return caches
+}
+
+// MarshalIndent returns the indented JSON of the manifest.
+func (m Manifest) MarshalIndent(prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(m, prefix, indent)
+}
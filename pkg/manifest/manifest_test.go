@@ -0,0 +1,113 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/manifest"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestResultHash(t *testing.T) {
+	hash := manifest.ResultHash([]string{"m5.large", "c5.large"})
+	h.Assert(t, regexp.MustCompile(`^sha256:[0-9a-f]{64}$`).MatchString(hash), "Unexpected hash %s", hash)
+	h.Equals(t, hash, manifest.ResultHash([]string{"m5.large", "c5.large"}))
+	h.Assert(t, hash != manifest.ResultHash([]string{"c5.large", "m5.large"}), "The order of the results should change the hash")
+	h.Assert(t, hash != manifest.ResultHash([]string{"m5.largec5.large"}), "The names should be separated in the hash")
+}
+
+func TestSetFilters(t *testing.T) {
+	filters := selector.Filters{
+		VCpusRange: &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+		AllowList:  regexp.MustCompile("^m5"),
+		MaxResults: aws.Int(10),
+	}
+	setFilters, err := manifest.SetFilters(filters)
+	h.Ok(t, err)
+	h.Equals(t, 3, len(setFilters))
+	h.Equals(t, `"^m5"`, string(setFilters["AllowList"]))
+	h.Equals(t, "10", string(setFilters["MaxResults"]))
+	vcpus := selector.Int32RangeFilter{}
+	h.Ok(t, json.Unmarshal(setFilters["VCpusRange"], &vcpus))
+	h.Equals(t, *filters.VCpusRange, vcpus)
+
+	setFilters, err = manifest.SetFilters(selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, 0, len(setFilters))
+}
+
+func TestCacheFiles(t *testing.T) {
+	cacheDir := t.TempDir()
+	h.Ok(t, os.WriteFile(filepath.Join(cacheDir, "us-east-1-"+instancetypes.CacheFileName), []byte("{}"), 0o600))
+	h.Ok(t, os.WriteFile(filepath.Join(cacheDir, "us-east-1-"+ec2pricing.SpotCacheFileName), []byte("{}"), 0o600))
+	h.Ok(t, os.WriteFile(filepath.Join(cacheDir, "us-east-2-"+ec2pricing.ODCacheFileName), []byte("{}"), 0o600))
+
+	caches := manifest.CacheFiles(cacheDir, "us-east-1")
+	h.Equals(t, 4, len(caches))
+	modified := map[string]bool{}
+	for _, cache := range caches {
+		modified[cache.Name] = cache.ModifiedAt != nil
+	}
+	h.Equals(t, map[string]bool{
+		selector.InstanceTypesCache:   true,
+		selector.OfferingsCache:       false,
+		selector.OnDemandPricingCache: false,
+		selector.SpotPricingCache:     true,
+	}, modified)
+	h.Equals(t, filepath.Join(cacheDir, "us-east-1-"+instancetypes.CacheFileName), caches[0].Path)
+}
+
+func TestNew(t *testing.T) {
+	arm64 := ec2types.ArchitectureTypeArm64
+	filters := selector.Filters{CPUArchitecture: &arm64}
+	groups := []selector.Filters{{InstanceTypes: &[]string{"m7g.large"}}}
+	selectionManifest, err := manifest.New("v3.1.0", []string{"--cpu-architecture", "arm64"}, "us-east-1", filters, groups, nil, []string{"m7g.large"})
+	h.Ok(t, err)
+	h.Equals(t, manifest.SchemaVersion, selectionManifest.SchemaVersion)
+	h.Equals(t, "v3.1.0", selectionManifest.ToolVersion)
+	h.Equals(t, "us-east-1", selectionManifest.Region)
+	h.Equals(t, `"arm64"`, string(selectionManifest.Filters["CPUArchitecture"]))
+	h.Equals(t, 1, len(selectionManifest.FilterGroups))
+	h.Equals(t, manifest.ResultHash([]string{"m7g.large"}), selectionManifest.ResultHash)
+
+	manifestJSON, err := selectionManifest.MarshalIndent("", "    ")
+	h.Ok(t, err)
+	decoded := manifest.Manifest{}
+	h.Ok(t, json.Unmarshal(manifestJSON, &decoded))
+	h.Equals(t, selectionManifest.ResultHash, decoded.ResultHash)
+	h.Equals(t, []string{"m7g.large"}, decoded.InstanceTypes)
+	h.Equals(t, []string{"--cpu-architecture", "arm64"}, decoded.Args)
+
+	selectionManifest, err = manifest.New("dev", nil, "us-east-1", selector.Filters{}, nil, nil, nil)
+	h.Ok(t, err)
+	manifestJSON, err = selectionManifest.MarshalIndent("", "")
+	h.Ok(t, err)
+	fields := map[string]json.RawMessage{}
+	h.Ok(t, json.Unmarshal(manifestJSON, &fields))
+	h.Equals(t, "[]", string(fields["instanceTypes"]))
+	h.Equals(t, "[]", string(fields["args"]))
+	h.Equals(t, "[]", string(fields["caches"]))
+	_, ok := fields["filterGroups"]
+	h.Assert(t, !ok, "Filter groups should be omitted when there are none")
+}
@@ -0,0 +1,142 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify sends notifications when the instance types that match a filter change.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+// webhookTimeout is the maximum time to wait for a webhook to respond.
+const webhookTimeout = 30 * time.Second
+
+// Notification is the JSON document sent to notification targets.
+type Notification struct {
+	Region    string
+	Timestamp time.Time
+	Added     []string
+	Removed   []string
+}
+
+// NewNotification creates a Notification of the instance types added and removed in a region.
+func NewNotification(region string, diff selector.InstanceTypesDiff) Notification {
+	notification := Notification{
+		Region:    region,
+		Timestamp: time.Now().UTC(),
+		Added:     diff.Added,
+		Removed:   diff.Removed,
+	}
+	if notification.Added == nil {
+		notification.Added = []string{}
+	}
+	if notification.Removed == nil {
+		notification.Removed = []string{}
+	}
+	return notification
+}
+
+// Notifier sends a Notification to a target.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotifyAll sends the notification to every notifier and returns the errors of all notifiers which failed.
+func NotifyAll(ctx context.Context, notifiers []Notifier, notification Notification) error {
+	var errs error
+	for _, notifier := range notifiers {
+		errs = multierr.Append(errs, notifier.Notify(ctx, notification))
+	}
+	return errs
+}
+
+// Webhook POSTs notifications as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhook creates a Webhook notifier for the passed in URL.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify POSTs the notification to the webhook URL and returns an error if the response status is not 2xx.
+func (w Webhook) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook notification failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// SNSPublishAPI is the subset of the SNS client used to publish notifications.
+type SNSPublishAPI interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNS publishes notifications as JSON to an SNS topic.
+type SNS struct {
+	TopicARN string
+	client   SNSPublishAPI
+}
+
+// NewSNS creates an SNS notifier which publishes to the passed in topic ARN.
+func NewSNS(client SNSPublishAPI, topicARN string) *SNS {
+	return &SNS{
+		TopicARN: topicARN,
+		client:   client,
+	}
+}
+
+// Notify publishes the notification to the SNS topic.
+func (s SNS) Notify(ctx context.Context, notification Notification) error {
+	message, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	if _, err := s.client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(s.TopicARN),
+		Subject:  aws.String(fmt.Sprintf("EC2 instance types changed in %s", notification.Region)),
+		Message:  aws.String(string(message)),
+	}); err != nil {
+		return fmt.Errorf("unable to publish SNS notification to %s: %w", s.TopicARN, err)
+	}
+	return nil
+}
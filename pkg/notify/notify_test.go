@@ -0,0 +1,101 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/notify"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocks
+
+type mockedSNS struct {
+	publishInputs []*sns.PublishInput
+	publishErr    error
+}
+
+func (m *mockedSNS) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	m.publishInputs = append(m.publishInputs, params)
+	return &sns.PublishOutput{}, m.publishErr
+}
+
+// Tests
+
+func TestNewNotification(t *testing.T) {
+	notification := notify.NewNotification("us-east-1", selector.InstanceTypesDiff{Added: []string{"c8g.large"}})
+	h.Equals(t, "us-east-1", notification.Region)
+	h.Equals(t, []string{"c8g.large"}, notification.Added)
+	h.Equals(t, []string{}, notification.Removed)
+	h.Assert(t, !notification.Timestamp.IsZero(), "Timestamp should be set")
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	received := notify.Notification{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Equals(t, http.MethodPost, r.Method)
+		h.Equals(t, "application/json", r.Header.Get("Content-Type"))
+		h.Ok(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notification := notify.NewNotification("us-east-1", selector.InstanceTypesDiff{Added: []string{"c8g.large"}, Removed: []string{"m4.large"}})
+	h.Ok(t, notify.NewWebhook(server.URL).Notify(context.Background(), notification))
+	h.Equals(t, notification.Added, received.Added)
+	h.Equals(t, notification.Removed, received.Removed)
+	h.Equals(t, "us-east-1", received.Region)
+}
+
+func TestWebhook_Notify_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	err := notify.NewWebhook(server.URL).Notify(context.Background(), notify.NewNotification("us-east-1", selector.InstanceTypesDiff{}))
+	h.Nok(t, err)
+}
+
+func TestSNS_Notify(t *testing.T) {
+	topicARN := "arn:aws:sns:us-east-1:123456789012:instance-types"
+	snsMock := &mockedSNS{}
+	notification := notify.NewNotification("us-east-1", selector.InstanceTypesDiff{Added: []string{"c8g.large"}})
+	h.Ok(t, notify.NewSNS(snsMock, topicARN).Notify(context.Background(), notification))
+	h.Equals(t, 1, len(snsMock.publishInputs))
+	h.Equals(t, topicARN, aws.ToString(snsMock.publishInputs[0].TopicArn))
+	published := notify.Notification{}
+	h.Ok(t, json.Unmarshal([]byte(aws.ToString(snsMock.publishInputs[0].Message)), &published))
+	h.Equals(t, []string{"c8g.large"}, published.Added)
+}
+
+func TestNotifyAll(t *testing.T) {
+	okSNS := &mockedSNS{}
+	failedSNS := &mockedSNS{publishErr: errors.New("access denied")}
+	notifiers := []notify.Notifier{
+		notify.NewSNS(failedSNS, "arn:aws:sns:us-east-1:123456789012:failed"),
+		notify.NewSNS(okSNS, "arn:aws:sns:us-east-1:123456789012:ok"),
+	}
+	err := notify.NotifyAll(context.Background(), notifiers, notify.NewNotification("us-east-1", selector.InstanceTypesDiff{}))
+	h.Nok(t, err)
+	h.Equals(t, 1, len(okSNS.publishInputs))
+}
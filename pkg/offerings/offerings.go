@@ -0,0 +1,223 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offerings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/mitchellh/go-homedir"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/filelock"
+)
+
+var CacheFileName = "ec2-instance-type-offerings.json"
+
+const (
+	// locationFilterKey is the DescribeInstanceTypeOfferings filter which selects the offerings of a single location
+	locationFilterKey = "location"
+	// describeInstanceTypeOfferingsMaxResults is the largest page size accepted by DescribeInstanceTypeOfferings
+	describeInstanceTypeOfferingsMaxResults = 1000
+)
+
+// ProviderIface is the instance type offerings Provider interface used to back Selector with alternative providers and to
+// mock out offerings during testing.
+type ProviderIface interface {
+	Get(ctx context.Context, locationType ec2types.LocationType, location string) ([]ec2types.InstanceTypeOffering, error)
+	CacheCount() int
+	Save() error
+	SetLogger(*log.Logger)
+	SetCacheReadOnly(readOnly bool)
+}
+
+// Provider retrieves the instance types offered in a location with DescribeInstanceTypeOfferings and caches the offerings
+// of each location for TTL.
+type Provider struct {
+	Region        string
+	DirectoryPath string
+	TTL           time.Duration
+	// ReadOnly prevents the cache file in DirectoryPath from being saved or removed
+	ReadOnly  bool
+	ec2Client ec2.DescribeInstanceTypeOfferingsAPIClient
+	cache     *cache.Cache
+	logger    *log.Logger
+}
+
+// cacheItem is the on-disk representation of the offerings of a location.
+type cacheItem struct {
+	Offerings []ec2types.InstanceTypeOffering
+	// Expiration is the time the offerings expire in unix nanoseconds
+	Expiration int64
+}
+
+// NewProvider creates a new instance type offerings provider which does not cache offerings.
+func NewProvider(region string, ec2Client ec2.DescribeInstanceTypeOfferingsAPIClient) *Provider {
+	return &Provider{
+		Region:    region,
+		ec2Client: ec2Client,
+		cache:     cache.New(0, 0),
+		logger:    log.New(io.Discard, "", 0),
+	}
+}
+
+// LoadFromOrNew creates a new instance type offerings provider which caches the offerings of each location for ttl and loads
+// the offerings which have not expired from the cache in directoryPath. A ttl of 0 turns off caching and removes the cache file.
+func LoadFromOrNew(directoryPath string, region string, ttl time.Duration, ec2Client ec2.DescribeInstanceTypeOfferingsAPIClient) (*Provider, error) {
+	expandedDirPath, err := homedir.Expand(directoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load instance type offerings cache directory %s: %w", expandedDirPath, err)
+	}
+	provider := NewProvider(region, ec2Client)
+	provider.DirectoryPath = expandedDirPath
+	if ttl <= 0 {
+		if err := provider.Clear(); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+	provider.TTL = ttl
+	offeringsCache, err := loadFrom(ttl, region, expandedDirPath)
+	// a cache file which is locked by another process is a cache miss
+	if err != nil && !os.IsNotExist(err) && !errors.Is(err, filelock.ErrLocked) {
+		return nil, fmt.Errorf("unable to load instance type offerings cache from %s: %w", expandedDirPath, err)
+	}
+	if err == nil {
+		provider.cache = offeringsCache
+	}
+	return provider, nil
+}
+
+func loadFrom(ttl time.Duration, region string, expandedDirPath string) (*cache.Cache, error) {
+	cacheBytes, err := filelock.ReadFile(getCacheFilePath(region, expandedDirPath))
+	if err != nil {
+		return nil, err
+	}
+	cacheItems := map[string]cacheItem{}
+	if err := json.Unmarshal(cacheBytes, &cacheItems); err != nil {
+		return nil, err
+	}
+	items := map[string]cache.Item{}
+	for key, item := range cacheItems {
+		items[key] = cache.Item{Object: item.Offerings, Expiration: item.Expiration}
+	}
+	c := cache.NewFrom(ttl, ttl, items)
+	c.DeleteExpired()
+	return c, nil
+}
+
+func getCacheFilePath(region string, expandedDirPath string) string {
+	return filepath.Join(expandedDirPath, fmt.Sprintf("%s-%s", region, CacheFileName))
+}
+
+// cacheKey returns the key of the offerings of the location, an empty location is every location of the location type.
+func cacheKey(locationType ec2types.LocationType, location string) string {
+	return fmt.Sprintf("%s/%s", locationType, location)
+}
+
+func (p *Provider) SetLogger(logger *log.Logger) {
+	p.logger = logger
+}
+
+// SetCacheReadOnly prevents the cache file from being saved to or removed from DirectoryPath.
+func (p *Provider) SetCacheReadOnly(readOnly bool) {
+	p.ReadOnly = readOnly
+}
+
+// Get returns the instance type offerings of the location which is a region name, zone name, zone ID, or Outpost ARN
+// depending on the location type. An empty location returns the offerings of every location of the location type in the region.
+func (p *Provider) Get(ctx context.Context, locationType ec2types.LocationType, location string) ([]ec2types.InstanceTypeOffering, error) {
+	key := cacheKey(locationType, location)
+	if cachedOfferings, ok := p.cache.Get(key); ok {
+		return cachedOfferings.([]ec2types.InstanceTypeOffering), nil
+	}
+	start := time.Now()
+	calls := 0
+	defer func() {
+		p.logger.Printf("Took %s and %d calls to collect the instance type offerings of %s", time.Since(start), calls, key)
+	}()
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: locationType,
+		MaxResults:   aws.Int32(describeInstanceTypeOfferingsMaxResults),
+	}
+	if location != "" {
+		input.Filters = []ec2types.Filter{
+			{
+				Name:   aws.String(locationFilterKey),
+				Values: []string{location},
+			},
+		}
+	}
+	instanceTypeOfferings := []ec2types.InstanceTypeOffering{}
+	s := ec2.NewDescribeInstanceTypeOfferingsPaginator(p.ec2Client, input)
+	for s.HasMorePages() {
+		calls++
+		output, err := s.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("encountered an error when describing instance type offerings: %w", err)
+		}
+		instanceTypeOfferings = append(instanceTypeOfferings, output.InstanceTypeOfferings...)
+	}
+	if p.TTL > 0 {
+		p.cache.Set(key, instanceTypeOfferings, p.TTL)
+	}
+	return instanceTypeOfferings, nil
+}
+
+// Save persists the offerings which have not expired to DirectoryPath if caching is configured, nothing is written without
+// a DirectoryPath so the offerings of a provider created by NewProvider are only cached in memory.
+func (p *Provider) Save() error {
+	if p.ReadOnly || p.TTL <= 0 || p.DirectoryPath == "" || p.cache.ItemCount() == 0 {
+		return nil
+	}
+	cacheItems := map[string]cacheItem{}
+	for key, item := range p.cache.Items() {
+		cacheItems[key] = cacheItem{Offerings: item.Object.([]ec2types.InstanceTypeOffering), Expiration: item.Expiration}
+	}
+	cacheBytes, err := json.Marshal(cacheItems)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(p.DirectoryPath, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	cacheFilePath := getCacheFilePath(p.Region, p.DirectoryPath)
+	return filelock.WriteFile(cacheFilePath, cacheBytes, 0600)
+}
+
+// Clear removes the cached offerings and the cache file unless the cache is read-only or there is no DirectoryPath.
+func (p *Provider) Clear() error {
+	p.cache.Flush()
+	if p.ReadOnly || p.DirectoryPath == "" {
+		return nil
+	}
+	if err := os.Remove(getCacheFilePath(p.Region, p.DirectoryPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// CacheCount returns the number of locations with cached offerings.
+func (p *Provider) CacheCount() int {
+	return p.cache.ItemCount()
+}
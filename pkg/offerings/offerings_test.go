@@ -0,0 +1,155 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offerings_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/offerings"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocking helpers
+
+// mockedEC2 serves the offerings of each location keyed by location, all locations are returned when the location is not filtered.
+type mockedEC2 struct {
+	offerings map[string][]ec2types.InstanceType
+	err       error
+	calls     int
+}
+
+func (m *mockedEC2) DescribeInstanceTypeOfferings(_ context.Context, input *ec2.DescribeInstanceTypeOfferingsInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+	for location, instanceTypes := range m.offerings {
+		if len(input.Filters) > 0 && input.Filters[0].Values[0] != location {
+			continue
+		}
+		for _, instanceType := range instanceTypes {
+			output.InstanceTypeOfferings = append(output.InstanceTypeOfferings, ec2types.InstanceTypeOffering{
+				InstanceType: instanceType,
+				Location:     aws.String(location),
+				LocationType: input.LocationType,
+			})
+		}
+	}
+	return output, nil
+}
+
+func newMockedEC2() *mockedEC2 {
+	return &mockedEC2{offerings: map[string][]ec2types.InstanceType{
+		"us-east-1a": {ec2types.InstanceTypeM5Large, ec2types.InstanceTypeC5Large},
+		"us-east-1b": {ec2types.InstanceTypeM5Large},
+	}}
+}
+
+// Tests
+
+func TestGet_NoCache(t *testing.T) {
+	ec2Mock := newMockedEC2()
+	provider := offerings.NewProvider("us-east-1", ec2Mock)
+	instanceTypeOfferings, err := provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1b")
+	h.Ok(t, err)
+	h.Equals(t, 1, len(instanceTypeOfferings))
+	h.Equals(t, ec2types.InstanceTypeM5Large, instanceTypeOfferings[0].InstanceType)
+
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1b")
+	h.Ok(t, err)
+	h.Equals(t, 2, ec2Mock.calls)
+	h.Equals(t, 0, provider.CacheCount())
+}
+
+func TestGet_Error(t *testing.T) {
+	provider := offerings.NewProvider("us-east-1", &mockedEC2{err: errors.New("throttled")})
+	_, err := provider.Get(context.Background(), ec2types.LocationTypeRegion, "us-east-1")
+	h.Nok(t, err)
+}
+
+func TestLoadFromOrNew(t *testing.T) {
+	cacheDir := t.TempDir()
+	ec2Mock := newMockedEC2()
+	provider, err := offerings.LoadFromOrNew(cacheDir, "us-east-1", time.Hour, ec2Mock)
+	h.Ok(t, err)
+	instanceTypeOfferings, err := provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "")
+	h.Ok(t, err)
+	h.Equals(t, 3, len(instanceTypeOfferings))
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	h.Equals(t, 2, ec2Mock.calls)
+	h.Ok(t, provider.Save())
+
+	// offerings are loaded from the cache file rather than described again
+	reloadedMock := newMockedEC2()
+	provider, err = offerings.LoadFromOrNew(cacheDir, "us-east-1", time.Hour, reloadedMock)
+	h.Ok(t, err)
+	h.Equals(t, 2, provider.CacheCount())
+	instanceTypeOfferings, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	h.Equals(t, 2, len(instanceTypeOfferings))
+	h.Equals(t, "us-east-1a", aws.ToString(instanceTypeOfferings[0].Location))
+	h.Equals(t, 0, reloadedMock.calls)
+
+	// a ttl of 0 removes the cache file
+	_, err = offerings.LoadFromOrNew(cacheDir, "us-east-1", 0, reloadedMock)
+	h.Ok(t, err)
+	_, err = os.Stat(filepath.Join(cacheDir, "us-east-1-"+offerings.CacheFileName))
+	h.Assert(t, os.IsNotExist(err), "the cache file should be removed when the ttl is 0")
+}
+
+func TestSave_InMemory(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	h.Ok(t, err)
+	h.Ok(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	ec2Mock := newMockedEC2()
+	provider := offerings.NewProvider("us-east-1", ec2Mock)
+	provider.TTL = time.Hour
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	h.Equals(t, 1, ec2Mock.calls)
+	h.Ok(t, provider.Save())
+	h.Ok(t, provider.Clear())
+	entries, err := os.ReadDir(dir)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(entries))
+}
+
+func TestSave_ReadOnly(t *testing.T) {
+	cacheDir := t.TempDir()
+	provider, err := offerings.LoadFromOrNew(cacheDir, "us-east-1", time.Hour, newMockedEC2())
+	h.Ok(t, err)
+	provider.SetCacheReadOnly(true)
+	_, err = provider.Get(context.Background(), ec2types.LocationTypeAvailabilityZone, "us-east-1a")
+	h.Ok(t, err)
+	h.Ok(t, provider.Save())
+	_, err = os.Stat(filepath.Join(cacheDir, "us-east-1-"+offerings.CacheFileName))
+	h.Assert(t, os.IsNotExist(err), "read-only caches should not be saved")
+}
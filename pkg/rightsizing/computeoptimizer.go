@@ -0,0 +1,177 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/computeoptimizer"
+	cotypes "github.com/aws/aws-sdk-go-v2/service/computeoptimizer/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// autoScalingService is the service of Auto Scaling group ARNs.
+const autoScalingService = "autoscaling"
+
+// ComputeOptimizerRecommendationsAPI is the subset of the Compute Optimizer client used to retrieve recommendations.
+type ComputeOptimizerRecommendationsAPI interface {
+	GetEC2InstanceRecommendations(ctx context.Context, params *computeoptimizer.GetEC2InstanceRecommendationsInput, optFns ...func(*computeoptimizer.Options)) (*computeoptimizer.GetEC2InstanceRecommendationsOutput, error)
+	GetAutoScalingGroupRecommendations(ctx context.Context, params *computeoptimizer.GetAutoScalingGroupRecommendationsInput, optFns ...func(*computeoptimizer.Options)) (*computeoptimizer.GetAutoScalingGroupRecommendationsOutput, error)
+}
+
+// ComputeOptimizerAPI retrieves the AWS Compute Optimizer recommendation for an instance or Auto Scaling group.
+type ComputeOptimizerAPI interface {
+	GetRecommendation(ctx context.Context, resourceARN string) (*ComputeOptimizerRecommendation, error)
+}
+
+// ComputeOptimizerRecommendation is the AWS Compute Optimizer finding and recommended instance types of a resource.
+type ComputeOptimizerRecommendation struct {
+	// Finding is the classification of the current instance type (Example: Overprovisioned, Underprovisioned, Optimized)
+	Finding             string
+	CurrentInstanceType ec2types.InstanceType
+	// Options are the recommended instance types ordered by rank
+	Options []ComputeOptimizerOption
+}
+
+// ComputeOptimizerOption is an instance type recommended by AWS Compute Optimizer.
+type ComputeOptimizerOption struct {
+	InstanceType ec2types.InstanceType
+	// Rank is the order of the option, 1 is the top recommendation
+	Rank int32
+	// PerformanceRisk is the risk of the option not meeting the performance needs of the workload, from 0 (very low) to 4 (very high)
+	PerformanceRisk float64
+}
+
+// CrossCheck annotates the instance types which are recommendation options with their ComputeOptimizerRank and returns
+// the options which are not in instanceTypes.
+func CrossCheck(instanceTypes []*instancetypes.Details, recommendation ComputeOptimizerRecommendation) []ComputeOptimizerOption {
+	ranks := map[ec2types.InstanceType]int32{}
+	for _, option := range recommendation.Options {
+		ranks[option.InstanceType] = option.Rank
+	}
+	matched := map[ec2types.InstanceType]bool{}
+	for _, instanceType := range instanceTypes {
+		if rank, ok := ranks[instanceType.InstanceType]; ok {
+			instanceType.ComputeOptimizerRank = aws.Int32(rank)
+			matched[instanceType.InstanceType] = true
+		}
+	}
+	missing := []ComputeOptimizerOption{}
+	for _, option := range recommendation.Options {
+		if !matched[option.InstanceType] {
+			missing = append(missing, option)
+		}
+	}
+	return missing
+}
+
+// ComputeOptimizerClient retrieves recommendations from AWS Compute Optimizer.
+type ComputeOptimizerClient struct {
+	computeOptimizer ComputeOptimizerRecommendationsAPI
+}
+
+// NewComputeOptimizerClient creates a ComputeOptimizerClient using a client created from the passed in aws config.
+func NewComputeOptimizerClient(cfg aws.Config) *ComputeOptimizerClient {
+	return NewComputeOptimizerClientFromClient(computeoptimizer.NewFromConfig(cfg))
+}
+
+// NewComputeOptimizerClientFromClient creates a ComputeOptimizerClient using the passed in Compute Optimizer client.
+func NewComputeOptimizerClientFromClient(computeOptimizerClient ComputeOptimizerRecommendationsAPI) *ComputeOptimizerClient {
+	return &ComputeOptimizerClient{computeOptimizer: computeOptimizerClient}
+}
+
+// GetRecommendation retrieves the recommendation of an instance or Auto Scaling group ARN.
+func (c ComputeOptimizerClient) GetRecommendation(ctx context.Context, resourceARN string) (*ComputeOptimizerRecommendation, error) {
+	parsedARN, err := arn.Parse(resourceARN)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ARN %s: %w", resourceARN, err)
+	}
+	var recommendation *ComputeOptimizerRecommendation
+	var recommendationErrors []cotypes.GetRecommendationError
+	if parsedARN.Service == autoScalingService {
+		output, err := c.computeOptimizer.GetAutoScalingGroupRecommendations(ctx, &computeoptimizer.GetAutoScalingGroupRecommendationsInput{
+			AutoScalingGroupArns: []string{resourceARN},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the compute optimizer recommendation for %s: %w", resourceARN, err)
+		}
+		recommendationErrors = output.Errors
+		if len(output.AutoScalingGroupRecommendations) > 0 {
+			recommendation = autoScalingGroupRecommendation(output.AutoScalingGroupRecommendations[0])
+		}
+	} else {
+		output, err := c.computeOptimizer.GetEC2InstanceRecommendations(ctx, &computeoptimizer.GetEC2InstanceRecommendationsInput{
+			InstanceArns: []string{resourceARN},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the compute optimizer recommendation for %s: %w", resourceARN, err)
+		}
+		recommendationErrors = output.Errors
+		if len(output.InstanceRecommendations) > 0 {
+			recommendation = instanceRecommendation(output.InstanceRecommendations[0])
+		}
+	}
+	for _, recommendationErr := range recommendationErrors {
+		return nil, fmt.Errorf("compute optimizer returned an error for %s: %s: %s", aws.ToString(recommendationErr.Identifier), aws.ToString(recommendationErr.Code), aws.ToString(recommendationErr.Message))
+	}
+	if recommendation == nil {
+		return nil, fmt.Errorf("compute optimizer has no recommendation for %s", resourceARN)
+	}
+	sort.SliceStable(recommendation.Options, func(i, j int) bool {
+		return recommendation.Options[i].Rank < recommendation.Options[j].Rank
+	})
+	return recommendation, nil
+}
+
+func instanceRecommendation(resourceRecommendation cotypes.InstanceRecommendation) *ComputeOptimizerRecommendation {
+	recommendation := &ComputeOptimizerRecommendation{
+		Finding:             string(resourceRecommendation.Finding),
+		CurrentInstanceType: ec2types.InstanceType(aws.ToString(resourceRecommendation.CurrentInstanceType)),
+	}
+	for _, option := range resourceRecommendation.RecommendationOptions {
+		recommendation.Options = append(recommendation.Options, ComputeOptimizerOption{
+			InstanceType:    ec2types.InstanceType(aws.ToString(option.InstanceType)),
+			Rank:            option.Rank,
+			PerformanceRisk: option.PerformanceRisk,
+		})
+	}
+	return recommendation
+}
+
+func autoScalingGroupRecommendation(resourceRecommendation cotypes.AutoScalingGroupRecommendation) *ComputeOptimizerRecommendation {
+	recommendation := &ComputeOptimizerRecommendation{
+		Finding:             string(resourceRecommendation.Finding),
+		CurrentInstanceType: configurationInstanceType(resourceRecommendation.CurrentConfiguration),
+	}
+	for _, option := range resourceRecommendation.RecommendationOptions {
+		recommendation.Options = append(recommendation.Options, ComputeOptimizerOption{
+			InstanceType:    configurationInstanceType(option.Configuration),
+			Rank:            option.Rank,
+			PerformanceRisk: option.PerformanceRisk,
+		})
+	}
+	return recommendation
+}
+
+func configurationInstanceType(configuration *cotypes.AutoScalingGroupConfiguration) ec2types.InstanceType {
+	if configuration == nil {
+		return ""
+	}
+	return ec2types.InstanceType(aws.ToString(configuration.InstanceType))
+}
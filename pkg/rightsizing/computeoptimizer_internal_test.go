@@ -0,0 +1,158 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/computeoptimizer"
+	cotypes "github.com/aws/aws-sdk-go-v2/service/computeoptimizer/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocking helpers
+
+type mockedComputeOptimizer struct {
+	instanceOutput         computeoptimizer.GetEC2InstanceRecommendationsOutput
+	autoScalingGroupOutput computeoptimizer.GetAutoScalingGroupRecommendationsOutput
+	err                    error
+	instanceInputs         []*computeoptimizer.GetEC2InstanceRecommendationsInput
+	autoScalingGroupInputs []*computeoptimizer.GetAutoScalingGroupRecommendationsInput
+}
+
+func (m *mockedComputeOptimizer) GetEC2InstanceRecommendations(_ context.Context, input *computeoptimizer.GetEC2InstanceRecommendationsInput, _ ...func(*computeoptimizer.Options)) (*computeoptimizer.GetEC2InstanceRecommendationsOutput, error) {
+	m.instanceInputs = append(m.instanceInputs, input)
+	return &m.instanceOutput, m.err
+}
+
+func (m *mockedComputeOptimizer) GetAutoScalingGroupRecommendations(_ context.Context, input *computeoptimizer.GetAutoScalingGroupRecommendationsInput, _ ...func(*computeoptimizer.Options)) (*computeoptimizer.GetAutoScalingGroupRecommendationsOutput, error) {
+	m.autoScalingGroupInputs = append(m.autoScalingGroupInputs, input)
+	return &m.autoScalingGroupOutput, m.err
+}
+
+// userAgentHTTPClient records the user agent of each request and responds without any recommendations.
+type userAgentHTTPClient struct {
+	userAgents []string
+}
+
+func (c *userAgentHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.userAgents = append(c.userAgents, req.Header.Get("User-Agent"))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(`{"instanceRecommendations": []}`)),
+	}, nil
+}
+
+// Tests
+
+func TestNewComputeOptimizerClient_UserAgent(t *testing.T) {
+	httpClient := &userAgentHTTPClient{}
+	client := NewComputeOptimizerClient(aws.Config{
+		Region:      "us-east-1",
+		HTTPClient:  httpClient,
+		AppID:       "test-app",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	})
+	_, err := client.GetRecommendation(context.Background(), "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0")
+	h.Nok(t, err)
+	h.Equals(t, 1, len(httpClient.userAgents))
+	h.Assert(t, strings.Contains(httpClient.userAgents[0], "api/computeoptimizer#"), "the user agent should be set by the SDK, got %s", httpClient.userAgents[0])
+	h.Assert(t, strings.Contains(httpClient.userAgents[0], "app/test-app"), "the user agent should include the app id, got %s", httpClient.userAgents[0])
+}
+
+func TestGetRecommendation_Instance(t *testing.T) {
+	instanceARN := "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"
+	computeOptimizer := &mockedComputeOptimizer{instanceOutput: computeoptimizer.GetEC2InstanceRecommendationsOutput{
+		InstanceRecommendations: []cotypes.InstanceRecommendation{{
+			Finding:             cotypes.FindingOverProvisioned,
+			CurrentInstanceType: aws.String("m5.4xlarge"),
+			RecommendationOptions: []cotypes.InstanceRecommendationOption{
+				{InstanceType: aws.String("m6i.xlarge"), Rank: 2, PerformanceRisk: 1.5},
+				{InstanceType: aws.String("m6g.xlarge"), Rank: 1, PerformanceRisk: 1.0},
+			},
+		}},
+	}}
+	recommendation, err := NewComputeOptimizerClientFromClient(computeOptimizer).GetRecommendation(context.Background(), instanceARN)
+	h.Ok(t, err)
+	h.Equals(t, []string{instanceARN}, computeOptimizer.instanceInputs[0].InstanceArns)
+	h.Equals(t, "Overprovisioned", recommendation.Finding)
+	h.Equals(t, ec2types.InstanceTypeM54xlarge, recommendation.CurrentInstanceType)
+	h.Equals(t, []ComputeOptimizerOption{
+		{InstanceType: ec2types.InstanceTypeM6gXlarge, Rank: 1, PerformanceRisk: 1.0},
+		{InstanceType: ec2types.InstanceTypeM6iXlarge, Rank: 2, PerformanceRisk: 1.5},
+	}, recommendation.Options)
+}
+
+func TestGetRecommendation_AutoScalingGroup(t *testing.T) {
+	asgARN := "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:uuid:autoScalingGroupName/web"
+	computeOptimizer := &mockedComputeOptimizer{autoScalingGroupOutput: computeoptimizer.GetAutoScalingGroupRecommendationsOutput{
+		AutoScalingGroupRecommendations: []cotypes.AutoScalingGroupRecommendation{{
+			Finding:               cotypes.FindingOptimized,
+			CurrentConfiguration:  &cotypes.AutoScalingGroupConfiguration{InstanceType: aws.String("c5.4xlarge")},
+			RecommendationOptions: []cotypes.AutoScalingGroupRecommendationOption{{Configuration: &cotypes.AutoScalingGroupConfiguration{InstanceType: aws.String("c5.4xlarge")}, Rank: 1}},
+		}},
+	}}
+	recommendation, err := NewComputeOptimizerClientFromClient(computeOptimizer).GetRecommendation(context.Background(), asgARN)
+	h.Ok(t, err)
+	h.Equals(t, []string{asgARN}, computeOptimizer.autoScalingGroupInputs[0].AutoScalingGroupArns)
+	h.Equals(t, 0, len(computeOptimizer.instanceInputs))
+	h.Equals(t, ec2types.InstanceTypeC54xlarge, recommendation.CurrentInstanceType)
+	h.Equals(t, []ComputeOptimizerOption{{InstanceType: ec2types.InstanceTypeC54xlarge, Rank: 1}}, recommendation.Options)
+}
+
+func TestGetRecommendation_Error(t *testing.T) {
+	instanceARN := "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0"
+	client := NewComputeOptimizerClientFromClient(&mockedComputeOptimizer{err: errors.New("OptInRequiredException: not opted in")})
+	_, err := client.GetRecommendation(context.Background(), instanceARN)
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "OptInRequiredException: not opted in"), "errors should include the API error, got %v", err)
+
+	client = NewComputeOptimizerClientFromClient(&mockedComputeOptimizer{instanceOutput: computeoptimizer.GetEC2InstanceRecommendationsOutput{
+		Errors: []cotypes.GetRecommendationError{{Identifier: aws.String(instanceARN), Code: aws.String("AccessDenied"), Message: aws.String("denied")}},
+	}})
+	_, err = client.GetRecommendation(context.Background(), instanceARN)
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "AccessDenied: denied"), "errors should include the recommendation error, got %v", err)
+
+	client = NewComputeOptimizerClientFromClient(&mockedComputeOptimizer{})
+	_, err = client.GetRecommendation(context.Background(), instanceARN)
+	h.Nok(t, err)
+
+	_, err = client.GetRecommendation(context.Background(), "i-0123456789abcdef0")
+	h.Nok(t, err)
+}
+
+func TestCrossCheck(t *testing.T) {
+	instanceTypes := []*instancetypes.Details{
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: ec2types.InstanceTypeM6iXlarge}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: ec2types.InstanceTypeM6iLarge}},
+	}
+	deviations := CrossCheck(instanceTypes, ComputeOptimizerRecommendation{Options: []ComputeOptimizerOption{
+		{InstanceType: ec2types.InstanceTypeM6gXlarge, Rank: 1},
+		{InstanceType: ec2types.InstanceTypeM6iXlarge, Rank: 2},
+	}})
+	h.Equals(t, []ComputeOptimizerOption{{InstanceType: ec2types.InstanceTypeM6gXlarge, Rank: 1}}, deviations)
+	h.Equals(t, int32(2), *instanceTypes[0].ComputeOptimizerRank)
+	h.Assert(t, instanceTypes[1].ComputeOptimizerRank == nil, "instance types which are not recommended should not have a rank")
+}
@@ -0,0 +1,286 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rightsizing derives vCPU and memory filters from the CloudWatch utilization of a running instance or Auto Scaling group.
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+const (
+	// DefaultHeadroom is the fraction of capacity added on top of the peak utilization.
+	DefaultHeadroom = 0.2
+	// DefaultLookback is how far back utilization is retrieved from.
+	DefaultLookback = 14 * 24 * time.Hour
+	// MaxLookback is the longest Lookback, CloudWatch only retains the hourly datapoints for 455 days.
+	MaxLookback = 455 * 24 * time.Hour
+
+	ec2Namespace     = "AWS/EC2"
+	cwAgentNamespace = "CWAgent"
+	cpuMetric        = "CPUUtilization"
+	memoryMetric     = "mem_used_percent"
+
+	instanceIDDimension       = "InstanceId"
+	autoScalingGroupDimension = "AutoScalingGroupName"
+
+	// utilization is the p95 of each hour, the peak hour is used so that daily peaks are not averaged away
+	utilizationPeriod    = time.Hour
+	utilizationStatistic = "p95"
+	// maxDatapoints is the maximum number of datapoints returned by a GetMetricStatistics call
+	maxDatapoints = 1440
+
+	// upperBoundFactor allows instance types up to twice the required capacity so that the next size of a family is included
+	upperBoundFactor = 2
+)
+
+// CloudWatchAPI is the subset of the CloudWatch client used to retrieve utilization.
+type CloudWatchAPI interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
+// EC2API is the subset of the EC2 client used to retrieve the current instance type.
+type EC2API interface {
+	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
+}
+
+// AutoScalingAPI is the subset of the Auto Scaling client used to retrieve the instance type of a group.
+type AutoScalingAPI interface {
+	DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+}
+
+// STSAPI is the subset of the STS client used to retrieve the partition of instance ARNs.
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// Recommendation is the utilization of the current instance type and the vCPU and memory ranges derived from it.
+type Recommendation struct {
+	InstanceType ec2types.InstanceType
+	VCpus        int32
+	MemoryMiB    int64
+
+	// CPUUtilization is the peak hourly p95 CPU utilization percentage
+	CPUUtilization float64
+	// MemoryUtilization is the peak hourly p95 memory utilization percentage, nil if the CloudWatch agent does not publish it
+	MemoryUtilization *float64
+
+	VCpusRange  selector.Int32RangeFilter
+	MemoryRange *selector.ByteQuantityRangeFilter
+
+	// ResourceARN is the ARN of the rightsized instance or Auto Scaling group
+	ResourceARN string
+}
+
+// Filters returns Filters with the recommended vCPU and memory ranges.
+func (r Recommendation) Filters() selector.Filters {
+	vcpusRange := r.VCpusRange
+	return selector.Filters{
+		VCpusRange:  &vcpusRange,
+		MemoryRange: r.MemoryRange,
+	}
+}
+
+// Rightsizer retrieves utilization from CloudWatch and recommends vCPU and memory ranges.
+type Rightsizer struct {
+	// Headroom is the fraction of capacity added on top of the peak utilization (Example: 0.2 for 20%)
+	Headroom float64
+	// Lookback is how far back utilization is retrieved from
+	Lookback time.Duration
+	// Region is the region of the rightsized instances, it is used to build instance ARNs
+	Region string
+
+	cloudWatch  CloudWatchAPI
+	ec2         EC2API
+	autoScaling AutoScalingAPI
+	sts         STSAPI
+	now         func() time.Time
+}
+
+// New creates a Rightsizer with the default headroom and lookback using clients created from the passed in aws config.
+func New(cfg aws.Config) *Rightsizer {
+	rightsizer := NewFromClients(cloudwatch.NewFromConfig(cfg), ec2.NewFromConfig(cfg), autoscaling.NewFromConfig(cfg), sts.NewFromConfig(cfg))
+	rightsizer.Region = cfg.Region
+	return rightsizer
+}
+
+// NewFromClients creates a Rightsizer with the default headroom and lookback using the passed in clients.
+func NewFromClients(cloudWatchClient CloudWatchAPI, ec2Client EC2API, autoScalingClient AutoScalingAPI, stsClient STSAPI) *Rightsizer {
+	return &Rightsizer{
+		Headroom:    DefaultHeadroom,
+		Lookback:    DefaultLookback,
+		cloudWatch:  cloudWatchClient,
+		ec2:         ec2Client,
+		autoScaling: autoScalingClient,
+		sts:         stsClient,
+		now:         time.Now,
+	}
+}
+
+// ForInstance recommends vCPU and memory ranges from the utilization of a running instance.
+func (r Rightsizer) ForInstance(ctx context.Context, instanceID string) (*Recommendation, error) {
+	output, err := r.ec2.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe instance %s: %w", instanceID, err)
+	}
+	var instanceType ec2types.InstanceType
+	var ownerID string
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			instanceType = instance.InstanceType
+			ownerID = aws.ToString(reservation.OwnerId)
+		}
+	}
+	if instanceType == "" {
+		return nil, fmt.Errorf("instance %s was not found", instanceID)
+	}
+	recommendation, err := r.recommend(ctx, instanceType, cwtypes.Dimension{Name: aws.String(instanceIDDimension), Value: aws.String(instanceID)})
+	if err != nil {
+		return nil, err
+	}
+	// instance ARNs are in the partition of the credentials since DescribeInstances does not return them
+	identity, err := r.sts.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the caller identity: %w", err)
+	}
+	callerARN, err := arn.Parse(aws.ToString(identity.Arn))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the caller ARN: %w", err)
+	}
+	recommendation.ResourceARN = arn.ARN{
+		Partition: callerARN.Partition,
+		Service:   "ec2",
+		Region:    r.Region,
+		AccountID: ownerID,
+		Resource:  "instance/" + instanceID,
+	}.String()
+	return recommendation, nil
+}
+
+// ForAutoScalingGroup recommends vCPU and memory ranges from the aggregate utilization of an Auto Scaling group.
+// All of the group's instances must be the same instance type since the utilization of different instance types can not be combined.
+func (r Rightsizer) ForAutoScalingGroup(ctx context.Context, name string) (*Recommendation, error) {
+	output, err := r.autoScaling.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: []string{name}})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe auto scaling group %s: %w", name, err)
+	}
+	if len(output.AutoScalingGroups) == 0 {
+		return nil, fmt.Errorf("auto scaling group %s was not found", name)
+	}
+	var instanceType ec2types.InstanceType
+	group := output.AutoScalingGroups[0]
+	for _, instance := range group.Instances {
+		currInstanceType := ec2types.InstanceType(aws.ToString(instance.InstanceType))
+		if instanceType != "" && currInstanceType != instanceType {
+			return nil, fmt.Errorf("auto scaling group %s has instances of more than one instance type (%s and %s)", name, instanceType, currInstanceType)
+		}
+		instanceType = currInstanceType
+	}
+	if instanceType == "" {
+		return nil, fmt.Errorf("auto scaling group %s has no instances", name)
+	}
+	recommendation, err := r.recommend(ctx, instanceType, cwtypes.Dimension{Name: aws.String(autoScalingGroupDimension), Value: aws.String(name)})
+	if err != nil {
+		return nil, err
+	}
+	recommendation.ResourceARN = aws.ToString(group.AutoScalingGroupARN)
+	return recommendation, nil
+}
+
+// recommend retrieves the utilization of the dimension and derives the ranges from the capacity of the instance type.
+func (r Rightsizer) recommend(ctx context.Context, instanceType ec2types.InstanceType, dimension cwtypes.Dimension) (*Recommendation, error) {
+	output, err := r.ec2.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{InstanceTypes: []ec2types.InstanceType{instanceType}})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe instance type %s: %w", instanceType, err)
+	}
+	if len(output.InstanceTypes) == 0 || output.InstanceTypes[0].VCpuInfo == nil || output.InstanceTypes[0].MemoryInfo == nil {
+		return nil, fmt.Errorf("instance type %s was not found", instanceType)
+	}
+	recommendation := &Recommendation{
+		InstanceType: instanceType,
+		VCpus:        aws.ToInt32(output.InstanceTypes[0].VCpuInfo.DefaultVCpus),
+		MemoryMiB:    aws.ToInt64(output.InstanceTypes[0].MemoryInfo.SizeInMiB),
+	}
+
+	cpuUtilization, err := r.peakUtilization(ctx, ec2Namespace, cpuMetric, dimension)
+	if err != nil {
+		return nil, err
+	}
+	if cpuUtilization == nil {
+		return nil, fmt.Errorf("no %s %s datapoints were found for %s %s in the last %s", ec2Namespace, cpuMetric, aws.ToString(dimension.Name), aws.ToString(dimension.Value), r.Lookback)
+	}
+	recommendation.CPUUtilization = *cpuUtilization
+	requiredVCpus := int32(math.Max(1, math.Ceil(float64(recommendation.VCpus)*(*cpuUtilization/100)*(1+r.Headroom))))
+	recommendation.VCpusRange = selector.Int32RangeFilter{LowerBound: requiredVCpus, UpperBound: requiredVCpus * upperBoundFactor}
+
+	recommendation.MemoryUtilization, err = r.peakUtilization(ctx, cwAgentNamespace, memoryMetric, dimension)
+	if err != nil {
+		return nil, err
+	}
+	if recommendation.MemoryUtilization != nil {
+		requiredMiB := uint64(math.Max(1, math.Ceil(float64(recommendation.MemoryMiB)*(*recommendation.MemoryUtilization/100)*(1+r.Headroom))))
+		recommendation.MemoryRange = &selector.ByteQuantityRangeFilter{
+			LowerBound: bytequantity.ByteQuantity{Quantity: requiredMiB},
+			UpperBound: bytequantity.ByteQuantity{Quantity: requiredMiB * upperBoundFactor},
+		}
+	}
+	return recommendation, nil
+}
+
+// peakUtilization returns the highest hourly p95 of a percentage metric over the lookback or nil if there are no datapoints.
+func (r Rightsizer) peakUtilization(ctx context.Context, namespace string, metricName string, dimension cwtypes.Dimension) (*float64, error) {
+	now := r.now()
+	// longer lookbacks use longer periods to stay within the datapoints returned by a single call
+	period := utilizationPeriod
+	for r.Lookback/period > maxDatapoints {
+		period += utilizationPeriod
+	}
+	output, err := r.cloudWatch.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:          aws.String(namespace),
+		MetricName:         aws.String(metricName),
+		Dimensions:         []cwtypes.Dimension{dimension},
+		StartTime:          aws.Time(now.Add(-r.Lookback)),
+		EndTime:            aws.Time(now),
+		Period:             aws.Int32(int32(period.Seconds())),
+		ExtendedStatistics: []string{utilizationStatistic},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve %s %s metrics: %w", namespace, metricName, err)
+	}
+	var peak *float64
+	for _, datapoint := range output.Datapoints {
+		value, ok := datapoint.ExtendedStatistics[utilizationStatistic]
+		if !ok {
+			continue
+		}
+		if peak == nil || value > *peak {
+			peak = aws.Float64(value)
+		}
+	}
+	return peak, nil
+}
@@ -0,0 +1,168 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rightsizing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	asgtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocking helpers
+
+type mockedCloudWatch struct {
+	// utilization are the hourly p95 datapoints keyed by metric name
+	utilization map[string][]float64
+	inputs      []*cloudwatch.GetMetricStatisticsInput
+}
+
+func (m *mockedCloudWatch) GetMetricStatistics(_ context.Context, input *cloudwatch.GetMetricStatisticsInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	m.inputs = append(m.inputs, input)
+	output := &cloudwatch.GetMetricStatisticsOutput{}
+	for _, value := range m.utilization[aws.ToString(input.MetricName)] {
+		output.Datapoints = append(output.Datapoints, cwtypes.Datapoint{ExtendedStatistics: map[string]float64{utilizationStatistic: value}})
+	}
+	return output, nil
+}
+
+type mockedEC2 struct {
+	instanceType ec2types.InstanceType
+}
+
+func (m mockedEC2) DescribeInstances(_ context.Context, _ *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{
+		{OwnerId: aws.String("123456789012"), Instances: []ec2types.Instance{{InstanceType: m.instanceType}}},
+	}}, nil
+}
+
+func (m mockedEC2) DescribeInstanceTypes(_ context.Context, input *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	return &ec2.DescribeInstanceTypesOutput{InstanceTypes: []ec2types.InstanceTypeInfo{{
+		InstanceType: input.InstanceTypes[0],
+		VCpuInfo:     &ec2types.VCpuInfo{DefaultVCpus: aws.Int32(16)},
+		MemoryInfo:   &ec2types.MemoryInfo{SizeInMiB: aws.Int64(65536)},
+	}}}, nil
+}
+
+type mockedAutoScaling struct {
+	instanceTypes []string
+}
+
+func (m mockedAutoScaling) DescribeAutoScalingGroups(_ context.Context, input *autoscaling.DescribeAutoScalingGroupsInput, _ ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	group := asgtypes.AutoScalingGroup{
+		AutoScalingGroupName: aws.String(input.AutoScalingGroupNames[0]),
+		AutoScalingGroupARN:  aws.String("arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:uuid:autoScalingGroupName/" + input.AutoScalingGroupNames[0]),
+	}
+	for _, instanceType := range m.instanceTypes {
+		group.Instances = append(group.Instances, asgtypes.Instance{InstanceType: aws.String(instanceType)})
+	}
+	return &autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: []asgtypes.AutoScalingGroup{group}}, nil
+}
+
+type mockedSTS struct {
+	callerARN string
+}
+
+func (m mockedSTS) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(m.callerARN)}, nil
+}
+
+var callerSTS = mockedSTS{callerARN: "arn:aws:iam::123456789012:user/test"}
+
+// Tests
+
+func TestForInstance(t *testing.T) {
+	cloudWatch := &mockedCloudWatch{utilization: map[string][]float64{
+		cpuMetric:    {10, 25, 20},
+		memoryMetric: {40, 50},
+	}}
+	r := NewFromClients(cloudWatch, mockedEC2{instanceType: ec2types.InstanceTypeM54xlarge}, mockedAutoScaling{}, callerSTS)
+	r.Region = "us-east-1"
+	recommendation, err := r.ForInstance(context.Background(), "i-0123456789abcdef0")
+	h.Ok(t, err)
+	h.Equals(t, ec2types.InstanceTypeM54xlarge, recommendation.InstanceType)
+	h.Equals(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0", recommendation.ResourceARN)
+	h.Equals(t, 25.0, recommendation.CPUUtilization)
+	h.Equals(t, 50.0, *recommendation.MemoryUtilization)
+	// 16 vCPUs * 25% * 1.2 headroom
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 5, UpperBound: 10}, recommendation.VCpusRange)
+	// 64 GiB * 50% * 1.2 headroom
+	h.Equals(t, bytequantity.ByteQuantity{Quantity: 39322}, recommendation.MemoryRange.LowerBound)
+	h.Equals(t, bytequantity.ByteQuantity{Quantity: 78644}, recommendation.MemoryRange.UpperBound)
+
+	filters := recommendation.Filters()
+	h.Equals(t, recommendation.VCpusRange, *filters.VCpusRange)
+	h.Equals(t, recommendation.MemoryRange, filters.MemoryRange)
+
+	h.Equals(t, 2, len(cloudWatch.inputs))
+	h.Equals(t, instanceIDDimension, aws.ToString(cloudWatch.inputs[0].Dimensions[0].Name))
+	h.Equals(t, int32(3600), aws.ToInt32(cloudWatch.inputs[0].Period))
+	h.Equals(t, DefaultLookback, cloudWatch.inputs[0].EndTime.Sub(*cloudWatch.inputs[0].StartTime))
+
+	r = NewFromClients(cloudWatch, mockedEC2{instanceType: ec2types.InstanceTypeM54xlarge}, mockedAutoScaling{}, mockedSTS{callerARN: "arn:aws-cn:iam::123456789012:user/test"})
+	r.Region = "cn-north-1"
+	recommendation, err = r.ForInstance(context.Background(), "i-0123456789abcdef0")
+	h.Ok(t, err)
+	h.Equals(t, "arn:aws-cn:ec2:cn-north-1:123456789012:instance/i-0123456789abcdef0", recommendation.ResourceARN)
+}
+
+func TestForInstance_NoMemoryMetrics(t *testing.T) {
+	cloudWatch := &mockedCloudWatch{utilization: map[string][]float64{cpuMetric: {1}}}
+	r := NewFromClients(cloudWatch, mockedEC2{instanceType: ec2types.InstanceTypeM54xlarge}, mockedAutoScaling{}, callerSTS)
+	r.Lookback = 90 * 24 * time.Hour
+	recommendation, err := r.ForInstance(context.Background(), "i-0123456789abcdef0")
+	h.Ok(t, err)
+	h.Assert(t, recommendation.MemoryUtilization == nil, "Memory utilization should be nil without CloudWatch agent metrics")
+	h.Assert(t, recommendation.Filters().MemoryRange == nil, "Memory should not be filtered without CloudWatch agent metrics")
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 1, UpperBound: 2}, recommendation.VCpusRange)
+	h.Assert(t, aws.ToInt32(cloudWatch.inputs[0].Period) == 2*3600, "Lookbacks longer than 1440 hours should use longer periods")
+}
+
+func TestForInstance_NoCPUMetrics(t *testing.T) {
+	r := NewFromClients(&mockedCloudWatch{}, mockedEC2{instanceType: ec2types.InstanceTypeM54xlarge}, mockedAutoScaling{}, callerSTS)
+	_, err := r.ForInstance(context.Background(), "i-0123456789abcdef0")
+	h.Nok(t, err)
+}
+
+func TestForAutoScalingGroup(t *testing.T) {
+	cloudWatch := &mockedCloudWatch{utilization: map[string][]float64{cpuMetric: {50}}}
+	r := NewFromClients(cloudWatch, mockedEC2{}, mockedAutoScaling{instanceTypes: []string{"c5.4xlarge", "c5.4xlarge"}}, callerSTS)
+	r.Headroom = 0
+	recommendation, err := r.ForAutoScalingGroup(context.Background(), "web")
+	h.Ok(t, err)
+	h.Equals(t, ec2types.InstanceTypeC54xlarge, recommendation.InstanceType)
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 8, UpperBound: 16}, recommendation.VCpusRange)
+	h.Equals(t, autoScalingGroupDimension, aws.ToString(cloudWatch.inputs[0].Dimensions[0].Name))
+	h.Equals(t, "web", aws.ToString(cloudWatch.inputs[0].Dimensions[0].Value))
+	h.Equals(t, "arn:aws:autoscaling:us-east-1:123456789012:autoScalingGroup:uuid:autoScalingGroupName/web", recommendation.ResourceARN)
+
+	r = NewFromClients(cloudWatch, mockedEC2{}, mockedAutoScaling{instanceTypes: []string{"c5.4xlarge", "m5.4xlarge"}}, callerSTS)
+	_, err = r.ForAutoScalingGroup(context.Background(), "web")
+	h.Nok(t, err)
+
+	r = NewFromClients(cloudWatch, mockedEC2{}, mockedAutoScaling{}, callerSTS)
+	_, err = r.ForAutoScalingGroup(context.Background(), "web")
+	h.Nok(t, err)
+}
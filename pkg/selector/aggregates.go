@@ -16,12 +16,15 @@ package selector
 import (
 	"context"
 	"fmt"
+	"math"
 	"regexp"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
 )
 
 const (
@@ -29,6 +32,13 @@ const (
 	AggregateLowPercentile = 0.9
 	// AggregateHighPercentile is the default upper percentile for resource ranges on similar instance type comparisons.
 	AggregateHighPercentile = 1.2
+
+	// DefaultHoursPerMonth is the average number of hours in a month which monthly and annual prices are derived with.
+	DefaultHoursPerMonth = 730.0
+
+	// defaultLaunchTemplateVersion is used when a launch template version is not specified.
+	defaultLaunchTemplateVersion = "$Default"
+	efaInterfaceType             = "efa"
 )
 
 var baseAllowedInstanceTypesRE = regexp.MustCompile(`^[cmr][3-9][agi]?\..*$|^t[2-9][gi]?\..*$`)
@@ -58,10 +68,10 @@ func (itf Selector) TransformBaseInstanceType(ctx context.Context, filters Filte
 		},
 	})
 	if err != nil {
-		return filters, err
+		return filters, newAPIError("DescribeInstanceTypes", err)
 	}
 	if len(instanceTypesOutput.InstanceTypes) == 0 {
-		return filters, fmt.Errorf("error instance type %s is not a valid instance type", *filters.InstanceTypeBase)
+		return filters, fmt.Errorf("error instance type %s is not a valid instance type: %w", *filters.InstanceTypeBase, ErrNotFound)
 	}
 	instanceTypeInfo := instanceTypesOutput.InstanceTypes[0]
 	if filters.BareMetal == nil {
@@ -99,6 +109,103 @@ func (itf Selector) TransformBaseInstanceType(ctx context.Context, filters Filte
 	return filters, nil
 }
 
+// TransformLaunchTemplate transforms lower level filters based on the AMI, network interfaces, and placement
+// constraints of the launch template.
+func (itf Selector) TransformLaunchTemplate(ctx context.Context, filters Filters) (Filters, error) {
+	if filters.LaunchTemplateID == nil {
+		return filters, nil
+	}
+	version := defaultLaunchTemplateVersion
+	if filters.LaunchTemplateVersion != nil && *filters.LaunchTemplateVersion != "" {
+		version = *filters.LaunchTemplateVersion
+	}
+	launchTemplateOutput, err := itf.EC2.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: filters.LaunchTemplateID,
+		Versions:         []string{version},
+	})
+	if err != nil {
+		return filters, newAPIError("DescribeLaunchTemplateVersions", err)
+	}
+	if len(launchTemplateOutput.LaunchTemplateVersions) == 0 || launchTemplateOutput.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return filters, fmt.Errorf("error launch template %s version %s could not be found: %w", *filters.LaunchTemplateID, version, ErrNotFound)
+	}
+	launchTemplateData := launchTemplateOutput.LaunchTemplateVersions[0].LaunchTemplateData
+
+	if launchTemplateData.ImageId != nil {
+		imagesOutput, err := itf.EC2.DescribeImages(ctx, &ec2.DescribeImagesInput{
+			ImageIds: []string{*launchTemplateData.ImageId},
+		})
+		if err != nil {
+			return filters, newAPIError("DescribeImages", err)
+		}
+		if len(imagesOutput.Images) == 0 {
+			return filters, fmt.Errorf("error image %s referenced by launch template %s could not be found: %w", *launchTemplateData.ImageId, *filters.LaunchTemplateID, ErrNotFound)
+		}
+		image := imagesOutput.Images[0]
+		if filters.CPUArchitecture == nil && image.Architecture != "" {
+			cpuArchitecture := ec2types.ArchitectureType(image.Architecture)
+			filters.CPUArchitecture = &cpuArchitecture
+		}
+		if filters.VirtualizationType == nil && image.VirtualizationType != "" {
+			virtualizationType := ec2types.VirtualizationType(image.VirtualizationType)
+			filters.VirtualizationType = &virtualizationType
+		}
+		if filters.RootDeviceType == nil && image.RootDeviceType != "" {
+			rootDeviceType := ec2types.RootDeviceType(image.RootDeviceType)
+			filters.RootDeviceType = &rootDeviceType
+		}
+	}
+
+	if len(launchTemplateData.NetworkInterfaces) > 0 {
+		if filters.NetworkInterfaces == nil {
+			filters.NetworkInterfaces = &Int32RangeFilter{LowerBound: int32(len(launchTemplateData.NetworkInterfaces)), UpperBound: math.MaxInt32}
+		}
+		for _, networkInterface := range launchTemplateData.NetworkInterfaces {
+			if filters.EfaSupport == nil && aws.ToString(networkInterface.InterfaceType) == efaInterfaceType {
+				filters.EfaSupport = aws.Bool(true)
+			}
+			if filters.IPv6 == nil && (aws.ToInt32(networkInterface.Ipv6AddressCount) > 0 || len(networkInterface.Ipv6Addresses) > 0) {
+				filters.IPv6 = aws.Bool(true)
+			}
+		}
+	}
+
+	if placement := launchTemplateData.Placement; placement != nil {
+		if filters.AvailabilityZones == nil && aws.ToString(placement.AvailabilityZone) != "" {
+			filters.AvailabilityZones = &[]string{*placement.AvailabilityZone}
+		}
+		if filters.DedicatedHosts == nil && placement.Tenancy == ec2types.TenancyHost {
+			filters.DedicatedHosts = aws.Bool(true)
+		}
+		if filters.Tenancy == nil && placement.Tenancy == ec2types.TenancyDedicated {
+			filters.Tenancy = &[]string{TenancyDedicated}
+		}
+		if filters.PlacementGroupStrategy == nil && filters.PlacementGroupStrategies == nil && (placement.GroupId != nil || placement.GroupName != nil) {
+			placementGroupsInput := &ec2.DescribePlacementGroupsInput{}
+			if placement.GroupId != nil {
+				placementGroupsInput.GroupIds = []string{*placement.GroupId}
+			} else {
+				placementGroupsInput.GroupNames = []string{*placement.GroupName}
+			}
+			placementGroupsOutput, err := itf.EC2.DescribePlacementGroups(ctx, placementGroupsInput)
+			if err != nil {
+				return filters, newAPIError("DescribePlacementGroups", err)
+			}
+			if len(placementGroupsOutput.PlacementGroups) != 0 && placementGroupsOutput.PlacementGroups[0].Strategy != "" {
+				filters.PlacementGroupStrategy = aws.String(string(placementGroupsOutput.PlacementGroups[0].Strategy))
+			}
+		}
+	}
+
+	if filters.HibernationSupported == nil && launchTemplateData.HibernationOptions != nil && aws.ToBool(launchTemplateData.HibernationOptions.Configured) {
+		filters.HibernationSupported = aws.Bool(true)
+	}
+	filters.LaunchTemplateID = nil
+	filters.LaunchTemplateVersion = nil
+
+	return filters, nil
+}
+
 // TransformFlexible transforms lower level filters based on a set of opinions.
 func (itf Selector) TransformFlexible(ctx context.Context, filters Filters) (Filters, error) {
 	if filters.Flexible == nil {
@@ -129,6 +236,66 @@ func (itf Selector) TransformFlexible(ctx context.Context, filters Filters) (Fil
 	return filters, nil
 }
 
+// TransformHibernationStrict requires hibernation support when the strict hibernation prerequisites are checked.
+func (itf Selector) TransformHibernationStrict(ctx context.Context, filters Filters) (Filters, error) {
+	if !aws.ToBool(filters.HibernationStrict) {
+		return filters, nil
+	}
+	if filters.HibernationSupported != nil && !*filters.HibernationSupported {
+		return filters, fmt.Errorf("%w: the strict hibernation prerequisites can not be checked when filtering for instance types which do not support hibernation", ErrInvalidFilterCombination)
+	}
+	filters.HibernationSupported = aws.Bool(true)
+	return filters, nil
+}
+
+// TransformPricePeriods transforms the monthly and annual price filters into the hourly price filter they are equivalent
+// to, the ranges are intersected when more than one of them is set.
+func (itf Selector) TransformPricePeriods(ctx context.Context, filters Filters) (Filters, error) {
+	if filters.PricePerMonth == nil && filters.PricePerYear == nil {
+		return filters, nil
+	}
+	hoursPerMonth := getHoursPerMonth(filters)
+	if hoursPerMonth <= 0 {
+		return filters, fmt.Errorf("%w: the hours per month must be greater than 0", ErrInvalidFilterCombination)
+	}
+	pricePerHour := filters.PricePerHour
+	for _, periodPrice := range []struct {
+		filter *Float64RangeFilter
+		hours  float64
+	}{
+		{filters.PricePerMonth, hoursPerMonth},
+		{filters.PricePerYear, hoursPerMonth * instancetypes.MonthsPerYear},
+	} {
+		if periodPrice.filter == nil {
+			continue
+		}
+		periodPricePerHour := Float64RangeFilter{
+			LowerBound: periodPrice.filter.LowerBound / periodPrice.hours,
+			UpperBound: periodPrice.filter.UpperBound / periodPrice.hours,
+		}
+		if pricePerHour != nil {
+			periodPricePerHour.LowerBound = math.Max(periodPricePerHour.LowerBound, pricePerHour.LowerBound)
+			periodPricePerHour.UpperBound = math.Min(periodPricePerHour.UpperBound, pricePerHour.UpperBound)
+		}
+		if periodPricePerHour.LowerBound > periodPricePerHour.UpperBound {
+			return filters, fmt.Errorf("%w: the hourly, monthly and annual price ranges do not overlap", ErrInvalidFilterCombination)
+		}
+		pricePerHour = &periodPricePerHour
+	}
+	filters.PricePerHour = pricePerHour
+	filters.PricePerMonth = nil
+	filters.PricePerYear = nil
+	return filters, nil
+}
+
+// getHoursPerMonth returns the hours per month filter or DefaultHoursPerMonth if it is not set.
+func getHoursPerMonth(filters Filters) float64 {
+	if filters.HoursPerMonth == nil {
+		return DefaultHoursPerMonth
+	}
+	return *filters.HoursPerMonth
+}
+
 // TransformForService transforms lower level filters based on the service.
 func (itf Selector) TransformForService(ctx context.Context, filters Filters) (Filters, error) {
 	return itf.ServiceRegistry.ExecuteTransforms(filters)
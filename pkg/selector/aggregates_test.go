@@ -14,8 +14,12 @@ package selector_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
 )
@@ -64,6 +68,98 @@ func TestTransformBaseInstanceTypeWithGPU(t *testing.T) {
 	h.Assert(t, filters.GpusRange.LowerBound == 1 && filters.GpusRange.UpperBound == 1, "should only return gpu instance types")
 }
 
+func TestTransformLaunchTemplate(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeLaunchTemplateVersionsResp: setupMock(t, describeLaunchTemplateVersions, "efa_cluster.json").DescribeLaunchTemplateVersionsResp,
+		DescribeImagesResp:                 setupMock(t, describeImages, "arm64_ebs.json").DescribeImagesResp,
+		DescribePlacementGroupsResp:        setupMock(t, describePlacementGroups, "cluster.json").DescribePlacementGroupsResp,
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	launchTemplateID := "lt-0123456789abcdef0"
+	filters := selector.Filters{
+		LaunchTemplateID: &launchTemplateID,
+	}
+	ctx := context.Background()
+	filters, err := itf.TransformLaunchTemplate(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, filters.LaunchTemplateID == nil, "should clear the launch template ID")
+	h.Assert(t, *filters.CPUArchitecture == "arm64", "should only return arm64 instance types")
+	h.Assert(t, *filters.VirtualizationType == "hvm", "should only return hvm instance types")
+	h.Assert(t, *filters.RootDeviceType == "ebs", "should only return ebs instance types")
+	h.Assert(t, filters.NetworkInterfaces.LowerBound == 2, "should return instance types supporting at least 2 network interfaces")
+	h.Assert(t, *filters.EfaSupport, "should only return EFA instance types")
+	h.Assert(t, *filters.IPv6, "should only return IPv6 instance types")
+	h.Equals(t, []string{"us-east-2a"}, *filters.AvailabilityZones)
+	h.Equals(t, "cluster", *filters.PlacementGroupStrategy)
+}
+
+func TestTransformLaunchTemplate_UserOverrides(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeLaunchTemplateVersionsResp: setupMock(t, describeLaunchTemplateVersions, "efa_cluster.json").DescribeLaunchTemplateVersionsResp,
+		DescribeImagesResp:                 setupMock(t, describeImages, "arm64_ebs.json").DescribeImagesResp,
+		DescribePlacementGroupsResp:        setupMock(t, describePlacementGroups, "cluster.json").DescribePlacementGroupsResp,
+	}
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	launchTemplateID := "lt-0123456789abcdef0"
+	availabilityZones := []string{"us-east-2b"}
+	filters := selector.Filters{
+		LaunchTemplateID:  &launchTemplateID,
+		AvailabilityZones: &availabilityZones,
+	}
+	ctx := context.Background()
+	filters, err := itf.TransformLaunchTemplate(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"us-east-2b"}, *filters.AvailabilityZones)
+}
+
+func TestTransformLaunchTemplate_DedicatedTenancy(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeLaunchTemplateVersionsResp: setupMock(t, describeLaunchTemplateVersions, "efa_cluster.json").DescribeLaunchTemplateVersionsResp,
+		DescribeImagesResp:                 setupMock(t, describeImages, "arm64_ebs.json").DescribeImagesResp,
+		DescribePlacementGroupsResp:        setupMock(t, describePlacementGroups, "cluster.json").DescribePlacementGroupsResp,
+	}
+	ec2Mock.DescribeLaunchTemplateVersionsResp.LaunchTemplateVersions[0].LaunchTemplateData.Placement.Tenancy = ec2types.TenancyDedicated
+	itf := selector.Selector{
+		EC2: ec2Mock,
+	}
+	launchTemplateID := "lt-0123456789abcdef0"
+	filters, err := itf.TransformLaunchTemplate(context.Background(), selector.Filters{LaunchTemplateID: &launchTemplateID})
+	h.Ok(t, err)
+	h.Equals(t, []string{selector.TenancyDedicated}, *filters.Tenancy)
+	h.Assert(t, filters.DedicatedHosts == nil, "should not require Dedicated Hosts support")
+}
+
+func TestTransformLaunchTemplate_NotFound(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{},
+	}
+	launchTemplateID := "lt-0123456789abcdef0"
+	filters := selector.Filters{
+		LaunchTemplateID: &launchTemplateID,
+	}
+	ctx := context.Background()
+	_, err := itf.TransformLaunchTemplate(ctx, filters)
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), "Should return ErrNotFound when the launch template is not found")
+}
+
+func TestTransformLaunchTemplate_APIError(t *testing.T) {
+	itf := selector.Selector{
+		EC2: mockedEC2{DescribeLaunchTemplateVersionsErr: errors.New("throttled")},
+	}
+	launchTemplateID := "lt-0123456789abcdef0"
+	filters := selector.Filters{
+		LaunchTemplateID: &launchTemplateID,
+	}
+	_, err := itf.TransformLaunchTemplate(context.Background(), filters)
+	var apiErr *selector.APIError
+	h.Assert(t, errors.As(err, &apiErr), "Should wrap the AWS error in an APIError")
+	h.Equals(t, "DescribeLaunchTemplateVersions", apiErr.API)
+}
+
 func TestTransformFamilyFlexibile(t *testing.T) {
 	itf := selector.Selector{}
 	flexible := true
@@ -77,3 +173,49 @@ func TestTransformFamilyFlexibile(t *testing.T) {
 	h.Assert(t, *filters.Fpga == false, "should filter out FPGA instances")
 	h.Assert(t, *filters.CPUArchitecture == "x86_64", "should only return x86_64 instance types")
 }
+
+func TestTransformHibernationStrict(t *testing.T) {
+	itf := selector.Selector{}
+	ctx := context.Background()
+	filters, err := itf.TransformHibernationStrict(ctx, selector.Filters{HibernationStrict: aws.Bool(true)})
+	h.Ok(t, err)
+	h.Assert(t, aws.ToBool(filters.HibernationSupported), "should require hibernation support")
+
+	filters, err = itf.TransformHibernationStrict(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Assert(t, filters.HibernationSupported == nil, "should not filter by hibernation support without the strict checks")
+
+	_, err = itf.TransformHibernationStrict(ctx, selector.Filters{HibernationStrict: aws.Bool(true), HibernationSupported: aws.Bool(false)})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination when hibernation support is excluded")
+}
+
+func TestTransformPricePeriods(t *testing.T) {
+	itf := selector.Selector{}
+	ctx := context.Background()
+	filters, err := itf.TransformPricePeriods(ctx, selector.Filters{
+		PricePerMonth: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 73},
+	})
+	h.Ok(t, err)
+	h.Equals(t, selector.Float64RangeFilter{LowerBound: 0, UpperBound: 0.1}, *filters.PricePerHour)
+	h.Assert(t, filters.PricePerMonth == nil, "the monthly price filter should be transformed into the hourly price filter")
+
+	filters, err = itf.TransformPricePeriods(ctx, selector.Filters{
+		PricePerHour:  &selector.Float64RangeFilter{LowerBound: 0.05, UpperBound: 1},
+		PricePerYear:  &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 1200},
+		HoursPerMonth: aws.Float64(100),
+	})
+	h.Ok(t, err)
+	h.Equals(t, selector.Float64RangeFilter{LowerBound: 0.05, UpperBound: 1}, *filters.PricePerHour)
+
+	_, err = itf.TransformPricePeriods(ctx, selector.Filters{
+		PricePerHour:  &selector.Float64RangeFilter{LowerBound: 1, UpperBound: 2},
+		PricePerMonth: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 73},
+	})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination when the price ranges do not overlap")
+
+	_, err = itf.TransformPricePeriods(ctx, selector.Filters{
+		PricePerMonth: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 73},
+		HoursPerMonth: aws.Float64(0),
+	})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination when the hours per month are not positive")
+}
@@ -0,0 +1,71 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// Annotations are advisory conditions of a matching instance type, they do not exclude the instance type from the
+// results but callers may want to surface them. Annotations which carry details are suffixed with ": <details>".
+const (
+	// AnnotationPreviousGeneration is added to instance types which are not of the current generation
+	AnnotationPreviousGeneration = "previous generation"
+	// AnnotationDeprecated is added to deprecated instance types, suffixed with the reason they are deprecated
+	AnnotationDeprecated = "deprecated"
+	// AnnotationOnDemandPriceUnavailable is added when on-demand prices were fetched but not for the instance type
+	AnnotationOnDemandPriceUnavailable = "on-demand price unavailable"
+	// AnnotationOnDemandPriceDiscounted is added when the on-demand price includes a discount, suffixed with the discount percentage
+	AnnotationOnDemandPriceDiscounted = "on-demand price discounted"
+	// AnnotationSpotPriceUnavailable is added when spot prices were fetched but not for the instance type
+	AnnotationSpotPriceUnavailable = "spot price unavailable"
+	// AnnotationSpotPriceStale is added when the spot price did not change over the days it is averaged over, suffixed with the
+	// date of the last change
+	AnnotationSpotPriceStale = "spot price stale"
+	// AnnotationSpotUnsupported is added when spot prices were fetched but the instance type does not support spot
+	AnnotationSpotUnsupported = "spot not supported"
+)
+
+// getAnnotations returns the annotations of an instance type whose prices and derived specs are already populated.
+// onDemandDiscountPercent is the discount included in the on-demand price, 0 if it is not discounted.
+// spotPriceLastChange is the time the spot price last changed, the zero time if it is unknown.
+func getAnnotations(instanceTypeInfo *instancetypes.Details, onDemandPricesFetched bool, onDemandDiscountPercent float64, spotPricesFetched bool, isSpotUsageClass bool, spotPriceLastChange time.Time) []string {
+	var annotations []string
+	if instanceTypeInfo.CurrentGeneration != nil && !*instanceTypeInfo.CurrentGeneration {
+		annotations = append(annotations, AnnotationPreviousGeneration)
+	}
+	if instanceTypeInfo.Deprecation != nil {
+		annotations = append(annotations, AnnotationDeprecated+": "+aws.ToString(instanceTypeInfo.Deprecation))
+	}
+	if onDemandPricesFetched && instanceTypeInfo.OndemandPricePerHour == nil {
+		annotations = append(annotations, AnnotationOnDemandPriceUnavailable)
+	}
+	if instanceTypeInfo.OndemandPricePerHour != nil && onDemandDiscountPercent > 0 {
+		annotations = append(annotations, fmt.Sprintf("%s: %g%%", AnnotationOnDemandPriceDiscounted, onDemandDiscountPercent))
+	}
+	if spotPricesFetched {
+		if !isSpotUsageClass {
+			annotations = append(annotations, AnnotationSpotUnsupported)
+		} else if instanceTypeInfo.SpotPrice == nil {
+			annotations = append(annotations, AnnotationSpotPriceUnavailable)
+		} else if !spotPriceLastChange.IsZero() && time.Since(spotPriceLastChange) > spotPriceLookupDays*24*time.Hour {
+			annotations = append(annotations, AnnotationSpotPriceStale+": unchanged since "+spotPriceLastChange.UTC().Format(time.DateOnly))
+		}
+	}
+	return annotations
+}
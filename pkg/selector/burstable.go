@@ -0,0 +1,86 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// fixedPerformanceBaselineCPU is the baseline CPU utilization of instance types which are not burstable.
+const fixedPerformanceBaselineCPU = float64(100)
+
+// burstablePerformance holds the baseline CPU utilization and CPU credit earn rate of a burstable instance type.
+type burstablePerformance struct {
+	// baselineCPUUtilization is the percentage of each vCPU that can be used without spending CPU credits
+	baselineCPUUtilization float64
+	// cpuCreditsPerHour is the number of CPU credits earned per hour
+	cpuCreditsPerHour float64
+}
+
+// burstablePerformanceSpecs are not returned by the EC2 API, so they are sourced from
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/burstable-credits-baseline-concepts.html
+var burstablePerformanceSpecs = map[ec2types.InstanceType]burstablePerformance{
+	ec2types.InstanceTypeT2Nano:     {baselineCPUUtilization: 5, cpuCreditsPerHour: 3},
+	ec2types.InstanceTypeT2Micro:    {baselineCPUUtilization: 10, cpuCreditsPerHour: 6},
+	ec2types.InstanceTypeT2Small:    {baselineCPUUtilization: 20, cpuCreditsPerHour: 12},
+	ec2types.InstanceTypeT2Medium:   {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT2Large:    {baselineCPUUtilization: 30, cpuCreditsPerHour: 36},
+	ec2types.InstanceTypeT2Xlarge:   {baselineCPUUtilization: 22.5, cpuCreditsPerHour: 54},
+	ec2types.InstanceTypeT22xlarge:  {baselineCPUUtilization: 17, cpuCreditsPerHour: 81.6},
+	ec2types.InstanceTypeT3Nano:     {baselineCPUUtilization: 5, cpuCreditsPerHour: 6},
+	ec2types.InstanceTypeT3Micro:    {baselineCPUUtilization: 10, cpuCreditsPerHour: 12},
+	ec2types.InstanceTypeT3Small:    {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT3Medium:   {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT3Large:    {baselineCPUUtilization: 30, cpuCreditsPerHour: 36},
+	ec2types.InstanceTypeT3Xlarge:   {baselineCPUUtilization: 40, cpuCreditsPerHour: 96},
+	ec2types.InstanceTypeT32xlarge:  {baselineCPUUtilization: 40, cpuCreditsPerHour: 192},
+	ec2types.InstanceTypeT3aNano:    {baselineCPUUtilization: 5, cpuCreditsPerHour: 6},
+	ec2types.InstanceTypeT3aMicro:   {baselineCPUUtilization: 10, cpuCreditsPerHour: 12},
+	ec2types.InstanceTypeT3aSmall:   {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT3aMedium:  {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT3aLarge:   {baselineCPUUtilization: 30, cpuCreditsPerHour: 36},
+	ec2types.InstanceTypeT3aXlarge:  {baselineCPUUtilization: 40, cpuCreditsPerHour: 96},
+	ec2types.InstanceTypeT3a2xlarge: {baselineCPUUtilization: 40, cpuCreditsPerHour: 192},
+	ec2types.InstanceTypeT4gNano:    {baselineCPUUtilization: 5, cpuCreditsPerHour: 6},
+	ec2types.InstanceTypeT4gMicro:   {baselineCPUUtilization: 10, cpuCreditsPerHour: 12},
+	ec2types.InstanceTypeT4gSmall:   {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT4gMedium:  {baselineCPUUtilization: 20, cpuCreditsPerHour: 24},
+	ec2types.InstanceTypeT4gLarge:   {baselineCPUUtilization: 30, cpuCreditsPerHour: 36},
+	ec2types.InstanceTypeT4gXlarge:  {baselineCPUUtilization: 40, cpuCreditsPerHour: 96},
+	ec2types.InstanceTypeT4g2xlarge: {baselineCPUUtilization: 40, cpuCreditsPerHour: 192},
+}
+
+// getBaselineCPUUtilization returns the baseline CPU utilization percentage per vCPU of an instance type.
+// Instance types which are not burstable always have a baseline of 100%.
+// nil is returned for burstable instance types with an unknown baseline.
+func getBaselineCPUUtilization(instanceTypeInfo *ec2types.InstanceTypeInfo) *float64 {
+	if instanceTypeInfo.BurstablePerformanceSupported == nil || !*instanceTypeInfo.BurstablePerformanceSupported {
+		baseline := fixedPerformanceBaselineCPU
+		return &baseline
+	}
+	specs, ok := burstablePerformanceSpecs[instanceTypeInfo.InstanceType]
+	if !ok {
+		return nil
+	}
+	return &specs.baselineCPUUtilization
+}
+
+// getCPUCreditsPerHour returns the CPU credits earned per hour by a burstable instance type
+// or nil if the instance type is not burstable.
+func getCPUCreditsPerHour(instanceTypeInfo *ec2types.InstanceTypeInfo) *float64 {
+	specs, ok := burstablePerformanceSpecs[instanceTypeInfo.InstanceType]
+	if !ok {
+		return nil
+	}
+	return &specs.cpuCreditsPerHour
+}
@@ -0,0 +1,104 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/mitchellh/go-homedir"
+)
+
+// powerCoefficients are the minimum and maximum watts drawn by a single unit of a resource.
+type powerCoefficients struct {
+	minWatts float64
+	maxWatts float64
+}
+
+// averageWatts returns the watts drawn at the average utilization assumed by the carbon score.
+func (p powerCoefficients) averageWatts() float64 {
+	return p.minWatts + carbonScoreUtilization*(p.maxWatts-p.minWatts)
+}
+
+const (
+	// carbonScoreUtilization is the average utilization assumed when estimating power draw
+	carbonScoreUtilization = 0.5
+	// memoryWattsPerGiB is the power drawn by each GiB of memory
+	memoryWattsPerGiB = 0.392
+	mibPerGiB         = 1024
+)
+
+// Power coefficients are not returned by the EC2 API, so they are sourced from the published AWS averages of the
+// Cloud Carbon Footprint methodology https://www.cloudcarbonfootprint.org/docs/methodology
+var (
+	x86VCPUPower   = powerCoefficients{minWatts: 0.74, maxWatts: 3.5}
+	arm64VCPUPower = powerCoefficients{minWatts: 0.47, maxWatts: 1.69}
+	gpuPower       = powerCoefficients{minWatts: 35, maxWatts: 250}
+)
+
+// LoadCarbonData loads per instance type carbon scores from a JSON file which overrides the built-in estimates.
+// The file must contain a JSON object of instance type names to scores (Example: {"m5.large": 12.5}).
+// Scores are relative, lower is better, and can come from any dataset as long as all scores in the file use the same unit.
+func LoadCarbonData(path string) (map[ec2types.InstanceType]float64, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand carbon data file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read carbon data file %s: %w", expandedPath, err)
+	}
+	carbonData := map[ec2types.InstanceType]float64{}
+	if err := json.Unmarshal(data, &carbonData); err != nil {
+		return nil, fmt.Errorf("unable to parse carbon data file %s: %w", expandedPath, err)
+	}
+	return carbonData, nil
+}
+
+// getCarbonScore returns the relative carbon score of an instance type, lower is better.
+// Scores from carbonData take precedence, otherwise the score is the estimated average power draw in watts
+// of the instance type's vCPUs, memory, and GPUs.
+// nil is returned if the score cannot be estimated.
+func getCarbonScore(instanceTypeInfo *ec2types.InstanceTypeInfo, carbonData map[ec2types.InstanceType]float64) *float64 {
+	if score, ok := carbonData[instanceTypeInfo.InstanceType]; ok {
+		return &score
+	}
+	if instanceTypeInfo.VCpuInfo == nil || instanceTypeInfo.VCpuInfo.DefaultVCpus == nil {
+		return nil
+	}
+	vcpuPower := x86VCPUPower
+	if instanceTypeInfo.ProcessorInfo != nil && isSupportingArm64(instanceTypeInfo.ProcessorInfo.SupportedArchitectures) {
+		vcpuPower = arm64VCPUPower
+	}
+	watts := float64(*instanceTypeInfo.VCpuInfo.DefaultVCpus) * vcpuPower.averageWatts()
+	if instanceTypeInfo.MemoryInfo != nil && instanceTypeInfo.MemoryInfo.SizeInMiB != nil {
+		watts += float64(*instanceTypeInfo.MemoryInfo.SizeInMiB) / mibPerGiB * memoryWattsPerGiB
+	}
+	if gpus := getTotalGpusCount(instanceTypeInfo.GpuInfo); gpus != nil {
+		watts += float64(*gpus) * gpuPower.averageWatts()
+	}
+	score := math.Round(watts*1000) / 1000
+	return &score
+}
+
+func isSupportingArm64(architectures []ec2types.ArchitectureType) bool {
+	for _, architecture := range architectures {
+		if architecture == ec2types.ArchitectureTypeArm64 || architecture == ec2types.ArchitectureTypeArm64Mac {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// InstanceTypesCatalog is every instance type of a region indexed by name, family, and size.
+type InstanceTypesCatalog struct {
+	// InstanceTypes are the details of every instance type sorted by name
+	InstanceTypes []*instancetypes.Details
+	byName        map[ec2types.InstanceType]*instancetypes.Details
+	byFamily      map[string][]*instancetypes.Details
+	bySize        map[string][]*instancetypes.Details
+}
+
+// Catalog returns every instance type of the selector's region from the instance types cache without filtering them,
+// instance types are only described if the cache is empty or expired. Prices are not looked up.
+func (s Selector) Catalog(ctx context.Context) (*InstanceTypesCatalog, error) {
+	instanceTypeDetails, err := s.instanceTypesProvider().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the instance types of the catalog: %w", err)
+	}
+	return NewInstanceTypesCatalog(instanceTypeDetails), nil
+}
+
+// NewInstanceTypesCatalog indexes shallow copies of the instance types, so that the fields of the Details in the catalog can
+// be set without modifying the instance types cache. The values the fields point to, such as the VCpuInfo of the
+// InstanceTypeInfo, are shared with the cache and must not be modified.
+func NewInstanceTypesCatalog(instanceTypeDetails []*instancetypes.Details) *InstanceTypesCatalog {
+	catalog := &InstanceTypesCatalog{
+		InstanceTypes: make([]*instancetypes.Details, 0, len(instanceTypeDetails)),
+		byName:        map[ec2types.InstanceType]*instancetypes.Details{},
+		byFamily:      map[string][]*instancetypes.Details{},
+		bySize:        map[string][]*instancetypes.Details{},
+	}
+	for _, instanceTypeInfo := range instanceTypeDetails {
+		instanceTypeCopy := *instanceTypeInfo
+		catalog.InstanceTypes = append(catalog.InstanceTypes, &instanceTypeCopy)
+	}
+	sortInstanceTypeInfo(catalog.InstanceTypes)
+	for _, instanceTypeInfo := range catalog.InstanceTypes {
+		family, size := InstanceTypeFamilyAndSize(instanceTypeInfo.InstanceType)
+		catalog.byName[instanceTypeInfo.InstanceType] = instanceTypeInfo
+		catalog.byFamily[family] = append(catalog.byFamily[family], instanceTypeInfo)
+		catalog.bySize[size] = append(catalog.bySize[size], instanceTypeInfo)
+	}
+	return catalog
+}
+
+// InstanceTypeFamilyAndSize splits an instance type name into its family and size (Ex: m5.xlarge is the m5 family and
+// xlarge size). The size is empty if the name does not contain a size.
+func InstanceTypeFamilyAndSize(instanceType ec2types.InstanceType) (string, string) {
+	family, size, _ := strings.Cut(string(instanceType), ".")
+	return family, size
+}
+
+// Get returns the details of the instance type and whether it is in the catalog.
+func (c *InstanceTypesCatalog) Get(instanceType ec2types.InstanceType) (*instancetypes.Details, bool) {
+	instanceTypeInfo, ok := c.byName[instanceType]
+	return instanceTypeInfo, ok
+}
+
+// Family returns the instance types of the family sorted by name (Ex: m5).
+func (c *InstanceTypesCatalog) Family(family string) []*instancetypes.Details {
+	return c.byFamily[family]
+}
+
+// Size returns the instance types of the size across all families sorted by name (Ex: xlarge).
+func (c *InstanceTypesCatalog) Size(size string) []*instancetypes.Details {
+	return c.bySize[size]
+}
+
+// Families returns the instance families in the catalog sorted by name.
+func (c *InstanceTypesCatalog) Families() []string {
+	return sortedKeys(c.byFamily)
+}
+
+// Sizes returns the instance sizes in the catalog sorted by name.
+func (c *InstanceTypesCatalog) Sizes() []string {
+	return sortedKeys(c.bySize)
+}
+
+func sortedKeys(index map[string][]*instancetypes.Details) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -16,6 +16,7 @@ import (
 	"math"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -140,6 +141,63 @@ func isSupportedInstanceTypeHypervisorType(instanceTypeValue ec2types.InstanceTy
 	return false
 }
 
+func isSupportedEbsOptimizedSupport(instanceTypeValue ec2types.EbsOptimizedSupport, target *ec2types.EbsOptimizedSupport) bool {
+	if target == nil {
+		return true
+	}
+	if reflect.ValueOf(*target).IsZero() {
+		return true
+	}
+	return instanceTypeValue == *target
+}
+
+// placementGroupStrategySupport is the instance spec of the PlacementGroupStrategy filter, the strategies the instance type
+// supports and whether it only has to support any rather than all of the filtered strategies.
+type placementGroupStrategySupport struct {
+	strategies []ec2types.PlacementGroupStrategy
+	matchAny   bool
+}
+
+// getPlacementGroupStrategies returns the PlacementGroupStrategy and PlacementGroupStrategies strategies of the filters
+// together, nil if neither is set.
+func getPlacementGroupStrategies(filters Filters) *[]string {
+	if filters.PlacementGroupStrategy == nil && filters.PlacementGroupStrategies == nil {
+		return nil
+	}
+	strategies := []string{}
+	if filters.PlacementGroupStrategy != nil {
+		strategies = append(strategies, *filters.PlacementGroupStrategy)
+	}
+	if filters.PlacementGroupStrategies != nil {
+		strategies = append(strategies, *filters.PlacementGroupStrategies...)
+	}
+	return &strategies
+}
+
+func getPlacementGroupStrategySupport(placementGroupInfo *ec2types.PlacementGroupInfo, match *string) placementGroupStrategySupport {
+	support := placementGroupStrategySupport{matchAny: aws.ToString(match) == PlacementGroupStrategyMatchAny}
+	if placementGroupInfo != nil {
+		support.strategies = placementGroupInfo.SupportedStrategies
+	}
+	return support
+}
+
+func isSupportedPlacementGroupStrategies(instanceTypeValue placementGroupStrategySupport, target []string) bool {
+	if len(target) == 0 {
+		return true
+	}
+	for _, strategy := range target {
+		supported := slices.Contains(instanceTypeValue.strategies, ec2types.PlacementGroupStrategy(strategy))
+		if supported && instanceTypeValue.matchAny {
+			return true
+		}
+		if !supported && !instanceTypeValue.matchAny {
+			return false
+		}
+	}
+	return !instanceTypeValue.matchAny
+}
+
 func isSupportedRootDeviceType(instanceTypeValue []ec2types.RootDeviceType, target *ec2types.RootDeviceType) bool {
 	if target == nil {
 		return true
@@ -193,6 +251,15 @@ func isSupportedWithRangeInt32(instanceTypeValue *int32, target *Int32RangeFilte
 	return *instanceTypeValue >= target.LowerBound && *instanceTypeValue <= target.UpperBound
 }
 
+// isSupportedWithSetInt32 returns true if the instance spec is one of the target values, instance types without the spec
+// are only supported when the target includes 0.
+func isSupportedWithSetInt32(instanceTypeValue *int32, target *Int32SetFilter) bool {
+	if target == nil {
+		return true
+	}
+	return slices.Contains(*target, aws.ToInt32(instanceTypeValue))
+}
+
 func isSupportedWithRangeUint64(instanceTypeValue *int64, target *Uint64RangeFilter) bool {
 	if target == nil {
 		return true
@@ -278,6 +345,44 @@ func getGPUModels(gpusInfo *ec2types.GpuInfo) []*string {
 	return models
 }
 
+// getMaximumNetworkCards returns the number of network cards of an instance type, instance types which were described
+// before the number of network cards was returned have a single network card.
+func getMaximumNetworkCards(networkInfo *ec2types.NetworkInfo) *int32 {
+	if networkInfo == nil || networkInfo.MaximumNetworkCards == nil {
+		return aws.Int32(1)
+	}
+	return networkInfo.MaximumNetworkCards
+}
+
+func getTotalFpgasCount(fpgaInfo *ec2types.FpgaInfo) *int32 {
+	if fpgaInfo == nil {
+		return nil
+	}
+	total := int32(0)
+	for _, fpga := range fpgaInfo.Fpgas {
+		total = total + aws.ToInt32(fpga.Count)
+	}
+	return &total
+}
+
+func getTotalFpgaMemory(fpgaInfo *ec2types.FpgaInfo) *int64 {
+	if fpgaInfo == nil || fpgaInfo.TotalFpgaMemoryInMiB == nil {
+		return nil
+	}
+	return aws.Int64(int64(*fpgaInfo.TotalFpgaMemoryInMiB))
+}
+
+func getFPGAManufacturers(fpgaInfo *ec2types.FpgaInfo) []*string {
+	if fpgaInfo == nil {
+		return nil
+	}
+	var manufacturers []*string
+	for _, info := range fpgaInfo.Fpgas {
+		manufacturers = append(manufacturers, info.Manufacturer)
+	}
+	return manufacturers
+}
+
 func getInferenceAcceleratorManufacturers(acceleratorInfo *ec2types.InferenceAcceleratorInfo) []*string {
 	if acceleratorInfo == nil {
 		return nil
@@ -428,6 +533,42 @@ func calculateVCpusToMemoryRatio(vcpusVal *int32, memoryVal *int64) *float64 {
 
 // Slice helper function
 
+// isNotMatchingAny returns true if none of the values of the instance spec are equal to one of the target values ignoring case.
+// The instance spec may be a string, a string based enum, or a slice or pointer of them.
+func isNotMatchingAny(instanceTypeValue interface{}, target *NotFilter) bool {
+	if target == nil {
+		return true
+	}
+	for _, specValue := range getStringValues(reflect.ValueOf(instanceTypeValue)) {
+		for _, value := range *target {
+			if strings.EqualFold(specValue, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getStringValues returns the string values of a string, a string based enum, or a slice or pointer of them.
+func getStringValues(value reflect.Value) []string {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return getStringValues(value.Elem())
+	case reflect.Slice, reflect.Array:
+		values := []string{}
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, getStringValues(value.Index(i))...)
+		}
+		return values
+	case reflect.String:
+		return []string{value.String()}
+	}
+	return nil
+}
+
 func contains(slice []*string, target string) bool {
 	for _, it := range slice {
 		if it != nil && strings.EqualFold(*it, target) {
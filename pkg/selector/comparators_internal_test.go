@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
 )
@@ -59,6 +60,16 @@ func TestIsSupportedFromString_NilTarget(t *testing.T) {
 	h.Assert(t, isSupported == true, "nil target should be supported for specified source string")
 }
 
+func TestIsNotMatchingAny(t *testing.T) {
+	h.Assert(t, isNotMatchingAny(aws.String("nitro"), nil), "nil target should not exclude any instance spec")
+	h.Assert(t, !isNotMatchingAny(aws.String("NVIDIA"), &NotFilter{"amd", "nvidia"}), "matching values should be excluded ignoring case")
+	h.Assert(t, isNotMatchingAny(aws.String("AMD"), &NotFilter{"nvidia"}), "values which do not match should not be excluded")
+	h.Assert(t, !isNotMatchingAny([]*string{aws.String("Xilinx"), aws.String("NVIDIA")}, &NotFilter{"nvidia"}), "any matching value of a slice should be excluded")
+	h.Assert(t, !isNotMatchingAny(CPUManufacturerIntel, &NotFilter{"intel"}), "string based enums should be compared")
+	h.Assert(t, isNotMatchingAny([]*string{}, &NotFilter{"nvidia"}), "empty instance specs should not be excluded")
+	h.Assert(t, isNotMatchingAny(nil, &NotFilter{"nvidia"}), "nil instance specs should not be excluded")
+}
+
 func TestIsSupportedWithBool(t *testing.T) {
 	hibernationSupported := aws.Bool(true)
 	userFilter := aws.Bool(true)
@@ -79,6 +90,48 @@ func TestIsSupportedWithBool_Unsupported(t *testing.T) {
 	h.Assert(t, isSupported == false, "Hibernation should NOT be supported")
 }
 
+func TestIsHibernationSupported(t *testing.T) {
+	instanceTypeInfo := ec2types.InstanceTypeInfo{
+		HibernationSupported:     aws.Bool(true),
+		MemoryInfo:               &ec2types.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+		SupportedRootDeviceTypes: []ec2types.RootDeviceType{ec2types.RootDeviceTypeEbs},
+		EbsInfo:                  &ec2types.EbsInfo{EncryptionSupport: ec2types.EbsEncryptionSupportSupported},
+	}
+	h.Assert(t, aws.ToBool(isHibernationSupported(&instanceTypeInfo, false)), "Hibernation should be supported")
+	h.Assert(t, aws.ToBool(isHibernationSupported(&instanceTypeInfo, true)), "Hibernation should be supported with the strict checks")
+
+	instanceTypeInfo.MemoryInfo.SizeInMiB = aws.Int64(150 * 1024)
+	h.Assert(t, !aws.ToBool(isHibernationSupported(&instanceTypeInfo, false)), "Hibernation should NOT be supported with 150 GiB of memory")
+}
+
+func TestIsHibernationSupported_Strict(t *testing.T) {
+	instanceStoreOnly := ec2types.InstanceTypeInfo{
+		HibernationSupported:     aws.Bool(true),
+		MemoryInfo:               &ec2types.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+		SupportedRootDeviceTypes: []ec2types.RootDeviceType{ec2types.RootDeviceTypeInstanceStore},
+		EbsInfo:                  &ec2types.EbsInfo{EncryptionSupport: ec2types.EbsEncryptionSupportSupported},
+	}
+	h.Assert(t, aws.ToBool(isHibernationSupported(&instanceStoreOnly, false)), "Hibernation should be supported without the strict checks")
+	h.Assert(t, !aws.ToBool(isHibernationSupported(&instanceStoreOnly, true)), "Hibernation should NOT be supported without EBS root volumes")
+
+	unencrypted := ec2types.InstanceTypeInfo{
+		HibernationSupported:     aws.Bool(true),
+		MemoryInfo:               &ec2types.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+		SupportedRootDeviceTypes: []ec2types.RootDeviceType{ec2types.RootDeviceTypeEbs},
+		EbsInfo:                  &ec2types.EbsInfo{EncryptionSupport: ec2types.EbsEncryptionSupportUnsupported},
+	}
+	h.Assert(t, !aws.ToBool(isHibernationSupported(&unencrypted, true)), "Hibernation should NOT be supported without EBS encryption")
+}
+
+func TestIsSupportedWithSetInt32(t *testing.T) {
+	target := Int32SetFilter{2, 4, 8}
+	h.Assert(t, isSupportedWithSetInt32(aws.Int32(4), &target), "4 should be in the set")
+	h.Assert(t, !isSupportedWithSetInt32(aws.Int32(6), &target), "6 should NOT be in the set")
+	h.Assert(t, !isSupportedWithSetInt32(nil, &target), "a missing spec should NOT be in a set without 0")
+	h.Assert(t, isSupportedWithSetInt32(nil, &Int32SetFilter{0, 1}), "a missing spec should be in a set with 0")
+	h.Assert(t, isSupportedWithSetInt32(aws.Int32(6), nil), "a nil set should support any value")
+}
+
 func TestIsSupportedWithRangeInt_SupportedExact(t *testing.T) {
 	target := IntRangeFilter{LowerBound: 4, UpperBound: 4}
 	isSupported := isSupportedWithRangeInt(aws.Int(4), &target)
@@ -323,3 +376,23 @@ func TestGetNetworkPerformance(t *testing.T) {
 	netPerformance = getNetworkPerformance(aws.String("abcd"))
 	h.Assert(t, *netPerformance == -1, "Networking performance should parse properly when an arbitrary string is passed")
 }
+
+func TestGetEnaQueues(t *testing.T) {
+	dataset, err := loadEnaQueues()
+	h.Ok(t, err)
+	for instanceType, counts := range dataset {
+		h.Assert(t, counts.MaximumPerInterface > 0 && counts.MaximumPerInterface <= counts.Maximum,
+			"%s should have no more ENA queues per interface than in total", instanceType)
+	}
+
+	perInterface, total := getEnaQueues("c5.large", &ec2types.NetworkInfo{EnaSupport: ec2types.EnaSupportRequired})
+	h.Equals(t, int32(2), *perInterface)
+	h.Equals(t, int32(6), *total)
+
+	perInterface, total = getEnaQueues("c4.large", &ec2types.NetworkInfo{EnaSupport: ec2types.EnaSupportUnsupported})
+	h.Equals(t, int32(0), *perInterface)
+	h.Equals(t, int32(0), *total)
+
+	perInterface, total = getEnaQueues("m7i.large", &ec2types.NetworkInfo{EnaSupport: ec2types.EnaSupportRequired})
+	h.Assert(t, perInterface == nil && total == nil, "Instance types with ENA support which are not in the dataset should have unknown ENA queue counts")
+}
@@ -0,0 +1,82 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/mitchellh/go-homedir"
+)
+
+const previousGenerationDeprecation = "previous generation"
+
+// deprecatedFamilies are the families listed on the EC2 previous generation instances page
+// https://aws.amazon.com/ec2/previous-generation/ keyed to the reason they are deprecated.
+// The EC2 API only returns whether an instance type is the current generation, which does not capture announced deprecations.
+var deprecatedFamilies = map[string]string{
+	"c1":  previousGenerationDeprecation,
+	"c3":  previousGenerationDeprecation,
+	"cc2": previousGenerationDeprecation,
+	"cg1": previousGenerationDeprecation,
+	"cr1": previousGenerationDeprecation,
+	"g2":  previousGenerationDeprecation,
+	"hi1": previousGenerationDeprecation,
+	"hs1": previousGenerationDeprecation,
+	"i2":  previousGenerationDeprecation,
+	"m1":  previousGenerationDeprecation,
+	"m2":  previousGenerationDeprecation,
+	"m3":  previousGenerationDeprecation,
+	"r3":  previousGenerationDeprecation,
+	"t1":  previousGenerationDeprecation,
+}
+
+// LoadDeprecations loads deprecations from a JSON file which are applied on top of the built-in previous generation families.
+// The file must contain a JSON object of instance type names or families to the reason they are deprecated
+// (Example: {"m4": "internal EOL 2025-06", "c4.large": "retiring"}). An empty reason removes a built-in deprecation.
+func LoadDeprecations(path string) (map[string]string, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand deprecations file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read deprecations file %s: %w", expandedPath, err)
+	}
+	deprecations := map[string]string{}
+	if err := json.Unmarshal(data, &deprecations); err != nil {
+		return nil, fmt.Errorf("unable to parse deprecations file %s: %w", expandedPath, err)
+	}
+	return deprecations, nil
+}
+
+// getDeprecation returns the reason an instance type is deprecated or nil if it is not deprecated.
+// Deprecations of the instance type take precedence over deprecations of its family, and deprecations
+// loaded from a file take precedence over the built-in previous generation families.
+func getDeprecation(instanceType ec2types.InstanceType, deprecations map[string]string) *string {
+	family, _, _ := strings.Cut(string(instanceType), ".")
+	for _, reasons := range []map[string]string{deprecations, deprecatedFamilies} {
+		for _, key := range []string{string(instanceType), family} {
+			if reason, ok := reasons[key]; ok {
+				if reason == "" {
+					return nil
+				}
+				return &reason
+			}
+		}
+	}
+	return nil
+}
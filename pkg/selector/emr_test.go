@@ -13,6 +13,7 @@
 package selector_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
@@ -153,6 +154,40 @@ func TestFilters_Version5_8_0(t *testing.T) {
 	h.Assert(t, !contains(*transformedFilters.InstanceTypes, "i3.xlarge"), "emr version 5.8.0 should not include i3.xlarge")
 }
 
+func TestFilters_Version7_0_0(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.Register("emr", &selector.EMR{})
+
+	emrWithVersion := "emr-" + "7.0.0"
+	filters := selector.Filters{
+		Service: &emrWithVersion,
+	}
+	transformedFilters, err := registry.ExecuteTransforms(filters)
+	h.Ok(t, err)
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "m6gd.xlarge"), "emr version 7.0.0 should include m6gd.xlarge")
+
+	emrWithPatchVersion := "emr-" + "6.15.1"
+	filters.Service = &emrWithPatchVersion
+	patchFilters, err := registry.ExecuteTransforms(filters)
+	h.Ok(t, err)
+	h.Equals(t, *transformedFilters.InstanceTypes, *patchFilters.InstanceTypes)
+}
+
+func TestFilters_UnsupportedVersion(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.Register("emr", &selector.EMR{})
+
+	for _, version := range []string{"3.11.0", "5.37.0", "99.0.0"} {
+		emrWithVersion := "emr-" + version
+		filters := selector.Filters{
+			Service: &emrWithVersion,
+		}
+		_, err := registry.ExecuteTransforms(filters)
+		h.Nok(t, err)
+		h.Assert(t, strings.Contains(err.Error(), "5.0-5.36"), "the error should list the supported release labels but got %v", err)
+	}
+}
+
 func contains(arr []string, input string) bool {
 	for _, entry := range arr {
 		if entry == input {
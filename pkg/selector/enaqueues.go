@@ -0,0 +1,63 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// enaQueuesDataset is generated by scripts/update-ena-queues with the AWS CLI. DescribeInstanceTypes returns the ENA
+// queue counts of each network card (MaximumEnaQueueCount), but the version of the EC2 SDK the selector is built against
+// does not model them, so they are bundled for DPDK and NFV workloads which size their queues.
+//
+//go:embed data/ena_queues.json
+var enaQueuesDataset []byte
+
+// enaQueueCounts are the maximum ENA queues of an instance type.
+type enaQueueCounts struct {
+	MaximumPerInterface int32 `json:"maximumEnaQueueCountPerInterface"`
+	Maximum             int32 `json:"maximumEnaQueueCount"`
+}
+
+// loadEnaQueues parses the bundled dataset once, it is checked by the unit tests so it is not expected to fail.
+var loadEnaQueues = sync.OnceValues(func() (map[ec2types.InstanceType]enaQueueCounts, error) {
+	dataset := map[ec2types.InstanceType]enaQueueCounts{}
+	if err := json.Unmarshal(enaQueuesDataset, &dataset); err != nil {
+		return nil, fmt.Errorf("unable to parse the ENA queues dataset: %w", err)
+	}
+	return dataset, nil
+})
+
+// getEnaQueues returns the maximum number of ENA queues of each network interface and across all of the network
+// interfaces of the instance type. Instance types which do not support ENA have none, nil is returned for the other
+// instance types which are not in the bundled dataset since their counts are unknown.
+func getEnaQueues(instanceType ec2types.InstanceType, networkInfo *ec2types.NetworkInfo) (*int32, *int32) {
+	if networkInfo != nil && networkInfo.EnaSupport == ec2types.EnaSupportUnsupported {
+		return aws.Int32(0), aws.Int32(0)
+	}
+	dataset, err := loadEnaQueues()
+	if err != nil {
+		return nil, nil
+	}
+	counts, ok := dataset[instanceType]
+	if !ok {
+		return nil, nil
+	}
+	return &counts.MaximumPerInterface, &counts.Maximum
+}
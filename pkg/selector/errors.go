@@ -0,0 +1,81 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The errors returned by the selector are wrapped with errors.Is compatible sentinels for the common classes of failures,
+// so that callers can branch on them without matching the error message.
+var (
+	// ErrNotFound is returned when a lookup which the filters depend on, such as the base instance type, the launch template,
+	// or its AMI, does not find it. Filtering returns an empty list, not an error, when no instance types match.
+	ErrNotFound = errors.New("not found")
+	// ErrRegionResolution is returned when a location cannot be resolved to a region, zone, or Outpost, or the zones of the
+	// region cannot be described.
+	ErrRegionResolution = errors.New("unable to resolve location")
+	// ErrPricingUnavailable is returned when prices are filtered on but the pricing cache they are looked up in is empty,
+	// or the pricing caches cannot be refreshed.
+	ErrPricingUnavailable = errors.New("pricing unavailable")
+	// ErrInvalidFilterCombination is returned when a filter is set which depends on another filter which is not set.
+	ErrInvalidFilterCombination = errors.New("invalid filter combination")
+)
+
+// APIError wraps an error returned by an AWS API with the name of the API which failed.
+type APIError struct {
+	// API is the name of the failing API, e.g. DescribeInstanceTypes
+	API string
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.API, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func newAPIError(api string, err error) error {
+	return &APIError{API: api, Err: err}
+}
+
+// PricingCaches is the Cache of a CacheError returned when the on-demand or spot pricing caches of an EC2PricingIface
+// which saves them together fail to save. Those which implement ec2pricing.CacheSaver return OnDemandPricingCache and
+// SpotPricingCache CacheErrors instead.
+const PricingCaches = "pricing"
+
+// CacheError wraps an error saving a cache with the name of the cache which failed. Selector.Save returns one for each
+// cache which failed to save, they can be split with multierr.Errors and matched with errors.As.
+type CacheError struct {
+	// Cache is the name of the failing cache, e.g. OnDemandPricingCache, SpotPricingCache, InstanceTypesCache, OfferingsCache, or PricingCaches
+	Cache string
+	Err   error
+}
+
+func (e *CacheError) Error() string {
+	return fmt.Sprintf("unable to save the %s cache: %v", e.Cache, e.Err)
+}
+
+func (e *CacheError) Unwrap() error {
+	return e.Err
+}
+
+func newCacheError(cache string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CacheError{Cache: cache, Err: err}
+}
@@ -0,0 +1,43 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// hibernationMaxMemoryMiB is the RAM instances can hibernate with, they must have less than 150 GiB. The hibernation
+// prerequisites are sourced from https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/hibernating-prerequisites.html
+const hibernationMaxMemoryMiB = 150 * 1024
+
+// isHibernationSupported returns true if instances of the instance type can hibernate, which requires the instance type to
+// support hibernation and to have less than 150 GiB of RAM. The strict checks also require EBS root volumes and EBS
+// encryption to be supported, since the RAM is saved to the encrypted EBS root volume.
+func isHibernationSupported(instanceTypeInfo *ec2types.InstanceTypeInfo, strict bool) *bool {
+	if !aws.ToBool(instanceTypeInfo.HibernationSupported) {
+		return aws.Bool(false)
+	}
+	if instanceTypeInfo.MemoryInfo == nil || aws.ToInt64(instanceTypeInfo.MemoryInfo.SizeInMiB) >= hibernationMaxMemoryMiB {
+		return aws.Bool(false)
+	}
+	if !strict {
+		return aws.Bool(true)
+	}
+	if !slices.Contains(instanceTypeInfo.SupportedRootDeviceTypes, ec2types.RootDeviceTypeEbs) {
+		return aws.Bool(false)
+	}
+	return aws.Bool(instanceTypeInfo.EbsInfo != nil && instanceTypeInfo.EbsInfo.EncryptionSupport == ec2types.EbsEncryptionSupportSupported)
+}
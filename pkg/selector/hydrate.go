@@ -0,0 +1,163 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// Caches which can be hydrated by HydrateCaches.
+const (
+	OnDemandPricingCache = "on-demand-pricing"
+	SpotPricingCache     = "spot-pricing"
+	InstanceTypesCache   = "instance-types"
+	OfferingsCache       = "instance-type-offerings"
+)
+
+// HydrateEvent is the stage of hydrating a cache reported to a HydrateProgressFn.
+type HydrateEvent string
+
+// Hydrate events in the order they are reported for each cache, a cache reports either HydrateCompleted or HydrateFailed
+// after HydrateStarted or only HydrateSkipped if it was already populated.
+const (
+	HydrateStarted   HydrateEvent = "started"
+	HydrateCompleted HydrateEvent = "completed"
+	HydrateFailed    HydrateEvent = "failed"
+	HydrateSkipped   HydrateEvent = "skipped"
+)
+
+// HydrateOptions configures which caches HydrateCaches hydrates.
+type HydrateOptions struct {
+	// Caches are the caches to hydrate (OnDemandPricingCache, SpotPricingCache, InstanceTypesCache, OfferingsCache), all of them if empty
+	Caches []string
+	// SpotPricingDaysBack is the number of days of spot price history to retrieve, 0 retrieves the last price
+	SpotPricingDaysBack int
+	// Refresh hydrates caches which are already populated rather than skipping them, the instance types and offerings
+	// are only retrieved again once they expire
+	Refresh bool
+}
+
+// HydrateProgress is the progress of hydrating a single cache.
+type HydrateProgress struct {
+	Cache string
+	Event HydrateEvent
+	// Count is the number of entries in the cache once it was hydrated or skipped
+	Count int
+	// Elapsed is the time spent hydrating the cache, it is only set for HydrateCompleted and HydrateFailed
+	Elapsed time.Duration
+	// Err is the reason the cache failed to hydrate, it is only set for HydrateFailed
+	Err error
+}
+
+// HydrateProgressFn is called with the progress of each cache. Calls are serialized so the function does not need to be
+// safe for concurrent use even though caches are hydrated in parallel.
+type HydrateProgressFn func(progress HydrateProgress)
+
+// hydrateTask hydrates a single cache and reports its size.
+type hydrateTask struct {
+	cache   string
+	count   func() int
+	hydrate func(ctx context.Context) error
+}
+
+// HydrateCaches hydrates the pricing and instance type caches in parallel so that subsequent filtering does not wait on them.
+// The progress of each cache is passed to progressFn if it is not nil. Failures of individual caches do not stop the others
+// from hydrating, they are combined into the returned error.
+func (s Selector) HydrateCaches(ctx context.Context, opts HydrateOptions, progressFn HydrateProgressFn) error {
+	offeringsProvider := s.getOfferingsProvider()
+	tasks := []hydrateTask{
+		{
+			cache:   OnDemandPricingCache,
+			count:   s.EC2Pricing.OnDemandCacheCount,
+			hydrate: s.EC2Pricing.RefreshOnDemandCache,
+		},
+		{
+			cache: SpotPricingCache,
+			count: s.EC2Pricing.SpotCacheCount,
+			hydrate: func(ctx context.Context) error {
+				return s.EC2Pricing.RefreshSpotCache(ctx, opts.SpotPricingDaysBack)
+			},
+		},
+		{
+			cache: InstanceTypesCache,
+			count: s.instanceTypesProvider().CacheCount,
+			hydrate: func(ctx context.Context) error {
+				_, err := s.instanceTypesProvider().Get(ctx, nil)
+				return err
+			},
+		},
+		{
+			// the zones each instance type is offered in, which are displayed by AddOfferedZones
+			cache: OfferingsCache,
+			count: offeringsProvider.CacheCount,
+			hydrate: func(ctx context.Context) error {
+				_, err := offeringsProvider.Get(ctx, zoneNameLocationType, "")
+				return err
+			},
+		},
+	}
+	for _, cache := range opts.Caches {
+		if !slices.ContainsFunc(tasks, func(task hydrateTask) bool { return task.cache == cache }) {
+			return fmt.Errorf("unable to hydrate unknown cache %s", cache)
+		}
+	}
+
+	progressMu := &sync.Mutex{}
+	report := func(progress HydrateProgress) {
+		if progressFn == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progressFn(progress)
+	}
+
+	var errs error
+	errsMu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for _, task := range tasks {
+		if len(opts.Caches) > 0 && !slices.Contains(opts.Caches, task.cache) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !opts.Refresh && task.count() > 0 {
+				report(HydrateProgress{Cache: task.cache, Event: HydrateSkipped, Count: task.count()})
+				return
+			}
+			report(HydrateProgress{Cache: task.cache, Event: HydrateStarted})
+			start := time.Now()
+			if err := task.hydrate(ctx); err != nil {
+				err = fmt.Errorf("there was a problem refreshing the %s cache: %w", task.cache, err)
+				if task.cache == OnDemandPricingCache || task.cache == SpotPricingCache {
+					err = fmt.Errorf("%w: %w", ErrPricingUnavailable, err)
+				}
+				errsMu.Lock()
+				errs = multierr.Append(errs, err)
+				errsMu.Unlock()
+				report(HydrateProgress{Cache: task.cache, Event: HydrateFailed, Elapsed: time.Since(start), Err: err})
+				return
+			}
+			report(HydrateProgress{Cache: task.cache, Event: HydrateCompleted, Count: task.count(), Elapsed: time.Since(start)})
+		}()
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,69 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestHydrateCaches(t *testing.T) {
+	itf := getSelector(mockedEC2{})
+	itf.EC2Pricing = &ec2PricingMock{
+		onDemandCacheCount:  5,
+		RefreshSpotCacheErr: errors.New("throttled"),
+	}
+	itf.CustomInstanceTypesProvider = &staticInstanceTypesProvider{}
+
+	events := map[string][]selector.HydrateEvent{}
+	err := itf.HydrateCaches(context.Background(), selector.HydrateOptions{}, func(progress selector.HydrateProgress) {
+		events[progress.Cache] = append(events[progress.Cache], progress.Event)
+		if progress.Event == selector.HydrateFailed {
+			h.Nok(t, progress.Err)
+		}
+		if progress.Event == selector.HydrateSkipped {
+			h.Equals(t, 5, progress.Count)
+		}
+	})
+	h.Assert(t, errors.Is(err, selector.ErrPricingUnavailable), "Should return ErrPricingUnavailable when a pricing cache fails to refresh")
+	h.Equals(t, []selector.HydrateEvent{selector.HydrateSkipped}, events[selector.OnDemandPricingCache])
+	h.Equals(t, []selector.HydrateEvent{selector.HydrateStarted, selector.HydrateFailed}, events[selector.SpotPricingCache])
+	h.Equals(t, []selector.HydrateEvent{selector.HydrateStarted, selector.HydrateCompleted}, events[selector.InstanceTypesCache])
+	h.Equals(t, []selector.HydrateEvent{selector.HydrateStarted, selector.HydrateCompleted}, events[selector.OfferingsCache])
+}
+
+func TestHydrateCaches_Options(t *testing.T) {
+	itf := getSelector(mockedEC2{})
+	itf.EC2Pricing = &ec2PricingMock{onDemandCacheCount: 5}
+	itf.CustomInstanceTypesProvider = &staticInstanceTypesProvider{}
+
+	events := map[string][]selector.HydrateEvent{}
+	opts := selector.HydrateOptions{Caches: []string{selector.OnDemandPricingCache}, Refresh: true}
+	err := itf.HydrateCaches(context.Background(), opts, func(progress selector.HydrateProgress) {
+		events[progress.Cache] = append(events[progress.Cache], progress.Event)
+	})
+	h.Ok(t, err)
+	h.Equals(t, map[string][]selector.HydrateEvent{
+		selector.OnDemandPricingCache: {selector.HydrateStarted, selector.HydrateCompleted},
+	}, events)
+
+	// a nil progress function is allowed
+	h.Ok(t, itf.HydrateCaches(context.Background(), selector.HydrateOptions{}, nil))
+
+	err = itf.HydrateCaches(context.Background(), selector.HydrateOptions{Caches: []string{"reserved-pricing"}}, nil)
+	h.Nok(t, err)
+}
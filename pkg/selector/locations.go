@@ -0,0 +1,153 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// Region is an AWS Region which is enabled for the account.
+type Region struct {
+	RegionName string
+	// OptInStatus is opt-in-not-required for regions enabled by default or opted-in for regions which were enabled
+	OptInStatus string
+}
+
+// Zone is an availability zone, Local Zone, or Wavelength Zone of a region.
+type Zone struct {
+	ZoneName string
+	ZoneID   string `json:"ZoneId"`
+	// ZoneType is availability-zone, local-zone, or wavelength-zone
+	ZoneType string
+	// OptInStatus is opt-in-not-required for availability zones, Local Zones and Wavelength Zones are not-opted-in until opted in to
+	OptInStatus string
+}
+
+// Regions returns the regions enabled for the account sorted by name. The EC2 client of the selector must implement
+// awsapi.RegionsDescriber.
+func (s Selector) Regions(ctx context.Context) ([]Region, error) {
+	regionsDescriber, ok := s.EC2.(awsapi.RegionsDescriber)
+	if !ok {
+		return nil, fmt.Errorf("unable to describe regions: the EC2 client does not implement DescribeRegions")
+	}
+	output, err := regionsDescriber.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe regions: %w", err)
+	}
+	regions := []Region{}
+	for _, region := range output.Regions {
+		regions = append(regions, Region{
+			RegionName:  aws.ToString(region.RegionName),
+			OptInStatus: aws.ToString(region.OptInStatus),
+		})
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].RegionName < regions[j].RegionName })
+	return regions, nil
+}
+
+// Zones returns all of the zones of the selector's region sorted by name, including Local Zones and Wavelength Zones which
+// have not been opted in to.
+func (s Selector) Zones(ctx context.Context) ([]Zone, error) {
+	output, err := s.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{AllAvailabilityZones: aws.Bool(true)})
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe availability zones: %w", err)
+	}
+	zones := []Zone{}
+	for _, zone := range output.AvailabilityZones {
+		zones = append(zones, Zone{
+			ZoneName:    aws.ToString(zone.ZoneName),
+			ZoneID:      aws.ToString(zone.ZoneId),
+			ZoneType:    aws.ToString(zone.ZoneType),
+			OptInStatus: string(zone.OptInStatus),
+		})
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ZoneName < zones[j].ZoneName })
+	return zones, nil
+}
+
+// RegionalSelectorFn creates the Selector used to filter instance types in a region.
+type RegionalSelectorFn func(ctx context.Context, region string) (*Selector, error)
+
+// FilterRegionsVerbose filters the instance types matching any of the groups of filterSet in each of the regions, with the
+// Selector newSelector creates for the region, and returns the instance types of all of the regions with their Region
+// populated sorted by region and then name, up to filterSet.MaxResults across all of the regions. Regions are filtered concurrently, the instance types of the regions which
+// could be filtered are returned along with an error for each region which could not be.
+// Availability zones are specific to a region so they can not be filtered on.
+func FilterRegionsVerbose(ctx context.Context, regions []string, newSelector RegionalSelectorFn, filterSet FilterSet) ([]*instancetypes.Details, error) {
+	for _, filters := range filterSet.Groups {
+		if filters.AvailabilityZones != nil {
+			return nil, fmt.Errorf("%w: availability zones can not be filtered on across regions", ErrInvalidFilterCombination)
+		}
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs error
+	regionInstanceTypes := map[string][]*instancetypes.Details{}
+	for _, region := range regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			instanceTypes, err := filterRegion(ctx, region, newSelector, filterSet)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s: %w", region, err))
+				return
+			}
+			regionInstanceTypes[region] = instanceTypes
+		}(region)
+	}
+	wg.Wait()
+
+	sortedRegions := append([]string{}, regions...)
+	sort.Strings(sortedRegions)
+	instanceTypeInfoSlice := []*instancetypes.Details{}
+	for _, region := range sortedRegions {
+		instanceTypeInfoSlice = append(instanceTypeInfoSlice, regionInstanceTypes[region]...)
+	}
+	if filterSet.MaxResults != nil && *filterSet.MaxResults < len(instanceTypeInfoSlice) {
+		instanceTypeInfoSlice = instanceTypeInfoSlice[:*filterSet.MaxResults]
+	}
+	return instanceTypeInfoSlice, errs
+}
+
+// filterRegion filters the instance types of a region with all of the groups of filterSet limited to the region.
+func filterRegion(ctx context.Context, region string, newSelector RegionalSelectorFn, filterSet FilterSet) ([]*instancetypes.Details, error) {
+	regionalSelector, err := newSelector(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	regionalFilterSet := FilterSet{}
+	for _, filters := range filterSet.Groups {
+		filters.Region = aws.String(region)
+		regionalFilterSet.Groups = append(regionalFilterSet.Groups, filters)
+	}
+	instanceTypes, err := regionalSelector.FilterGroupsVerbose(ctx, regionalFilterSet)
+	if err != nil {
+		return nil, err
+	}
+	for _, instanceType := range instanceTypes {
+		instanceType.Region = region
+	}
+	return instanceTypes, nil
+}
@@ -0,0 +1,101 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestRegions(t *testing.T) {
+	itf := getSelector(mockedEC2{
+		DescribeRegionsResp: ec2.DescribeRegionsOutput{
+			Regions: []ec2types.Region{
+				{RegionName: aws.String("us-west-2"), OptInStatus: aws.String("opt-in-not-required")},
+				{RegionName: aws.String("ap-east-1"), OptInStatus: aws.String("opted-in")},
+			},
+		},
+	})
+	regions, err := itf.Regions(context.Background())
+	h.Ok(t, err)
+	h.Equals(t, []selector.Region{
+		{RegionName: "ap-east-1", OptInStatus: "opted-in"},
+		{RegionName: "us-west-2", OptInStatus: "opt-in-not-required"},
+	}, regions)
+
+	_, err = getSelector(mockedEC2{DescribeRegionsErr: errors.New("access denied")}).Regions(context.Background())
+	h.Nok(t, err)
+
+	// EC2 clients which only implement awsapi.SelectorInterface can not describe regions
+	itf.EC2 = struct{ awsapi.SelectorInterface }{mockedEC2{}}
+	_, err = itf.Regions(context.Background())
+	h.Nok(t, err)
+}
+
+func TestZones(t *testing.T) {
+	ec2Mock := setupMock(t, describeAvailabilityZones, "us-east-2.json")
+	ec2Mock.DescribeAvailabilityZonesResp.AvailabilityZones = append(ec2Mock.DescribeAvailabilityZonesResp.AvailabilityZones, ec2types.AvailabilityZone{
+		RegionName:  aws.String("us-east-2"),
+		ZoneName:    aws.String("us-east-2-chi-2a"),
+		ZoneId:      aws.String("use2-chi2-az1"),
+		ZoneType:    aws.String("local-zone"),
+		OptInStatus: ec2types.AvailabilityZoneOptInStatusNotOptedIn,
+	})
+	zones, err := getSelector(ec2Mock).Zones(context.Background())
+	h.Ok(t, err)
+	h.Equals(t, 4, len(zones))
+	h.Equals(t, selector.Zone{ZoneName: "us-east-2-chi-2a", ZoneID: "use2-chi2-az1", ZoneType: "local-zone", OptInStatus: "not-opted-in"}, zones[0])
+	h.Equals(t, "us-east-2a", zones[1].ZoneName)
+	h.Equals(t, "use2-az1", zones[1].ZoneID)
+}
+
+func TestFilterRegionsVerbose(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "t3_micro.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+		DescribeAvailabilityZonesResp:     setupMock(t, describeAvailabilityZones, "us-east-2.json").DescribeAvailabilityZonesResp,
+	}
+	newSelector := func(ctx context.Context, region string) (*selector.Selector, error) {
+		if region == "eu-west-1" {
+			return nil, errors.New("unable to load credentials")
+		}
+		itf := getSelector(ec2Mock)
+		return &itf, nil
+	}
+	filters := selector.Filters{
+		VCpusRange: &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	ctx := context.Background()
+	results, err := selector.FilterRegionsVerbose(ctx, []string{"us-east-2"}, newSelector, selector.FilterSet{Groups: []selector.Filters{filters}})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, "t3.micro", string(results[0].InstanceType))
+	h.Equals(t, "us-east-2", results[0].Region)
+
+	results, err = selector.FilterRegionsVerbose(ctx, []string{"us-east-2", "eu-west-1"}, newSelector, selector.FilterSet{Groups: []selector.Filters{filters}})
+	h.Nok(t, err)
+	h.Equals(t, 1, len(results))
+
+	filters.AvailabilityZones = &[]string{"us-east-2a"}
+	_, err = selector.FilterRegionsVerbose(ctx, []string{"us-east-2"}, newSelector, selector.FilterSet{Groups: []selector.Filters{filters}})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "availability zones should not be filtered on across regions")
+}
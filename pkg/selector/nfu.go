@@ -0,0 +1,67 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// The normalization factors of the instance sizes are the ones Reserved Instances use to apply size flexibly, see
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/apply_ri.html
+var (
+	// normalizationFactorBySize holds the normalization factors of the sizes which are not a multiple of xlarge
+	normalizationFactorBySize = map[string]float64{
+		"nano":   0.25,
+		"micro":  0.5,
+		"small":  1,
+		"medium": 2,
+		"large":  4,
+		"xlarge": 8,
+	}
+	// xlargeMultipleRegex matches sizes which are a multiple of xlarge (i.e. 12xlarge) and metal sizes named after the
+	// xlarge size they are equivalent to (i.e. metal-24xl)
+	xlargeMultipleRegex = regexp.MustCompile(`^(?:metal-)?([0-9]+)xl(?:arge)?$`)
+)
+
+// normalizationFactorPerXlarge is the normalization factor of each multiple of xlarge.
+const normalizationFactorPerXlarge = 8
+
+// getNormalizationFactor returns the normalization factor units (NFU) of the instance type derived from its size, which
+// finance and Reserved Instance planning reconcile usage in. The metal size is equivalent to the largest size of its
+// family, which is approximated from the vCPUs since an xlarge has 4 vCPUs in most families. nil is returned for sizes
+// which do not have a normalization factor.
+func getNormalizationFactor(instanceTypeInfo *ec2types.InstanceTypeInfo) *float64 {
+	_, size, ok := strings.Cut(string(instanceTypeInfo.InstanceType), ".")
+	if !ok {
+		return nil
+	}
+	if normalizationFactor, ok := normalizationFactorBySize[size]; ok {
+		return aws.Float64(normalizationFactor)
+	}
+	if matches := xlargeMultipleRegex.FindStringSubmatch(size); matches != nil {
+		multiple, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil
+		}
+		return aws.Float64(float64(multiple * normalizationFactorPerXlarge))
+	}
+	if size == "metal" && instanceTypeInfo.VCpuInfo != nil && instanceTypeInfo.VCpuInfo.DefaultVCpus != nil {
+		return aws.Float64(float64(*instanceTypeInfo.VCpuInfo.DefaultVCpus) * normalizationFactorPerXlarge / 4)
+	}
+	return nil
+}
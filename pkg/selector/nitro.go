@@ -0,0 +1,103 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// The EC2 API does not return the generation of the Nitro cards an instance type is built on, so it is derived from the
+// instance family. Families are mapped to the Nitro generation of their launch era which is an approximation for families
+// that received newer Nitro cards later on.
+var (
+	// nitroGenerationByInstanceGeneration holds the Nitro generation of the mainstream families of each instance generation (i.e. m6i is 6)
+	nitroGenerationByInstanceGeneration = map[int]int{
+		5: 3,
+		6: 4,
+		7: 5,
+		8: 5,
+	}
+
+	// nitroGenerationByFamily holds the Nitro generation of families which are not named after the instance generation
+	// they were launched in or which launched with a different Nitro generation than the mainstream families
+	nitroGenerationByFamily = map[string]int{
+		"a1":     3,
+		"t3":     3,
+		"t3a":    3,
+		"z1d":    3,
+		"i3en":   3,
+		"p3dn":   3,
+		"g4dn":   3,
+		"g4ad":   3,
+		"inf1":   3,
+		"mac1":   3,
+		"t4g":    4,
+		"d3":     4,
+		"d3en":   4,
+		"dl1":    4,
+		"i4i":    4,
+		"i4g":    4,
+		"im4gn":  4,
+		"is4gen": 4,
+		"inf2":   4,
+		"p4d":    4,
+		"p4de":   4,
+		"trn1":   4,
+		"trn1n":  4,
+		"vt1":    4,
+		"mac2":   4,
+		"x2gd":   4,
+		"x2idn":  4,
+		"x2iedn": 4,
+		"x2iezn": 4,
+		"p5":     5,
+		"p5e":    5,
+		"trn2":   5,
+		"c8gn":   6,
+	}
+)
+
+// getNitroGeneration returns the generation of the Nitro cards the instance type is built on.
+// 0 is returned for Xen instance types and nil is returned if the Nitro generation of the family is unknown.
+func getNitroGeneration(instanceTypeInfo *ec2types.InstanceTypeInfo) *int {
+	if instanceTypeInfo.Hypervisor == ec2types.InstanceTypeHypervisorXen {
+		return aws.Int(0)
+	}
+	if instanceTypeInfo.Hypervisor != ec2types.InstanceTypeHypervisorNitro && !aws.ToBool(instanceTypeInfo.BareMetal) {
+		return nil
+	}
+	family, _, _ := strings.Cut(string(instanceTypeInfo.InstanceType), ".")
+	// high memory instance families are named by their memory (i.e. u-6tb1)
+	if strings.HasPrefix(family, "u-") {
+		return aws.Int(4)
+	}
+	if nitroGeneration, ok := nitroGenerationByFamily[family]; ok {
+		return &nitroGeneration
+	}
+	if nitroGeneration, ok := nitroGenerationByInstanceGeneration[*getInstanceTypeGeneration(family)]; ok {
+		return &nitroGeneration
+	}
+	return nil
+}
+
+// isIPv6OnlySubnetCapable returns true if the instance type can be launched in an IPv6-only subnet.
+// The EC2 API does not return this attribute, IPv6-only subnets are supported by instance types built on the Nitro System which support IPv6.
+func isIPv6OnlySubnetCapable(instanceTypeInfo *ec2types.InstanceTypeInfo) *bool {
+	if instanceTypeInfo.NetworkInfo == nil || !aws.ToBool(instanceTypeInfo.NetworkInfo.Ipv6Supported) {
+		return aws.Bool(false)
+	}
+	return aws.Bool(instanceTypeInfo.Hypervisor == ec2types.InstanceTypeHypervisorNitro || aws.ToBool(instanceTypeInfo.BareMetal))
+}
@@ -0,0 +1,64 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"reflect"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// filterAliases are the equivalent values of enum filters keyed by the type of the filter, each alias is replaced by
+// the value the EC2 API uses.
+var filterAliases = map[reflect.Type]map[string]string{
+	reflect.TypeOf(ec2types.ArchitectureType("")): {
+		string(ArchitectureTypeAMD64): string(ec2types.ArchitectureTypeX8664),
+	},
+	reflect.TypeOf(ec2types.VirtualizationType("")): {
+		string(VirtualizationTypePv): string(ec2types.VirtualizationTypeParavirtual),
+	},
+}
+
+// NormalizeFilters returns a copy of filters whose enum filters (Example: CPUArchitecture) are trimmed, lower cased and
+// have their aliases replaced by the values the EC2 API uses, so amd64 matches x86_64 and pv matches paravirtual.
+// The values filters point to are not modified.
+func NormalizeFilters(filters Filters) Filters {
+	filtersValue := reflect.ValueOf(&filters).Elem()
+	for i := 0; i < filtersValue.NumField(); i++ {
+		field := filtersValue.Field(i)
+		if field.Kind() != reflect.Ptr || field.IsNil() || !isEnumType(field.Type().Elem()) {
+			continue
+		}
+		value := strings.ToLower(strings.TrimSpace(field.Elem().String()))
+		if alias, ok := filterAliases[field.Type().Elem()][value]; ok {
+			value = alias
+		}
+		if value == field.Elem().String() {
+			continue
+		}
+		normalized := reflect.New(field.Type().Elem())
+		normalized.Elem().SetString(value)
+		field.Set(normalized)
+	}
+	return filters
+}
+
+// isEnumType returns true for string types which list their known values like the EC2 API enums and CPUManufacturer.
+func isEnumType(t reflect.Type) bool {
+	if t.Kind() != reflect.String {
+		return false
+	}
+	_, ok := t.MethodByName("Values")
+	return ok
+}
@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestNormalizeFilters(t *testing.T) {
+	cpuArchitecture := selector.ArchitectureTypeAMD64
+	virtualizationType := ec2types.VirtualizationType(" PV ")
+	cpuManufacturer := selector.CPUManufacturer("Intel")
+	filters := selector.Filters{
+		CPUArchitecture:    &cpuArchitecture,
+		VirtualizationType: &virtualizationType,
+		CPUManufacturer:    &cpuManufacturer,
+		GPUManufacturer:    aws.String("NVIDIA"),
+	}
+	normalized := selector.NormalizeFilters(filters)
+	h.Equals(t, ec2types.ArchitectureTypeX8664, *normalized.CPUArchitecture)
+	h.Equals(t, ec2types.VirtualizationTypeParavirtual, *normalized.VirtualizationType)
+	h.Equals(t, selector.CPUManufacturerIntel, *normalized.CPUManufacturer)
+	h.Equals(t, "NVIDIA", *normalized.GPUManufacturer)
+
+	h.Equals(t, selector.ArchitectureTypeAMD64, cpuArchitecture)
+	h.Equals(t, ec2types.VirtualizationType(" PV "), virtualizationType)
+}
+
+func TestFilter_AutoArchitectureRejected(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	cpuArchitecture := ec2types.ArchitectureType("Auto")
+	_, err := itf.Filter(context.Background(), selector.Filters{CPUArchitecture: &cpuArchitecture})
+	h.Nok(t, err)
+}
+
+func TestAggregateFilterTransform_Normalized(t *testing.T) {
+	itf := selector.Selector{ServiceRegistry: selector.NewRegistry()}
+	cpuArchitecture := selector.ArchitectureTypeAMD64
+	filters, err := itf.AggregateFilterTransform(context.Background(), selector.Filters{CPUArchitecture: &cpuArchitecture})
+	h.Ok(t, err)
+	h.Equals(t, ec2types.ArchitectureTypeX8664, *filters.CPUArchitecture)
+}
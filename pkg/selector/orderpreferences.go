@@ -0,0 +1,50 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/mitchellh/go-homedir"
+)
+
+// LoadOrderPreferences loads the instance types to order first from a file of newline-delimited instance type names in
+// order of preference (Example: m7i.large). Empty lines and lines starting with # are skipped.
+func LoadOrderPreferences(path string) ([]ec2types.InstanceType, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand order preference file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read order preference file %s: %w", expandedPath, err)
+	}
+	preferences := []ec2types.InstanceType{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.ContainsAny(line, " \t,") {
+			return nil, fmt.Errorf("unable to parse order preference file %s: line %d must contain a single instance type name", expandedPath, i+1)
+		}
+		preferences = append(preferences, ec2types.InstanceType(line))
+	}
+	if len(preferences) == 0 {
+		return nil, fmt.Errorf("order preference file %s does not contain any instance types", expandedPath)
+	}
+	return preferences, nil
+}
@@ -0,0 +1,129 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
+)
+
+// customColumnsKey is the section of a columns file which maps the custom column names to their json paths.
+const customColumnsKey = "Columns"
+
+// CustomColumn is a column displayed after the built-in columns of the wide table and interactive outputs whose values
+// are looked up with a json path of the instance type like the paths accepted by sorter.Sort (Ex: ".PlacementGroupInfo").
+type CustomColumn struct {
+	Name string
+	Path string
+}
+
+// LoadCustomColumns reads the custom columns of a YAML file with a Columns mapping of column names to json paths
+// (Example: {Columns: {"Max ENIs": .NetworkInfo.MaximumNetworkInterfaces}}), the columns are displayed in the order
+// they are listed in. Errors include the file name and the line of the invalid column.
+func LoadCustomColumns(path string) ([]CustomColumn, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand columns file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read columns file %s: %w", expandedPath, err)
+	}
+	file := struct {
+		Columns yaml.Node `yaml:"Columns"`
+	}{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid columns file %s: %w", expandedPath, err)
+	}
+	customColumns, err := parseCustomColumns(&file.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid columns file %s: %w", expandedPath, err)
+	}
+	return customColumns, nil
+}
+
+// parseCustomColumns returns the custom columns of a mapping of column names to json paths in the order of the mapping.
+func parseCustomColumns(columns *yaml.Node) ([]CustomColumn, error) {
+	if columns.Kind == 0 {
+		return nil, fmt.Errorf("%s must be set to a mapping of column names to json paths", customColumnsKey)
+	}
+	if columns.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: %s must be a mapping of column names to json paths", columns.Line, customColumnsKey)
+	}
+	builtInColumns := map[string]bool{}
+	structType := reflect.TypeOf(wideColumnsData{})
+	for i := 0; i < structType.NumField(); i++ {
+		builtInColumns[structType.Field(i).Tag.Get(columnTag)] = true
+	}
+	customColumns := []CustomColumn{}
+	names := map[string]bool{}
+	for i := 0; i+1 < len(columns.Content); i += 2 {
+		name, path := columns.Content[i], columns.Content[i+1]
+		if name.Value == "" || builtInColumns[name.Value] || names[name.Value] {
+			return nil, fmt.Errorf("line %d: column names must be unique and must not be the name of a built-in column, got %q", name.Line, name.Value)
+		}
+		if path.Kind != yaml.ScalarNode || path.Value == "" {
+			return nil, fmt.Errorf("line %d: the json path of column %s must be a string", path.Line, name.Value)
+		}
+		// paths which can not be looked up fail on any instance type
+		if _, err := sorter.Lookup(&instancetypes.Details{}, path.Value); err != nil {
+			return nil, fmt.Errorf("line %d: invalid json path %s of column %s: %w", path.Line, path.Value, name.Value, err)
+		}
+		names[name.Value] = true
+		customColumns = append(customColumns, CustomColumn{Name: name.Value, Path: path.Value})
+	}
+	return customColumns, nil
+}
+
+// customColumnValues returns the formatted values of the custom columns of the instance type keyed by column name.
+func customColumnValues(instanceType *instancetypes.Details, customColumns []CustomColumn) map[string]string {
+	values := map[string]string{}
+	for _, customColumn := range customColumns {
+		value, err := sorter.Lookup(instanceType, customColumn.Path)
+		if err != nil {
+			value = nil
+		}
+		values[customColumn.Name] = formatCustomColumnValue(value)
+	}
+	return values
+}
+
+// formatCustomColumnValue formats scalar values like the built-in columns and other values as compact JSON.
+func formatCustomColumnValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.String:
+		return reflectValue.String()
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(reflectValue.Float())
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%v", value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
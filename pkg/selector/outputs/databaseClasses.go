@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+const (
+	rdsClassPrefix         = "db."
+	elastiCacheClassPrefix = "cache."
+)
+
+// rdsFamilies are the instance families which RDS and Aurora offer as db. instance classes.
+var rdsFamilies = map[string]bool{
+	"t2": true, "t3": true, "t4g": true,
+	"m4": true, "m5": true, "m5d": true, "m6g": true, "m6gd": true, "m6i": true, "m6id": true, "m6idn": true, "m6in": true, "m7g": true, "m7i": true, "m8g": true,
+	"r4": true, "r5": true, "r5b": true, "r5d": true, "r6g": true, "r6gd": true, "r6i": true, "r6id": true, "r6idn": true, "r6in": true, "r7g": true, "r7i": true, "r8g": true,
+	"c6gd": true, "x1": true, "x1e": true, "x2g": true, "x2idn": true, "x2iedn": true, "z1d": true,
+}
+
+// elastiCacheFamilies are the instance families which ElastiCache offers as cache. node types.
+var elastiCacheFamilies = map[string]bool{
+	"t2": true, "t3": true, "t4g": true,
+	"m4": true, "m5": true, "m6g": true, "m7g": true,
+	"r4": true, "r5": true, "r6g": true, "r6gd": true, "r7g": true,
+	"c7gn": true,
+}
+
+// RDSClassesOutput is an output function which prints the db. instance classes of the instance types whose family
+// RDS offers (Example: db.r6g.large), instance types without an instance class are omitted.
+func RDSClassesOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return databaseClasses(instanceTypeInfoSlice, rdsClassPrefix, rdsFamilies)
+}
+
+// ElastiCacheClassesOutput is an output function which prints the cache. node types of the instance types whose family
+// ElastiCache offers (Example: cache.r6g.large), instance types without a node type are omitted.
+func ElastiCacheClassesOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return databaseClasses(instanceTypeInfoSlice, elastiCacheClassPrefix, elastiCacheFamilies)
+}
+
+// databaseClasses prefixes the names of the instance types in families with prefix, bare metal sizes are not offered.
+func databaseClasses(instanceTypeInfoSlice []*instancetypes.Details, prefix string, families map[string]bool) []string {
+	classes := []string{}
+	for _, instanceType := range instanceTypeInfoSlice {
+		family, size, found := strings.Cut(string(instanceType.InstanceType), ".")
+		if !found || !families[family] || strings.HasPrefix(size, "metal") {
+			continue
+		}
+		classes = append(classes, prefix+string(instanceType.InstanceType))
+	}
+	return classes
+}
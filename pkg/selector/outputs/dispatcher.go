@@ -0,0 +1,163 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// Names of the built-in output formats.
+const (
+	TableFormat              = "table"
+	TableWideFormat          = "table-wide"
+	OneLineFormat            = "one-line"
+	FamiliesOnlyFormat       = "families-only"
+	NDJSONFormat             = "ndjson"
+	JSONFormat               = "json"
+	CSVFormat                = "csv"
+	YAMLFormat               = "yaml"
+	CDKTSFormat              = "cdk-ts"
+	CDKGoFormat              = "cdk-go"
+	RDSClassesFormat         = "rds-classes"
+	ElastiCacheClassesFormat = "elasticache-classes"
+	InteractiveFormat        = "interactive"
+	CompareFormat            = "compare"
+)
+
+// Format is an output format which can be selected by name.
+type Format struct {
+	Name string
+	// Output formats the instance types, extraColumns are the optional columns displayed by the wide table (i.e. EBSColumns)
+	// and customColumns are displayed after them. It is nil for interactive formats which are rendered by the tui package
+	// rather than printed.
+	Output func(instanceTypes []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string
+	// RequiresPrices is true for formats which display both the on-demand and spot prices so both pricing caches must be hydrated
+	RequiresPrices bool
+	// RequiresZones is true for formats which display the availability zones each instance type is offered in
+	RequiresZones bool
+	// Interactive is true for formats which are rendered by the tui package
+	Interactive bool
+	// PerFamily is true for formats which print the instance families rather than the instance types, so the results are
+	// narrowed to FirstOfEachFamily before they are truncated to the maximum number of results
+	PerFamily bool
+	// Extensions are the file extensions (Example: .json) of the files the format is selected for when no format is passed
+	Extensions []string
+	// VerticalOutput formats the instance types like Output with a block of lines for each instance type rather than a
+	// row, it is nil for formats which don't have a vertical layout
+	VerticalOutput func(instanceTypes []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string
+	// StreamOutput writes the lines of Output to w as each one is formatted, it is nil for formats which can only be
+	// formatted once every instance type is known
+	StreamOutput func(w io.Writer, instanceTypes []*instancetypes.Details) error
+}
+
+// UnknownFormatError is returned for output format names which are not registered.
+type UnknownFormatError struct {
+	Format string
+	// Formats are the names of the registered formats
+	Formats []string
+}
+
+func (e *UnknownFormatError) Error() string {
+	return fmt.Sprintf("unknown output format %q, valid formats are: %s", e.Format, strings.Join(e.Formats, ", "))
+}
+
+// Dispatcher looks up output formats by name.
+type Dispatcher struct {
+	formats []Format
+}
+
+// NewDispatcher creates a Dispatcher with the built-in output formats registered.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{formats: []Format{
+		{Name: TableFormat, Output: withoutColumns(TableOutputShort), VerticalOutput: withoutColumns(TableOutputShortVertical)},
+		{Name: TableWideFormat, Output: tableOutputWide, VerticalOutput: tableOutputWideVertical, RequiresPrices: true, RequiresZones: true},
+		{Name: OneLineFormat, Output: withoutColumns(OneLineOutput)},
+		{Name: FamiliesOnlyFormat, Output: withoutColumns(FamiliesOnlyOutput), PerFamily: true},
+		{Name: NDJSONFormat, Output: withoutColumns(NDJSONOutput), StreamOutput: WriteNDJSON, RequiresZones: true, Extensions: []string{".ndjson", ".jsonl"}},
+		{Name: JSONFormat, Output: withoutColumns(VerboseInstanceTypeOutput), RequiresZones: true, Extensions: []string{".json"}},
+		{Name: CSVFormat, Output: csvOutput, RequiresPrices: true, RequiresZones: true, Extensions: []string{".csv"}},
+		{Name: YAMLFormat, Output: withoutColumns(YAMLOutput), RequiresZones: true, Extensions: []string{".yaml", ".yml"}},
+		{Name: CDKTSFormat, Output: withoutColumns(CDKTypeScriptOutput)},
+		{Name: CDKGoFormat, Output: withoutColumns(CDKGoOutput)},
+		{Name: RDSClassesFormat, Output: withoutColumns(RDSClassesOutput)},
+		{Name: ElastiCacheClassesFormat, Output: withoutColumns(ElastiCacheClassesOutput)},
+		{Name: InteractiveFormat, RequiresPrices: true, RequiresZones: true, Interactive: true},
+	}}
+}
+
+// CompareTableFormat is the format of the compare command which displays the instance types side-by-side, it is not
+// registered by NewDispatcher since it is only readable for a few instance types. Its vertical layout is the vertical
+// layout of the wide table.
+var CompareTableFormat = Format{Name: CompareFormat, Output: compareTableOutput, VerticalOutput: tableOutputWideVertical, RequiresPrices: true, RequiresZones: true}
+
+// Register adds an output format, formats can not be registered more than once.
+func (d *Dispatcher) Register(format Format) error {
+	if format.Name == "" {
+		return fmt.Errorf("output formats must have a name")
+	}
+	if _, err := d.Format(format.Name); err == nil {
+		return fmt.Errorf("output format %s is already registered", format.Name)
+	}
+	if format.Output == nil && !format.Interactive {
+		return fmt.Errorf("output format %s must have an Output function", format.Name)
+	}
+	d.formats = append(d.formats, format)
+	return nil
+}
+
+// Formats returns the names of the registered formats in the order they were registered.
+func (d Dispatcher) Formats() []string {
+	names := []string{}
+	for _, format := range d.formats {
+		names = append(names, format.Name)
+	}
+	return names
+}
+
+// Format returns the registered format with the name or an *UnknownFormatError.
+func (d Dispatcher) Format(name string) (Format, error) {
+	for _, format := range d.formats {
+		if format.Name == name {
+			return format, nil
+		}
+	}
+	return Format{}, &UnknownFormatError{Format: name, Formats: d.Formats()}
+}
+
+// FormatForFile returns the registered format selected for files with the extension of the path (Example: .csv), the
+// extension is matched case-insensitively. False is returned if no format is registered for the extension.
+func (d Dispatcher) FormatForFile(filePath string) (Format, bool) {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	if extension == "" {
+		return Format{}, false
+	}
+	for _, format := range d.formats {
+		if slices.Contains(format.Extensions, extension) {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// withoutColumns adapts an output function which does not display optional or custom columns to a Format Output.
+func withoutColumns(outputFn func([]*instancetypes.Details) []string) func([]*instancetypes.Details, []string, []CustomColumn) []string {
+	return func(instanceTypes []*instancetypes.Details, _ []string, _ []CustomColumn) []string {
+		return outputFn(instanceTypes)
+	}
+}
@@ -0,0 +1,91 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestDispatcher_Format(t *testing.T) {
+	dispatcher := outputs.NewDispatcher()
+	h.Equals(t, []string{"table", "table-wide", "one-line", "families-only", "ndjson", "json", "csv", "yaml", "cdk-ts", "cdk-go", "rds-classes", "elasticache-classes", "interactive"}, dispatcher.Formats())
+
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	format, err := dispatcher.Format(outputs.TableWideFormat)
+	h.Ok(t, err)
+	h.Assert(t, format.RequiresPrices, "table-wide should require prices")
+	h.Assert(t, format.RequiresZones, "table-wide should require zones")
+	outputStr := strings.Join(format.Output(instanceTypes, outputs.EBSColumns, nil), "")
+	h.Assert(t, strings.Contains(outputStr, outputs.EBSBandwidthColumn), "table-wide should display the extra columns")
+	outputStr = strings.Join(format.VerticalOutput(instanceTypes, outputs.EBSColumns, nil), "")
+	h.Assert(t, strings.Contains(outputStr, outputs.EBSBandwidthColumn+":"), "the vertical table-wide should display the extra columns")
+
+	format, err = dispatcher.Format(outputs.OneLineFormat)
+	h.Ok(t, err)
+	h.Assert(t, !format.RequiresPrices, "one-line should not require prices")
+	h.Equals(t, []string{"g2.2xlarge"}, format.Output(instanceTypes, outputs.EBSColumns, nil))
+	h.Assert(t, format.VerticalOutput == nil, "one-line should not have a vertical layout")
+
+	format, err = dispatcher.Format(outputs.InteractiveFormat)
+	h.Ok(t, err)
+	h.Assert(t, format.Interactive && format.RequiresPrices, "interactive should be interactive and require prices")
+}
+
+func TestDispatcher_UnknownFormat(t *testing.T) {
+	_, err := outputs.NewDispatcher().Format("xml")
+	var unknownFormatErr *outputs.UnknownFormatError
+	h.Assert(t, errors.As(err, &unknownFormatErr), "unknown formats should return an UnknownFormatError")
+	h.Equals(t, "xml", unknownFormatErr.Format)
+	h.Assert(t, strings.Contains(err.Error(), "table-wide"), "the error should list the valid formats")
+}
+
+func TestDispatcher_FormatForFile(t *testing.T) {
+	dispatcher := outputs.NewDispatcher()
+	for filePath, formatName := range map[string]string{
+		"instance-types.json":               outputs.JSONFormat,
+		"reports/instance-types.CSV":        outputs.CSVFormat,
+		"s3://reports/instance-types.yml":   outputs.YAMLFormat,
+		"instance-types.yaml":               outputs.YAMLFormat,
+		"instance-types.ndjson":             outputs.NDJSONFormat,
+		"C:\\reports\\instance-types.jsonl": outputs.NDJSONFormat,
+	} {
+		format, ok := dispatcher.FormatForFile(filePath)
+		h.Assert(t, ok, "a format should be selected for %s", filePath)
+		h.Equals(t, formatName, format.Name)
+	}
+	for _, filePath := range []string{"instance-types", "instance-types.txt", "reports.json/instance-types"} {
+		_, ok := dispatcher.FormatForFile(filePath)
+		h.Assert(t, !ok, "no format should be selected for %s", filePath)
+	}
+}
+
+func TestDispatcher_Register(t *testing.T) {
+	dispatcher := outputs.NewDispatcher()
+	count := func(instanceTypes []*instancetypes.Details, _ []string, _ []outputs.CustomColumn) []string {
+		return []string{strings.Repeat("#", len(instanceTypes))}
+	}
+	h.Ok(t, dispatcher.Register(outputs.Format{Name: "count", Output: count}))
+	format, err := dispatcher.Format("count")
+	h.Ok(t, err)
+	h.Equals(t, []string{"#"}, format.Output(getInstanceTypes(t, "g2_2xlarge.json"), nil, nil))
+
+	h.Nok(t, dispatcher.Register(outputs.Format{Name: "count", Output: count}))
+	h.Nok(t, dispatcher.Register(outputs.Format{Name: "no-output"}))
+	h.Nok(t, dispatcher.Register(outputs.Format{Output: count}))
+}
@@ -15,14 +15,21 @@ package outputs
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"gopkg.in/yaml.v3"
+
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
 )
 
@@ -32,19 +39,97 @@ const columnTag = "column"
 // of a wide output row.
 type wideColumnsData struct {
 	instanceName       string `column:"Instance Type"`
+	region             string `column:"Region"`
 	vcpu               int32  `column:"VCPUs"`
 	memory             string `column:"Mem (GiB)"`
+	nfu                string `column:"NFU"`
 	hypervisor         string `column:"Hypervisor"`
+	nitroGeneration    string `column:"Nitro Gen"`
 	currentGen         bool   `column:"Current Gen"`
+	prevGen            string `column:"Prev Gen"`
+	deprecated         string `column:"Deprecated"`
 	hibernationSupport bool   `column:"Hibernation Support"`
+	autoRecovery       bool   `column:"Auto Recovery"`
+	dedicatedHosts     bool   `column:"Dedicated Hosts"`
+	freeTier           bool   `column:"Free Tier"`
+	ebsOptimized       string `column:"EBS Optimized"`
 	cpuArch            string `column:"CPU Arch"`
 	networkPerformance string `column:"Network Performance"`
 	eni                int32  `column:"ENIs"`
+	networkCards       int32  `column:"Network Cards"`
+	enaQueues          string `column:"ENA Queues (ENI/Max)"`
 	gpu                int32  `column:"GPUs"`
 	gpuMemory          string `column:"GPU Mem (GiB)"`
 	gpuInfo            string `column:"GPU Info"`
+	ebsBandwidth       string `column:"EBS Mbps (Base/Max)"`
+	ebsThroughput      string `column:"EBS MB/s (Base/Max)"`
+	ebsIOPS            string `column:"EBS IOPS (Base/Max)"`
 	odPrice            string `column:"On-Demand Price/Hr"`
 	spotPrice          string `column:"Spot Price/Hr"`
+	spotSavings        string `column:"Spot Savings"`
+	odPricePerMonth    string `column:"On-Demand Price/Mo"`
+	spotPricePerMonth  string `column:"Spot Price/Mo"`
+	odPricePerYear     string `column:"On-Demand Price/Yr"`
+	spotPricePerYear   string `column:"Spot Price/Yr"`
+	zones              string `column:"Zones"`
+	coRank             string `column:"CO Rank"`
+	// customColumns are the values of the custom columns keyed by column name, they are displayed after the other columns
+	customColumns map[string]string
+}
+
+// zonesColumn is only displayed when at least one of the instance types has availability zones, deprecatedColumn is only displayed
+// when at least one of the instance types is deprecated, coRankColumn is only displayed when at least one
+// of the instance types is an AWS Compute Optimizer recommendation option and regionColumn is only displayed when at least
+// one of the instance types has a region, which is only set when the instance types were filtered across regions.
+const (
+	regionColumn     = "Region"
+	zonesColumn      = "Zones"
+	deprecatedColumn = "Deprecated"
+	coRankColumn     = "CO Rank"
+)
+
+// Optional columns are only displayed when they are passed to TableOutputWideWithColumns or tui.WithColumns.
+const (
+	EBSBandwidthColumn  = "EBS Mbps (Base/Max)"
+	EBSThroughputColumn = "EBS MB/s (Base/Max)"
+	EBSIOPSColumn       = "EBS IOPS (Base/Max)"
+	PrevGenColumn       = "Prev Gen"
+	NetworkCardsColumn  = "Network Cards"
+	ENAQueuesColumn     = "ENA Queues (ENI/Max)"
+	NFUColumn           = "NFU"
+
+	OnDemandPricePerMonthColumn = "On-Demand Price/Mo"
+	SpotPricePerMonthColumn     = "Spot Price/Mo"
+	OnDemandPricePerYearColumn  = "On-Demand Price/Yr"
+	SpotPricePerYearColumn      = "Spot Price/Yr"
+)
+
+// EBSColumns are the optional columns of the baseline and maximum dedicated EBS bandwidth, throughput, and IOPS.
+var EBSColumns = []string{EBSBandwidthColumn, EBSThroughputColumn, EBSIOPSColumn}
+
+// NetworkColumns are the optional columns of the network cards and the maximum ENA queues per network interface and
+// across all of the network interfaces.
+var NetworkColumns = []string{NetworkCardsColumn, ENAQueuesColumn}
+
+// PricePerMonthColumns and PricePerYearColumns are the optional columns of the on-demand and spot prices per month and
+// per year, which are derived from the hourly prices.
+var (
+	PricePerMonthColumns = []string{OnDemandPricePerMonthColumn, SpotPricePerMonthColumn}
+	PricePerYearColumns  = []string{OnDemandPricePerYearColumn, SpotPricePerYearColumn}
+)
+
+var optionalColumns = map[string]bool{
+	EBSBandwidthColumn:          true,
+	EBSThroughputColumn:         true,
+	EBSIOPSColumn:               true,
+	PrevGenColumn:               true,
+	NetworkCardsColumn:          true,
+	ENAQueuesColumn:             true,
+	NFUColumn:                   true,
+	OnDemandPricePerMonthColumn: true,
+	SpotPricePerMonthColumn:     true,
+	OnDemandPricePerYearColumn:  true,
+	SpotPricePerYearColumn:      true,
 }
 
 // SimpleInstanceTypeOutput is an OutputFn which outputs a slice of instance type names.
@@ -58,17 +143,48 @@ func SimpleInstanceTypeOutput(instanceTypeInfoSlice []*instancetypes.Details) []
 
 // VerboseInstanceTypeOutput is an OutputFn which outputs a slice of instance type names.
 func VerboseInstanceTypeOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
-	output, err := json.MarshalIndent(instanceTypeInfoSlice, "", "    ")
-	if err != nil {
-		log.Println("Unable to convert instance type info to JSON")
+	if len(instanceTypeInfoSlice) == 0 {
 		return []string{}
 	}
-	if string(output) == "[]" || string(output) == "null" {
+	output, err := json.MarshalIndent(withSchemaVersion(instanceTypeInfoSlice), "", "    ")
+	if err != nil {
+		log.Println("Unable to convert instance type info to JSON")
 		return []string{}
 	}
 	return []string{string(output)}
 }
 
+// NDJSONOutput is an OutputFn which outputs the verbose JSON of each instance type on its own line
+// so that the output can be streamed by tools like jq -c rather than parsed as one array.
+func NDJSONOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	lines := []string{}
+	for _, instanceTypeInfo := range withSchemaVersion(instanceTypeInfoSlice) {
+		output, err := json.Marshal(instanceTypeInfo)
+		if err != nil {
+			log.Printf("Unable to convert instance type info of %s to JSON", instanceTypeInfo.InstanceType)
+			continue
+		}
+		lines = append(lines, string(output))
+	}
+	return lines
+}
+
+// WriteNDJSON writes the lines of NDJSONOutput to w one at a time as each instance type is converted to JSON, rather than
+// holding the whole output in memory.
+func WriteNDJSON(w io.Writer, instanceTypeInfoSlice []*instancetypes.Details) error {
+	for _, instanceTypeInfo := range withSchemaVersion(instanceTypeInfoSlice) {
+		output, err := json.Marshal(instanceTypeInfo)
+		if err != nil {
+			log.Printf("Unable to convert instance type info of %s to JSON", instanceTypeInfo.InstanceType)
+			continue
+		}
+		if _, err := w.Write(append(output, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TableOutputShort is an OutputFn which returns a CLI table for easy reading.
 func TableOutputShort(instanceTypeInfoSlice []*instancetypes.Details) []string {
 	if len(instanceTypeInfoSlice) == 0 {
@@ -79,34 +195,78 @@ func TableOutputShort(instanceTypeInfoSlice []*instancetypes.Details) []string {
 	w.Init(buf, 8, 8, 8, ' ', 0)
 	defer w.Flush()
 
-	headers := []interface{}{
-		"Instance Type",
-		"VCPUs",
-		"Mem (GiB)",
-	}
+	columnHeaders, rows := shortColumns(instanceTypeInfoSlice)
+	headers := []interface{}{}
 	separators := []interface{}{}
-
-	headerFormat := ""
-	for _, header := range headers {
-		headerFormat = headerFormat + "%s\t"
-		separators = append(separators, strings.Repeat("-", len(header.(string))))
+	for _, columnHeader := range columnHeaders {
+		headers = append(headers, columnHeader)
+		separators = append(separators, strings.Repeat("-", len(columnHeader)))
 	}
+	headerFormat := strings.Repeat("%s\t", len(columnHeaders))
 	fmt.Fprintf(w, headerFormat, headers...)
 	fmt.Fprintf(w, "\n"+headerFormat, separators...)
 
-	for _, instanceTypeInfo := range instanceTypeInfoSlice {
-		fmt.Fprintf(w, "\n%s\t%d\t%s\t",
-			instanceTypeInfo.InstanceType,
-			*instanceTypeInfo.VCpuInfo.DefaultVCpus,
-			formatFloat(float64(*instanceTypeInfo.MemoryInfo.SizeInMiB)/1024.0),
-		)
+	for _, row := range rows {
+		fmt.Fprint(w, "\n")
+		for _, columnHeader := range columnHeaders {
+			fmt.Fprintf(w, "%v\t", row[columnHeader])
+		}
 	}
 	w.Flush()
 	return []string{buf.String()}
 }
 
+// TableOutputShortVertical is an OutputFn which returns the columns of the short table as a block of lines for each
+// instance type, which fits in narrow terminals.
+func TableOutputShortVertical(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	columnHeaders, rows := shortColumns(instanceTypeInfoSlice)
+	return verticalTableOutput(columnHeaders, rows)
+}
+
+// shortColumns returns the headers of the columns of the short table and the values of the columns of each instance
+// type keyed by header, the region is only displayed when the instance types were filtered across regions.
+func shortColumns(instanceTypeInfoSlice []*instancetypes.Details) ([]string, []map[string]interface{}) {
+	displayRegion := slices.ContainsFunc(instanceTypeInfoSlice, func(instanceTypeInfo *instancetypes.Details) bool { return instanceTypeInfo.Region != "" })
+	columnHeaders := []string{"Instance Type"}
+	if displayRegion {
+		columnHeaders = append(columnHeaders, regionColumn)
+	}
+	columnHeaders = append(columnHeaders, "VCPUs", "Mem (GiB)")
+
+	rows := []map[string]interface{}{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		row := map[string]interface{}{
+			"Instance Type": instanceTypeInfo.InstanceType,
+			"VCPUs":         *instanceTypeInfo.VCpuInfo.DefaultVCpus,
+			"Mem (GiB)":     formatFloat(float64(*instanceTypeInfo.MemoryInfo.SizeInMiB) / 1024.0),
+		}
+		if displayRegion {
+			row[regionColumn] = instanceTypeInfo.Region
+		}
+		rows = append(rows, row)
+	}
+	return columnHeaders, rows
+}
+
 // TableOutputWide is an OutputFn which returns a detailed CLI table for easy reading.
 func TableOutputWide(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return tableOutputWide(instanceTypeInfoSlice, nil, nil)
+}
+
+// TableOutputWideWithColumns returns an OutputFn which returns a detailed CLI table including the passed in optional columns (i.e. EBSColumns).
+func TableOutputWideWithColumns(extraColumns ...string) func(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return TableOutputWideWithCustomColumns(nil, extraColumns...)
+}
+
+// TableOutputWideWithCustomColumns returns an OutputFn which returns a detailed CLI table including the passed in optional
+// columns followed by the custom columns.
+func TableOutputWideWithCustomColumns(customColumns []CustomColumn, extraColumns ...string) func(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return func(instanceTypeInfoSlice []*instancetypes.Details) []string {
+		return tableOutputWide(instanceTypeInfoSlice, extraColumns, customColumns)
+	}
+}
+
+func tableOutputWide(instanceTypeInfoSlice []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string {
 	if len(instanceTypeInfoSlice) == 0 {
 		return nil
 	}
@@ -115,47 +275,210 @@ func TableOutputWide(instanceTypeInfoSlice []*instancetypes.Details) []string {
 	w.Init(buf, 8, 8, 2, ' ', 0)
 	defer w.Flush()
 
-	columnDataStruct := wideColumnsData{}
+	columnHeaders, rows := WideColumns(instanceTypeInfoSlice, extraColumns, customColumns)
+
 	headers := []interface{}{}
-	structType := reflect.TypeOf(columnDataStruct)
-	for i := 0; i < structType.NumField(); i++ {
-		columnHeader := structType.Field(i).Tag.Get(columnTag)
+	separators := []interface{}{}
+	for _, columnHeader := range columnHeaders {
 		headers = append(headers, columnHeader)
+		separators = append(separators, strings.Repeat("-", len(columnHeader)))
 	}
-	separators := make([]interface{}, 0)
-
-	headerFormat := ""
-	for _, header := range headers {
-		headerFormat = headerFormat + "%s\t"
-		separators = append(separators, strings.Repeat("-", len(header.(string))))
-	}
+	headerFormat := strings.Repeat("%s\t", len(columnHeaders))
 	fmt.Fprintf(w, headerFormat, headers...)
 	fmt.Fprintf(w, "\n"+headerFormat, separators...)
 
-	columnsData := getWideColumnsData(instanceTypeInfoSlice)
+	for _, row := range rows {
+		fmt.Fprint(w, "\n")
+		for _, columnHeader := range columnHeaders {
+			fmt.Fprintf(w, "%v\t", row[columnHeader])
+		}
+	}
+	w.Flush()
+	return []string{buf.String()}
+}
 
-	for _, data := range columnsData {
-		fmt.Fprintf(w, "\n%s\t%d\t%s\t%s\t%t\t%t\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t",
-			data.instanceName,
-			data.vcpu,
-			data.memory,
-			data.hypervisor,
-			data.currentGen,
-			data.hibernationSupport,
-			data.cpuArch,
-			data.networkPerformance,
-			data.eni,
-			data.gpu,
-			data.gpuMemory,
-			data.gpuInfo,
-			data.odPrice,
-			data.spotPrice,
-		)
+// TableOutputWideVertical is an OutputFn which returns the columns of the wide table as a block of lines for each
+// instance type, which fits in narrow terminals.
+func TableOutputWideVertical(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return tableOutputWideVertical(instanceTypeInfoSlice, nil, nil)
+}
+
+func tableOutputWideVertical(instanceTypeInfoSlice []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	columnHeaders, rows := WideColumns(instanceTypeInfoSlice, extraColumns, customColumns)
+	return verticalTableOutput(columnHeaders, rows)
+}
+
+// verticalTableOutput returns a block with a line for each column of each row, the blocks are separated by an empty line.
+// Every block has the same headers so their values are aligned even though tabwriter aligns each block separately.
+func verticalTableOutput(columnHeaders []string, rows []map[string]interface{}) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	buf := new(bytes.Buffer)
+	w.Init(buf, 8, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	for i, row := range rows {
+		if i > 0 {
+			fmt.Fprint(w, "\n\n")
+		}
+		for j, columnHeader := range columnHeaders {
+			if j > 0 {
+				fmt.Fprint(w, "\n")
+			}
+			fmt.Fprintf(w, "%s:\t%v", columnHeader, row[columnHeader])
+		}
+	}
+	w.Flush()
+	return []string{buf.String()}
+}
+
+// CompareTableOutput is an OutputFn which returns a CLI table with a row for each column of the wide table and a column
+// for each instance type, so that a few instance types can be compared side-by-side.
+func CompareTableOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return compareTableOutput(instanceTypeInfoSlice, nil, nil)
+}
+
+func compareTableOutput(instanceTypeInfoSlice []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	buf := new(bytes.Buffer)
+	w.Init(buf, 8, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	columnHeaders, rows := WideColumns(instanceTypeInfoSlice, extraColumns, customColumns)
+	for i, columnHeader := range columnHeaders {
+		cells := []string{columnHeader}
+		for _, row := range rows {
+			cells = append(cells, fmt.Sprintf("%v", row[columnHeader]))
+		}
+		if i > 0 {
+			fmt.Fprint(w, "\n")
+		}
+		fmt.Fprint(w, strings.Join(cells, "\t")+"\t")
+		// the instance type names are the header of the table
+		if i == 0 {
+			separators := []string{}
+			for _, cell := range cells {
+				separators = append(separators, strings.Repeat("-", len(cell)))
+			}
+			fmt.Fprint(w, "\n"+strings.Join(separators, "\t")+"\t")
+		}
 	}
 	w.Flush()
 	return []string{buf.String()}
 }
 
+// WideColumns returns the headers of the columns the wide table and interactive outputs display for the instance types
+// in display order, which are the default columns, the passed in optional columns (i.e. EBSColumns), and the custom
+// columns, along with the values of the columns of each instance type keyed by header.
+func WideColumns(instanceTypeInfoSlice []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) ([]string, []map[string]interface{}) {
+	columnsData := getWideColumnsData(instanceTypeInfoSlice, customColumns)
+
+	structType := reflect.TypeOf(wideColumnsData{})
+	columnHeaders := []string{}
+	fieldIndexes := []int{}
+	for i := 0; i < structType.NumField(); i++ {
+		columnHeader := structType.Field(i).Tag.Get(columnTag)
+		if !isWideColumnDisplayed(columnsData, columnHeader, extraColumns) {
+			continue
+		}
+		columnHeaders = append(columnHeaders, columnHeader)
+		fieldIndexes = append(fieldIndexes, i)
+	}
+	for _, customColumn := range customColumns {
+		columnHeaders = append(columnHeaders, customColumn.Name)
+	}
+
+	rows := []map[string]interface{}{}
+	for _, data := range columnsData {
+		structValue := reflect.ValueOf(*data)
+		row := map[string]interface{}{}
+		for i, fieldIndex := range fieldIndexes {
+			row[columnHeaders[i]] = getUnderlyingValue(structValue.Field(fieldIndex))
+		}
+		for columnName, value := range data.customColumns {
+			row[columnName] = value
+		}
+		rows = append(rows, row)
+	}
+	return columnHeaders, rows
+}
+
+// CSVOutput is an OutputFn which outputs the columns of the wide table as CSV, the first line is the header.
+func CSVOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	return csvOutput(instanceTypeInfoSlice, nil, nil)
+}
+
+// csvOutput returns a line for the header and each instance type so that the header can be left out when appending to
+// an existing CSV file.
+func csvOutput(instanceTypeInfoSlice []*instancetypes.Details, extraColumns []string, customColumns []CustomColumn) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	columnHeaders, rows := WideColumns(instanceTypeInfoSlice, extraColumns, customColumns)
+	records := [][]string{columnHeaders}
+	for _, row := range rows {
+		record := []string{}
+		for _, columnHeader := range columnHeaders {
+			record = append(record, fmt.Sprintf("%v", row[columnHeader]))
+		}
+		records = append(records, record)
+	}
+	lines := []string{}
+	for _, record := range records {
+		buf := new(bytes.Buffer)
+		w := csv.NewWriter(buf)
+		if err := w.Write(record); err != nil {
+			log.Println("Unable to convert instance type info to CSV")
+			return []string{}
+		}
+		w.Flush()
+		lines = append(lines, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return lines
+}
+
+// YAMLOutput is an OutputFn which outputs the verbose instance type info as YAML, in the same shape as the verbose JSON.
+func YAMLOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return []string{}
+	}
+	output, err := json.Marshal(withSchemaVersion(instanceTypeInfoSlice))
+	if err != nil {
+		log.Println("Unable to convert instance type info to YAML")
+		return []string{}
+	}
+	// JSON is valid YAML, decoding it as a node keeps the order of the fields and the json tag names
+	node := yaml.Node{}
+	if err := yaml.Unmarshal(output, &node); err != nil {
+		log.Println("Unable to convert instance type info to YAML")
+		return []string{}
+	}
+	resetStyle(&node)
+	output, err = yaml.Marshal(&node)
+	if err != nil {
+		log.Println("Unable to convert instance type info to YAML")
+		return []string{}
+	}
+	return []string{strings.TrimSuffix(string(output), "\n")}
+}
+
+// resetStyle clears the JSON flow style and quoting of the node and its children so that they are encoded as block YAML,
+// strings are still quoted when they would otherwise be read as another type.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
 // OneLineOutput is an output function which prints the instance type names on a single line separated by commas.
 func OneLineOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
 	instanceTypeNames := []string{}
@@ -168,6 +491,101 @@ func OneLineOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
 	return []string{strings.Join(instanceTypeNames, ",")}
 }
 
+// FamiliesOnlyOutput is an output function which prints the family of each instance type once, in the order the families
+// are first matched (Example: m5), for tooling which selects instance families rather than instance types.
+func FamiliesOnlyOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	families := []string{}
+	for _, instanceType := range FirstOfEachFamily(instanceTypeInfoSlice) {
+		families = append(families, instanceFamily(string(instanceType.InstanceType)))
+	}
+	return families
+}
+
+// FirstOfEachFamily returns the first instance type of each family so that results can be truncated by family.
+func FirstOfEachFamily(instanceTypeInfoSlice []*instancetypes.Details) []*instancetypes.Details {
+	seen := map[string]bool{}
+	firstOfEachFamily := []*instancetypes.Details{}
+	for _, instanceType := range instanceTypeInfoSlice {
+		family := instanceFamily(string(instanceType.InstanceType))
+		if seen[family] {
+			continue
+		}
+		seen[family] = true
+		firstOfEachFamily = append(firstOfEachFamily, instanceType)
+	}
+	return firstOfEachFamily
+}
+
+// instanceFamily returns everything before the size of the instance type name, which keeps the ml. prefix of SageMaker
+// instance type names (Example: ml.m5 of ml.m5.large).
+func instanceFamily(instanceType string) string {
+	if i := strings.LastIndex(instanceType, "."); i >= 0 {
+		return instanceType[:i]
+	}
+	return instanceType
+}
+
+// CDKTypeScriptOutput is an output function which prints an AWS CDK TypeScript snippet with the instance types as an
+// ec2.InstanceType array and a function returning an autoscaling.MixedInstancesPolicy which overrides with them.
+func CDKTypeScriptOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return []string{}
+	}
+	snippet := strings.Builder{}
+	snippet.WriteString("import * as autoscaling from 'aws-cdk-lib/aws-autoscaling';\n")
+	snippet.WriteString("import * as ec2 from 'aws-cdk-lib/aws-ec2';\n\n")
+	snippet.WriteString("export const instanceTypes: ec2.InstanceType[] = [\n")
+	for _, instanceType := range instanceTypeInfoSlice {
+		fmt.Fprintf(&snippet, "  new ec2.InstanceType('%s'),\n", instanceType.InstanceType)
+	}
+	snippet.WriteString("];\n\n")
+	snippet.WriteString("export function mixedInstancesPolicy(launchTemplate: ec2.ILaunchTemplate): autoscaling.MixedInstancesPolicy {\n")
+	snippet.WriteString("  return {\n")
+	snippet.WriteString("    launchTemplate,\n")
+	snippet.WriteString("    launchTemplateOverrides: instanceTypes.map((instanceType) => ({ instanceType })),\n")
+	snippet.WriteString("  };\n")
+	snippet.WriteString("}")
+	return []string{snippet.String()}
+}
+
+// CDKGoOutput is an output function which prints an AWS CDK Go snippet with the instance types as an awsec2.InstanceType
+// slice and a function returning an awsautoscaling.MixedInstancesPolicy which overrides with them.
+func CDKGoOutput(instanceTypeInfoSlice []*instancetypes.Details) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return []string{}
+	}
+	snippet := strings.Builder{}
+	snippet.WriteString("import (\n")
+	snippet.WriteString("\t\"github.com/aws/aws-cdk-go/awscdk/v2/awsautoscaling\"\n")
+	snippet.WriteString("\t\"github.com/aws/aws-cdk-go/awscdk/v2/awsec2\"\n")
+	snippet.WriteString("\t\"github.com/aws/jsii-runtime-go\"\n")
+	snippet.WriteString(")\n\n")
+	snippet.WriteString("var InstanceTypes = []awsec2.InstanceType{\n")
+	for _, instanceType := range instanceTypeInfoSlice {
+		fmt.Fprintf(&snippet, "\tawsec2.NewInstanceType(jsii.String(%q)),\n", instanceType.InstanceType)
+	}
+	snippet.WriteString("}\n\n")
+	snippet.WriteString("func MixedInstancesPolicy(launchTemplate awsec2.ILaunchTemplate) *awsautoscaling.MixedInstancesPolicy {\n")
+	snippet.WriteString("\toverrides := []*awsautoscaling.LaunchTemplateOverrides{}\n")
+	snippet.WriteString("\tfor _, instanceType := range InstanceTypes {\n")
+	snippet.WriteString("\t\toverrides = append(overrides, &awsautoscaling.LaunchTemplateOverrides{InstanceType: instanceType})\n")
+	snippet.WriteString("\t}\n")
+	snippet.WriteString("\treturn &awsautoscaling.MixedInstancesPolicy{\n")
+	snippet.WriteString("\t\tLaunchTemplate:          launchTemplate,\n")
+	snippet.WriteString("\t\tLaunchTemplateOverrides: &overrides,\n")
+	snippet.WriteString("\t}\n")
+	snippet.WriteString("}")
+	return []string{snippet.String()}
+}
+
+// formatPricePeriods returns the monthly and annual prices rounded to cents or -Not Fetched- if the price is unknown.
+func formatPricePeriods(pricePerMonth *float64) (string, string) {
+	if pricePerMonth == nil {
+		return "-Not Fetched-", "-Not Fetched-"
+	}
+	return "$" + formatFloat(math.Round(*pricePerMonth*100)/100), "$" + formatFloat(math.Round(*pricePerMonth*instancetypes.MonthsPerYear*100)/100)
+}
+
 func formatFloat(f float64) string {
 	s := strconv.FormatFloat(f, 'f', 5, 64)
 	parts := strings.Split(s, ".")
@@ -186,6 +604,17 @@ func formatFloat(f float64) string {
 	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
 }
 
+// formatBaseMax formats a baseline and maximum value as "baseline / maximum".
+func formatBaseMax[T int32 | float64](baseline *T, maximum *T) string {
+	format := func(v *T) string {
+		if v == nil {
+			return "-"
+		}
+		return formatFloat(float64(*v))
+	}
+	return fmt.Sprintf("%s / %s", format(baseline), format(maximum))
+}
+
 func reverse(s string) string {
 	runes := []rune(s)
 	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
@@ -196,7 +625,7 @@ func reverse(s string) string {
 
 // getWideColumnsData returns the column data necessary for a wide output for each of
 // the given instance types.
-func getWideColumnsData(instanceTypes []*instancetypes.Details) []*wideColumnsData {
+func getWideColumnsData(instanceTypes []*instancetypes.Details, customColumns []CustomColumn) []*wideColumnsData {
 	columnsData := []*wideColumnsData{}
 
 	for _, instanceType := range instanceTypes {
@@ -220,6 +649,54 @@ func getWideColumnsData(instanceTypes []*instancetypes.Details) []*wideColumnsDa
 			gpuType = append(gpuType, none)
 		}
 
+		nitroGenerationStr := "-"
+		if instanceType.NitroGeneration != nil && *instanceType.NitroGeneration > 0 {
+			nitroGenerationStr = fmt.Sprintf("v%d", *instanceType.NitroGeneration)
+		}
+
+		// EBS optimization which is only supported rather than default is an extra cost
+		ebsOptimized := "-"
+		if instanceType.EbsInfo != nil && instanceType.EbsInfo.EbsOptimizedSupport != "" {
+			ebsOptimized = string(instanceType.EbsInfo.EbsOptimizedSupport)
+		}
+
+		ebsBandwidth, ebsThroughput, ebsIOPS := "-", "-", "-"
+		if ebsOptimizedInfo := instanceType.EbsInfo.EbsOptimizedInfo; ebsOptimizedInfo != nil {
+			ebsBandwidth = formatBaseMax(ebsOptimizedInfo.BaselineBandwidthInMbps, ebsOptimizedInfo.MaximumBandwidthInMbps)
+			ebsThroughput = formatBaseMax(ebsOptimizedInfo.BaselineThroughputInMBps, ebsOptimizedInfo.MaximumThroughputInMBps)
+			ebsIOPS = formatBaseMax(ebsOptimizedInfo.BaselineIops, ebsOptimizedInfo.MaximumIops)
+		}
+
+		networkCards := int32(1)
+		if instanceType.NetworkInfo.MaximumNetworkCards != nil {
+			networkCards = *instanceType.NetworkInfo.MaximumNetworkCards
+		}
+
+		zones := []string{}
+		for _, zone := range instanceType.AvailabilityZones {
+			zones = append(zones, zone.String())
+		}
+
+		nfu := "-"
+		if instanceType.NormalizationFactor != nil {
+			nfu = formatFloat(*instanceType.NormalizationFactor)
+		}
+
+		deprecated := "-"
+		if instanceType.Deprecation != nil {
+			deprecated = *instanceType.Deprecation
+		}
+
+		prevGen := "no"
+		if !*instanceType.CurrentGeneration {
+			prevGen = "yes"
+		}
+
+		coRank := "-"
+		if instanceType.ComputeOptimizerRank != nil {
+			coRank = fmt.Sprintf("%d", *instanceType.ComputeOptimizerRank)
+		}
+
 		onDemandPricePerHourStr := "-Not Fetched-"
 		spotPricePerHourStr := "-Not Fetched-"
 		if instanceType.OndemandPricePerHour != nil {
@@ -228,22 +705,50 @@ func getWideColumnsData(instanceTypes []*instancetypes.Details) []*wideColumnsDa
 		if instanceType.SpotPrice != nil {
 			spotPricePerHourStr = "$" + formatFloat(*instanceType.SpotPrice)
 		}
+		odPricePerMonthStr, odPricePerYearStr := formatPricePeriods(instanceType.OndemandPricePerMonth)
+		spotPricePerMonthStr, spotPricePerYearStr := formatPricePeriods(instanceType.SpotPricePerMonth)
+		spotSavingsStr := "-"
+		if instanceType.SpotSavings != nil {
+			spotSavingsStr = fmt.Sprintf("%.0f%%", *instanceType.SpotSavings)
+		}
 
 		newColumn := wideColumnsData{
 			instanceName:       string(instanceType.InstanceType),
+			region:             instanceType.Region,
 			vcpu:               *instanceType.VCpuInfo.DefaultVCpus,
 			memory:             formatFloat(float64(*instanceType.MemoryInfo.SizeInMiB) / 1024.0),
+			nfu:                nfu,
 			hypervisor:         string(instanceType.Hypervisor),
+			nitroGeneration:    nitroGenerationStr,
 			currentGen:         *instanceType.CurrentGeneration,
+			prevGen:            prevGen,
+			deprecated:         deprecated,
 			hibernationSupport: *instanceType.HibernationSupported,
+			autoRecovery:       aws.ToBool(instanceType.AutoRecoverySupported),
+			dedicatedHosts:     aws.ToBool(instanceType.DedicatedHostsSupported),
+			freeTier:           aws.ToBool(instanceType.FreeTierEligible),
+			ebsOptimized:       ebsOptimized,
 			cpuArch:            strings.Join(cpuArchitectures, ", "),
 			networkPerformance: *instanceType.NetworkInfo.NetworkPerformance,
 			eni:                *instanceType.NetworkInfo.MaximumNetworkInterfaces,
+			networkCards:       networkCards,
+			enaQueues:          formatBaseMax(instanceType.EnaQueuesPerInterface, instanceType.EnaQueues),
 			gpu:                gpus,
 			gpuMemory:          formatFloat(float64(gpuMemory) / 1024.0),
 			gpuInfo:            strings.Join(gpuType, ", "),
+			ebsBandwidth:       ebsBandwidth,
+			ebsThroughput:      ebsThroughput,
+			ebsIOPS:            ebsIOPS,
 			odPrice:            onDemandPricePerHourStr,
 			spotPrice:          spotPricePerHourStr,
+			spotSavings:        spotSavingsStr,
+			odPricePerMonth:    odPricePerMonthStr,
+			spotPricePerMonth:  spotPricePerMonthStr,
+			odPricePerYear:     odPricePerYearStr,
+			spotPricePerYear:   spotPricePerYearStr,
+			zones:              strings.Join(zones, ", "),
+			coRank:             coRank,
+			customColumns:      customColumnValues(instanceType, customColumns),
 		}
 
 		columnsData = append(columnsData, &newColumn)
@@ -252,6 +757,29 @@ func getWideColumnsData(instanceTypes []*instancetypes.Details) []*wideColumnsDa
 	return columnsData
 }
 
+// isWideColumnDisplayed returns false for the region, zones, deprecated and CO rank columns if none of the instance types have
+// a region, availability zones, are deprecated or are Compute Optimizer options and for optional columns which were not passed in.
+func isWideColumnDisplayed(columnsData []*wideColumnsData, columnHeader string, extraColumns []string) bool {
+	if columnHeader == "" {
+		// the custom columns are displayed after the other columns
+		return false
+	}
+	if optionalColumns[columnHeader] {
+		return slices.Contains(extraColumns, columnHeader)
+	}
+	if columnHeader != regionColumn && columnHeader != zonesColumn && columnHeader != deprecatedColumn && columnHeader != coRankColumn {
+		return true
+	}
+	for _, data := range columnsData {
+		if (columnHeader == regionColumn && data.region != "") || (columnHeader == zonesColumn && data.zones != "") ||
+			(columnHeader == deprecatedColumn && data.deprecated != "-") ||
+			(columnHeader == coRankColumn && data.coRank != "-") {
+			return true
+		}
+	}
+	return false
+}
+
 // getUnderlyingValue returns the underlying value of the given
 // reflect.Value type.
 func getUnderlyingValue(value reflect.Value) interface{} {
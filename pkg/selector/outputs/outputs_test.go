@@ -13,13 +13,20 @@
 package outputs_test
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"gopkg.in/yaml.v3"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
@@ -61,12 +68,19 @@ func TestSimpleInstanceTypeOutput(t *testing.T) {
 
 func TestVerboseInstanceTypeOutput(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "t3_micro.json")
-	outputExpectation, err := json.MarshalIndent(instanceTypes, "", "    ")
+	outputExpectation, err := json.Marshal(instanceTypes)
 	h.Ok(t, err)
 
 	instanceTypeOut := outputs.VerboseInstanceTypeOutput(instanceTypes)
 	h.Assert(t, len(instanceTypeOut) == len(instanceTypes), "Should return the same number of instance types as the data passed in")
-	h.Assert(t, instanceTypeOut[0] == string(outputExpectation), "Should only return t3.micro")
+	outputDetails := []*instancetypes.Details{}
+	h.Ok(t, json.Unmarshal([]byte(instanceTypeOut[0]), &outputDetails))
+	outputJSON, err := json.Marshal(outputDetails)
+	h.Ok(t, err)
+	h.Equals(t, string(outputExpectation), string(outputJSON))
+	schemaVersions := []struct{ SchemaVersion string }{}
+	h.Ok(t, json.Unmarshal([]byte(instanceTypeOut[0]), &schemaVersions))
+	h.Equals(t, outputs.SchemaVersion, schemaVersions[0].SchemaVersion)
 
 	instanceTypeOut = outputs.VerboseInstanceTypeOutput([]*instancetypes.Details{})
 	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed empty slice")
@@ -84,6 +98,50 @@ func TestTableOutputShort(t *testing.T) {
 	h.Assert(t, strings.Contains(outputStr, "t3.micro"), "short table should include instance type")
 }
 
+func TestTableOutputShortVertical(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	outputStr := strings.Join(outputs.TableOutputShortVertical(instanceTypes), "")
+	blocks := strings.Split(outputStr, "\n\n")
+	h.Equals(t, 2, len(blocks))
+	h.Equals(t, "Instance Type:  t3.micro\nVCPUs:          2\nMem (GiB):      1", blocks[0])
+	h.Equals(t, "Instance Type:  p3.16xlarge\nVCPUs:          64\nMem (GiB):      488", blocks[1])
+
+	instanceTypes[0].Region = "eu-west-1"
+	outputStr = strings.Join(outputs.TableOutputShortVertical(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "Region:         eu-west-1\n"), "the vertical short table should include the region, got %s", outputStr)
+
+	h.Assert(t, len(outputs.TableOutputShortVertical([]*instancetypes.Details{})) == 0, "Should return 0 lines when passed empty slice")
+}
+
+func TestTableOutputWideVertical(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	outputStr := strings.Join(outputs.TableOutputWideVertical(instanceTypes), "")
+	columnHeaders, _ := outputs.WideColumns(instanceTypes, nil, nil)
+	for _, block := range strings.Split(outputStr, "\n\n") {
+		lines := strings.Split(block, "\n")
+		h.Equals(t, len(columnHeaders), len(lines))
+		for i, line := range lines {
+			h.Assert(t, strings.HasPrefix(line, columnHeaders[i]+":"), "Each line should start with the header of a column, got %s", line)
+			h.Assert(t, line == strings.TrimRight(line, " "), "Lines should not have trailing spaces, got %q", line)
+		}
+	}
+	h.Assert(t, strings.Contains(outputStr, "GPU Info:             NVIDIA V100"), "the vertical wide table should align the values, got %s", outputStr)
+
+	h.Assert(t, len(outputs.TableOutputWideVertical([]*instancetypes.Details{})) == 0, "Should return 0 lines when passed empty slice")
+}
+
+func TestTableOutput_Region(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro.json")
+	h.Assert(t, !strings.Contains(strings.Join(outputs.TableOutputShort(instanceTypes), ""), "Region"), "short table should not include the region column without regions")
+	h.Assert(t, !strings.Contains(strings.Join(outputs.TableOutputWide(instanceTypes), ""), "Region"), "wide table should not include the region column without regions")
+
+	instanceTypes[0].Region = "eu-west-1"
+	for _, outputStr := range []string{strings.Join(outputs.TableOutputShort(instanceTypes), ""), strings.Join(outputs.TableOutputWide(instanceTypes), "")} {
+		h.Assert(t, strings.Contains(outputStr, "Region"), "table should include the region column")
+		h.Assert(t, strings.Contains(outputStr, "eu-west-1"), "table should include the region of the instance type")
+	}
+}
+
 func TestTableOutputWide(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
 	instanceTypeOut := outputs.TableOutputWide(instanceTypes)
@@ -95,6 +153,175 @@ func TestTableOutputWide(t *testing.T) {
 	h.Assert(t, strings.Contains(outputStr, "NVIDIA K520"), "wide table should include GPU Info")
 }
 
+func TestTableOutputWide_AutoRecoveryAndDedicatedHosts(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypes[0].AutoRecoverySupported = aws.Bool(true)
+	instanceTypes[0].DedicatedHostsSupported = aws.Bool(false)
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "Auto Recovery"), "wide table should include the Auto Recovery column")
+	h.Assert(t, strings.Contains(outputStr, "Dedicated Hosts"), "wide table should include the Dedicated Hosts column")
+	h.Assert(t, strings.Contains(outputStr, "Free Tier"), "wide table should include the Free Tier column")
+
+	outputStr = strings.Join(outputs.VerboseInstanceTypeOutput(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, `"AutoRecoverySupported": true`), "verbose output should include auto recovery support")
+	h.Assert(t, strings.Contains(outputStr, `"DedicatedHostsSupported": false`), "verbose output should include dedicated hosts support")
+}
+
+func TestTableOutputWide_EBSOptimized(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypes[0].EbsInfo.EbsOptimizedSupport = ec2types.EbsOptimizedSupportSupported
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "EBS Optimized"), "wide table should include the EBS Optimized column")
+	h.Assert(t, strings.Contains(outputStr, "  supported  "), "wide table should show that EBS optimization is only supported, got: %s", outputStr)
+}
+
+func TestTableOutputWide_Zones(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, "Zones"), "wide table should not include zones when availability zones are not filtered")
+
+	instanceTypes[0].AvailabilityZones = []instancetypes.AvailabilityZone{
+		{ZoneName: "us-east-1a", ZoneID: "use1-az6"},
+		{ZoneName: "us-east-1b", ZoneID: "use1-az1"},
+	}
+	outputStr = strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "Zones"), "wide table should include a zones column")
+	h.Assert(t, strings.Contains(outputStr, "us-east-1a (use1-az6), us-east-1b (use1-az1)"), "wide table should include zone names and IDs")
+
+	outputStr = strings.Join(outputs.VerboseInstanceTypeOutput(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, `"ZoneId": "use1-az6"`), "verbose output should include zone IDs")
+}
+
+func TestTableOutputWide_EBSColumns(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, outputs.EBSBandwidthColumn), "wide table should not include EBS columns by default")
+
+	instanceTypes[0].EbsInfo.EbsOptimizedInfo = &ec2types.EbsOptimizedInfo{
+		BaselineBandwidthInMbps:  aws.Int32(1000),
+		MaximumBandwidthInMbps:   aws.Int32(4750),
+		BaselineThroughputInMBps: aws.Float64(125),
+		MaximumThroughputInMBps:  aws.Float64(593.75),
+		BaselineIops:             aws.Int32(8000),
+		MaximumIops:              aws.Int32(20000),
+	}
+	outputStr = strings.Join(outputs.TableOutputWideWithColumns(outputs.EBSColumns...)(instanceTypes), "")
+	for _, column := range outputs.EBSColumns {
+		h.Assert(t, strings.Contains(outputStr, column), "wide table should include the %s column", column)
+	}
+	h.Assert(t, strings.Contains(outputStr, "1,000 / 4,750"), "wide table should include the baseline and maximum EBS bandwidth")
+	h.Assert(t, strings.Contains(outputStr, "125 / 593.75"), "wide table should include the baseline and maximum EBS throughput")
+	h.Assert(t, strings.Contains(outputStr, "8,000 / 20,000"), "wide table should include the baseline and maximum EBS IOPS")
+}
+
+func TestTableOutputWide_CustomColumns(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	customColumns := []outputs.CustomColumn{
+		{Name: "Max ENIs", Path: ".NetworkInfo.MaximumNetworkInterfaces"},
+		{Name: "Root Devices", Path: ".SupportedRootDeviceTypes"},
+		{Name: "Nitro TPM", Path: ".NitroTpmInfo"},
+	}
+	outputStr := strings.Join(outputs.TableOutputWideWithCustomColumns(customColumns)(instanceTypes), "")
+	lines := strings.Split(outputStr, "\n")
+	h.Assert(t, strings.Index(lines[0], "Spot Savings") < strings.Index(lines[0], "Max ENIs"), "custom columns should be displayed after the other columns; got %s", lines[0])
+	fields := strings.Fields(lines[2])
+	h.Equals(t, []string{"4", `["ebs","instance-store"]`, "-"}, fields[len(fields)-3:])
+}
+
+func TestLoadCustomColumns(t *testing.T) {
+	columnsFile := filepath.Join(t.TempDir(), "columns.yaml")
+	h.Ok(t, os.WriteFile(columnsFile, []byte("Columns:\n  Max ENIs: .NetworkInfo.MaximumNetworkInterfaces\n  Cores: .VCpuInfo.DefaultCores\n"), 0o600))
+	customColumns, err := outputs.LoadCustomColumns(columnsFile)
+	h.Ok(t, err)
+	h.Equals(t, []outputs.CustomColumn{
+		{Name: "Max ENIs", Path: ".NetworkInfo.MaximumNetworkInterfaces"},
+		{Name: "Cores", Path: ".VCpuInfo.DefaultCores"},
+	}, customColumns)
+
+	for _, invalid := range []string{
+		"Columns: .VCpuInfo.DefaultCores\n",
+		"Columns:\n  VCPUs: .VCpuInfo.DefaultVCpus\n",
+		"Columns:\n  Cores: [.VCpuInfo.DefaultCores]\n",
+		"Columns:\n  Cores: \"fdsafdsafdjskalfjlsf #@\"\n",
+		"Sort: .VCpuInfo.DefaultCores\n",
+	} {
+		h.Ok(t, os.WriteFile(columnsFile, []byte(invalid), 0o600))
+		_, err = outputs.LoadCustomColumns(columnsFile)
+		h.Assert(t, err != nil, "columns file %q should be invalid", invalid)
+	}
+}
+
+func TestTableOutputWide_NetworkColumns(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "p5_48xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, outputs.NetworkCardsColumn), "wide table should not include the network columns by default")
+
+	instanceTypes[0].EnaQueuesPerInterface = aws.Int32(32)
+	instanceTypes[0].EnaQueues = aws.Int32(2048)
+	outputStr = strings.Join(outputs.TableOutputWideWithColumns(outputs.NetworkColumns...)(instanceTypes), "")
+	for _, column := range outputs.NetworkColumns {
+		h.Assert(t, strings.Contains(outputStr, column), "wide table should include the %s column", column)
+	}
+	h.Assert(t, strings.Contains(outputStr, "  32  "), "wide table should include the network cards, got: %s", outputStr)
+	h.Assert(t, strings.Contains(outputStr, "32 / 2,048"), "wide table should include the ENA queues per interface and in total, got: %s", outputStr)
+}
+
+func TestTableOutputWide_PricePeriodColumns(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, outputs.OnDemandPricePerMonthColumn), "wide table should not include the monthly price columns by default")
+
+	instanceTypes[0].OndemandPricePerMonth = aws.Float64(474.5)
+	columns := append(outputs.PricePerMonthColumns, outputs.PricePerYearColumns...)
+	outputStr = strings.Join(outputs.TableOutputWideWithColumns(columns...)(instanceTypes), "")
+	for _, column := range columns {
+		h.Assert(t, strings.Contains(outputStr, column), "wide table should include the %s column", column)
+	}
+	h.Assert(t, strings.Contains(outputStr, "$474.5"), "wide table should include the monthly on-demand price")
+	h.Assert(t, strings.Contains(outputStr, "$5,694"), "wide table should include the annual on-demand price")
+}
+
+func TestTableOutputWide_NFUColumn(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, outputs.NFUColumn), "wide table should not include the NFU column by default")
+
+	instanceTypes[0].NormalizationFactor = aws.Float64(16)
+	outputStr = strings.Join(outputs.TableOutputWideWithColumns(outputs.NFUColumn)(instanceTypes), "")
+	lines := strings.Split(outputStr, "\n")
+	h.Assert(t, strings.Contains(lines[0], outputs.NFUColumn), "wide table should include the NFU column")
+	h.Equals(t, "16", strings.Fields(lines[2])[3])
+}
+
+func TestTableOutputWide_DeprecatedColumn(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, "Deprecated"), "wide table should not include the Deprecated column when no instance types are deprecated")
+
+	instanceTypes[0].Deprecation = aws.String("previous generation")
+	outputStr = strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "Deprecated"), "wide table should include the Deprecated column")
+	h.Assert(t, strings.Contains(outputStr, "previous generation"), "wide table should include the deprecation reason")
+}
+
+func TestTableOutputWide_SpotSavings(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	instanceTypes[0].SpotSavings = aws.Float64(71.6)
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "Spot Savings"), "wide table should include the Spot Savings column")
+	h.Assert(t, strings.Contains(outputStr, "72%"), "wide table should include the rounded spot savings percentage")
+}
+
+func TestTableOutputWide_ComputeOptimizerRankColumn(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
+	outputStr := strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, !strings.Contains(outputStr, "CO Rank"), "wide table should not include the CO Rank column without Compute Optimizer options")
+
+	instanceTypes[0].ComputeOptimizerRank = aws.Int32(2)
+	outputStr = strings.Join(outputs.TableOutputWide(instanceTypes), "")
+	h.Assert(t, strings.Contains(outputStr, "CO Rank"), "wide table should include the CO Rank column")
+}
+
 func TestTableOutput_MBtoGB(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "g2_2xlarge.json")
 	instanceTypeOut := outputs.TableOutputWide(instanceTypes)
@@ -107,6 +334,113 @@ func TestTableOutput_MBtoGB(t *testing.T) {
 	h.Assert(t, strings.Contains(outputStr, "15"), "table should include 15 GB of memory")
 }
 
+func TestNDJSONOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.NDJSONOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == len(instanceTypes), "Should return one line per instance type")
+	for i, line := range instanceTypeOut {
+		h.Assert(t, !strings.Contains(line, "\n"), "Each instance type should be on a single line")
+		details := instancetypes.Details{}
+		h.Ok(t, json.Unmarshal([]byte(line), &details))
+		h.Equals(t, instanceTypes[i].InstanceType, details.InstanceType)
+		h.Assert(t, strings.HasPrefix(line, `{"schemaVersion":"1",`), "Each instance type should start with the schema version, got: %s", line)
+	}
+
+	instanceTypeOut = outputs.NDJSONOutput([]*instancetypes.Details{})
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed empty slice")
+
+	instanceTypeOut = outputs.NDJSONOutput(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	buf := bytes.Buffer{}
+	h.Ok(t, outputs.WriteNDJSON(&buf, instanceTypes))
+	h.Equals(t, strings.Join(outputs.NDJSONOutput(instanceTypes), "\n")+"\n", buf.String())
+
+	buf.Reset()
+	h.Ok(t, outputs.WriteNDJSON(&buf, nil))
+	h.Equals(t, "", buf.String())
+}
+
+func TestCSVOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.CSVOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == len(instanceTypes)+1, "Should return a header and one line per instance type")
+	records, err := csv.NewReader(strings.NewReader(strings.Join(instanceTypeOut, "\n"))).ReadAll()
+	h.Ok(t, err)
+	h.Equals(t, "Instance Type", records[0][0])
+	h.Equals(t, "VCPUs", records[0][1])
+	for i, instanceType := range instanceTypes {
+		h.Equals(t, string(instanceType.InstanceType), records[i+1][0])
+		h.Equals(t, len(records[0]), len(records[i+1]))
+	}
+
+	instanceTypeOut = outputs.CSVOutput([]*instancetypes.Details{})
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 lines when passed empty slice")
+}
+
+func TestCompareTableOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	outputStr := strings.Join(outputs.CompareTableOutput(instanceTypes), "")
+	lines := strings.Split(outputStr, "\n")
+	cells := func(line string) []string { return regexp.MustCompile(`\s{2,}`).Split(strings.TrimSpace(line), -1) }
+	columnHeaders, _ := outputs.WideColumns(instanceTypes, nil, nil)
+	h.Equals(t, len(columnHeaders)+1, len(lines))
+	h.Equals(t, []string{"Instance Type", "t3.micro", "p3.16xlarge"}, cells(lines[0]))
+	h.Equals(t, []string{"-------------", "--------", "-----------"}, cells(lines[1]))
+	h.Equals(t, []string{"VCPUs", "2", "64"}, cells(lines[2]))
+	h.Assert(t, strings.HasPrefix(lines[len(lines)-1], columnHeaders[len(columnHeaders)-1]), "The last row should be the last column of the wide table")
+
+	formatOut := outputs.CompareTableFormat.Output(instanceTypes, nil, nil)
+	h.Equals(t, outputStr, strings.Join(formatOut, ""))
+
+	h.Assert(t, len(outputs.CompareTableOutput([]*instancetypes.Details{})) == 0, "Should return 0 lines when passed empty slice")
+}
+
+func TestYAMLOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.YAMLOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == 1, "Should return 1 document")
+	h.Assert(t, !strings.Contains(instanceTypeOut[0], "{"), "Should not use the JSON flow style")
+	outputDetails := []struct {
+		SchemaVersion string `yaml:"schemaVersion"`
+		InstanceType  string `yaml:"InstanceType"`
+	}{}
+	h.Ok(t, yaml.Unmarshal([]byte(instanceTypeOut[0]), &outputDetails))
+	h.Equals(t, len(instanceTypes), len(outputDetails))
+	for i, instanceType := range instanceTypes {
+		h.Equals(t, string(instanceType.InstanceType), outputDetails[i].InstanceType)
+		h.Equals(t, outputs.SchemaVersion, outputDetails[i].SchemaVersion)
+	}
+
+	instanceTypeOut = outputs.YAMLOutput(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 documents when passed nil")
+}
+
+func TestCDKTypeScriptOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.CDKTypeScriptOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == 1, "Should always return 1 snippet")
+	h.Assert(t, strings.Contains(instanceTypeOut[0], "  new ec2.InstanceType('t3.micro'),\n  new ec2.InstanceType('p3.16xlarge'),\n"), "Should include both instance types in order")
+	h.Assert(t, strings.Contains(instanceTypeOut[0], "autoscaling.MixedInstancesPolicy"), "Should include a MixedInstancesPolicy")
+
+	instanceTypeOut = outputs.CDKTypeScriptOutput([]*instancetypes.Details{})
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 snippets when passed empty slice")
+}
+
+func TestCDKGoOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	instanceTypeOut := outputs.CDKGoOutput(instanceTypes)
+	h.Assert(t, len(instanceTypeOut) == 1, "Should always return 1 snippet")
+	h.Assert(t, strings.Contains(instanceTypeOut[0], "\tawsec2.NewInstanceType(jsii.String(\"t3.micro\")),\n\tawsec2.NewInstanceType(jsii.String(\"p3.16xlarge\")),\n"), "Should include both instance types in order")
+	h.Assert(t, strings.Contains(instanceTypeOut[0], "*awsautoscaling.MixedInstancesPolicy"), "Should include a MixedInstancesPolicy")
+
+	instanceTypeOut = outputs.CDKGoOutput(nil)
+	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 snippets when passed nil")
+}
+
 func TestOneLineOutput(t *testing.T) {
 	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
 	instanceTypeOut := outputs.OneLineOutput(instanceTypes)
@@ -119,3 +453,45 @@ func TestOneLineOutput(t *testing.T) {
 	instanceTypeOut = outputs.OneLineOutput(nil)
 	h.Assert(t, len(instanceTypeOut) == 0, "Should return 0 instance types when passed nil")
 }
+
+func TestFamiliesOnlyOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "25_instances.json")
+	h.Equals(t, []string{"a1", "c1", "c3", "c4", "c5"}, outputs.FamiliesOnlyOutput(instanceTypes))
+	h.Equals(t, 5, len(outputs.FirstOfEachFamily(instanceTypes)))
+
+	instanceTypes = []*instancetypes.Details{
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "ml.m5.large"}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "ml.m5.xlarge"}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "ml.c5.large"}},
+	}
+	h.Equals(t, []string{"ml.m5", "ml.c5"}, outputs.FamiliesOnlyOutput(instanceTypes))
+	h.Equals(t, []string{}, outputs.FamiliesOnlyOutput(nil))
+}
+
+func TestDatabaseClassesOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "t3_micro_and_p3_16xl.json")
+	h.Equals(t, []string{"db.t3.micro"}, outputs.RDSClassesOutput(instanceTypes))
+	h.Equals(t, []string{"cache.t3.micro"}, outputs.ElastiCacheClassesOutput(instanceTypes))
+
+	instanceTypes = []*instancetypes.Details{
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "r6i.large"}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "r6i.metal"}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "c7gn.xlarge"}},
+	}
+	h.Equals(t, []string{"db.r6i.large"}, outputs.RDSClassesOutput(instanceTypes))
+	h.Equals(t, []string{"cache.c7gn.xlarge"}, outputs.ElastiCacheClassesOutput(instanceTypes))
+	h.Equals(t, []string{}, outputs.RDSClassesOutput(nil))
+}
+
+func TestSummaryOutput(t *testing.T) {
+	instanceTypes := getInstanceTypes(t, "25_instances.json")
+	prices := []float64{0.1, 0.4, 0.2}
+	for i, price := range prices {
+		instanceTypes[i].SpotPrice = &price
+	}
+	outputStr := strings.Join(outputs.SummaryOutput(instanceTypes, 20), "")
+	h.Assert(t, strings.Contains(outputStr, "25 (20 displayed)"), "summary should include the number of matches and displayed instance types")
+	h.Assert(t, strings.Contains(outputStr, "min $0.53, median $0.53, max $0.53"), "summary should include the on-demand price range")
+	h.Assert(t, strings.Contains(outputStr, "min $0.1, median $0.2, max $0.4"), "summary should include the spot price range of the instance types with spot prices")
+	h.Assert(t, len(outputs.SummaryOutput(nil, 0)) == 0, "summary should be empty without instance types")
+}
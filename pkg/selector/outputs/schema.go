@@ -0,0 +1,162 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// SchemaVersion is the version of the schema of the instance types printed by the verbose, ndjson, and interactive JSON
+// export outputs, it is embedded in each instance type as schemaVersion. Fields are only added within a schema version,
+// the version is bumped when fields are removed, renamed, or change type so that parsers can detect breaking changes.
+const SchemaVersion = "1"
+
+// jsonSchemaDialect is the JSON Schema draft the schema returned by JSONSchema conforms to.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// versionedDetails is an instance type of the JSON outputs with the version of their schema.
+type versionedDetails struct {
+	SchemaVersion string `json:"schemaVersion"`
+	*instancetypes.Details
+}
+
+// withSchemaVersion adds the schema version to each of the instance types.
+func withSchemaVersion(instanceTypeInfoSlice []*instancetypes.Details) []versionedDetails {
+	versioned := make([]versionedDetails, 0, len(instanceTypeInfoSlice))
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		versioned = append(versioned, versionedDetails{SchemaVersion: SchemaVersion, Details: instanceTypeInfo})
+	}
+	return versioned
+}
+
+// JSONSchema returns the JSON Schema of each instance type printed by the verbose, ndjson, and interactive JSON export
+// outputs. It is generated from the fields of instancetypes.Details so that it never drifts from the outputs, fields
+// which are not in the schema of the same schema version may be added and should be ignored by parsers.
+func JSONSchema() ([]byte, error) {
+	schema := jsonSchemaOf(reflect.TypeOf(versionedDetails{}), map[reflect.Type]bool{})
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = "EC2 Instance Selector instance type"
+	schema["description"] = "An instance type of the ec2-instance-selector verbose, ndjson, and interactive JSON export outputs"
+	schema["properties"].(map[string]any)["schemaVersion"] = map[string]any{"const": SchemaVersion}
+	return json.MarshalIndent(schema, "", "    ")
+}
+
+// jsonSchemaOf returns the JSON Schema of the values encoding/json marshals a type to. Types which are already being
+// described higher up, which only recursive types are, are described as any value.
+func jsonSchemaOf(t reflect.Type, describing map[reflect.Type]bool) map[string]any {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(jsonSchemaOf(t.Elem(), describing))
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// byte slices are base64 encoded
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return nullable(map[string]any{"type": "array", "items": jsonSchemaOf(t.Elem(), describing)})
+	case t.Kind() == reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem(), describing)})
+	case t.Kind() == reflect.Struct:
+		if describing[t] {
+			return map[string]any{}
+		}
+		describing[t] = true
+		defer delete(describing, t)
+		properties := map[string]any{}
+		required := []string{}
+		addStructProperties(t, properties, &required, describing)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	case t.Kind() == reflect.String:
+		schema := map[string]any{"type": "string"}
+		// the EC2 SDK enums list their known values, values added to the EC2 API after the SDK was released are still valid
+		if values := enumValues(t); len(values) > 0 {
+			schema["examples"] = values
+		}
+		return schema
+	}
+	return map[string]any{}
+}
+
+// addStructProperties adds the fields of a struct to properties like encoding/json marshals them, fields of embedded
+// structs are promoted and fields without omitempty are always present so they are required.
+func addStructProperties(t reflect.Type, properties map[string]any, required *[]string, describing map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			addStructProperties(fieldType, properties, required, describing)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchemaOf(field.Type, describing)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows a schema to also be null, which nil pointers, slices, and maps are marshaled to.
+func nullable(schema map[string]any) map[string]any {
+	if schemaType, ok := schema["type"].(string); ok {
+		schema["type"] = []string{schemaType, "null"}
+		return schema
+	}
+	if len(schema) == 0 {
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
+
+// enumValues returns the values of string enum types which implement a Values() method like the EC2 SDK enums.
+func enumValues(t reflect.Type) []string {
+	valuesMethod, ok := t.MethodByName("Values")
+	if !ok || valuesMethod.Type.NumIn() != 1 || valuesMethod.Type.NumOut() != 1 {
+		return nil
+	}
+	values := valuesMethod.Func.Call([]reflect.Value{reflect.Zero(t)})[0]
+	if values.Kind() != reflect.Slice {
+		return nil
+	}
+	enum := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		enum = append(enum, values.Index(i).String())
+	}
+	return enum
+}
@@ -0,0 +1,68 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schemaJSON, err := outputs.JSONSchema()
+	h.Ok(t, err)
+	schema := map[string]any{}
+	h.Ok(t, json.Unmarshal(schemaJSON, &schema))
+	h.Equals(t, map[string]any{"const": outputs.SchemaVersion}, schema["properties"].(map[string]any)["schemaVersion"])
+
+	instanceTypes := append(getInstanceTypes(t, "t3_micro_and_p3_16xl.json"), getInstanceTypes(t, "p5_48xlarge.json")...)
+	instanceTypes[0].OndemandPricePerHour = aws.Float64(0.0104)
+	instanceTypes[0].AvailabilityZones = []instancetypes.AvailabilityZone{{ZoneName: "us-east-1a", ZoneID: "use1-az6"}}
+	for _, line := range outputs.NDJSONOutput(instanceTypes) {
+		instanceType := map[string]any{}
+		h.Ok(t, json.Unmarshal([]byte(line), &instanceType))
+		assertMatchesSchema(t, "", schema, instanceType)
+	}
+}
+
+// assertMatchesSchema asserts that every field of the value is described by the schema and that its required fields are present.
+func assertMatchesSchema(t *testing.T, path string, schema map[string]any, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		properties, ok := schema["properties"].(map[string]any)
+		if !ok {
+			return
+		}
+		for key, fieldValue := range v {
+			fieldSchema, ok := properties[key].(map[string]any)
+			h.Assert(t, ok, "%s.%s should be described by the schema", path, key)
+			assertMatchesSchema(t, path+"."+key, fieldSchema, fieldValue)
+		}
+		required, _ := schema["required"].([]any)
+		for _, key := range required {
+			_, ok := v[key.(string)]
+			h.Assert(t, ok, "%s.%s should be present since it is required by the schema", path, key)
+		}
+	case []any:
+		items, ok := schema["items"].(map[string]any)
+		h.Assert(t, ok, "%s should be described as an array by the schema", path)
+		for _, item := range v {
+			assertMatchesSchema(t, path+"[]", items, item)
+		}
+	}
+}
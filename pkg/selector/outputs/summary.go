@@ -0,0 +1,86 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// SummaryOutput returns a summary of the instance types to print after a table, the number of instance types which
+// matched, displayed is the number of instance types in the table, and the ranges of their vCPUs, memory, and prices.
+func SummaryOutput(instanceTypeInfoSlice []*instancetypes.Details, displayed int) []string {
+	if len(instanceTypeInfoSlice) == 0 {
+		return nil
+	}
+	var vcpus, memory, onDemandPrices, spotPrices []float64
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		if instanceTypeInfo.VCpuInfo != nil && instanceTypeInfo.VCpuInfo.DefaultVCpus != nil {
+			vcpus = append(vcpus, float64(*instanceTypeInfo.VCpuInfo.DefaultVCpus))
+		}
+		if instanceTypeInfo.MemoryInfo != nil && instanceTypeInfo.MemoryInfo.SizeInMiB != nil {
+			memory = append(memory, float64(*instanceTypeInfo.MemoryInfo.SizeInMiB)/1024.0)
+		}
+		if instanceTypeInfo.OndemandPricePerHour != nil {
+			onDemandPrices = append(onDemandPrices, *instanceTypeInfo.OndemandPricePerHour)
+		}
+		if instanceTypeInfo.SpotPrice != nil {
+			spotPrices = append(spotPrices, *instanceTypeInfo.SpotPrice)
+		}
+	}
+
+	w := new(tabwriter.Writer)
+	buf := new(bytes.Buffer)
+	w.Init(buf, 8, 8, 2, ' ', 0)
+	matches := fmt.Sprintf("%d", len(instanceTypeInfoSlice))
+	if displayed < len(instanceTypeInfoSlice) {
+		matches = fmt.Sprintf("%s (%d displayed)", matches, displayed)
+	}
+	fmt.Fprintf(w, "Matches:\t%s\n", matches)
+	fmt.Fprintf(w, "VCPUs:\t%s\n", formatRange(vcpus))
+	fmt.Fprintf(w, "Mem (GiB):\t%s\n", formatRange(memory))
+	fmt.Fprintf(w, "On-Demand Price/Hr:\t%s\n", formatMinMedianMax(onDemandPrices, "$"))
+	fmt.Fprintf(w, "Spot Price/Hr (30d avg):\t%s", formatMinMedianMax(spotPrices, "$"))
+	w.Flush()
+	return []string{buf.String()}
+}
+
+// formatRange formats the minimum and maximum of values as "min - max" or a single value if they are equal.
+func formatRange(values []float64) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	minValue, maxValue := slices.Min(values), slices.Max(values)
+	if minValue == maxValue {
+		return formatFloat(minValue)
+	}
+	return fmt.Sprintf("%s - %s", formatFloat(minValue), formatFloat(maxValue))
+}
+
+// formatMinMedianMax formats the minimum, median, and maximum of values.
+func formatMinMedianMax(values []float64, prefix string) string {
+	if len(values) == 0 {
+		return "-Not Fetched-"
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return fmt.Sprintf("min %s%s, median %s%s, max %s%s", prefix, formatFloat(sorted[0]), prefix, formatFloat(median), prefix, formatFloat(sorted[len(sorted)-1]))
+}
@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// instanceTypeGlobRegex matches patterns which only contain the characters of instance type names and at least one
+// * or ? wildcard (i.e. m5*.large or c?.xlarge).
+var instanceTypeGlobRegex = regexp.MustCompile(`^[a-z0-9.\-*?]*[*?][a-z0-9.\-*?]*$`)
+
+// IsInstanceTypeGlob returns true if the pattern is a glob of instance type names rather than a regex.
+// Patterns containing .* or .? are treated as regexes so that existing regexes like m5.* keep their meaning.
+func IsInstanceTypeGlob(pattern string) bool {
+	return instanceTypeGlobRegex.MatchString(pattern) && !strings.Contains(pattern, ".*") && !strings.Contains(pattern, ".?")
+}
+
+// CompileInstanceTypePattern compiles an allow or deny list pattern.
+// Globs where * matches any characters and ? matches a single character are translated to a regex which must match the
+// whole instance type name, so m5*.large matches m5.large and m5dn.large but not xm5.large or m5.large2.
+// Any other pattern is compiled as a regex.
+func CompileInstanceTypePattern(pattern string) (*regexp.Regexp, error) {
+	if !IsInstanceTypeGlob(pattern) {
+		return regexp.Compile(pattern)
+	}
+	var regex strings.Builder
+	regex.WriteString("^")
+	for _, char := range pattern {
+		switch char {
+		case '*':
+			regex.WriteString(".*")
+		case '?':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	regex.WriteString("$")
+	compiled, err := regexp.Compile(regex.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile the glob %s: %w", pattern, err)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,130 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// spotPriceLookupDays is the number of days the spot prices returned by Prices are averaged over, the same as the
+// spot prices which instance types are filtered by.
+const spotPriceLookupDays = 30
+
+// InstanceTypePrices are the hourly on-demand price and the hourly spot price in each availability zone of an instance type.
+type InstanceTypePrices struct {
+	InstanceType         ec2types.InstanceType
+	OnDemandPricePerHour *float64 `json:",omitempty"`
+	// SpotPricesPerHour are the 30 day average spot prices keyed by availability zone name
+	// It is empty if the instance type can not be purchased as spot instances
+	SpotPricesPerHour map[string]float64 `json:",omitempty"`
+}
+
+// SpotZones returns the availability zone names of the spot prices in order.
+func (p InstanceTypePrices) SpotZones() []string {
+	zones := []string{}
+	for zone := range p.SpotPricesPerHour {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// Prices looks up the prices of the instance types from the pricing caches without filtering them, prices which are not
+// cached are retrieved from the pricing APIs. Spot prices are only returned for the availabilityZones if any are passed.
+func (s Selector) Prices(ctx context.Context, instanceTypes []ec2types.InstanceType, availabilityZones []string) ([]InstanceTypePrices, error) {
+	instanceTypeDetails, err := s.instanceTypesProvider().Get(ctx, instanceTypes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the instance types to look up prices for: %w", err)
+	}
+	prices := []InstanceTypePrices{}
+	for _, instanceTypeInfo := range instanceTypeDetails {
+		instanceTypePrices := InstanceTypePrices{InstanceType: instanceTypeInfo.InstanceType}
+		onDemandPrice, err := s.EC2Pricing.GetOnDemandInstanceTypeCost(ctx, instanceTypeInfo.InstanceType)
+		if err != nil {
+			s.Logger.Printf("Could not retrieve the on-demand price for instance type %s - %s\n", instanceTypeInfo.InstanceType, err)
+		} else {
+			instanceTypePrices.OnDemandPricePerHour = &onDemandPrice
+		}
+		if slices.Contains(instanceTypeInfo.SupportedUsageClasses, ec2types.UsageClassTypeSpot) {
+			spotPrices, err := s.getSpotPricesByZone(ctx, instanceTypeInfo.InstanceType, availabilityZones)
+			if err != nil {
+				s.Logger.Printf("Could not retrieve the spot prices for instance type %s - %s\n", instanceTypeInfo.InstanceType, err)
+			}
+			instanceTypePrices.SpotPricesPerHour = map[string]float64{}
+			for zone, price := range spotPrices {
+				if len(availabilityZones) == 0 || slices.Contains(availabilityZones, zone) {
+					instanceTypePrices.SpotPricesPerHour[zone] = price
+				}
+			}
+		}
+		prices = append(prices, instanceTypePrices)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].InstanceType < prices[j].InstanceType
+	})
+	return prices, nil
+}
+
+// getSpotPricesByZone returns the spot prices of the instance type keyed by availability zone name. Pricing sources which
+// do not implement ec2pricing.ZonalSpotPricer are asked for the price of each of the availabilityZones separately.
+func (s Selector) getSpotPricesByZone(ctx context.Context, instanceType ec2types.InstanceType, availabilityZones []string) (map[string]float64, error) {
+	if zonalPricer, ok := s.EC2Pricing.(ec2pricing.ZonalSpotPricer); ok {
+		return zonalPricer.GetSpotInstanceTypeNDayAvgCostByZone(ctx, instanceType, spotPriceLookupDays)
+	}
+	if len(availabilityZones) == 0 {
+		return nil, fmt.Errorf("the pricing source does not retrieve spot prices by availability zone, pass availability zones to look up")
+	}
+	spotPrices := map[string]float64{}
+	for _, zone := range availabilityZones {
+		price, err := s.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(ctx, instanceType, []string{zone}, spotPriceLookupDays)
+		if err != nil {
+			return spotPrices, err
+		}
+		spotPrices[zone] = price
+	}
+	return spotPrices, nil
+}
+
+// AddPrices returns copies of instance types returned by FilterVerbose with the prices of the pricing caches and the
+// values derived from them (Ex: SpotSavings and Annotations), so that instance types which were filtered before the
+// pricing caches were hydrated can be priced once they are. filters are the filters the instance types were filtered
+// with. Capacity Block prices are not cached so the Capacity Block prices of the instance types are kept.
+func (s Selector) AddPrices(ctx context.Context, filters Filters, instanceTypes []*instancetypes.Details) ([]*instancetypes.Details, error) {
+	availabilityZones, err := s.getPricingZoneNames(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the availability zones to look up prices in: %w", err)
+	}
+	if filters.UsageClass != nil && *filters.UsageClass == ec2types.UsageClassTypeCapacityBlock {
+		filters.UsageClass = nil
+	}
+	priced := make([]*instancetypes.Details, 0, len(instanceTypes))
+	for _, instanceTypeInfo := range instanceTypes {
+		// the prices are looked up by the EC2 name of instance types which were renamed with SageMakerInstanceTypeName
+		instanceTypeName := instanceTypeInfo.InstanceType
+		unpriced := *instanceTypeInfo
+		unpriced.InstanceType = ec2types.InstanceType(strings.TrimPrefix(string(instanceTypeName), SageMakerInstanceTypePrefix))
+		pricedInstanceType, _ := s.prepareInstanceType(ctx, filters, unpriced, availabilityZones)
+		pricedInstanceType.InstanceType = instanceTypeName
+		priced = append(priced, &pricedInstanceType)
+	}
+	return priced, nil
+}
@@ -0,0 +1,140 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// SageMakerInstanceTypePrefix is the prefix of the SageMaker names of instance types (Example: ml.m5.large)
+	SageMakerInstanceTypePrefix = "ml."
+
+	sageMakerTraining = "training"
+	sageMakerHosting  = "hosting"
+	sageMakerNotebook = "notebook"
+)
+
+var (
+	sageMakerSizesLargeTo24xlarge  = []string{"large", "xlarge", "2xlarge", "4xlarge", "12xlarge", "24xlarge"}
+	sageMakerSizesXlargeTo24xlarge = []string{"xlarge", "2xlarge", "4xlarge", "12xlarge", "24xlarge"}
+	sageMakerSizesC5               = []string{"xlarge", "2xlarge", "4xlarge", "9xlarge", "18xlarge"}
+	sageMakerSizesC4               = []string{"xlarge", "2xlarge", "4xlarge", "8xlarge"}
+	sageMakerSizesM4               = []string{"xlarge", "2xlarge", "4xlarge", "10xlarge", "16xlarge"}
+	sageMakerSizesT                = []string{"medium", "large", "xlarge", "2xlarge"}
+	sageMakerSizesP2               = []string{"xlarge", "8xlarge", "16xlarge"}
+	sageMakerSizesP3               = []string{"2xlarge", "8xlarge", "16xlarge"}
+	sageMakerSizesG4dn             = []string{"xlarge", "2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge"}
+	sageMakerSizesG5               = []string{"xlarge", "2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge", "24xlarge", "48xlarge"}
+	sageMakerSizesInf1             = []string{"xlarge", "2xlarge", "6xlarge", "24xlarge"}
+	sageMakerSizesInf2             = []string{"xlarge", "8xlarge", "24xlarge", "48xlarge"}
+)
+
+// sageMakerFamilies are the sizes of each instance family SageMaker supports for each kind of workload.
+var sageMakerFamilies = map[string]map[string][]string{
+	sageMakerTraining: {
+		"m4":    sageMakerSizesM4,
+		"m5":    sageMakerSizesLargeTo24xlarge,
+		"c4":    sageMakerSizesC4,
+		"c5":    sageMakerSizesC5,
+		"c5n":   sageMakerSizesC5,
+		"r5":    sageMakerSizesLargeTo24xlarge,
+		"p2":    sageMakerSizesP2,
+		"p3":    sageMakerSizesP3,
+		"p3dn":  {"24xlarge"},
+		"p4d":   {"24xlarge"},
+		"p4de":  {"24xlarge"},
+		"p5":    {"48xlarge"},
+		"g4dn":  sageMakerSizesG4dn,
+		"g5":    sageMakerSizesG5,
+		"trn1":  {"2xlarge", "32xlarge"},
+		"trn1n": {"32xlarge"},
+	},
+	sageMakerHosting: {
+		"t2":   sageMakerSizesT,
+		"m4":   sageMakerSizesM4,
+		"m5":   sageMakerSizesLargeTo24xlarge,
+		"m5d":  sageMakerSizesLargeTo24xlarge,
+		"c4":   sageMakerSizesC4,
+		"c5":   sageMakerSizesC5,
+		"c5d":  sageMakerSizesC5,
+		"r5":   sageMakerSizesLargeTo24xlarge,
+		"r5d":  sageMakerSizesLargeTo24xlarge,
+		"p2":   sageMakerSizesP2,
+		"p3":   sageMakerSizesP3,
+		"g4dn": sageMakerSizesG4dn,
+		"g5":   sageMakerSizesG5,
+		"inf1": sageMakerSizesInf1,
+		"inf2": sageMakerSizesInf2,
+	},
+	sageMakerNotebook: {
+		"t2":   sageMakerSizesT,
+		"t3":   sageMakerSizesT,
+		"m4":   sageMakerSizesM4,
+		"m5":   sageMakerSizesXlargeTo24xlarge,
+		"c4":   sageMakerSizesC4,
+		"c5":   sageMakerSizesC5,
+		"r5":   sageMakerSizesLargeTo24xlarge,
+		"p2":   sageMakerSizesP2,
+		"p3":   sageMakerSizesP3,
+		"g4dn": sageMakerSizesG4dn,
+		"g5":   sageMakerSizesG5,
+	},
+}
+
+// SageMaker is a Service type for a custom service filter transform.
+// The version is the kind of workload: training, hosting or notebook. All of them are included when it is empty.
+type SageMaker struct{}
+
+// Filters implements the Service interface contract for SageMaker.
+func (s SageMaker) Filters(version string) (Filters, error) {
+	filters := Filters{}
+	workloads := []string{sageMakerTraining, sageMakerHosting, sageMakerNotebook}
+	if version != "" {
+		if _, ok := sageMakerFamilies[version]; !ok {
+			return filters, fmt.Errorf("SageMaker workload %s is not supported, the supported workloads are %s", version, strings.Join(workloads, ", "))
+		}
+		workloads = []string{version}
+	}
+	instanceTypes := s.getSageMakerInstanceTypes(workloads)
+	filters.InstanceTypes = &instanceTypes
+	return filters, nil
+}
+
+// getSageMakerInstanceTypes returns the EC2 names of the instance types SageMaker supports for any of the workloads.
+func (SageMaker) getSageMakerInstanceTypes(workloads []string) []string {
+	uniqueInstanceTypes := map[string]bool{}
+	for _, workload := range workloads {
+		for family, sizes := range sageMakerFamilies[workload] {
+			for _, size := range sizes {
+				uniqueInstanceTypes[fmt.Sprintf("%s.%s", family, size)] = true
+			}
+		}
+	}
+	instanceTypes := []string{}
+	for instanceType := range uniqueInstanceTypes {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	sort.Strings(instanceTypes)
+	return instanceTypes
+}
+
+// SageMakerInstanceTypeName returns the SageMaker name of an EC2 instance type (Example: m5.large is ml.m5.large).
+func SageMakerInstanceTypeName(instanceType string) string {
+	if strings.HasPrefix(instanceType, SageMakerInstanceTypePrefix) {
+		return instanceType
+	}
+	return SageMakerInstanceTypePrefix + instanceType
+}
@@ -0,0 +1,60 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestSageMakerDefaultService(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.RegisterAWSServices()
+
+	sageMaker := "sagemaker"
+	transformedFilters, err := registry.ExecuteTransforms(selector.Filters{Service: &sageMaker})
+	h.Ok(t, err)
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "p4d.24xlarge"), "sagemaker should include the p4d.24xlarge training instance type")
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "inf2.xlarge"), "sagemaker should include the inf2.xlarge hosting instance type")
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "t3.medium"), "sagemaker should include the t3.medium notebook instance type")
+}
+
+func TestSageMakerWorkloads(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.RegisterAWSServices()
+
+	training := "sagemaker-training"
+	transformedFilters, err := registry.ExecuteTransforms(selector.Filters{Service: &training})
+	h.Ok(t, err)
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "trn1.32xlarge"), "sagemaker training should include trn1.32xlarge")
+	h.Assert(t, !contains(*transformedFilters.InstanceTypes, "inf2.xlarge"), "sagemaker training should not include inf2.xlarge")
+
+	hosting := "sagemaker-hosting"
+	transformedFilters, err = registry.ExecuteTransforms(selector.Filters{Service: &hosting})
+	h.Ok(t, err)
+	h.Assert(t, contains(*transformedFilters.InstanceTypes, "inf2.xlarge"), "sagemaker hosting should include inf2.xlarge")
+	h.Assert(t, !contains(*transformedFilters.InstanceTypes, "p4d.24xlarge"), "sagemaker hosting should not include p4d.24xlarge")
+
+	unknown := "sagemaker-inference"
+	_, err = registry.ExecuteTransforms(selector.Filters{Service: &unknown})
+	h.Nok(t, err)
+}
+
+func TestSageMakerInstanceTypeName(t *testing.T) {
+	h.Equals(t, "ml.m5.large", selector.SageMakerInstanceTypeName("m5.large"))
+	h.Equals(t, "ml.m5.large", selector.SageMakerInstanceTypeName("ml.m5.large"))
+}
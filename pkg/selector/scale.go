@@ -0,0 +1,99 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// instanceTypeCapacity is the number of vCPUs and the memory of an instance type which its size is ordered by.
+type instanceTypeCapacity struct {
+	vcpus     int32
+	memoryMiB int64
+}
+
+func capacityOf(instanceTypeInfo *instancetypes.Details) instanceTypeCapacity {
+	capacity := instanceTypeCapacity{}
+	if instanceTypeInfo.VCpuInfo != nil {
+		capacity.vcpus = aws.ToInt32(instanceTypeInfo.VCpuInfo.DefaultVCpus)
+	}
+	if instanceTypeInfo.MemoryInfo != nil {
+		capacity.memoryMiB = aws.ToInt64(instanceTypeInfo.MemoryInfo.SizeInMiB)
+	}
+	return capacity
+}
+
+func compareCapacity(a, b instanceTypeCapacity) int {
+	if c := cmp.Compare(a.vcpus, b.vcpus); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.memoryMiB, b.memoryMiB)
+}
+
+// ScaleInstanceType returns the instance type of the same family which is steps sizes larger than instanceType, or smaller
+// if steps is negative (Ex: 1 step from m5.xlarge is m5.2xlarge). Sizes are ordered by their vCPUs and then their memory,
+// sizes with the same vCPUs and memory count as a single step and the first of them by name is returned (Ex: m5.24xlarge
+// rather than m5.metal).
+func (c *InstanceTypesCatalog) ScaleInstanceType(instanceType ec2types.InstanceType, steps int) (*instancetypes.Details, error) {
+	instanceTypeInfo, ok := c.Get(instanceType)
+	if !ok {
+		return nil, fmt.Errorf("instance type %s is not in the catalog: %w", instanceType, ErrNotFound)
+	}
+	family, _ := InstanceTypeFamilyAndSize(instanceType)
+	// the family is sorted by name so the first instance type of each capacity is kept
+	sizes := []*instancetypes.Details{}
+	for _, familyInstanceType := range c.Family(family) {
+		if !slices.ContainsFunc(sizes, func(size *instancetypes.Details) bool {
+			return compareCapacity(capacityOf(size), capacityOf(familyInstanceType)) == 0
+		}) {
+			sizes = append(sizes, familyInstanceType)
+		}
+	}
+	slices.SortStableFunc(sizes, func(a, b *instancetypes.Details) int {
+		return compareCapacity(capacityOf(a), capacityOf(b))
+	})
+	current := slices.IndexFunc(sizes, func(size *instancetypes.Details) bool {
+		return compareCapacity(capacityOf(size), capacityOf(instanceTypeInfo)) == 0
+	})
+	target := current + steps
+	if target < 0 || target >= len(sizes) {
+		return nil, fmt.Errorf("the %s family has %d sizes smaller and %d sizes larger than %s, unable to scale by %d steps: %w",
+			family, current, len(sizes)-current-1, instanceType, steps, ErrNotFound)
+	}
+	return sizes[target], nil
+}
+
+// EquivalentInstanceTypes returns the instance types of other families with the same vCPUs and memory as instanceType
+// sorted by name (Ex: c5.2xlarge and m5.xlarge are not equivalent, m5.xlarge and m6i.xlarge are).
+func (c *InstanceTypesCatalog) EquivalentInstanceTypes(instanceType ec2types.InstanceType) ([]*instancetypes.Details, error) {
+	instanceTypeInfo, ok := c.Get(instanceType)
+	if !ok {
+		return nil, fmt.Errorf("instance type %s is not in the catalog: %w", instanceType, ErrNotFound)
+	}
+	family, _ := InstanceTypeFamilyAndSize(instanceType)
+	equivalents := []*instancetypes.Details{}
+	for _, otherInstanceType := range c.InstanceTypes {
+		otherFamily, _ := InstanceTypeFamilyAndSize(otherInstanceType.InstanceType)
+		if otherFamily != family && compareCapacity(capacityOf(otherInstanceType), capacityOf(instanceTypeInfo)) == 0 {
+			equivalents = append(equivalents, otherInstanceType)
+		}
+	}
+	return equivalents, nil
+}
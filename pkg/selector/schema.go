@@ -0,0 +1,119 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Filter schema types.
+const (
+	FilterTypeBool              = "bool"
+	FilterTypeInt               = "int"
+	FilterTypeFloat             = "float"
+	FilterTypeString            = "string"
+	FilterTypeStringList        = "string-list"
+	FilterTypeIntList           = "int-list"
+	FilterTypeRegex             = "regex"
+	FilterTypeIntRange          = "int-range"
+	FilterTypeFloatRange        = "float-range"
+	FilterTypeByteQuantityRange = "byte-quantity-range"
+)
+
+// FilterSchemaField describes a field of the Filters struct so that user interfaces can be built on top of the selector.
+type FilterSchemaField struct {
+	// Name is the name of the field in the Filters struct which is also the key used in filters files
+	Name string
+	// Type is one of the FilterType constants
+	Type string
+	// Description is a short human readable description of the filter
+	Description string
+	// Units are the units of the filter's values if the filter is a quantity (i.e. GiB)
+	Units string `json:",omitempty"`
+	// Options are the accepted values of string filters which only accept a fixed set of values
+	Options []string `json:",omitempty"`
+}
+
+// FilterSchema returns the name, type, description, units, and options of every field in the Filters struct
+// in the order they are declared.
+func FilterSchema() []FilterSchemaField {
+	filtersType := reflect.TypeOf(Filters{})
+	schema := make([]FilterSchemaField, 0, filtersType.NumField())
+	for i := 0; i < filtersType.NumField(); i++ {
+		field := filtersType.Field(i)
+		schemaField := FilterSchemaField{
+			Name:        field.Name,
+			Type:        getFilterSchemaType(field.Type.Elem()),
+			Description: field.Tag.Get("description"),
+			Units:       field.Tag.Get("units"),
+		}
+		if options := field.Tag.Get("options"); options != "" {
+			schemaField.Options = strings.Split(options, ",")
+		} else {
+			schemaField.Options = getEnumValues(field.Type.Elem())
+		}
+		schema = append(schema, schemaField)
+	}
+	return schema
+}
+
+// getFilterSchemaType returns the FilterType of a field's type or the Go type name if it is not a known filter type.
+func getFilterSchemaType(fieldType reflect.Type) string {
+	switch fieldType {
+	case reflect.TypeOf(IntRangeFilter{}), reflect.TypeOf(Int32RangeFilter{}), reflect.TypeOf(Uint64RangeFilter{}):
+		return FilterTypeIntRange
+	case reflect.TypeOf(Float64RangeFilter{}):
+		return FilterTypeFloatRange
+	case reflect.TypeOf(ByteQuantityRangeFilter{}):
+		return FilterTypeByteQuantityRange
+	case reflect.TypeOf(regexp.Regexp{}):
+		return FilterTypeRegex
+	}
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return FilterTypeBool
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return FilterTypeInt
+	case reflect.Float64:
+		return FilterTypeFloat
+	case reflect.String:
+		return FilterTypeString
+	case reflect.Slice:
+		switch fieldType.Elem().Kind() {
+		case reflect.String:
+			return FilterTypeStringList
+		case reflect.Int32:
+			return FilterTypeIntList
+		}
+	}
+	return fieldType.String()
+}
+
+// getEnumValues returns the values of string enum types which implement a Values() method like the EC2 SDK enums.
+func getEnumValues(fieldType reflect.Type) []string {
+	if fieldType.Kind() != reflect.String {
+		return nil
+	}
+	valuesMethod, ok := fieldType.MethodByName("Values")
+	if !ok {
+		return nil
+	}
+	values := valuesMethod.Func.Call([]reflect.Value{reflect.Zero(fieldType)})[0]
+	options := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		options = append(options, values.Index(i).String())
+	}
+	return options
+}
@@ -20,19 +20,24 @@ import (
 	"log"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"go.uber.org/multierr"
 
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/offerings"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
 )
 
@@ -40,38 +45,53 @@ import (
 var versionID = "dev"
 
 const (
-	locationFilterKey      = "location"
 	zoneIDLocationType     = ec2types.LocationTypeAvailabilityZoneId
 	zoneNameLocationType   = ec2types.LocationTypeAvailabilityZone
 	regionNameLocationType = ec2types.LocationTypeRegion
+	outpostLocationType    = ec2types.LocationTypeOutpost
 	sdkName                = "instance-selector"
+	// appIDSeparator separates the app ID of the aws config from the one passed to WithAppID, it is a valid user agent character
+	appIDSeparator = "_"
 
 	// Filter Keys.
 
 	cpuArchitecture                  = "cpuArchitecture"
 	cpuManufacturer                  = "cpuManufacturer"
+	cpuManufacturerNot               = "cpuManufacturerNot"
 	usageClass                       = "usageClass"
 	rootDeviceType                   = "rootDeviceType"
 	hibernationSupported             = "hibernationSupported"
 	vcpusRange                       = "vcpusRange"
+	vcpusList                        = "vcpusList"
 	memoryRange                      = "memoryRange"
 	gpuMemoryRange                   = "gpuMemoryRange"
 	gpusRange                        = "gpusRange"
+	gpusList                         = "gpusList"
 	gpuManufacturer                  = "gpuManufacturer"
 	gpuModel                         = "gpuModel"
+	gpuManufacturerNot               = "gpuManufacturerNot"
+	gpuModelNot                      = "gpuModelNot"
 	inferenceAcceleratorsRange       = "inferenceAcceleratorsRange"
 	inferenceAcceleratorManufacturer = "inferenceAcceleartorManufacturer"
 	inferenceAcceleratorModel        = "inferenceAcceleratorModel"
+	inferenceAccelManufacturerNot    = "inferenceAcceleratorManufacturerNot"
+	inferenceAcceleratorModelNot     = "inferenceAcceleratorModelNot"
 	placementGroupStrategy           = "placementGroupStrategy"
 	hypervisor                       = "hypervisor"
 	baremetal                        = "baremetal"
 	burstable                        = "burstable"
 	fpga                             = "fpga"
+	fpgasRange                       = "fpgasRange"
+	fpgaMemoryRange                  = "fpgaMemoryRange"
+	fpgaManufacturer                 = "fpgaManufacturer"
 	enaSupport                       = "enaSupport"
 	efaSupport                       = "efaSupport"
 	vcpusToMemoryRatio               = "vcpusToMemoryRatio"
 	currentGeneration                = "currentGeneration"
 	networkInterfaces                = "networkInterfaces"
+	networkCards                     = "networkCards"
+	enaQueuesPerInterface            = "enaQueuesPerInterface"
+	enaQueues                        = "enaQueues"
 	networkPerformance               = "networkPerformance"
 	networkEncryption                = "networkEncryption"
 	ipv6                             = "ipv6"
@@ -84,50 +104,195 @@ const (
 	diskType                         = "diskType"
 	nvme                             = "nvme"
 	ebsOptimized                     = "ebsOptimized"
+	ebsOptimizedSupport              = "ebsOptimizedSupport"
 	ebsOptimizedBaselineBandwidth    = "ebsOptimizedBaselineBandwidth"
 	ebsOptimizedBaselineIOPS         = "ebsOptimizedBaselineIOPS"
 	ebsOptimizedBaselineThroughput   = "ebsOptimizedBaselineThroughput"
 	freeTier                         = "freeTier"
 	autoRecovery                     = "autoRecovery"
 	dedicatedHosts                   = "dedicatedHosts"
+	tenancy                          = "tenancy"
 	generation                       = "generation"
+	nitroGeneration                  = "nitroGeneration"
+	ipv6OnlySubnetCapable            = "ipv6OnlySubnetCapable"
 
-	cpuArchitectureAMD64 = "amd64"
+	pricePerHour = "pricePerHour"
+	spotSavings  = "spotSavings"
 
-	virtualizationTypePV = "pv"
+	baselineCPURange = "baselineCPURange"
 
-	pricePerHour = "pricePerHour"
+	// capacityBlockDurationHours is the shortest Capacity Block for ML duration which is used to look up offerings
+	capacityBlockDurationHours = 24
 )
 
+// Option overrides a default dependency of a Selector created by New or NewWithCache.
+type Option func(*selectorOptions)
+
+type selectorOptions struct {
+	instanceTypesProvider instancetypes.ProviderIface
+	pricing               ec2pricing.EC2PricingIface
+	offeringsProvider     offerings.ProviderIface
+	offeringsCacheTTL     *time.Duration
+	appID                 string
+	apiCallBudget         *awsapi.Budget
+	// inMemory creates default providers which do not read or write cache files
+	inMemory bool
+}
+
+// WithInstanceTypesProvider sets the provider used to retrieve instance types instead of the EC2 backed provider.
+func WithInstanceTypesProvider(instanceTypesProvider instancetypes.ProviderIface) Option {
+	return func(o *selectorOptions) {
+		o.instanceTypesProvider = instanceTypesProvider
+	}
+}
+
+// WithPricing sets the client used to retrieve on-demand and spot pricing instead of the AWS pricing API backed client.
+func WithPricing(pricing ec2pricing.EC2PricingIface) Option {
+	return func(o *selectorOptions) {
+		o.pricing = pricing
+	}
+}
+
+// WithOfferingsProvider sets the provider used to retrieve the instance types offered in locations instead of the EC2 backed provider.
+func WithOfferingsProvider(offeringsProvider offerings.ProviderIface) Option {
+	return func(o *selectorOptions) {
+		o.offeringsProvider = offeringsProvider
+	}
+}
+
+// WithOfferingsCacheTTL caches the instance types offered in each location for ttl rather than the cache TTL of NewWithCache.
+func WithOfferingsCacheTTL(ttl time.Duration) Option {
+	return func(o *selectorOptions) {
+		o.offeringsCacheTTL = &ttl
+	}
+}
+
+// WithAppID appends the application ID to the user agent of the AWS API calls made by the selector (app/<appID>) so that
+// the calls of the application embedding the selector can be attributed in CloudTrail. An AppID set in the aws config is
+// kept and the application ID is appended to it (Example: app/platform_my-app).
+func WithAppID(appID string) Option {
+	return func(o *selectorOptions) {
+		o.appID = appID
+	}
+}
+
+// WithAPICallBudget caps the AWS API calls made by the selector's clients, calls beyond the budget fail with
+// awsapi.ErrBudgetExceeded without being sent. The budget can be shared between the selectors of several regions to cap the
+// calls of an invocation.
+func WithAPICallBudget(budget *awsapi.Budget) Option {
+	return func(o *selectorOptions) {
+		o.apiCallBudget = budget
+	}
+}
+
 // New creates an instance of Selector provided an aws session.
-func New(ctx context.Context, cfg aws.Config) (*Selector, error) {
-	return NewWithCache(ctx, cfg, 0, "")
+func New(ctx context.Context, cfg aws.Config, opts ...Option) (*Selector, error) {
+	return NewWithCache(ctx, cfg, 0, "", opts...)
+}
+
+// NewInMemory creates an instance of Selector whose caches are only kept in memory for the lifetime of the Selector. No files
+// are read, written, or removed and the home directory is not looked up, so it can be used where the filesystem is read-only
+// such as AWS Lambda functions. WithOfferingsCacheTTL caches the offerings in memory, so a Selector reused across
+// invocations only retrieves them again once they expire.
+func NewInMemory(ctx context.Context, cfg aws.Config, opts ...Option) (*Selector, error) {
+	return NewWithCache(ctx, cfg, 0, "", append(slices.Clip(opts), func(o *selectorOptions) {
+		o.inMemory = true
+	})...)
 }
 
 // NewWithCache creates an instance of Selector backed by an on-disk cache provided an aws session and cache configuration parameters.
-func NewWithCache(ctx context.Context, cfg aws.Config, ttl time.Duration, cacheDir string) (*Selector, error) {
+// The cache configuration is not used by the providers passed in with opts.
+func NewWithCache(ctx context.Context, cfg aws.Config, ttl time.Duration, cacheDir string, opts ...Option) (*Selector, error) {
+	options := selectorOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	serviceRegistry := NewRegistry()
 	serviceRegistry.RegisterAWSServices()
-	ec2Client := ec2.NewFromConfig(cfg, func(options *ec2.Options) {
-		options.APIOptions = append(options.APIOptions, middleware.AddUserAgentKeyValue(sdkName, versionID))
-	})
-	pricingClient, err := ec2pricing.NewWithCache(ctx, cfg, ttl, cacheDir)
-	if err != nil {
-		return nil, err
+	// every client created from the config identifies the selector and its version in the user agent
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clip(cfg.APIOptions), middleware.AddUserAgentKeyValue(sdkName, versionID))
+	if options.appID != "" && cfg.AppID != "" {
+		cfg.AppID += appIDSeparator + options.appID
+	} else if options.appID != "" {
+		cfg.AppID = options.appID
+	}
+	if options.apiCallBudget != nil {
+		cfg.APIOptions = append(cfg.APIOptions, options.apiCallBudget.AddMiddleware)
+	}
+	ec2Client := ec2.NewFromConfig(cfg)
+	if options.inMemory {
+		newInMemoryProviders(cfg, ec2Client, &options)
+	}
+	if options.pricing == nil {
+		pricingClient, err := ec2pricing.NewWithCache(ctx, cfg, ttl, cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		options.pricing = pricingClient
 	}
 
-	instanceTypeProvider, err := instancetypes.LoadFromOrNew(cacheDir, cfg.Region, ttl, ec2Client)
-	if err != nil {
-		return nil, fmt.Errorf("unable to initialize instance type provider: %w", err)
+	if options.instanceTypesProvider == nil {
+		instanceTypeProvider, err := instancetypes.LoadFromOrNew(cacheDir, cfg.Region, ttl, ec2Client)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize instance type provider: %w", err)
+		}
+		instanceTypeProvider.SetOfferingsClient(ec2Client)
+		options.instanceTypesProvider = instanceTypeProvider
 	}
 
-	return &Selector{
-		EC2:                   ec2Client,
-		EC2Pricing:            pricingClient,
-		InstanceTypesProvider: instanceTypeProvider,
-		ServiceRegistry:       serviceRegistry,
-		Logger:                log.New(io.Discard, "", 0),
-	}, nil
+	if options.offeringsProvider == nil {
+		offeringsTTL := ttl
+		if options.offeringsCacheTTL != nil {
+			offeringsTTL = *options.offeringsCacheTTL
+		}
+		offeringsProvider, err := offerings.LoadFromOrNew(cacheDir, cfg.Region, offeringsTTL, ec2Client)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize instance type offerings provider: %w", err)
+		}
+		options.offeringsProvider = offeringsProvider
+	}
+
+	instanceSelector := &Selector{
+		EC2:               ec2Client,
+		EC2Pricing:        options.pricing,
+		OfferingsProvider: options.offeringsProvider,
+		ServiceRegistry:   serviceRegistry,
+		Logger:            log.New(io.Discard, "", 0),
+	}
+	if instanceTypeProvider, ok := options.instanceTypesProvider.(*instancetypes.Provider); ok {
+		instanceSelector.InstanceTypesProvider = instanceTypeProvider
+	} else {
+		instanceSelector.CustomInstanceTypesProvider = options.instanceTypesProvider
+	}
+	return instanceSelector, nil
+}
+
+// instanceTypesProvider returns the CustomInstanceTypesProvider if it is set and the InstanceTypesProvider otherwise.
+func (s Selector) instanceTypesProvider() instancetypes.ProviderIface {
+	if s.CustomInstanceTypesProvider != nil {
+		return s.CustomInstanceTypesProvider
+	}
+	return s.InstanceTypesProvider
+}
+
+// newInMemoryProviders sets the providers which are not passed in opts to providers without a cache directory.
+func newInMemoryProviders(cfg aws.Config, ec2Client *ec2.Client, options *selectorOptions) {
+	if options.pricing == nil {
+		options.pricing = ec2pricing.NewInMemory(cfg)
+	}
+	if options.instanceTypesProvider == nil {
+		instanceTypeProvider := instancetypes.NewProvider(cfg.Region, ec2Client)
+		instanceTypeProvider.SetOfferingsClient(ec2Client)
+		options.instanceTypesProvider = instanceTypeProvider
+	}
+	if options.offeringsProvider == nil {
+		offeringsProvider := offerings.NewProvider(cfg.Region, ec2Client)
+		if options.offeringsCacheTTL != nil {
+			offeringsProvider.TTL = *options.offeringsCacheTTL
+		}
+		options.offeringsProvider = offeringsProvider
+	}
 }
 
 // SetLogger can be called to log more detailed logs about what selector is doing
@@ -135,13 +300,37 @@ func NewWithCache(ctx context.Context, cfg aws.Config, ttl time.Duration, cacheD
 // If SetLogger is not called, no logs will be displayed.
 func (s *Selector) SetLogger(logger *log.Logger) {
 	s.Logger = logger
-	s.InstanceTypesProvider.SetLogger(logger)
+	s.instanceTypesProvider().SetLogger(logger)
 	s.EC2Pricing.SetLogger(logger)
+	if s.OfferingsProvider != nil {
+		s.OfferingsProvider.SetLogger(logger)
+	}
+}
+
+// SetCacheReadOnly prevents the pricing, instance type, and offerings caches from being saved to or removed from the cache directory.
+// Caches are still loaded from the cache directory and refreshed in memory when they expire.
+func (s *Selector) SetCacheReadOnly(readOnly bool) {
+	s.instanceTypesProvider().SetCacheReadOnly(readOnly)
+	s.EC2Pricing.SetCacheReadOnly(readOnly)
+	if s.OfferingsProvider != nil {
+		s.OfferingsProvider.SetCacheReadOnly(readOnly)
+	}
 }
 
 // Save persists the selector cache data to disk if caching is configured.
+// The on-demand and spot pricing caches are saved separately if the EC2PricingIface implements ec2pricing.CacheSaver.
 func (s Selector) Save() error {
-	return multierr.Append(s.EC2Pricing.Save(), s.InstanceTypesProvider.Save())
+	var err error
+	if saver, ok := s.EC2Pricing.(ec2pricing.CacheSaver); ok {
+		err = multierr.Append(newCacheError(OnDemandPricingCache, saver.SaveOnDemand()), newCacheError(SpotPricingCache, saver.SaveSpot()))
+	} else {
+		err = newCacheError(PricingCaches, s.EC2Pricing.Save())
+	}
+	err = multierr.Append(err, newCacheError(InstanceTypesCache, s.instanceTypesProvider().Save()))
+	if s.OfferingsProvider != nil {
+		err = multierr.Append(err, newCacheError(OfferingsCache, s.OfferingsProvider.Save()))
+	}
+	return err
 }
 
 // Filter accepts a Filters struct which is used to select the available instance types
@@ -163,6 +352,46 @@ func (s Selector) FilterVerbose(ctx context.Context, filters Filters) ([]*instan
 	return instanceTypeInfoSlice, nil
 }
 
+// FilterGroups accepts a FilterSet which is used to select the available instance types matching
+// any of the groups of filters and returns a list of instance type names sorted by name.
+func (s Selector) FilterGroups(ctx context.Context, filterSet FilterSet) ([]string, error) {
+	instanceTypeInfoSlice, err := s.FilterGroupsVerbose(ctx, filterSet)
+	if err != nil {
+		return nil, err
+	}
+	instanceTypes := []string{}
+	for _, instanceTypeInfo := range instanceTypeInfoSlice {
+		instanceTypes = append(instanceTypes, string(instanceTypeInfo.InstanceType))
+	}
+	return instanceTypes, nil
+}
+
+// FilterGroupsVerbose accepts a FilterSet which is used to select the available instance types matching
+// any of the groups of filters and returns the detailed specs of the instance types sorted by name.
+// Instance types matching more than one group are only returned once with the details of the first group they matched.
+func (s Selector) FilterGroupsVerbose(ctx context.Context, filterSet FilterSet) ([]*instancetypes.Details, error) {
+	if len(filterSet.Groups) == 0 {
+		return nil, fmt.Errorf("the filter set must contain at least one group of filters: %w", ErrInvalidFilterCombination)
+	}
+	matched := map[ec2types.InstanceType]bool{}
+	instanceTypeInfoSlice := []*instancetypes.Details{}
+	for i, filters := range filterSet.Groups {
+		groupInstanceTypes, err := s.rawFilter(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("unable to filter instance types of group %d: %w", i+1, err)
+		}
+		for _, instanceTypeInfo := range groupInstanceTypes {
+			if matched[instanceTypeInfo.InstanceType] {
+				continue
+			}
+			matched[instanceTypeInfo.InstanceType] = true
+			instanceTypeInfoSlice = append(instanceTypeInfoSlice, instanceTypeInfo)
+		}
+	}
+	instanceTypeInfoSlice, _ = s.truncateResults(filterSet.MaxResults, sortInstanceTypeInfo(instanceTypeInfoSlice))
+	return instanceTypeInfoSlice, nil
+}
+
 // FilterWithOutput accepts a Filters struct which is used to select the available instance types
 // matching the criteria within Filters and returns a list of strings based on the custom outputFn.
 func (s Selector) FilterWithOutput(ctx context.Context, filters Filters, outputFn InstanceTypesOutput) ([]string, int, error) {
@@ -187,10 +416,15 @@ func (s Selector) truncateResults(maxResults *int, instanceTypeInfoSlice []*inst
 }
 
 // AggregateFilterTransform takes higher level filters which are used to affect multiple raw filters in an opinionated way.
+// The filters are normalized first so that aliases like amd64 are transformed the same as the EC2 API values.
 func (s Selector) AggregateFilterTransform(ctx context.Context, filters Filters) (Filters, error) {
+	filters = NormalizeFilters(filters)
 	transforms := []FiltersTransform{
 		TransformFn(s.TransformBaseInstanceType),
+		TransformFn(s.TransformLaunchTemplate),
 		TransformFn(s.TransformFlexible),
+		TransformFn(s.TransformHibernationStrict),
+		TransformFn(s.TransformPricePeriods),
 		TransformFn(s.TransformForService),
 	}
 	var err error
@@ -206,45 +440,24 @@ func (s Selector) AggregateFilterTransform(ctx context.Context, filters Filters)
 // rawFilter accepts a Filters struct which is used to select the available instance types
 // matching the criteria within Filters and returns the detailed specs of matching instance types.
 func (s Selector) rawFilter(ctx context.Context, filters Filters) ([]*instancetypes.Details, error) {
-	filters, err := s.AggregateFilterTransform(ctx, filters)
-	if err != nil {
-		return nil, err
-	}
-	var locations, availabilityZones []string
-
-	if filters.CPUArchitecture != nil && *filters.CPUArchitecture == cpuArchitectureAMD64 {
-		*filters.CPUArchitecture = ec2types.ArchitectureTypeX8664
-	}
-	if filters.VirtualizationType != nil && *filters.VirtualizationType == virtualizationTypePV {
-		*filters.VirtualizationType = ec2types.VirtualizationTypeParavirtual
-	}
-	if filters.AvailabilityZones != nil {
-		availabilityZones = *filters.AvailabilityZones
-		locations = *filters.AvailabilityZones
-	} else if filters.Region != nil {
-		locations = []string{*filters.Region}
-	}
-	locationInstanceOfferings, err := s.RetrieveInstanceTypesSupportedInLocations(ctx, locations)
-	if err != nil {
-		return nil, err
-	}
-
-	instanceTypeDetails, err := s.InstanceTypesProvider.Get(ctx, nil)
+	pass, err := s.prepareFilterPass(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 	filteredInstanceTypes := []*instancetypes.Details{}
 	var wg sync.WaitGroup
-	instanceTypes := make(chan *instancetypes.Details, len(instanceTypeDetails))
-	for _, instanceTypeInfo := range instanceTypeDetails {
+	instanceTypes := make(chan *instancetypes.Details, len(pass.instanceTypeDetails))
+	for _, instanceTypeInfo := range pass.instanceTypeDetails {
 		wg.Add(1)
 		go func(instanceTypeInfo instancetypes.Details) {
 			defer wg.Done()
-			it, err := s.prepareFilter(ctx, filters, instanceTypeInfo, availabilityZones, locationInstanceOfferings)
+			it, err := s.prepareFilter(ctx, pass.filters, instanceTypeInfo, pass.availabilityZones, pass.locationInstanceOfferings)
 			if err != nil {
 				s.Logger.Printf("Unable to prepare filter for %s, %v", instanceTypeInfo.InstanceType, err)
 			}
 			if it != nil {
+				// instance types must be offered in all of the filtered locations
+				it.AvailabilityZones = pass.zones
 				instanceTypes <- it
 			}
 		}(*instanceTypeInfo)
@@ -259,11 +472,134 @@ func (s Selector) rawFilter(ctx context.Context, filters Filters) ([]*instancety
 	return sortInstanceTypeInfo(filteredInstanceTypes), nil
 }
 
+// filterPass holds what is resolved once before the instance types are filtered.
+type filterPass struct {
+	// filters are the filters after the aggregate filters are transformed
+	filters Filters
+	zones   []instancetypes.AvailabilityZone
+	// availabilityZones are the names of zones which are used for pricing lookups
+	availabilityZones         []string
+	locationInstanceOfferings map[ec2types.InstanceType]string
+	instanceTypeDetails       []*instancetypes.Details
+}
+
+// prepareFilterPass validates and transforms the filters and retrieves the filtered zones, the instance types offered in the
+// filtered locations, and the instance types to filter.
+func (s Selector) prepareFilterPass(ctx context.Context, filters Filters) (filterPass, error) {
+	if err := validateFilters(filters); err != nil {
+		return filterPass{}, err
+	}
+	filters, err := s.AggregateFilterTransform(ctx, filters)
+	if err != nil {
+		return filterPass{}, err
+	}
+	if err := s.checkPricingAvailable(filters); err != nil {
+		return filterPass{}, err
+	}
+	var locations, availabilityZones []string
+
+	var zones []instancetypes.AvailabilityZone
+	if filters.AvailabilityZones != nil {
+		zones, err = s.getAvailabilityZones(ctx, *filters.AvailabilityZones, filters.LocationType)
+		if err != nil {
+			return filterPass{}, err
+		}
+		// zone names are used for pricing lookups since zone IDs are not returned with prices
+		for _, zone := range zones {
+			availabilityZones = append(availabilityZones, zone.ZoneName)
+		}
+		locations = *filters.AvailabilityZones
+	} else if filters.Region != nil {
+		locations = []string{*filters.Region}
+	}
+	locationInstanceOfferings, err := s.retrieveInstanceTypesSupportedInLocations(ctx, locations, filters.LocationType)
+	if err != nil {
+		return filterPass{}, err
+	}
+
+	instanceTypeDetails, err := s.instanceTypesProvider().Get(ctx, nil)
+	if err != nil {
+		return filterPass{}, err
+	}
+	return filterPass{
+		filters:                   filters,
+		zones:                     zones,
+		availabilityZones:         availabilityZones,
+		locationInstanceOfferings: locationInstanceOfferings,
+		instanceTypeDetails:       instanceTypeDetails,
+	}, nil
+}
+
+// validateFilters returns ErrInvalidFilterCombination if a filter is set without the filter it depends on, and an error if
+// the CPU architecture is ArchitectureTypeAuto.
+func validateFilters(filters Filters) error {
+	if filters.LocationType != nil && filters.AvailabilityZones == nil {
+		return fmt.Errorf("%w: the location type can only be set with availability zones", ErrInvalidFilterCombination)
+	}
+	if filters.LaunchTemplateVersion != nil && filters.LaunchTemplateID == nil {
+		return fmt.Errorf("%w: the launch template version can only be set with a launch template ID", ErrInvalidFilterCombination)
+	}
+	// the machine the selector runs on is not necessarily the machine the instance types are picked for, such as a Lambda function
+	if filters.CPUArchitecture != nil && strings.EqualFold(strings.TrimSpace(string(*filters.CPUArchitecture)), string(ArchitectureTypeAuto)) {
+		return fmt.Errorf("the %s CPU architecture must be resolved to an EC2 architecture before filtering", ArchitectureTypeAuto)
+	}
+	return nil
+}
+
+// checkPricingAvailable returns ErrPricingUnavailable if prices are filtered on but the pricing cache of the filtered usage
+// class is empty, since none of the instance types would match. Capacity Block prices are not cached so they are not checked.
+func (s Selector) checkPricingAvailable(filters Filters) error {
+	if filters.PricePerHour != nil {
+		if filters.UsageClass != nil && *filters.UsageClass == ec2types.UsageClassTypeSpot {
+			if s.EC2Pricing.SpotCacheCount() == 0 {
+				return fmt.Errorf("%w: the spot pricing cache is empty", ErrPricingUnavailable)
+			}
+		} else if filters.UsageClass == nil || *filters.UsageClass != ec2types.UsageClassTypeCapacityBlock {
+			if s.EC2Pricing.OnDemandCacheCount() == 0 {
+				return fmt.Errorf("%w: the on-demand pricing cache is empty", ErrPricingUnavailable)
+			}
+		}
+	}
+	if filters.SpotSavings != nil && (s.EC2Pricing.OnDemandCacheCount() == 0 || s.EC2Pricing.SpotCacheCount() == 0) {
+		return fmt.Errorf("%w: spot savings require both the on-demand and spot pricing caches", ErrPricingUnavailable)
+	}
+	return nil
+}
+
 func (s Selector) prepareFilter(ctx context.Context, filters Filters, instanceTypeInfo instancetypes.Details, availabilityZones []string, locationInstanceOfferings map[ec2types.InstanceType]string) (*instancetypes.Details, error) {
+	instanceTypeInfo, filterToInstanceSpecMappingPairs := s.prepareInstanceType(ctx, filters, instanceTypeInfo, availabilityZones)
+	instanceTypeName := instanceTypeInfo.InstanceType
+
+	if isInDenyList(filters.DenyList, instanceTypeName) || !isInAllowList(filters.AllowList, instanceTypeName) {
+		return nil, nil
+	}
+
+	if !isSupportedInLocation(locationInstanceOfferings, instanceTypeName) {
+		return nil, nil
+	}
+
+	if aws.ToBool(filters.ExcludeDeprecated) && instanceTypeInfo.Deprecation != nil {
+		return nil, nil
+	}
+
+	var isInstanceSupported bool
+	isInstanceSupported, err := s.executeFilters(ctx, filterToInstanceSpecMappingPairs, instanceTypeName)
+	if err != nil {
+		return nil, err
+	}
+	if !isInstanceSupported {
+		return nil, nil
+	}
+	return &instanceTypeInfo, nil
+}
+
+// prepareInstanceType populates the prices and derived specs of the instance type and returns them along with the
+// filter pairs the instance type is filtered by.
+func (s Selector) prepareInstanceType(ctx context.Context, filters Filters, instanceTypeInfo instancetypes.Details, availabilityZones []string) (instancetypes.Details, map[string]filterPair) {
 	instanceTypeName := instanceTypeInfo.InstanceType
 	isFpga := instanceTypeInfo.FpgaInfo != nil
-	var instanceTypeHourlyPriceForFilter float64 // Price used to filter based on usage class
-	var instanceTypeHourlyPriceOnDemand, instanceTypeHourlyPriceSpot *float64
+	var instanceTypeHourlyPriceForFilter *float64 // Price used to filter based on usage class, nil if it is unknown
+	var instanceTypeHourlyPriceOnDemand, instanceTypeHourlyPriceSpot, instanceTypeHourlyPriceCapacityBlock *float64
 	// If prices are fetched, populate the fields irrespective of the price filters
 	if s.EC2Pricing.OnDemandCacheCount() > 0 {
 		price, err := s.EC2Pricing.GetOnDemandInstanceTypeCost(ctx, instanceTypeName)
@@ -283,7 +619,7 @@ func (s Selector) prepareFilter(ctx context.Context, filters Filters, instanceTy
 	}
 
 	if s.EC2Pricing.SpotCacheCount() > 0 && isSpotUsageClass {
-		price, err := s.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(ctx, instanceTypeName, availabilityZones, 30)
+		price, err := s.EC2Pricing.GetSpotInstanceTypeNDayAvgCost(ctx, instanceTypeName, availabilityZones, spotPriceLookupDays)
 		if err != nil {
 			s.Logger.Printf("Could not retrieve 30 day avg hourly spot price for instance type %s\n", instanceTypeName)
 		} else {
@@ -291,17 +627,53 @@ func (s Selector) prepareFilter(ctx context.Context, filters Filters, instanceTy
 			instanceTypeInfo.SpotPrice = instanceTypeHourlyPriceSpot
 		}
 	}
+	// Capacity Block offerings are not cached since they depend on the current time, so they are only retrieved when filtering on them
+	if filters.UsageClass != nil && *filters.UsageClass == ec2types.UsageClassTypeCapacityBlock && isSupportedUsageClassType(instanceTypeInfo.SupportedUsageClasses, filters.UsageClass) {
+		price, err := s.getCapacityBlockPricePerHour(ctx, instanceTypeName, availabilityZones)
+		if err != nil {
+			s.Logger.Printf("Could not retrieve capacity block offerings for instance type %s - %s\n", instanceTypeName, err)
+		} else if price != nil {
+			instanceTypeHourlyPriceCapacityBlock = price
+			instanceTypeInfo.CapacityBlockPricePerHour = instanceTypeHourlyPriceCapacityBlock
+		}
+	}
+	instanceTypeInfo.SpotSavings = getSpotSavings(instanceTypeHourlyPriceOnDemand, instanceTypeHourlyPriceSpot)
+	instanceTypeInfo.OndemandPricePerMonth = getPricePerMonth(instanceTypeHourlyPriceOnDemand, getHoursPerMonth(filters))
+	instanceTypeInfo.SpotPricePerMonth = getPricePerMonth(instanceTypeHourlyPriceSpot, getHoursPerMonth(filters))
 	if filters.PricePerHour != nil {
 		// If price filter is present, prices should be already fetched
 		// If prices are not fetched, filter should fail and the corresponding error is already printed
+		// Instance types without a capacity block offering are excluded since their price is unknown, instance types
+		// without an on-demand price are filtered as if they cost $0
 		if filters.UsageClass != nil && *filters.UsageClass == ec2types.UsageClassTypeSpot && instanceTypeHourlyPriceSpot != nil {
-			instanceTypeHourlyPriceForFilter = *instanceTypeHourlyPriceSpot
+			instanceTypeHourlyPriceForFilter = instanceTypeHourlyPriceSpot
+		} else if filters.UsageClass != nil && *filters.UsageClass == ec2types.UsageClassTypeCapacityBlock {
+			instanceTypeHourlyPriceForFilter = instanceTypeHourlyPriceCapacityBlock
 		} else if instanceTypeHourlyPriceOnDemand != nil {
-			instanceTypeHourlyPriceForFilter = *instanceTypeHourlyPriceOnDemand
+			instanceTypeHourlyPriceForFilter = instanceTypeHourlyPriceOnDemand
+		} else {
+			instanceTypeHourlyPriceForFilter = aws.Float64(0)
 		}
 	}
+	instanceTypeInfo.BaselineCPUUtilization = getBaselineCPUUtilization(&instanceTypeInfo.InstanceTypeInfo)
+	if instanceTypeInfo.BurstablePerformanceSupported != nil && *instanceTypeInfo.BurstablePerformanceSupported {
+		instanceTypeInfo.CPUCreditsPerHour = getCPUCreditsPerHour(&instanceTypeInfo.InstanceTypeInfo)
+	}
+	instanceTypeInfo.CarbonScore = getCarbonScore(&instanceTypeInfo.InstanceTypeInfo, s.CarbonData)
+	instanceTypeInfo.NitroGeneration = getNitroGeneration(&instanceTypeInfo.InstanceTypeInfo)
+	instanceTypeInfo.NormalizationFactor = getNormalizationFactor(&instanceTypeInfo.InstanceTypeInfo)
+	instanceTypeInfo.EnaQueuesPerInterface, instanceTypeInfo.EnaQueues = getEnaQueues(instanceTypeName, instanceTypeInfo.NetworkInfo)
+	instanceTypeInfo.Deprecation = getDeprecation(instanceTypeName, s.Deprecations)
+	var onDemandDiscountPercent float64
+	if discounter, ok := s.EC2Pricing.(ec2pricing.OnDemandDiscounter); ok {
+		onDemandDiscountPercent = discounter.GetOnDemandDiscountPercent(instanceTypeName)
+	}
+	var spotPriceLastChange time.Time
+	if changeTimer, ok := s.EC2Pricing.(ec2pricing.SpotPriceChangeTimer); ok && instanceTypeHourlyPriceSpot != nil {
+		spotPriceLastChange, _ = changeTimer.GetSpotInstanceTypeLastPriceChange(instanceTypeName, availabilityZones)
+	}
+	instanceTypeInfo.Annotations = getAnnotations(&instanceTypeInfo, s.EC2Pricing.OnDemandCacheCount() > 0, onDemandDiscountPercent, s.EC2Pricing.SpotCacheCount() > 0, isSpotUsageClass, spotPriceLastChange)
 	eneaSupport := string(instanceTypeInfo.NetworkInfo.EnaSupport)
-	ebsOptimizedSupport := string(instanceTypeInfo.EbsInfo.EbsOptimizedSupport)
 
 	// filterToInstanceSpecMappingPairs is a map of filter name [key] to filter pair [value].
 	// A filter pair includes user input filter value and instance spec value retrieved from DescribeInstanceTypes
@@ -310,32 +682,43 @@ func (s Selector) prepareFilter(ctx context.Context, filters Filters, instanceTy
 		cpuManufacturer:                  {filters.CPUManufacturer, getCPUManufacturer(&instanceTypeInfo.InstanceTypeInfo)},
 		usageClass:                       {filters.UsageClass, instanceTypeInfo.SupportedUsageClasses},
 		rootDeviceType:                   {filters.RootDeviceType, instanceTypeInfo.SupportedRootDeviceTypes},
-		hibernationSupported:             {filters.HibernationSupported, instanceTypeInfo.HibernationSupported},
+		hibernationSupported:             {filters.HibernationSupported, isHibernationSupported(&instanceTypeInfo.InstanceTypeInfo, aws.ToBool(filters.HibernationStrict))},
 		vcpusRange:                       {filters.VCpusRange, instanceTypeInfo.VCpuInfo.DefaultVCpus},
+		vcpusList:                        {filters.VCpusList, instanceTypeInfo.VCpuInfo.DefaultVCpus},
 		memoryRange:                      {filters.MemoryRange, instanceTypeInfo.MemoryInfo.SizeInMiB},
 		gpuMemoryRange:                   {filters.GpuMemoryRange, getTotalGpuMemory(instanceTypeInfo.GpuInfo)},
 		gpusRange:                        {filters.GpusRange, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
+		gpusList:                         {filters.GpusList, getTotalGpusCount(instanceTypeInfo.GpuInfo)},
 		inferenceAcceleratorsRange:       {filters.InferenceAcceleratorsRange, getTotalAcceleratorsCount(instanceTypeInfo.InferenceAcceleratorInfo)},
-		placementGroupStrategy:           {filters.PlacementGroupStrategy, instanceTypeInfo.PlacementGroupInfo.SupportedStrategies},
+		placementGroupStrategy:           {getPlacementGroupStrategies(filters), getPlacementGroupStrategySupport(instanceTypeInfo.PlacementGroupInfo, filters.PlacementGroupStrategyMatch)},
 		hypervisor:                       {filters.Hypervisor, instanceTypeInfo.Hypervisor},
 		baremetal:                        {filters.BareMetal, instanceTypeInfo.BareMetal},
 		burstable:                        {filters.Burstable, instanceTypeInfo.BurstablePerformanceSupported},
 		fpga:                             {filters.Fpga, &isFpga},
+		fpgasRange:                       {filters.FpgasRange, getTotalFpgasCount(instanceTypeInfo.FpgaInfo)},
+		fpgaMemoryRange:                  {filters.FpgaMemoryRange, getTotalFpgaMemory(instanceTypeInfo.FpgaInfo)},
+		fpgaManufacturer:                 {filters.FPGAManufacturer, getFPGAManufacturers(instanceTypeInfo.FpgaInfo)},
 		enaSupport:                       {filters.EnaSupport, supportSyntaxToBool(&eneaSupport)},
 		efaSupport:                       {filters.EfaSupport, instanceTypeInfo.NetworkInfo.EfaSupported},
 		vcpusToMemoryRatio:               {filters.VCpusToMemoryRatio, calculateVCpusToMemoryRatio(instanceTypeInfo.VCpuInfo.DefaultVCpus, instanceTypeInfo.MemoryInfo.SizeInMiB)},
 		currentGeneration:                {filters.CurrentGeneration, instanceTypeInfo.CurrentGeneration},
 		networkInterfaces:                {filters.NetworkInterfaces, instanceTypeInfo.NetworkInfo.MaximumNetworkInterfaces},
+		networkCards:                     {filters.NetworkCards, getMaximumNetworkCards(instanceTypeInfo.NetworkInfo)},
+		enaQueuesPerInterface:            {filters.ENAQueuesPerInterface, instanceTypeInfo.EnaQueuesPerInterface},
+		enaQueues:                        {filters.ENAQueues, instanceTypeInfo.EnaQueues},
 		networkPerformance:               {filters.NetworkPerformance, getNetworkPerformance(instanceTypeInfo.NetworkInfo.NetworkPerformance)},
 		networkEncryption:                {filters.NetworkEncryption, instanceTypeInfo.NetworkInfo.EncryptionInTransitSupported},
 		ipv6:                             {filters.IPv6, instanceTypeInfo.NetworkInfo.Ipv6Supported},
 		instanceTypes:                    {filters.InstanceTypes, instanceTypeInfo.InstanceType},
 		virtualizationType:               {filters.VirtualizationType, instanceTypeInfo.SupportedVirtualizationTypes},
-		pricePerHour:                     {filters.PricePerHour, &instanceTypeHourlyPriceForFilter},
+		pricePerHour:                     {filters.PricePerHour, instanceTypeHourlyPriceForFilter},
+		spotSavings:                      {filters.SpotSavings, instanceTypeInfo.SpotSavings},
+		baselineCPURange:                 {filters.BaselineCPURange, instanceTypeInfo.BaselineCPUUtilization},
 		instanceStorageRange:             {filters.InstanceStorageRange, getInstanceStorage(instanceTypeInfo.InstanceStorageInfo)},
 		diskType:                         {filters.DiskType, getDiskType(instanceTypeInfo.InstanceStorageInfo)},
 		nvme:                             {filters.NVME, getNVMESupport(instanceTypeInfo.InstanceStorageInfo, instanceTypeInfo.EbsInfo)},
-		ebsOptimized:                     {filters.EBSOptimized, supportSyntaxToBool(&ebsOptimizedSupport)},
+		ebsOptimized:                     {filters.EBSOptimized, supportSyntaxToBool(aws.String(string(instanceTypeInfo.EbsInfo.EbsOptimizedSupport)))},
+		ebsOptimizedSupport:              {filters.EBSOptimizedSupport, instanceTypeInfo.EbsInfo.EbsOptimizedSupport},
 		diskEncryption:                   {filters.DiskEncryption, getDiskEncryptionSupport(instanceTypeInfo.InstanceStorageInfo, instanceTypeInfo.EbsInfo)},
 		ebsOptimizedBaselineBandwidth:    {filters.EBSOptimizedBaselineBandwidth, getEBSOptimizedBaselineBandwidth(instanceTypeInfo.EbsInfo)},
 		ebsOptimizedBaselineThroughput:   {filters.EBSOptimizedBaselineThroughput, getEBSOptimizedBaselineThroughput(instanceTypeInfo.EbsInfo)},
@@ -347,26 +730,22 @@ func (s Selector) prepareFilter(ctx context.Context, filters Filters, instanceTy
 		inferenceAcceleratorManufacturer: {filters.InferenceAcceleratorManufacturer, getInferenceAcceleratorManufacturers(instanceTypeInfo.InferenceAcceleratorInfo)},
 		inferenceAcceleratorModel:        {filters.InferenceAcceleratorModel, getInferenceAcceleratorModels(instanceTypeInfo.InferenceAcceleratorInfo)},
 		dedicatedHosts:                   {filters.DedicatedHosts, instanceTypeInfo.DedicatedHostsSupported},
+		tenancy:                          {filters.Tenancy, getTenancySupport(&instanceTypeInfo.InstanceTypeInfo)},
 		generation:                       {filters.Generation, getInstanceTypeGeneration(string(instanceTypeInfo.InstanceType))},
+		nitroGeneration:                  {filters.NitroGeneration, instanceTypeInfo.NitroGeneration},
+		ipv6OnlySubnetCapable:            {filters.IPv6OnlySubnetCapable, isIPv6OnlySubnetCapable(&instanceTypeInfo.InstanceTypeInfo)},
+		cpuManufacturerNot:               {filters.CPUManufacturerNot, getCPUManufacturer(&instanceTypeInfo.InstanceTypeInfo)},
+		gpuManufacturerNot:               {filters.GPUManufacturerNot, getGPUManufacturers(instanceTypeInfo.GpuInfo)},
+		gpuModelNot:                      {filters.GPUModelNot, getGPUModels(instanceTypeInfo.GpuInfo)},
+		inferenceAccelManufacturerNot:    {filters.InferenceAcceleratorManufacturerNot, getInferenceAcceleratorManufacturers(instanceTypeInfo.InferenceAcceleratorInfo)},
+		inferenceAcceleratorModelNot:     {filters.InferenceAcceleratorModelNot, getInferenceAcceleratorModels(instanceTypeInfo.InferenceAcceleratorInfo)},
 	}
-
-	if isInDenyList(filters.DenyList, instanceTypeName) || !isInAllowList(filters.AllowList, instanceTypeName) {
-		return nil, nil
-	}
-
-	if !isSupportedInLocation(locationInstanceOfferings, instanceTypeName) {
-		return nil, nil
-	}
-
-	var isInstanceSupported bool
-	isInstanceSupported, err := s.executeFilters(ctx, filterToInstanceSpecMappingPairs, instanceTypeName)
-	if err != nil {
-		return nil, err
-	}
-	if !isInstanceSupported {
-		return nil, nil
+	// instance types whose ENA queue counts are unknown are not excluded by the ENA queue filters
+	if instanceTypeInfo.EnaQueues == nil {
+		delete(filterToInstanceSpecMappingPairs, enaQueuesPerInterface)
+		delete(filterToInstanceSpecMappingPairs, enaQueues)
 	}
-	return &instanceTypeInfo, nil
+	return instanceTypeInfo, filterToInstanceSpecMappingPairs
 }
 
 // sortInstanceTypeInfo will sort based on instance type info alpha-numerically.
@@ -489,6 +868,15 @@ func exec(instanceType ec2types.InstanceType, filterName string, filter filterPa
 		default:
 			return false, errInvalidInstanceSpec
 		}
+	case *Int32SetFilter:
+		switch iSpec := instanceSpec.(type) {
+		case *int32:
+			if !isSupportedWithSetInt32(iSpec, filter) {
+				return false, nil
+			}
+		default:
+			return false, errInvalidInstanceSpec
+		}
 	case *Float64RangeFilter:
 		switch iSpec := instanceSpec.(type) {
 		case *float64:
@@ -582,6 +970,15 @@ func exec(instanceType ec2types.InstanceType, filterName string, filter filterPa
 		default:
 			return false, errInvalidInstanceSpec
 		}
+	case *ec2types.EbsOptimizedSupport:
+		switch iSpec := instanceSpec.(type) {
+		case ec2types.EbsOptimizedSupport:
+			if !isSupportedEbsOptimizedSupport(iSpec, filter) {
+				return false, nil
+			}
+		default:
+			return false, errInvalidInstanceSpec
+		}
 	case *ec2types.RootDeviceType:
 		switch iSpec := instanceSpec.(type) {
 		case []ec2types.RootDeviceType:
@@ -593,6 +990,14 @@ func exec(instanceType ec2types.InstanceType, filterName string, filter filterPa
 		}
 	case *[]string:
 		switch iSpec := instanceSpec.(type) {
+		case placementGroupStrategySupport:
+			if !isSupportedPlacementGroupStrategies(iSpec, *filter) {
+				return false, nil
+			}
+		case tenancySupport:
+			if !isSupportedTenancies(iSpec, *filter) {
+				return false, nil
+			}
 		case *string:
 			filterOfPtrs := []*string{}
 			for _, f := range *filter {
@@ -607,6 +1012,11 @@ func exec(instanceType ec2types.InstanceType, filterName string, filter filterPa
 		default:
 			return false, errInvalidInstanceSpec
 		}
+	case *NotFilter:
+		// negated filters apply to any string based instance spec
+		if !isNotMatchingAny(instanceSpec, filter) {
+			return false, nil
+		}
 	default:
 		return false, fmt.Errorf("no filter handler found for %s", filterDetailsMsg)
 	}
@@ -614,43 +1024,35 @@ func exec(instanceType ec2types.InstanceType, filterName string, filter filterPa
 }
 
 // RetrieveInstanceTypesSupportedInLocations returns a map of instance type -> AZ or Region for all instance types supported in the intersected locations passed in
-// The location can be a zone-id (ie. use1-az1), a zone-name (us-east-1a), or a region name (us-east-1).
+// The location can be a zone-id (ie. use1-az1), a zone-name (us-east-1a), a Local Zone or Wavelength Zone name or id
+// (us-west-2-lax-1a), an Outpost ARN, or a region name (us-east-1).
 // Note that zone names are not necessarily the same across accounts.
 func (s Selector) RetrieveInstanceTypesSupportedInLocations(ctx context.Context, locations []string) (map[ec2types.InstanceType]string, error) {
+	return s.retrieveInstanceTypesSupportedInLocations(ctx, locations, nil)
+}
+
+// retrieveInstanceTypesSupportedInLocations is RetrieveInstanceTypesSupportedInLocations with the location type of all of the
+// locations, it is detected from each location when locationType is nil.
+func (s Selector) retrieveInstanceTypesSupportedInLocations(ctx context.Context, locations []string, locationType *ec2types.LocationType) (map[ec2types.InstanceType]string, error) {
 	if len(locations) == 0 {
 		return nil, nil
 	}
 	availableInstanceTypes := map[ec2types.InstanceType]int{}
 	for _, location := range locations {
-		locationType, err := s.getLocationType(ctx, location)
+		offeringsLocationType, err := s.getLocationType(ctx, location, locationType)
 		if err != nil {
 			return nil, err
 		}
 
-		instanceTypeOfferingsInput := &ec2.DescribeInstanceTypeOfferingsInput{
-			LocationType: locationType,
-			Filters: []ec2types.Filter{
-				{
-					Name:   aws.String(locationFilterKey),
-					Values: []string{location},
-				},
-			},
+		instanceTypeOfferings, err := s.getOfferingsProvider().Get(ctx, offeringsLocationType, location)
+		if err != nil {
+			return nil, err
 		}
-
-		p := ec2.NewDescribeInstanceTypeOfferingsPaginator(s.EC2, instanceTypeOfferingsInput)
-
-		for p.HasMorePages() {
-			instanceTypeOfferings, err := p.NextPage(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("encountered an error when describing instance type offerings: %w", err)
-			}
-
-			for _, instanceType := range instanceTypeOfferings.InstanceTypeOfferings {
-				if i, ok := availableInstanceTypes[instanceType.InstanceType]; !ok {
-					availableInstanceTypes[instanceType.InstanceType] = 1
-				} else {
-					availableInstanceTypes[instanceType.InstanceType] = i + 1
-				}
+		for _, instanceType := range instanceTypeOfferings {
+			if i, ok := availableInstanceTypes[instanceType.InstanceType]; !ok {
+				availableInstanceTypes[instanceType.InstanceType] = 1
+			} else {
+				availableInstanceTypes[instanceType.InstanceType] = i + 1
 			}
 		}
 	}
@@ -664,10 +1066,91 @@ func (s Selector) RetrieveInstanceTypesSupportedInLocations(ctx context.Context,
 	return availableInstanceTypesAllLocations, nil
 }
 
-func (s Selector) getLocationType(ctx context.Context, location string) (ec2types.LocationType, error) {
-	azs, err := s.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{})
+// getAvailabilityZones returns the name and ID of each of the passed in zone names or zone IDs.
+// Region names and Outposts are skipped.
+func (s Selector) getAvailabilityZones(ctx context.Context, locations []string, locationType *ec2types.LocationType) ([]instancetypes.AvailabilityZone, error) {
+	zones := []instancetypes.AvailabilityZone{}
+	if locationType != nil && (*locationType == outpostLocationType || *locationType == regionNameLocationType) {
+		return zones, nil
+	}
+	azs, err := s.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{AllAvailabilityZones: aws.Bool(true)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRegionResolution, newAPIError("DescribeAvailabilityZones", err))
+	}
+	for _, location := range locations {
+		if isOutpostARN(location) {
+			continue
+		}
+		found := false
+		for _, zone := range azs.AvailabilityZones {
+			if location == aws.ToString(zone.RegionName) {
+				found = true
+				break
+			}
+			if location == aws.ToString(zone.ZoneName) || location == aws.ToString(zone.ZoneId) {
+				zones = append(zones, instancetypes.AvailabilityZone{ZoneName: aws.ToString(zone.ZoneName), ZoneID: aws.ToString(zone.ZoneId)})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: the location passed in (%s) is not a valid zone-id, zone-name, region name, or outpost ARN", ErrRegionResolution, location)
+		}
+	}
+	return zones, nil
+}
+
+// AddOfferedZones sets the AvailabilityZones of the instance types which do not already have them, because availability
+// zones were not filtered on, to all of the zones in the region the instance type is offered in sorted by zone name.
+func (s Selector) AddOfferedZones(ctx context.Context, instanceTypes []*instancetypes.Details) error {
+	azs, err := s.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{AllAvailabilityZones: aws.Bool(true)})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRegionResolution, newAPIError("DescribeAvailabilityZones", err))
+	}
+	zoneIDs := map[string]string{}
+	for _, zone := range azs.AvailabilityZones {
+		zoneIDs[aws.ToString(zone.ZoneName)] = aws.ToString(zone.ZoneId)
+	}
+	offeredZones := map[ec2types.InstanceType][]instancetypes.AvailabilityZone{}
+	instanceTypeOfferings, err := s.getOfferingsProvider().Get(ctx, zoneNameLocationType, "")
+	if err != nil {
+		return err
+	}
+	for _, offering := range instanceTypeOfferings {
+		zoneName := aws.ToString(offering.Location)
+		offeredZones[offering.InstanceType] = append(offeredZones[offering.InstanceType], instancetypes.AvailabilityZone{ZoneName: zoneName, ZoneID: zoneIDs[zoneName]})
+	}
+	for _, instanceType := range instanceTypes {
+		if len(instanceType.AvailabilityZones) > 0 {
+			continue
+		}
+		zones := offeredZones[instanceType.InstanceType]
+		sort.Slice(zones, func(i, j int) bool { return zones[i].ZoneName < zones[j].ZoneName })
+		instanceType.AvailabilityZones = zones
+	}
+	return nil
+}
+
+// getOfferingsProvider returns the OfferingsProvider or a provider which does not cache offerings if it is not set.
+func (s Selector) getOfferingsProvider() offerings.ProviderIface {
+	if s.OfferingsProvider == nil {
+		return offerings.NewProvider("", s.EC2)
+	}
+	return s.OfferingsProvider
+}
+
+// getLocationType returns locationType if it is not nil, otherwise the location type is detected from the location.
+// All zones are described, not only the opted-in ones, so that Local Zones and Wavelength Zones are detected as well.
+func (s Selector) getLocationType(ctx context.Context, location string, locationType *ec2types.LocationType) (ec2types.LocationType, error) {
+	if locationType != nil {
+		return *locationType, nil
+	}
+	if isOutpostARN(location) {
+		return outpostLocationType, nil
+	}
+	azs, err := s.EC2.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{AllAvailabilityZones: aws.Bool(true)})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %w", ErrRegionResolution, newAPIError("DescribeAvailabilityZones", err))
 	}
 	for _, zone := range azs.AvailabilityZones {
 		if location == *zone.RegionName {
@@ -678,7 +1161,109 @@ func (s Selector) getLocationType(ctx context.Context, location string) (ec2type
 			return zoneIDLocationType, nil
 		}
 	}
-	return "", fmt.Errorf("the location passed in (%s) is not a valid zone-id, zone-name, or region name", location)
+	return "", fmt.Errorf("%w: the location passed in (%s) is not a valid zone-id, zone-name, region name, or outpost ARN", ErrRegionResolution, location)
+}
+
+// isOutpostARN returns true if the location is an Outpost ARN (arn:aws:outposts:us-west-2:123456789012:outpost/op-0123456789abcdef0).
+func isOutpostARN(location string) bool {
+	parsed, err := arn.Parse(location)
+	return err == nil && parsed.Service == "outposts" && strings.HasPrefix(parsed.Resource, "outpost/")
+}
+
+// getSpotSavings returns the percentage the spot price saves compared to the on-demand price or nil if either price is unknown.
+func getSpotSavings(onDemandPrice *float64, spotPrice *float64) *float64 {
+	if onDemandPrice == nil || spotPrice == nil || *onDemandPrice <= 0 {
+		return nil
+	}
+	savings := (1 - *spotPrice / *onDemandPrice) * 100
+	return &savings
+}
+
+// getPricePerMonth returns the hourly price multiplied by the hours per month or nil if the hourly price is unknown.
+func getPricePerMonth(pricePerHour *float64, hoursPerMonth float64) *float64 {
+	if pricePerHour == nil {
+		return nil
+	}
+	pricePerMonth := *pricePerHour * hoursPerMonth
+	return &pricePerMonth
+}
+
+// getCapacityBlockPricePerHour returns the lowest hourly price of the Capacity Block for ML offerings currently available for the instance type.
+// If availabilityZones are passed in, only offerings in those zones are considered. Returns nil if there are no offerings.
+func (s Selector) getCapacityBlockPricePerHour(ctx context.Context, instanceType ec2types.InstanceType, availabilityZones []string) (*float64, error) {
+	p := ec2.NewDescribeCapacityBlockOfferingsPaginator(s.EC2, &ec2.DescribeCapacityBlockOfferingsInput{
+		InstanceType:          aws.String(string(instanceType)),
+		InstanceCount:         aws.Int32(1),
+		CapacityDurationHours: aws.Int32(capacityBlockDurationHours),
+	})
+	var lowestPrice *float64
+	for p.HasMorePages() {
+		offerings, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, newAPIError("DescribeCapacityBlockOfferings", err)
+		}
+		for _, offering := range offerings.CapacityBlockOfferings {
+			if len(availabilityZones) > 0 && !slices.Contains(availabilityZones, aws.ToString(offering.AvailabilityZone)) {
+				continue
+			}
+			upfrontFee, err := strconv.ParseFloat(aws.ToString(offering.UpfrontFee), 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse capacity block upfront fee %s: %w", aws.ToString(offering.UpfrontFee), err)
+			}
+			hours := aws.ToInt32(offering.CapacityBlockDurationHours) * max(aws.ToInt32(offering.InstanceCount), 1)
+			if hours <= 0 {
+				continue
+			}
+			price := upfrontFee / float64(hours)
+			if lowestPrice == nil || price < *lowestPrice {
+				lowestPrice = &price
+			}
+		}
+	}
+	return lowestPrice, nil
+}
+
+// RefreshSpotCacheForFilters refreshes the spot pricing cache with only the instance types which match the non-price filters
+// in the filtered availability zones. This transfers far less data than RefreshSpotCache when the filters are selective.
+func (s Selector) RefreshSpotCacheForFilters(ctx context.Context, filters Filters, days int) error {
+	filters.PricePerHour = nil
+	filters.PricePerMonth = nil
+	filters.PricePerYear = nil
+	filters.SpotSavings = nil
+	filters.MaxResults = nil
+	instanceTypeDetails, err := s.FilterVerbose(ctx, filters)
+	if err != nil {
+		return fmt.Errorf("unable to determine the candidate instance types for the spot pricing refresh: %w", err)
+	}
+	if len(instanceTypeDetails) == 0 {
+		return nil
+	}
+	instanceTypes := make([]ec2types.InstanceType, 0, len(instanceTypeDetails))
+	for _, it := range instanceTypeDetails {
+		instanceTypes = append(instanceTypes, it.InstanceType)
+	}
+	availabilityZones, err := s.getPricingZoneNames(ctx, filters)
+	if err != nil {
+		return err
+	}
+	return s.EC2Pricing.RefreshSpotCacheFor(ctx, days, instanceTypes, availabilityZones)
+}
+
+// getPricingZoneNames returns the names of the filtered availability zones which are used for pricing lookups, nil if
+// the instance types are not filtered by availability zone.
+func (s Selector) getPricingZoneNames(ctx context.Context, filters Filters) ([]string, error) {
+	if filters.AvailabilityZones == nil {
+		return nil, nil
+	}
+	zones, err := s.getAvailabilityZones(ctx, *filters.AvailabilityZones, filters.LocationType)
+	if err != nil {
+		return nil, err
+	}
+	var availabilityZones []string
+	for _, zone := range zones {
+		availabilityZones = append(availabilityZones, zone.ZoneName)
+	}
+	return availabilityZones, nil
 }
 
 func isSupportedInLocation(instanceOfferings map[ec2types.InstanceType]string, instanceType ec2types.InstanceType) bool {
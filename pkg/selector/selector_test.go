@@ -17,29 +17,45 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"go.uber.org/multierr"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/awsapi"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/offerings"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
 )
 
 const (
-	describeInstanceTypes         = "DescribeInstanceTypes"
-	describeInstanceTypeOfferings = "DescribeInstanceTypeOfferings"
-	describeAvailabilityZones     = "DescribeAvailabilityZones"
-	mockFilesPath                 = "../../test/static"
+	describeInstanceTypes          = "DescribeInstanceTypes"
+	describeInstanceTypeOfferings  = "DescribeInstanceTypeOfferings"
+	describeAvailabilityZones      = "DescribeAvailabilityZones"
+	describeLaunchTemplateVersions = "DescribeLaunchTemplateVersions"
+	describeImages                 = "DescribeImages"
+	describePlacementGroups        = "DescribePlacementGroups"
+	describeCapacityBlockOfferings = "DescribeCapacityBlockOfferings"
+	mockFilesPath                  = "../../test/static"
 )
 
 // Mocking helpers.
@@ -53,12 +69,42 @@ type mockedEC2 struct {
 	DescribeInstanceTypeOfferingsErr    error
 	DescribeAvailabilityZonesResp       ec2.DescribeAvailabilityZonesOutput
 	DescribeAvailabilityZonesErr        error
+	DescribeRegionsResp                 ec2.DescribeRegionsOutput
+	DescribeRegionsErr                  error
+	DescribeLaunchTemplateVersionsResp  ec2.DescribeLaunchTemplateVersionsOutput
+	DescribeLaunchTemplateVersionsErr   error
+	DescribeImagesResp                  ec2.DescribeImagesOutput
+	DescribeImagesErr                   error
+	DescribePlacementGroupsResp         ec2.DescribePlacementGroupsOutput
+	DescribePlacementGroupsErr          error
+	DescribeCapacityBlockOfferingsResp  ec2.DescribeCapacityBlockOfferingsOutput
+	DescribeCapacityBlockOfferingsErr   error
+}
+
+func (m mockedEC2) DescribeCapacityBlockOfferings(ctx context.Context, input *ec2.DescribeCapacityBlockOfferingsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeCapacityBlockOfferingsOutput, error) {
+	return &m.DescribeCapacityBlockOfferingsResp, m.DescribeCapacityBlockOfferingsErr
+}
+
+func (m mockedEC2) DescribeLaunchTemplateVersions(ctx context.Context, input *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	return &m.DescribeLaunchTemplateVersionsResp, m.DescribeLaunchTemplateVersionsErr
+}
+
+func (m mockedEC2) DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	return &m.DescribeImagesResp, m.DescribeImagesErr
+}
+
+func (m mockedEC2) DescribePlacementGroups(ctx context.Context, input *ec2.DescribePlacementGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribePlacementGroupsOutput, error) {
+	return &m.DescribePlacementGroupsResp, m.DescribePlacementGroupsErr
 }
 
 func (m mockedEC2) DescribeAvailabilityZones(ctx context.Context, input *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
 	return &m.DescribeAvailabilityZonesResp, m.DescribeAvailabilityZonesErr
 }
 
+func (m mockedEC2) DescribeRegions(ctx context.Context, input *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	return &m.DescribeRegionsResp, m.DescribeRegionsErr
+}
+
 func (m mockedEC2) DescribeInstanceTypes(ctx context.Context, input *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
 	var response ec2.DescribeInstanceTypesOutput
 	if m.DescribeInstanceTypesRespFn != nil {
@@ -101,7 +147,7 @@ func mockMultiRespDescribeInstanceTypesOfferings(t *testing.T, locationToFile ma
 	}
 }
 
-func setupMock(t *testing.T, api string, file string) mockedEC2 {
+func setupMock(t testing.TB, api string, file string) mockedEC2 {
 	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, api, file)
 	mockFile, err := os.ReadFile(mockFilename)
 	h.Assert(t, err == nil, "Error reading mock file "+string(mockFilename))
@@ -127,6 +173,34 @@ func setupMock(t *testing.T, api string, file string) mockedEC2 {
 		return mockedEC2{
 			DescribeAvailabilityZonesResp: dazo,
 		}
+	case describeLaunchTemplateVersions:
+		dltvo := ec2.DescribeLaunchTemplateVersionsOutput{}
+		err = json.Unmarshal(mockFile, &dltvo)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedEC2{
+			DescribeLaunchTemplateVersionsResp: dltvo,
+		}
+	case describeImages:
+		dio := ec2.DescribeImagesOutput{}
+		err = json.Unmarshal(mockFile, &dio)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedEC2{
+			DescribeImagesResp: dio,
+		}
+	case describePlacementGroups:
+		dpgo := ec2.DescribePlacementGroupsOutput{}
+		err = json.Unmarshal(mockFile, &dpgo)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedEC2{
+			DescribePlacementGroupsResp: dpgo,
+		}
+	case describeCapacityBlockOfferings:
+		dcboo := ec2.DescribeCapacityBlockOfferingsOutput{}
+		err = json.Unmarshal(mockFile, &dcboo)
+		h.Assert(t, err == nil, "Error parsing mock json file contents"+mockFilename)
+		return mockedEC2{
+			DescribeCapacityBlockOfferingsResp: dcboo,
+		}
 	default:
 		h.Assert(t, false, "Unable to mock the provided API type "+api)
 	}
@@ -150,6 +224,129 @@ func TestNew(t *testing.T) {
 	h.Assert(t, itf != nil, "selector instance created without error")
 }
 
+// chdirTemp changes the working directory to a temporary directory for the duration of the test.
+func chdirTemp(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	h.Ok(t, err)
+	h.Ok(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+func TestNewInMemory(t *testing.T) {
+	ctx := context.Background()
+	dir := chdirTemp(t)
+	// the home directory must not be looked up
+	t.Setenv("HOME", filepath.Join(dir, "missing-home"))
+	// files which would be removed or overwritten if the caches used the working directory as their cache directory
+	decoys := []string{"us-east-1-" + instancetypes.CacheFileName, "us-east-1-" + offerings.CacheFileName, "us-east-1-" + ec2pricing.ODCacheFileName, "us-east-1-" + ec2pricing.SpotCacheFileName}
+	for _, decoy := range decoys {
+		h.Ok(t, os.WriteFile(decoy, []byte("{}"), 0o600))
+	}
+
+	itf, err := selector.NewInMemory(ctx, aws.Config{Region: "us-east-1"}, selector.WithOfferingsCacheTTL(time.Hour))
+	h.Ok(t, err)
+	h.Equals(t, time.Hour, itf.OfferingsProvider.(*offerings.Provider).TTL)
+	h.Equals(t, "", itf.InstanceTypesProvider.DirectoryPath)
+	itf.SetCacheReadOnly(false)
+	h.Ok(t, itf.Save())
+	h.Ok(t, itf.InstanceTypesProvider.Clear())
+	h.Ok(t, itf.OfferingsProvider.(*offerings.Provider).Clear())
+
+	entries, err := os.ReadDir(dir)
+	h.Ok(t, err)
+	h.Equals(t, len(decoys), len(entries))
+	for _, decoy := range decoys {
+		data, err := os.ReadFile(decoy)
+		h.Ok(t, err)
+		h.Equals(t, "{}", string(data))
+	}
+}
+
+// staticInstanceTypesProvider serves a fixed list of instance types without calling EC2.
+type staticInstanceTypesProvider struct {
+	instanceTypes []*instancetypes.Details
+	readOnly      bool
+}
+
+func (p *staticInstanceTypesProvider) Get(_ context.Context, _ []ec2types.InstanceType) ([]*instancetypes.Details, error) {
+	return p.instanceTypes, nil
+}
+func (p *staticInstanceTypesProvider) CacheCount() int                { return len(p.instanceTypes) }
+func (p *staticInstanceTypesProvider) Save() error                    { return nil }
+func (p *staticInstanceTypesProvider) SetLogger(_ *log.Logger)        {}
+func (p *staticInstanceTypesProvider) SetCacheReadOnly(readOnly bool) { p.readOnly = readOnly }
+
+func TestNew_WithOptions(t *testing.T) {
+	ctx := context.Background()
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	provider := &staticInstanceTypesProvider{}
+	for _, it := range ec2Mock.DescribeInstanceTypesResp.InstanceTypes {
+		provider.instanceTypes = append(provider.instanceTypes, &instancetypes.Details{InstanceTypeInfo: it})
+	}
+	pricingMock := &ec2PricingMock{}
+	itf, err := selector.New(ctx, aws.Config{Region: "us-east-1"}, selector.WithInstanceTypesProvider(provider), selector.WithPricing(pricingMock))
+	h.Ok(t, err)
+	h.Assert(t, itf.CustomInstanceTypesProvider == provider, "Should use the instance types provider passed in")
+	h.Assert(t, itf.EC2Pricing == pricingMock, "Should use the pricing client passed in")
+
+	itf.EC2 = mockedEC2{}
+	itf.SetCacheReadOnly(true)
+	h.Assert(t, provider.readOnly, "Should set the passed in instance types provider to read-only")
+	results, err := itf.Filter(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// an instance types Provider passed in is still set as the InstanceTypesProvider
+	instanceTypesProvider := instancetypes.NewProvider("us-east-1", ec2Mock)
+	itf, err = selector.New(ctx, aws.Config{Region: "us-east-1"}, selector.WithInstanceTypesProvider(instanceTypesProvider), selector.WithPricing(pricingMock))
+	h.Ok(t, err)
+	h.Assert(t, itf.InstanceTypesProvider == instanceTypesProvider, "Should set the instance types Provider passed in as the InstanceTypesProvider")
+	h.Assert(t, itf.CustomInstanceTypesProvider == nil, "Should not set a custom instance types provider")
+}
+
+// userAgentHTTPClient records the user agent of each request and responds with an empty DescribeRegions response.
+type userAgentHTTPClient struct {
+	userAgents []string
+}
+
+func (c *userAgentHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.userAgents = append(c.userAgents, req.Header.Get("User-Agent"))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader("<DescribeRegionsResponse><regionInfo></regionInfo></DescribeRegionsResponse>")),
+	}, nil
+}
+
+func TestNew_WithAppID(t *testing.T) {
+	ctx := context.Background()
+	httpClient := &userAgentHTTPClient{}
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		HTTPClient:  httpClient,
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+	itf, err := selector.New(ctx, cfg, selector.WithPricing(&ec2PricingMock{}), selector.WithAppID("my-app"))
+	h.Ok(t, err)
+	_, err = itf.Regions(ctx)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(httpClient.userAgents))
+	h.Assert(t, strings.Contains(httpClient.userAgents[0], "instance-selector/"), "the user agent should identify the selector, got %s", httpClient.userAgents[0])
+	h.Assert(t, strings.Contains(httpClient.userAgents[0], "app/my-app"), "the user agent should include the app id, got %s", httpClient.userAgents[0])
+	h.Equals(t, 0, len(cfg.APIOptions))
+
+	// the app id of the config is kept
+	cfg.AppID = "platform"
+	itf, err = selector.New(ctx, cfg, selector.WithPricing(&ec2PricingMock{}), selector.WithAppID("my-app"))
+	h.Ok(t, err)
+	_, err = itf.Regions(ctx)
+	h.Ok(t, err)
+	h.Assert(t, strings.Contains(httpClient.userAgents[1], "app/platform_my-app"), "the app id should be appended to the app id of the config, got %s", httpClient.userAgents[1])
+	h.Equals(t, "platform", cfg.AppID)
+}
+
 func TestFilterVerbose(t *testing.T) {
 	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
 	filters := selector.Filters{
@@ -200,6 +397,7 @@ func TestFilterVerbose_AZFilteredIn(t *testing.T) {
 	h.Ok(t, err)
 	h.Assert(t, len(results) == 1, "Should only return 1 instance type with 2 vcpus but actually returned "+strconv.Itoa(len(results)))
 	h.Assert(t, results[0].InstanceType == "t3.micro", "Should return t3.micro, got %s instead", results[0].InstanceType)
+	h.Equals(t, []instancetypes.AvailabilityZone{{ZoneName: "us-east-2a", ZoneID: "use2-az1"}}, results[0].AvailabilityZones)
 }
 
 func TestFilterVerbose_AZFilteredOut(t *testing.T) {
@@ -226,7 +424,22 @@ func TestFilterVerboseAZ_FilteredErr(t *testing.T) {
 	}
 	ctx := context.Background()
 	_, err := itf.FilterVerbose(ctx, filters)
-	h.Assert(t, err != nil, "Should error since bad zone was passed in")
+	h.Assert(t, errors.Is(err, selector.ErrRegionResolution), "Should return ErrRegionResolution since bad zone was passed in")
+}
+
+func TestFilterVerbose_InvalidFilterCombination(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	locationType := ec2types.LocationTypeOutpost
+	ctx := context.Background()
+	_, err := itf.FilterVerbose(ctx, selector.Filters{LocationType: &locationType})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination for a location type without availability zones")
+
+	version := "5"
+	_, err = itf.FilterVerbose(ctx, selector.Filters{LaunchTemplateVersion: &version})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination for a launch template version without a launch template")
+
+	_, err = itf.FilterGroupsVerbose(ctx, selector.FilterSet{})
+	h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "Should return ErrInvalidFilterCombination for an empty filter set")
 }
 
 func TestFilterVerbose_Gpus(t *testing.T) {
@@ -345,6 +558,100 @@ func TestRetrieveInstanceTypesSupportedInAZ_WithRegion(t *testing.T) {
 	h.Assert(t, len(results) == 228, "Should return 228 entries in us-east-2 golden file w/ no resource filter applied")
 }
 
+func TestRetrieveInstanceTypesSupportedInAZ_WithLocalZone(t *testing.T) {
+	ec2Mock := mockMultiRespDescribeInstanceTypesOfferings(t, map[string]string{
+		"us-west-2-lax-1a": "us-east-2a_only_c5d12x.json",
+		"usw2-lax1-az1":    "us-east-2a_only_c5d12x.json",
+	})
+	ec2Mock.DescribeAvailabilityZonesResp = ec2.DescribeAvailabilityZonesOutput{
+		AvailabilityZones: []ec2types.AvailabilityZone{
+			{
+				RegionName: aws.String("us-west-2"),
+				ZoneName:   aws.String("us-west-2-lax-1a"),
+				ZoneId:     aws.String("usw2-lax1-az1"),
+				ZoneType:   aws.String("local-zone"),
+			},
+		},
+	}
+	itf := getSelector(ec2Mock)
+	ctx := context.Background()
+	results, err := itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{"us-west-2-lax-1a"})
+	h.Ok(t, err)
+	h.Equals(t, map[ec2types.InstanceType]string{ec2types.InstanceTypeC5d12xlarge: ""}, results)
+
+	results, err = itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{"usw2-lax1-az1"})
+	h.Ok(t, err)
+	h.Equals(t, map[ec2types.InstanceType]string{ec2types.InstanceTypeC5d12xlarge: ""}, results)
+}
+
+func TestRetrieveInstanceTypesSupportedInAZ_WithOutpost(t *testing.T) {
+	outpostARN := "arn:aws:outposts:us-east-2:123456789012:outpost/op-0123456789abcdef0"
+	itf := getSelector(mockMultiRespDescribeInstanceTypesOfferings(t, map[string]string{
+		outpostARN: "us-east-2a_only_c5d12x.json",
+	}))
+	ctx := context.Background()
+	results, err := itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{outpostARN})
+	h.Ok(t, err)
+	h.Equals(t, map[ec2types.InstanceType]string{ec2types.InstanceTypeC5d12xlarge: ""}, results)
+
+	_, err = itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{"arn:aws:outposts:us-east-2:123456789012:site/os-0123456789abcdef0"})
+	h.Nok(t, err)
+}
+
+func TestFilter_Outpost(t *testing.T) {
+	outpostARN := "arn:aws:outposts:us-east-2:123456789012:outpost/op-0123456789abcdef0"
+	ec2Mock := mockMultiRespDescribeInstanceTypesOfferings(t, map[string]string{
+		outpostARN: "us-east-2a.json",
+	})
+	ec2Mock.DescribeInstanceTypesResp = setupMock(t, describeInstanceTypes, "c4_large.json").DescribeInstanceTypesResp
+	itf := getSelector(ec2Mock)
+	filters := selector.Filters{
+		AvailabilityZones: &[]string{outpostARN},
+	}
+	results, err := itf.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.large"}, results)
+
+	// the location type can be set explicitly for locations which are not detected, like outpost ids
+	locationType := ec2types.LocationTypeOutpost
+	ec2Mock = mockMultiRespDescribeInstanceTypesOfferings(t, map[string]string{
+		"op-0123456789abcdef0": "us-east-2a.json",
+	})
+	ec2Mock.DescribeInstanceTypesResp = setupMock(t, describeInstanceTypes, "c4_large.json").DescribeInstanceTypesResp
+	itf = getSelector(ec2Mock)
+	filters = selector.Filters{
+		AvailabilityZones: &[]string{"op-0123456789abcdef0"},
+		LocationType:      &locationType,
+	}
+	results, err = itf.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"c4.large"}, results)
+}
+
+func TestRetrieveInstanceTypesSupportedInAZ_CachedOfferings(t *testing.T) {
+	offeringsResp := setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp
+	calls := 0
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypeOfferingsRespFn: func(zone string) ec2.DescribeInstanceTypeOfferingsOutput {
+			calls++
+			return offeringsResp
+		},
+		DescribeAvailabilityZonesResp: setupMock(t, describeAvailabilityZones, "us-east-2.json").DescribeAvailabilityZonesResp,
+	}
+	itf := getSelector(ec2Mock)
+	offeringsProvider := offerings.NewProvider("us-east-2", ec2Mock)
+	offeringsProvider.TTL = time.Hour
+	itf.OfferingsProvider = offeringsProvider
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		results, err := itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{"us-east-2a"})
+		h.Ok(t, err)
+		h.Assert(t, len(results) == 228, "Should return 228 entries in us-east-2a golden file w/ no resource filters applied")
+	}
+	h.Equals(t, 1, calls)
+	h.Equals(t, 1, offeringsProvider.CacheCount())
+}
+
 func TestRetrieveInstanceTypesSupportedInAZ_WithBadZone(t *testing.T) {
 	ec2Mock := setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json")
 	ec2Mock.DescribeAvailabilityZonesResp = setupMock(t, describeAvailabilityZones, "us-east-2.json").DescribeAvailabilityZonesResp
@@ -460,7 +767,49 @@ func TestRetrieveInstanceTypesSupportedInAZs_DescribeAZErr(t *testing.T) {
 	itf := getSelector(mockedEC2{DescribeAvailabilityZonesErr: fmt.Errorf("error")})
 	ctx := context.Background()
 	_, err := itf.RetrieveInstanceTypesSupportedInLocations(ctx, []string{"us-east-2a"})
-	h.Nok(t, err)
+	h.Assert(t, errors.Is(err, selector.ErrRegionResolution), "Should return ErrRegionResolution when the zones cannot be described")
+	var apiErr *selector.APIError
+	h.Assert(t, errors.As(err, &apiErr), "Should wrap the AWS error in an APIError")
+	h.Equals(t, "DescribeAvailabilityZones", apiErr.API)
+}
+
+func TestSave_CacheError(t *testing.T) {
+	itf := getSelector(mockedEC2{})
+	saveErr := errors.New("disk full")
+	itf.EC2Pricing = &ec2PricingMock{SaveErr: saveErr}
+	itf.CustomInstanceTypesProvider = &staticInstanceTypesProvider{}
+
+	errs := multierr.Errors(itf.Save())
+	h.Equals(t, 1, len(errs))
+	var cacheErr *selector.CacheError
+	h.Assert(t, errors.As(errs[0], &cacheErr), "Should wrap the error in a CacheError")
+	h.Equals(t, selector.PricingCaches, cacheErr.Cache)
+	h.Assert(t, errors.Is(errs[0], saveErr), "Should wrap the error of the failing cache")
+
+	itf.EC2Pricing = &ec2PricingMock{}
+	h.Ok(t, itf.Save())
+
+	// the on-demand and spot pricing caches of a CacheSaver are saved separately
+	itf.EC2Pricing = &cacheSaverPricingMock{SaveSpotErr: saveErr}
+	errs = multierr.Errors(itf.Save())
+	h.Equals(t, 1, len(errs))
+	h.Assert(t, errors.As(errs[0], &cacheErr), "Should wrap the error in a CacheError")
+	h.Equals(t, selector.SpotPricingCache, cacheErr.Cache)
+}
+
+// cacheSaverPricingMock is an ec2PricingMock which saves the on-demand and spot pricing caches separately.
+type cacheSaverPricingMock struct {
+	ec2PricingMock
+	SaveOnDemandErr error
+	SaveSpotErr     error
+}
+
+func (p *cacheSaverPricingMock) SaveOnDemand() error {
+	return p.SaveOnDemandErr
+}
+
+func (p *cacheSaverPricingMock) SaveSpot() error {
+	return p.SaveSpotErr
 }
 
 func TestFilter_AllowList(t *testing.T) {
@@ -517,6 +866,49 @@ func TestFilter_AllowAndDenyList(t *testing.T) {
 	h.Assert(t, len(results) == 4, "Allow/Deny List Regex: 'c4.large' should return 4 instance types matching the regex but returned %d", len(results))
 }
 
+func TestFilter_AllowListGlob(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	allowRegex, err := selector.CompileInstanceTypePattern("c?.2xlarge")
+	h.Ok(t, err)
+	results, err := itf.Filter(context.Background(), selector.Filters{AllowList: allowRegex})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c3.2xlarge", "c4.2xlarge", "c5.2xlarge"}, results)
+}
+
+func TestCompileInstanceTypePattern(t *testing.T) {
+	for pattern, matches := range map[string]map[string]bool{
+		"m5*.large":  {"m5.large": true, "m5dn.large": true, "xm5.large": false, "m5.large2": false, "m5.xlarge": false},
+		"c?.xlarge":  {"c4.xlarge": true, "c5.xlarge": true, "c5a.xlarge": false, "mc4.xlarge": false},
+		"*.metal":    {"m5.metal": true, "a1.metal": true, "m5.large": false},
+		"c4.large":   {"c4.large": true, "mc4.large": true},
+		"m5.*":       {"m5.large": true, "m5a.large": true},
+		"^m[3-5]\\.": {"m4.large": true, "m6.large": false},
+	} {
+		regex, err := selector.CompileInstanceTypePattern(pattern)
+		h.Ok(t, err)
+		for instanceType, expected := range matches {
+			h.Assert(t, regex.MatchString(instanceType) == expected, "%s matching %s should be %t", pattern, instanceType, expected)
+		}
+	}
+	h.Assert(t, selector.IsInstanceTypeGlob("m5*.large"), "m5*.large should be a glob")
+	h.Assert(t, !selector.IsInstanceTypeGlob("m5.*"), "m5.* should be a regex")
+	h.Assert(t, !selector.IsInstanceTypeGlob("c?.?xlarge"), "patterns containing .? should be a regex")
+	h.Assert(t, !selector.IsInstanceTypeGlob("c4.large"), "c4.large should be a regex")
+
+	_, err := selector.CompileInstanceTypePattern("((")
+	h.Nok(t, err)
+}
+
+func TestFilter_VCpusList(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	filters := selector.Filters{
+		VCpusList: &selector.Int32SetFilter{1, 36},
+	}
+	results, err := itf.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.medium", "c4.8xlarge", "c5.9xlarge"}, results)
+}
+
 func TestFilter_X8664_AMD64(t *testing.T) {
 	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
 	ArchitectureType := selector.ArchitectureTypeAMD64
@@ -551,20 +943,28 @@ func TestFilter_VirtType_PV(t *testing.T) {
 }
 
 type ec2PricingMock struct {
-	GetOndemandInstanceTypeCostResp    float64
-	GetOndemandInstanceTypeCostErr     error
-	GetSpotInstanceTypeNDayAvgCostResp float64
-	GetSpotInstanceTypeNDayAvgCostErr  error
-	RefreshOnDemandCacheErr            error
-	RefreshSpotCacheErr                error
-	onDemandCacheCount                 int
-	spotCacheCount                     int
+	GetOndemandInstanceTypeCostResp          float64
+	GetOndemandInstanceTypeCostErr           error
+	GetSpotInstanceTypeNDayAvgCostResp       float64
+	GetSpotInstanceTypeNDayAvgCostErr        error
+	GetSpotInstanceTypeNDayAvgCostByZoneResp map[string]float64
+	RefreshOnDemandCacheErr                  error
+	RefreshSpotCacheErr                      error
+	SaveErr                                  error
+	onDemandCacheCount                       int
+	spotCacheCount                           int
+	refreshedSpotInstanceTypes               []ec2types.InstanceType
+	refreshedSpotZones                       []string
 }
 
 func (p *ec2PricingMock) GetOnDemandInstanceTypeCost(ctx context.Context, instanceType ec2types.InstanceType) (float64, error) {
 	return p.GetOndemandInstanceTypeCostResp, p.GetOndemandInstanceTypeCostErr
 }
 
+func (p *ec2PricingMock) GetSpotInstanceTypeNDayAvgCostByZone(ctx context.Context, instanceType ec2types.InstanceType, days int) (map[string]float64, error) {
+	return p.GetSpotInstanceTypeNDayAvgCostByZoneResp, p.GetSpotInstanceTypeNDayAvgCostErr
+}
+
 func (p *ec2PricingMock) GetSpotInstanceTypeNDayAvgCost(ctx context.Context, instanceType ec2types.InstanceType, availabilityZones []string, days int) (float64, error) {
 	return p.GetSpotInstanceTypeNDayAvgCostResp, p.GetSpotInstanceTypeNDayAvgCostErr
 }
@@ -577,6 +977,12 @@ func (p *ec2PricingMock) RefreshSpotCache(ctx context.Context, days int) error {
 	return p.RefreshSpotCacheErr
 }
 
+func (p *ec2PricingMock) RefreshSpotCacheFor(ctx context.Context, days int, instanceTypes []ec2types.InstanceType, availabilityZones []string) error {
+	p.refreshedSpotInstanceTypes = instanceTypes
+	p.refreshedSpotZones = availabilityZones
+	return p.RefreshSpotCacheErr
+}
+
 func (p *ec2PricingMock) OnDemandCacheCount() int {
 	return p.onDemandCacheCount
 }
@@ -586,9 +992,10 @@ func (p *ec2PricingMock) SpotCacheCount() int {
 }
 
 func (p *ec2PricingMock) Save() error {
-	return nil
+	return p.SaveErr
 }
 func (p *ec2PricingMock) SetLogger(_ *log.Logger) {}
+func (p *ec2PricingMock) SetCacheReadOnly(_ bool) {}
 
 func TestFilter_PricePerHour(t *testing.T) {
 	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
@@ -608,60 +1015,948 @@ func TestFilter_PricePerHour(t *testing.T) {
 	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
 }
 
-func TestFilter_PricePerHour_NoResults(t *testing.T) {
+func TestPrices(t *testing.T) {
 	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
 	itf.EC2Pricing = &ec2PricingMock{
 		GetOndemandInstanceTypeCostResp: 0.0104,
-		onDemandCacheCount:              1,
-	}
-	filters := selector.Filters{
-		PricePerHour: &selector.Float64RangeFilter{
-			LowerBound: 0.0105,
-			UpperBound: 0.0105,
+		GetSpotInstanceTypeNDayAvgCostByZoneResp: map[string]float64{
+			"us-east-1a": 0.0031,
+			"us-east-1b": 0.0042,
 		},
 	}
 	ctx := context.Background()
-	results, err := itf.Filter(ctx, filters)
+	prices, err := itf.Prices(ctx, []ec2types.InstanceType{ec2types.InstanceTypeT3Micro}, nil)
 	h.Ok(t, err)
-	h.Assert(t, len(results) == 0, "Should return 0 instance types")
+	h.Assert(t, len(prices) == 1, fmt.Sprintf("Should return prices of 1 instance type; got %d", len(prices)))
+	h.Equals(t, ec2types.InstanceTypeT3Micro, prices[0].InstanceType)
+	h.Equals(t, 0.0104, *prices[0].OnDemandPricePerHour)
+	h.Equals(t, []string{"us-east-1a", "us-east-1b"}, prices[0].SpotZones())
+
+	prices, err = itf.Prices(ctx, []ec2types.InstanceType{ec2types.InstanceTypeT3Micro}, []string{"us-east-1b"})
+	h.Ok(t, err)
+	h.Equals(t, map[string]float64{"us-east-1b": 0.0042}, prices[0].SpotPricesPerHour)
 }
 
-func TestFilter_PricePerHour_OD(t *testing.T) {
+func TestPrices_NotZonalSpotPricer(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	// only the methods of EC2PricingIface are promoted so the pricing does not implement ec2pricing.ZonalSpotPricer
+	itf.EC2Pricing = struct{ ec2pricing.EC2PricingIface }{&ec2PricingMock{
+		GetOndemandInstanceTypeCostResp:    0.0104,
+		GetSpotInstanceTypeNDayAvgCostResp: 0.0031,
+	}}
+	itf.Logger = log.New(io.Discard, "", 0)
+	ctx := context.Background()
+	prices, err := itf.Prices(ctx, []ec2types.InstanceType{ec2types.InstanceTypeT3Micro}, []string{"us-east-1a", "us-east-1b"})
+	h.Ok(t, err)
+	h.Equals(t, map[string]float64{"us-east-1a": 0.0031, "us-east-1b": 0.0031}, prices[0].SpotPricesPerHour)
+
+	prices, err = itf.Prices(ctx, []ec2types.InstanceType{ec2types.InstanceTypeT3Micro}, nil)
+	h.Ok(t, err)
+	h.Equals(t, 0.0104, *prices[0].OnDemandPricePerHour)
+	h.Equals(t, map[string]float64{}, prices[0].SpotPricesPerHour)
+}
+
+func TestAddPrices(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	pricing := &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp:    0.0104,
+		GetSpotInstanceTypeNDayAvgCostResp: 0.0031,
+	}
+	itf.EC2Pricing = pricing
+	ctx := context.Background()
+	filters := selector.Filters{}
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Assert(t, results[0].OndemandPricePerHour == nil, "Should not be priced before the pricing caches are hydrated")
+
+	pricing.onDemandCacheCount = 1
+	pricing.spotCacheCount = 1
+	results[0].InstanceType = ec2types.InstanceType(selector.SageMakerInstanceTypeName(string(results[0].InstanceType)))
+	priced, err := itf.AddPrices(ctx, filters, results)
+	h.Ok(t, err)
+	h.Assert(t, len(priced) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(priced)))
+	h.Equals(t, "ml.t3.micro", string(priced[0].InstanceType))
+	h.Equals(t, 0.0104, *priced[0].OndemandPricePerHour)
+	h.Equals(t, 0.0031, *priced[0].SpotPrice)
+	h.Assert(t, priced[0].SpotSavings != nil, "Should derive the spot savings from the prices")
+	h.Assert(t, results[0].OndemandPricePerHour == nil, "Should not modify the passed in instance types")
+}
+
+func TestCatalog(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	catalog, err := itf.Catalog(context.Background())
+	h.Ok(t, err)
+	h.Equals(t, 25, len(catalog.InstanceTypes))
+	h.Equals(t, ec2types.InstanceTypeA12xlarge, catalog.InstanceTypes[0].InstanceType)
+	h.Equals(t, []string{"a1", "c1", "c3", "c4", "c5"}, catalog.Families())
+
+	instanceTypeInfo, ok := catalog.Get(ec2types.InstanceTypeC4Large)
+	h.Assert(t, ok, "Should get c4.large from the catalog")
+	h.Equals(t, ec2types.InstanceTypeC4Large, instanceTypeInfo.InstanceType)
+	_, ok = catalog.Get(ec2types.InstanceTypeM5Large)
+	h.Assert(t, !ok, "Should not get m5.large from the catalog")
+
+	h.Equals(t, []string{"c1.medium", "c1.xlarge"}, instanceTypeNames(catalog.Family("c1")))
+	h.Equals(t, []string{"a1.medium", "c1.medium"}, instanceTypeNames(catalog.Size("medium")))
+	h.Equals(t, 0, len(catalog.Family("m5")))
+
+	// modifying the catalog does not modify the instance types cache
+	instanceTypeInfo.Region = "us-west-2"
+	catalog, err = itf.Catalog(context.Background())
+	h.Ok(t, err)
+	instanceTypeInfo, _ = catalog.Get(ec2types.InstanceTypeC4Large)
+	h.Equals(t, "", instanceTypeInfo.Region)
+}
+
+func TestScaleInstanceType(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	catalog, err := itf.Catalog(context.Background())
+	h.Ok(t, err)
+	for _, tc := range []struct {
+		instanceType ec2types.InstanceType
+		steps        int
+		expected     ec2types.InstanceType
+	}{
+		{instanceType: "a1.xlarge", steps: 1, expected: "a1.2xlarge"},
+		{instanceType: "a1.2xlarge", steps: -2, expected: "a1.large"},
+		{instanceType: "c5.large", steps: 2, expected: "c5.4xlarge"},
+		// a1.metal has the same vCPUs and memory as a1.4xlarge
+		{instanceType: "a1.metal", steps: 0, expected: "a1.4xlarge"},
+		{instanceType: "a1.metal", steps: -1, expected: "a1.2xlarge"},
+	} {
+		scaledInstanceType, err := catalog.ScaleInstanceType(tc.instanceType, tc.steps)
+		h.Ok(t, err)
+		h.Equals(t, tc.expected, scaledInstanceType.InstanceType)
+	}
+
+	_, err = catalog.ScaleInstanceType("a1.4xlarge", 1)
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), fmt.Sprintf("Should not scale beyond the largest size of the family, got %v", err))
+	_, err = catalog.ScaleInstanceType("a1.medium", -1)
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), fmt.Sprintf("Should not scale below the smallest size of the family, got %v", err))
+	_, err = catalog.ScaleInstanceType("m5.large", 1)
+	h.Assert(t, errors.Is(err, selector.ErrNotFound), fmt.Sprintf("Should not scale instance types which are not in the catalog, got %v", err))
+}
+
+func TestEquivalentInstanceTypes(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	catalog, err := itf.Catalog(context.Background())
+	h.Ok(t, err)
+	equivalents, err := catalog.EquivalentInstanceTypes("c5.2xlarge")
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge"}, instanceTypeNames(equivalents))
+	equivalents, err = catalog.EquivalentInstanceTypes("c4.4xlarge")
+	h.Ok(t, err)
+	h.Equals(t, []string{"c3.4xlarge"}, instanceTypeNames(equivalents))
+	_, err = catalog.EquivalentInstanceTypes("m5.large")
+	h.Nok(t, err)
+}
+
+func TestInstanceTypeFamilyAndSize(t *testing.T) {
+	family, size := selector.InstanceTypeFamilyAndSize(ec2types.InstanceTypeM5dnXlarge)
+	h.Equals(t, "m5dn", family)
+	h.Equals(t, "xlarge", size)
+	family, size = selector.InstanceTypeFamilyAndSize("u-6tb1.metal")
+	h.Equals(t, "u-6tb1", family)
+	h.Equals(t, "metal", size)
+}
+
+func instanceTypeNames(instanceTypeDetails []*instancetypes.Details) []string {
+	names := []string{}
+	for _, instanceTypeInfo := range instanceTypeDetails {
+		names = append(names, string(instanceTypeInfo.InstanceType))
+	}
+	return names
+}
+
+func TestFilter_PricePerMonth(t *testing.T) {
 	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
 	itf.EC2Pricing = &ec2PricingMock{
-		GetOndemandInstanceTypeCostResp: 0.0104,
+		GetOndemandInstanceTypeCostResp: 0.01,
 		onDemandCacheCount:              1,
 	}
-	onDemandUsage := ec2types.UsageClassTypeOnDemand
 	filters := selector.Filters{
-		PricePerHour: &selector.Float64RangeFilter{
-			LowerBound: 0.0104,
-			UpperBound: 0.0104,
-		},
-		UsageClass: &onDemandUsage,
+		PricePerMonth: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 7.5},
 	}
 	ctx := context.Background()
-	results, err := itf.Filter(ctx, filters)
+	results, err := itf.FilterVerbose(ctx, filters)
 	h.Ok(t, err)
 	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Equals(t, 7.3, *results[0].OndemandPricePerMonth)
+
+	filters.HoursPerMonth = aws.Float64(1000)
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 0, fmt.Sprintf("Should return 0 instance types when running 1000 hours per month; got %d", len(results)))
 }
 
-func TestFilter_PricePerHour_Spot(t *testing.T) {
-	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
-	itf.EC2Pricing = &ec2PricingMock{
-		GetSpotInstanceTypeNDayAvgCostResp: 0.0104,
-		spotCacheCount:                     1,
+func TestFilter_PricePerHour_CapacityBlock(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:          setupMock(t, describeInstanceTypes, "p5_48xlarge.json").DescribeInstanceTypesResp,
+		DescribeCapacityBlockOfferingsResp: setupMock(t, describeCapacityBlockOfferings, "p5_48xlarge.json").DescribeCapacityBlockOfferingsResp,
 	}
-	spotUsage := ec2types.UsageClassTypeSpot
+	itf := getSelector(ec2Mock)
+	capacityBlockUsage := ec2types.UsageClassTypeCapacityBlock
 	filters := selector.Filters{
 		PricePerHour: &selector.Float64RangeFilter{
-			LowerBound: 0.0104,
-			UpperBound: 0.0104,
+			LowerBound: 30,
+			UpperBound: 30,
 		},
-		UsageClass: &spotUsage,
+		UsageClass: &capacityBlockUsage,
 	}
 	ctx := context.Background()
-	results, err := itf.Filter(ctx, filters)
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Equals(t, float64(30), *results[0].CapacityBlockPricePerHour)
+
+	// instance types without capacity block offerings have an unknown price and are excluded by the price filters
+	ec2Mock.DescribeCapacityBlockOfferingsResp = ec2.DescribeCapacityBlockOfferingsOutput{}
+	itf = getSelector(ec2Mock)
+	filters.PricePerHour = &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 30}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 0, fmt.Sprintf("Should not return instance types without a capacity block price; got %d", len(results)))
+	filters.PricePerHour = &selector.Float64RangeFilter{LowerBound: 30, UpperBound: 30}
+
+	onDemandUsage := ec2types.UsageClassTypeOnDemand
+	filters.UsageClass = &onDemandUsage
+	itf.EC2Pricing = &ec2PricingMock{onDemandCacheCount: 1}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 0, "Should not use capacity block pricing for the on-demand usage class")
+}
+
+func TestFilter_BaselineCPU(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"))
+	filters := selector.Filters{
+		BaselineCPURange: &selector.Float64RangeFilter{
+			LowerBound: 5,
+			UpperBound: 10,
+		},
+	}
+	ctx := context.Background()
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Equals(t, ec2types.InstanceTypeT3Micro, results[0].InstanceType)
+	h.Equals(t, float64(10), *results[0].BaselineCPUUtilization)
+	h.Equals(t, float64(12), *results[0].CPUCreditsPerHour)
+
+	filters.BaselineCPURange = &selector.Float64RangeFilter{
+		LowerBound: 100,
+		UpperBound: 100,
+	}
+	results, err = itf.FilterVerbose(ctx, filters)
 	h.Ok(t, err)
 	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Equals(t, ec2types.InstanceTypeP316xlarge, results[0].InstanceType)
+	h.Equals(t, float64(100), *results[0].BaselineCPUUtilization)
+	h.Assert(t, results[0].CPUCreditsPerHour == nil, "Should not populate CPU credits for instance types that are not burstable")
+}
+
+func TestFilter_CarbonScore(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"))
+	ctx := context.Background()
+	results, err := itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 2, fmt.Sprintf("Should return 2 instance types; got %d", len(results)))
+	carbonScores := map[ec2types.InstanceType]float64{}
+	for _, result := range results {
+		h.Assert(t, result.CarbonScore != nil, fmt.Sprintf("Should estimate a carbon score for %s", result.InstanceType))
+		carbonScores[result.InstanceType] = *result.CarbonScore
+	}
+	h.Equals(t, 4.632, carbonScores[ec2types.InstanceTypeT3Micro])
+	h.Equals(t, 1466.976, carbonScores[ec2types.InstanceTypeP316xlarge])
+
+	carbonDataFile := filepath.Join(t.TempDir(), "carbon.json")
+	h.Ok(t, os.WriteFile(carbonDataFile, []byte(`{"t3.micro": 1.5}`), 0o600))
+	itf.CarbonData, err = selector.LoadCarbonData(carbonDataFile)
+	h.Ok(t, err)
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	for _, result := range results {
+		carbonScores[result.InstanceType] = *result.CarbonScore
+	}
+	h.Equals(t, 1.5, carbonScores[ec2types.InstanceTypeT3Micro])
+	h.Equals(t, 1466.976, carbonScores[ec2types.InstanceTypeP316xlarge])
+}
+
+func TestFilter_NitroGeneration(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	results, err := itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	nitroGenerations := map[ec2types.InstanceType]int{}
+	for _, result := range results {
+		h.Assert(t, result.NitroGeneration != nil, fmt.Sprintf("Should derive a nitro generation for %s", result.InstanceType))
+		nitroGenerations[result.InstanceType] = *result.NitroGeneration
+	}
+	h.Equals(t, 3, nitroGenerations[ec2types.InstanceTypeA1Metal])
+	h.Equals(t, 3, nitroGenerations[ec2types.InstanceTypeC5Large])
+	h.Equals(t, 0, nitroGenerations[ec2types.InstanceTypeC4Large])
+
+	filters := selector.Filters{
+		NitroGeneration: &selector.IntRangeFilter{LowerBound: 1, UpperBound: math.MaxInt},
+	}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 13, fmt.Sprintf("Should return the 13 nitro instance types; got %d", len(results)))
+}
+
+func TestFilter_NormalizationFactor(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	results, err := itf.FilterVerbose(context.Background(), selector.Filters{})
+	h.Ok(t, err)
+	normalizationFactors := map[ec2types.InstanceType]float64{}
+	for _, result := range results {
+		h.Assert(t, result.NormalizationFactor != nil, "Should derive a normalization factor for %s", result.InstanceType)
+		normalizationFactors[result.InstanceType] = *result.NormalizationFactor
+	}
+	h.Equals(t, 2.0, normalizationFactors[ec2types.InstanceTypeA1Medium])
+	h.Equals(t, 4.0, normalizationFactors[ec2types.InstanceTypeC5Large])
+	h.Equals(t, 144.0, normalizationFactors[ec2types.InstanceTypeC518xlarge])
+	// the metal size is equivalent to the largest size of the family
+	h.Equals(t, normalizationFactors[ec2types.InstanceTypeA14xlarge], normalizationFactors[ec2types.InstanceTypeA1Metal])
+}
+
+func TestFilter_IPv6OnlySubnetCapable(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	filters := selector.Filters{
+		IPv6OnlySubnetCapable: aws.Bool(true),
+	}
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) > 0, "Should return at least 1 instance type")
+	for _, result := range results {
+		h.Assert(t, aws.ToBool(result.NetworkInfo.Ipv6Supported), fmt.Sprintf("%s should support IPv6", result.InstanceType))
+		h.Assert(t, result.Hypervisor == ec2types.InstanceTypeHypervisorNitro || aws.ToBool(result.BareMetal), fmt.Sprintf("%s should be built on the Nitro System", result.InstanceType))
+	}
+
+	filters.IPv6OnlySubnetCapable = aws.Bool(false)
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	instanceTypes := map[ec2types.InstanceType]bool{}
+	for _, result := range results {
+		instanceTypes[result.InstanceType] = true
+	}
+	h.Assert(t, instanceTypes[ec2types.InstanceTypeC4Large], "c4.large should not be IPv6-only subnet capable")
+}
+
+func TestFilter_EBSOptimizedSupport(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	for _, support := range []ec2types.EbsOptimizedSupport{ec2types.EbsOptimizedSupportDefault, ec2types.EbsOptimizedSupportSupported} {
+		filters := selector.Filters{
+			EBSOptimizedSupport: &support,
+		}
+		results, err := itf.FilterVerbose(ctx, filters)
+		h.Ok(t, err)
+		h.Assert(t, len(results) > 0, "Should return at least 1 instance type where EBS optimization is %s", support)
+		for _, result := range results {
+			h.Equals(t, support, result.EbsInfo.EbsOptimizedSupport)
+		}
+	}
+
+	// both default and supported are EBS optimized
+	filters := selector.Filters{
+		EBSOptimized: aws.Bool(true),
+	}
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	supports := map[ec2types.EbsOptimizedSupport]bool{}
+	for _, result := range results {
+		supports[result.EbsInfo.EbsOptimizedSupport] = true
+	}
+	h.Equals(t, map[ec2types.EbsOptimizedSupport]bool{ec2types.EbsOptimizedSupportDefault: true, ec2types.EbsOptimizedSupportSupported: true}, supports)
+}
+
+func TestFilter_PlacementGroupStrategy(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	// c1 instance types support the partition and spread strategies but not cluster
+	filters := selector.Filters{
+		PlacementGroupStrategy: aws.String("cluster"),
+	}
+	results, err := itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 23, len(results))
+
+	filters = selector.Filters{
+		PlacementGroupStrategies: &[]string{"cluster", "partition"},
+	}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 23, len(results))
+	for _, result := range results {
+		h.Assert(t, slices.Contains(result.PlacementGroupInfo.SupportedStrategies, ec2types.PlacementGroupStrategyCluster), "%s should support the cluster strategy", result.InstanceType)
+	}
+
+	filters.PlacementGroupStrategyMatch = aws.String(selector.PlacementGroupStrategyMatchAny)
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+
+	// PlacementGroupStrategy is matched along with PlacementGroupStrategies
+	filters = selector.Filters{
+		PlacementGroupStrategy:   aws.String("cluster"),
+		PlacementGroupStrategies: &[]string{"spread"},
+	}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 23, len(results))
+
+	filters = selector.Filters{
+		PlacementGroupStrategies: &[]string{"spread"},
+	}
+	results, err = itf.FilterVerbose(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+}
+
+func TestLoadCarbonData_Invalid(t *testing.T) {
+	_, err := selector.LoadCarbonData(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	h.Nok(t, err)
+
+	carbonDataFile := filepath.Join(t.TempDir(), "carbon.json")
+	h.Ok(t, os.WriteFile(carbonDataFile, []byte(`{"t3.micro": "low"}`), 0o600))
+	_, err = selector.LoadCarbonData(carbonDataFile)
+	h.Nok(t, err)
+}
+
+func TestFilter_ExcludeDeprecated(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	results, err := itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	deprecations := map[ec2types.InstanceType]string{}
+	for _, result := range results {
+		if result.Deprecation != nil {
+			deprecations[result.InstanceType] = *result.Deprecation
+		}
+	}
+	h.Equals(t, "previous generation", deprecations[ec2types.InstanceTypeC1Medium])
+	h.Equals(t, "previous generation", deprecations[ec2types.InstanceTypeC3Large])
+	_, ok := deprecations[ec2types.InstanceTypeC4Large]
+	h.Assert(t, !ok, "c4.large should not be deprecated")
+
+	// deprecations of instance types take precedence over their family and an empty reason removes a built-in deprecation
+	itf.Deprecations = map[string]string{"c4": "internal EOL", "c4.xlarge": "", "c3": ""}
+	results, err = itf.FilterVerbose(ctx, selector.Filters{ExcludeDeprecated: aws.Bool(true)})
+	h.Ok(t, err)
+	h.Assert(t, len(results) > 0, "Should return at least 1 instance type")
+	instanceTypes := map[ec2types.InstanceType]bool{}
+	for _, result := range results {
+		h.Assert(t, result.Deprecation == nil, "%s should not be deprecated", result.InstanceType)
+		instanceTypes[result.InstanceType] = true
+	}
+	h.Assert(t, !instanceTypes[ec2types.InstanceTypeC1Medium], "c1.medium should be excluded")
+	h.Assert(t, !instanceTypes[ec2types.InstanceTypeC4Large], "c4.large should be excluded")
+	h.Assert(t, instanceTypes[ec2types.InstanceTypeC4Xlarge], "c4.xlarge should not be excluded")
+	h.Assert(t, instanceTypes[ec2types.InstanceTypeC3Large], "c3.large should not be excluded")
+}
+
+func TestLoadDeprecations(t *testing.T) {
+	deprecationsFile := filepath.Join(t.TempDir(), "deprecations.json")
+	h.Ok(t, os.WriteFile(deprecationsFile, []byte(`{"m4": "internal EOL", "c3": ""}`), 0o600))
+	deprecations, err := selector.LoadDeprecations(deprecationsFile)
+	h.Ok(t, err)
+	h.Equals(t, map[string]string{"m4": "internal EOL", "c3": ""}, deprecations)
+
+	_, err = selector.LoadDeprecations(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	h.Nok(t, err)
+	h.Ok(t, os.WriteFile(deprecationsFile, []byte(`{"m4": true}`), 0o600))
+	_, err = selector.LoadDeprecations(deprecationsFile)
+	h.Nok(t, err)
+}
+
+func TestLoadOrderPreferences(t *testing.T) {
+	orderPreferenceFile := filepath.Join(t.TempDir(), "order-preferences.txt")
+	h.Ok(t, os.WriteFile(orderPreferenceFile, []byte("# preferred for the ASG overrides\nm7i.large\n\n  c7i.large  \nm6i.large\n"), 0o600))
+	preferences, err := selector.LoadOrderPreferences(orderPreferenceFile)
+	h.Ok(t, err)
+	h.Equals(t, []ec2types.InstanceType{"m7i.large", "c7i.large", "m6i.large"}, preferences)
+}
+
+func TestLoadOrderPreferences_Invalid(t *testing.T) {
+	_, err := selector.LoadOrderPreferences(filepath.Join(t.TempDir(), "does-not-exist.txt"))
+	h.Nok(t, err)
+
+	orderPreferenceFile := filepath.Join(t.TempDir(), "order-preferences.txt")
+	for _, contents := range []string{"m7i.large,c7i.large\n", "m7i.large c7i.large\n", "# no instance types\n\n"} {
+		h.Ok(t, os.WriteFile(orderPreferenceFile, []byte(contents), 0o600))
+		_, err = selector.LoadOrderPreferences(orderPreferenceFile)
+		h.Nok(t, err)
+	}
+}
+
+func TestFilter_PricePerHour_NoResults(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: 0.0104,
+		onDemandCacheCount:              1,
+	}
+	filters := selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{
+			LowerBound: 0.0105,
+			UpperBound: 0.0105,
+		},
+	}
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 0, "Should return 0 instance types")
+}
+
+func TestFilter_PricePerHour_PricingUnavailable(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	filters := selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{
+			LowerBound: 0.0104,
+			UpperBound: 0.0104,
+		},
+	}
+	ctx := context.Background()
+	_, err := itf.Filter(ctx, filters)
+	h.Assert(t, errors.Is(err, selector.ErrPricingUnavailable), "Should return ErrPricingUnavailable when the on-demand pricing cache is empty")
+
+	spotUsage := ec2types.UsageClassTypeSpot
+	filters.UsageClass = &spotUsage
+	itf.EC2Pricing = &ec2PricingMock{onDemandCacheCount: 1}
+	_, err = itf.Filter(ctx, filters)
+	h.Assert(t, errors.Is(err, selector.ErrPricingUnavailable), "Should return ErrPricingUnavailable when the spot pricing cache is empty")
+}
+
+func TestFilter_PricePerHour_OD(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: 0.0104,
+		onDemandCacheCount:              1,
+	}
+	onDemandUsage := ec2types.UsageClassTypeOnDemand
+	filters := selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{
+			LowerBound: 0.0104,
+			UpperBound: 0.0104,
+		},
+		UsageClass: &onDemandUsage,
+	}
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+}
+
+func TestFilter_PricePerHour_Spot(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetSpotInstanceTypeNDayAvgCostResp: 0.0104,
+		spotCacheCount:                     1,
+	}
+	spotUsage := ec2types.UsageClassTypeSpot
+	filters := selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{
+			LowerBound: 0.0104,
+			UpperBound: 0.0104,
+		},
+		UsageClass: &spotUsage,
+	}
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+}
+
+func TestFilter_PricePerHour_WithoutPrice(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.Logger = log.New(io.Discard, "", 0)
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostErr:    errors.New("no on-demand price"),
+		GetSpotInstanceTypeNDayAvgCostErr: errors.New("no spot price"),
+		onDemandCacheCount:                1,
+		spotCacheCount:                    1,
+	}
+	filters := selector.Filters{
+		PricePerHour: &selector.Float64RangeFilter{
+			LowerBound: 0,
+			UpperBound: 0.0104,
+		},
+	}
+	ctx := context.Background()
+	// an instance type without an on-demand price is filtered as if it costs $0
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	// an instance type without a spot price is filtered by its on-demand price
+	spotUsage := ec2types.UsageClassTypeSpot
+	filters.UsageClass = &spotUsage
+	results, err = itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	filters.PricePerHour.LowerBound = 0.0001
+	results, err = itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+}
+
+func TestFilter_SpotSavings(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp:    0.0104,
+		onDemandCacheCount:                 1,
+		GetSpotInstanceTypeNDayAvgCostResp: 0.0026,
+		spotCacheCount:                     1,
+	}
+	ctx := context.Background()
+	results, err := itf.FilterVerbose(ctx, selector.Filters{SpotSavings: &selector.Float64RangeFilter{LowerBound: 60, UpperBound: math.MaxFloat64}})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 1, fmt.Sprintf("Should return 1 instance type; got %d", len(results)))
+	h.Equals(t, 75.0, *results[0].SpotSavings)
+
+	results, err = itf.FilterVerbose(ctx, selector.Filters{SpotSavings: &selector.Float64RangeFilter{LowerBound: 80, UpperBound: math.MaxFloat64}})
+	h.Ok(t, err)
+	h.Assert(t, len(results) == 0, fmt.Sprintf("Should return 0 instance types; got %d", len(results)))
+
+	itf.EC2Pricing = &ec2PricingMock{GetOndemandInstanceTypeCostResp: 0.0104, onDemandCacheCount: 1}
+	_, err = itf.FilterVerbose(ctx, selector.Filters{SpotSavings: &selector.Float64RangeFilter{LowerBound: 60, UpperBound: math.MaxFloat64}})
+	h.Assert(t, errors.Is(err, selector.ErrPricingUnavailable), "Should return ErrPricingUnavailable without spot prices")
+}
+
+func TestAddOfferedZones(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+		DescribeAvailabilityZonesResp:     setupMock(t, describeAvailabilityZones, "us-east-2.json").DescribeAvailabilityZonesResp,
+	}
+	itf := getSelector(ec2Mock)
+	filteredZones := []instancetypes.AvailabilityZone{{ZoneName: "us-east-2b", ZoneID: "use2-az2"}}
+	instanceTypes := []*instancetypes.Details{
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: ec2types.InstanceTypeT3Micro}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: ec2types.InstanceTypeM512xlarge}, AvailabilityZones: filteredZones},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: ec2types.InstanceTypeU12tb1Metal}},
+	}
+	err := itf.AddOfferedZones(context.Background(), instanceTypes)
+	h.Ok(t, err)
+	h.Equals(t, []instancetypes.AvailabilityZone{{ZoneName: "us-east-2a", ZoneID: "use2-az1"}}, instanceTypes[0].AvailabilityZones)
+	h.Equals(t, filteredZones, instanceTypes[1].AvailabilityZones)
+	h.Assert(t, len(instanceTypes[2].AvailabilityZones) == 0, "Instance types which are not offered should not have zones")
+}
+
+func TestRefreshSpotCacheForFilters(t *testing.T) {
+	ec2Mock := mockedEC2{
+		DescribeInstanceTypesResp:         setupMock(t, describeInstanceTypes, "25_instances.json").DescribeInstanceTypesResp,
+		DescribeInstanceTypeOfferingsResp: setupMock(t, describeInstanceTypeOfferings, "us-east-2a.json").DescribeInstanceTypeOfferingsResp,
+		DescribeAvailabilityZonesResp:     setupMock(t, describeAvailabilityZones, "us-east-2.json").DescribeAvailabilityZonesResp,
+	}
+	itf := getSelector(ec2Mock)
+	pricingMock := &ec2PricingMock{}
+	itf.EC2Pricing = pricingMock
+	filters := selector.Filters{
+		VCpusRange:        &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2},
+		AvailabilityZones: &[]string{"us-east-2a"},
+		PricePerHour:      &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 0.001},
+	}
+	ctx := context.Background()
+	err := itf.RefreshSpotCacheForFilters(ctx, filters, 30)
+	h.Ok(t, err)
+	expected, err := itf.Filter(ctx, selector.Filters{
+		VCpusRange:        filters.VCpusRange,
+		AvailabilityZones: filters.AvailabilityZones,
+	})
+	h.Ok(t, err)
+	h.Assert(t, len(expected) > 0, "Should match at least 1 instance type without the price filter")
+	refreshed := []string{}
+	for _, it := range pricingMock.refreshedSpotInstanceTypes {
+		refreshed = append(refreshed, string(it))
+	}
+	sort.Strings(refreshed)
+	sort.Strings(expected)
+	h.Equals(t, expected, refreshed)
+	h.Equals(t, []string{"us-east-2a"}, pricingMock.refreshedSpotZones)
+
+	// zone ids are scoped by their zone names
+	filters.AvailabilityZones = &[]string{"use2-az2"}
+	err = itf.RefreshSpotCacheForFilters(ctx, filters, 30)
+	h.Ok(t, err)
+	h.Equals(t, []string{"us-east-2b"}, pricingMock.refreshedSpotZones)
+}
+
+func TestFilterGroups(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	ctx := context.Background()
+	c4Large, err := itf.Filter(ctx, selector.Filters{AllowList: regexp.MustCompile(`^c4\.large$`)})
+	h.Ok(t, err)
+	twoVCPUs, err := itf.Filter(ctx, selector.Filters{VCpusRange: &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2}})
+	h.Ok(t, err)
+	eightVCPUs, err := itf.Filter(ctx, selector.Filters{VCpusRange: &selector.Int32RangeFilter{LowerBound: 8, UpperBound: 8}})
+	h.Ok(t, err)
+
+	filterSet := selector.FilterSet{Groups: []selector.Filters{
+		{VCpusRange: &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2}},
+		{VCpusRange: &selector.Int32RangeFilter{LowerBound: 8, UpperBound: 8}},
+		{AllowList: regexp.MustCompile(`^c4\.large$`)},
+	}}
+	results, err := itf.FilterGroups(ctx, filterSet)
+	h.Ok(t, err)
+	expected := append(append([]string{}, twoVCPUs...), eightVCPUs...)
+	h.Assert(t, len(c4Large) == 1, "c4.large should be in the mock instance types")
+	h.Assert(t, len(results) == len(expected), "Should return the union of the groups without duplicates, expected %d but returned %d", len(expected), len(results))
+	h.Assert(t, sort.StringsAreSorted(results), "Should return instance types sorted by name")
+	for _, instanceType := range append(expected, c4Large...) {
+		h.Assert(t, slices.Contains(results, instanceType), "Should contain %s", instanceType)
+	}
+
+	filterSet.MaxResults = aws.Int(2)
+	results, err = itf.FilterGroups(ctx, filterSet)
+	h.Ok(t, err)
+	h.Equals(t, 2, len(results))
+
+	_, err = itf.FilterGroups(ctx, selector.FilterSet{})
+	h.Nok(t, err)
+}
+
+func TestFilter_GPUManufacturerNot(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"))
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, selector.Filters{GPUManufacturerNot: &selector.NotFilter{"nvidia"}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+
+	results, err = itf.Filter(ctx, selector.Filters{GPUManufacturerNot: &selector.NotFilter{"amd"}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p3.16xlarge", "t3.micro"}, results)
+
+	results, err = itf.Filter(ctx, selector.Filters{CPUManufacturerNot: &selector.NotFilter{"intel"}})
+	h.Ok(t, err)
+	h.Equals(t, []string{}, results)
+}
+
+func TestFilter_FPGAs(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "f1_2xlarge_and_16xlarge.json"))
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, selector.Filters{FpgasRange: &selector.Int32RangeFilter{LowerBound: 8, UpperBound: 8}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"f1.16xlarge"}, results)
+
+	results, err = itf.Filter(ctx, selector.Filters{FpgaMemoryRange: &selector.ByteQuantityRangeFilter{
+		LowerBound: bytequantity.FromGiB(64),
+		UpperBound: bytequantity.FromGiB(64),
+	}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"f1.2xlarge"}, results)
+
+	results, err = itf.Filter(ctx, selector.Filters{FPGAManufacturer: aws.String("xilinx")})
+	h.Ok(t, err)
+	h.Equals(t, []string{"f1.16xlarge", "f1.2xlarge"}, results)
+
+	results, err = itf.Filter(ctx, selector.Filters{FPGAManufacturer: aws.String("amd")})
+	h.Ok(t, err)
+	h.Equals(t, []string{}, results)
+}
+
+func TestFilter_NetworkCardsAndENAQueues(t *testing.T) {
+	ctx := context.Background()
+	itf := getSelector(setupMock(t, describeInstanceTypes, "p5_48xlarge.json"))
+	results, err := itf.Filter(ctx, selector.Filters{NetworkCards: &selector.Int32RangeFilter{LowerBound: 8, UpperBound: 64}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"p5.48xlarge"}, results)
+
+	// instance types which do not return the number of network cards have a single network card
+	itf = getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	results, err = itf.Filter(ctx, selector.Filters{NetworkCards: &selector.Int32RangeFilter{LowerBound: 1, UpperBound: 1}})
+	h.Ok(t, err)
+	h.Equals(t, 25, len(results))
+
+	results, err = itf.Filter(ctx, selector.Filters{ENAQueuesPerInterface: &selector.Int32RangeFilter{LowerBound: 8, UpperBound: 8}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"a1.2xlarge", "a1.4xlarge", "a1.metal", "c5.12xlarge", "c5.18xlarge", "c5.24xlarge", "c5.2xlarge", "c5.4xlarge", "c5.9xlarge"}, results)
+
+	// instance types without ENA support have no ENA queues
+	verboseResults, err := itf.FilterVerbose(ctx, selector.Filters{ENAQueues: &selector.Int32RangeFilter{LowerBound: 100, UpperBound: 200}})
+	h.Ok(t, err)
+	h.Equals(t, 2, len(verboseResults))
+	for _, result := range verboseResults {
+		h.Equals(t, int32(120), *result.EnaQueues)
+	}
+
+	// instance types with ENA support which are not in the ENA queues dataset are not excluded
+	itf = getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	verboseResults, err = itf.FilterVerbose(ctx, selector.Filters{ENAQueues: &selector.Int32RangeFilter{LowerBound: 100, UpperBound: 200}})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(verboseResults))
+	h.Assert(t, verboseResults[0].EnaQueues == nil, "The ENA queues of t3.micro should be unknown")
+}
+
+func TestFilter_FreeTier(t *testing.T) {
+	ctx := context.Background()
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	filters := selector.Filters{FreeTier: aws.Bool(true)}
+	itf := getSelector(ec2Mock)
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{}, results)
+
+	// the FreeTierEligible attribute described for the region is used as is
+	ec2Mock.DescribeInstanceTypesResp.InstanceTypes[0].FreeTierEligible = aws.Bool(true)
+	itf = getSelector(ec2Mock)
+	results, err = itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []string{"t3.micro"}, results)
+}
+
+func TestFilter_Tenancy(t *testing.T) {
+	ctx := context.Background()
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	results, err := itf.Filter(ctx, selector.Filters{Tenancy: &[]string{selector.TenancyDedicated, selector.TenancyHost}})
+	h.Ok(t, err)
+	h.Equals(t, 21, len(results))
+	h.Assert(t, !slices.Contains(results, "c1.medium"), "c1.medium does not support Dedicated Hosts")
+
+	ec2Mock := setupMock(t, describeInstanceTypes, "t3_micro.json")
+	ec2Mock.DescribeInstanceTypesResp.InstanceTypes[0].InstanceType = "t2.micro"
+	results, err = getSelector(ec2Mock).Filter(ctx, selector.Filters{Tenancy: &[]string{selector.TenancyDedicated}})
+	h.Ok(t, err)
+	h.Equals(t, []string{}, results)
+
+	// mac instance types can only be launched on Dedicated Hosts
+	ec2Mock.DescribeInstanceTypesResp.InstanceTypes[0].InstanceType = "mac2.metal"
+	ec2Mock.DescribeInstanceTypesResp.InstanceTypes[0].DedicatedHostsSupported = aws.Bool(true)
+	results, err = getSelector(ec2Mock).Filter(ctx, selector.Filters{Tenancy: &[]string{selector.TenancyShared}})
+	h.Ok(t, err)
+	h.Equals(t, []string{}, results)
+	results, err = getSelector(ec2Mock).Filter(ctx, selector.Filters{Tenancy: &[]string{selector.TenancyHost}})
+	h.Ok(t, err)
+	h.Equals(t, []string{"mac2.metal"}, results)
+}
+
+func TestFilter_Annotations(t *testing.T) {
+	ctx := context.Background()
+	itf := getSelector(setupMock(t, describeInstanceTypes, "g2_2xlarge.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostErr: errors.New("no on-demand price"),
+		onDemandCacheCount:             1,
+		spotCacheCount:                 1,
+	}
+	itf.Logger = log.New(io.Discard, "", 0)
+	results, err := itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, 1, len(results))
+	h.Equals(t, []string{
+		selector.AnnotationPreviousGeneration,
+		selector.AnnotationDeprecated + ": " + *results[0].Deprecation,
+		selector.AnnotationOnDemandPriceUnavailable,
+		selector.AnnotationSpotUnsupported,
+	}, results[0].Annotations)
+
+	itf = getSelector(setupMock(t, describeInstanceTypes, "t3_micro.json"))
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp:   0.0104,
+		GetSpotInstanceTypeNDayAvgCostErr: errors.New("no spot price"),
+		onDemandCacheCount:                1,
+		spotCacheCount:                    1,
+	}
+	itf.Logger = log.New(io.Discard, "", 0)
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, []string{selector.AnnotationSpotPriceUnavailable}, results[0].Annotations)
+
+	// discounted on-demand prices are annotated with the discount
+	itf.EC2Pricing, err = ec2pricing.NewDiscountedPricing(&ec2PricingMock{
+		GetOndemandInstanceTypeCostResp: 0.0104,
+		onDemandCacheCount:              1,
+	}, 27)
+	h.Ok(t, err)
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, []string{selector.AnnotationOnDemandPriceDiscounted + ": 27%"}, results[0].Annotations)
+
+	// spot prices which did not change over the days they are averaged over are annotated with the date of the last change
+	lastChange := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	itf.EC2Pricing = &spotPriceChangeTimerPricingMock{
+		ec2PricingMock: ec2PricingMock{GetSpotInstanceTypeNDayAvgCostResp: 0.0031, spotCacheCount: 1},
+		LastChange:     lastChange,
+	}
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Equals(t, []string{selector.AnnotationSpotPriceStale + ": unchanged since 2020-01-02"}, results[0].Annotations)
+	itf.EC2Pricing = &spotPriceChangeTimerPricingMock{
+		ec2PricingMock: ec2PricingMock{GetSpotInstanceTypeNDayAvgCostResp: 0.0031, spotCacheCount: 1},
+		LastChange:     time.Now().Add(-time.Hour),
+	}
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Assert(t, results[0].Annotations == nil, "A recently changed spot price should not be annotated; got %v", results[0].Annotations)
+
+	// instance types without advisory conditions are not annotated
+	itf.EC2Pricing = &ec2PricingMock{}
+	results, err = itf.FilterVerbose(ctx, selector.Filters{})
+	h.Ok(t, err)
+	h.Assert(t, results[0].Annotations == nil, "t3.micro should not be annotated; got %v", results[0].Annotations)
+}
+
+// spotPriceChangeTimerPricingMock is an ec2PricingMock which knows when the spot prices last changed.
+type spotPriceChangeTimerPricingMock struct {
+	ec2PricingMock
+	LastChange time.Time
+}
+
+func (p *spotPriceChangeTimerPricingMock) GetSpotInstanceTypeLastPriceChange(instanceType ec2types.InstanceType, availabilityZones []string) (time.Time, bool) {
+	return p.LastChange, !p.LastChange.IsZero()
+}
+
+// getBenchmarkSelector returns a selector of more instance types than a region offers, which are copies of the instance
+// types of 25_instances.json in 40 families of each, and the on-demand and spot prices of all of them.
+func getBenchmarkSelector(b *testing.B) selector.Selector {
+	ec2Mock := setupMock(b, describeInstanceTypes, "25_instances.json")
+	instanceTypes := []ec2types.InstanceTypeInfo{}
+	for i := 0; i < 40; i++ {
+		for _, instanceTypeInfo := range ec2Mock.DescribeInstanceTypesResp.InstanceTypes {
+			family, size, _ := strings.Cut(string(instanceTypeInfo.InstanceType), ".")
+			instanceTypeInfo.InstanceType = ec2types.InstanceType(fmt.Sprintf("%s%d.%s", family, i, size))
+			instanceTypes = append(instanceTypes, instanceTypeInfo)
+		}
+	}
+	ec2Mock.DescribeInstanceTypesResp.InstanceTypes = instanceTypes
+	itf := getSelector(ec2Mock)
+	itf.EC2Pricing = &ec2PricingMock{
+		GetOndemandInstanceTypeCostResp:    0.096,
+		onDemandCacheCount:                 len(instanceTypes),
+		GetSpotInstanceTypeNDayAvgCostResp: 0.031,
+		spotCacheCount:                     len(instanceTypes),
+	}
+	return itf
+}
+
+func BenchmarkFilter(b *testing.B) {
+	itf := getBenchmarkSelector(b)
+	filters := selector.Filters{
+		VCpusRange:   &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 16},
+		PricePerHour: &selector.Float64RangeFilter{LowerBound: 0, UpperBound: 1},
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := itf.Filter(ctx, filters)
+		h.Ok(b, err)
+	}
+}
+
+func BenchmarkFilterVerbose(b *testing.B) {
+	itf := getBenchmarkSelector(b)
+	cpuArchitecture := ec2types.ArchitectureTypeX8664
+	filters := selector.Filters{
+		VCpusRange:      &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 16},
+		CPUArchitecture: &cpuArchitecture,
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		instanceTypes, err := itf.FilterVerbose(ctx, filters)
+		h.Ok(b, err)
+		h.Assert(b, len(instanceTypes) > 0, "Should match instance types")
+	}
 }
@@ -14,6 +14,7 @@ package selector
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"dario.cat/mergo"
@@ -59,15 +60,47 @@ func (sr *ServiceRegistry) Register(name string, service Service) {
 // RegisterAWSServices registers the built-in AWS service filter transforms.
 func (sr *ServiceRegistry) RegisterAWSServices() {
 	sr.Register("emr", &EMR{})
+	sr.Register("sagemaker", &SageMaker{})
 }
 
 // ExecuteTransforms will execute the ServiceRegistry's registered service filter transforms
-// Filters.Service will be parsed as <service-name>-<version> and passed to Service.Filters.
+// Filters.Service will be parsed as a comma separated list of <service-name>-<version> and passed to Service.Filters.
+// When more than one service is passed, instance types must be supported by all of the services.
 func (sr *ServiceRegistry) ExecuteTransforms(filters Filters) (Filters, error) {
-	if filters.Service == nil || *filters.Service == "" || *filters.Service == "eks" {
+	if filters.Service == nil {
 		return filters, nil
 	}
-	serviceAndVersion := strings.ToLower(*filters.Service)
+	var combinedFilters *Filters
+	for _, serviceAndVersion := range strings.Split(strings.ToLower(*filters.Service), ",") {
+		serviceAndVersion = strings.TrimSpace(serviceAndVersion)
+		if serviceAndVersion == "" || serviceAndVersion == "eks" {
+			continue
+		}
+		serviceFilters, err := sr.executeTransform(serviceAndVersion)
+		if err != nil {
+			return filters, err
+		}
+		if combinedFilters == nil {
+			combinedFilters = &serviceFilters
+			continue
+		}
+		intersection, err := intersectServiceFilters(*combinedFilters, serviceFilters)
+		if err != nil {
+			return filters, err
+		}
+		combinedFilters = &intersection
+	}
+	if combinedFilters == nil {
+		return filters, nil
+	}
+	if err := mergo.Merge(&filters, *combinedFilters); err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
+
+// executeTransform parses serviceAndVersion as <service-name>-<version> and returns the filters of the service.
+func (sr *ServiceRegistry) executeTransform(serviceAndVersion string) (Filters, error) {
 	versionParts := strings.Split(serviceAndVersion, "-")
 	serviceName := versionParts[0]
 	version := ""
@@ -76,15 +109,52 @@ func (sr *ServiceRegistry) ExecuteTransforms(filters Filters) (Filters, error) {
 	}
 	service, ok := sr.services[serviceName]
 	if !ok {
-		return filters, fmt.Errorf("Service %s is not registered", serviceName)
+		return Filters{}, fmt.Errorf("Service %s is not registered", serviceName)
 	}
+	return (*service).Filters(version)
+}
 
-	serviceFilters, err := (*service).Filters(version)
-	if err != nil {
-		return filters, err
+// intersectServiceFilters combines the filters of two services so that instance types must match both.
+// The instance type lists are intersected and any other filter set by both services must be equal.
+func intersectServiceFilters(a Filters, b Filters) (Filters, error) {
+	intersection := a
+	if a.InstanceTypes != nil && b.InstanceTypes != nil {
+		bInstanceTypes := map[string]bool{}
+		for _, instanceType := range *b.InstanceTypes {
+			bInstanceTypes[instanceType] = true
+		}
+		instanceTypes := []string{}
+		for _, instanceType := range *a.InstanceTypes {
+			if bInstanceTypes[instanceType] {
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+		if len(instanceTypes) == 0 {
+			return Filters{}, fmt.Errorf("%w: the services do not support any of the same instance types", ErrInvalidFilterCombination)
+		}
+		intersection.InstanceTypes = &instanceTypes
+	} else if b.InstanceTypes != nil {
+		intersection.InstanceTypes = b.InstanceTypes
 	}
-	if err := mergo.Merge(&filters, serviceFilters); err != nil {
-		return filters, err
+
+	aValue := reflect.ValueOf(&intersection).Elem()
+	bValue := reflect.ValueOf(b)
+	for i := 0; i < aValue.NumField(); i++ {
+		fieldName := aValue.Type().Field(i).Name
+		if fieldName == "InstanceTypes" {
+			continue
+		}
+		aField, bField := aValue.Field(i), bValue.Field(i)
+		if bField.IsZero() {
+			continue
+		}
+		if aField.IsZero() {
+			aField.Set(bField)
+			continue
+		}
+		if !reflect.DeepEqual(aField.Interface(), bField.Interface()) {
+			return Filters{}, fmt.Errorf("%w: the services require different values of %s", ErrInvalidFilterCombination, fieldName)
+		}
 	}
-	return filters, nil
+	return intersection, nil
 }
@@ -13,9 +13,11 @@
 package selector_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
 	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
@@ -132,3 +134,66 @@ func TestExecuteTransforms_LongVersionWithExtraDash(t *testing.T) {
 	_, err := registry.ExecuteTransforms(filters)
 	h.Ok(t, err)
 }
+
+func TestExecuteTransforms_MultipleServices(t *testing.T) {
+	registry := selector.NewRegistry()
+	hvm := ec2types.VirtualizationTypeHvm
+	registry.Register("servicea", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.InstanceTypes = &[]string{"m5.large", "c5.large", "r5.large"}
+		filters.VirtualizationType = &hvm
+		return filters, nil
+	}))
+	registry.Register("serviceb", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.InstanceTypes = &[]string{"c5.large", "r5.large", "t3.large"}
+		filters.VirtualizationType = &hvm
+		filters.BareMetal = aws.Bool(false)
+		return filters, nil
+	}))
+
+	services := "servicea-1.0.0, serviceb,eks"
+	transformedFilters, err := registry.ExecuteTransforms(selector.Filters{Service: &services})
+	h.Ok(t, err)
+	h.Equals(t, []string{"c5.large", "r5.large"}, *transformedFilters.InstanceTypes)
+	h.Equals(t, hvm, *transformedFilters.VirtualizationType)
+	h.Equals(t, false, *transformedFilters.BareMetal)
+}
+
+func TestExecuteTransforms_MultipleServicesConflict(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.Register("servicea", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.InstanceTypes = &[]string{"m5.large"}
+		return filters, nil
+	}))
+	registry.Register("serviceb", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.InstanceTypes = &[]string{"c5.large"}
+		return filters, nil
+	}))
+	registry.Register("servicec", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.InstanceTypes = &[]string{"m5.large"}
+		filters.BareMetal = aws.Bool(true)
+		return filters, nil
+	}))
+	registry.Register("serviced", selector.ServiceFiltersFn(func(version string) (filters selector.Filters, err error) {
+		filters.BareMetal = aws.Bool(false)
+		return filters, nil
+	}))
+
+	for _, services := range []string{"servicea,serviceb", "servicec,serviced"} {
+		_, err := registry.ExecuteTransforms(selector.Filters{Service: &services})
+		h.Assert(t, errors.Is(err, selector.ErrInvalidFilterCombination), "%s should be an invalid filter combination but got %v", services, err)
+	}
+}
+
+func TestExecuteTransforms_EMRAndEKS(t *testing.T) {
+	registry := selector.NewRegistry()
+	registry.RegisterAWSServices()
+
+	emr := "emr-6.10.0"
+	emrFilters, err := registry.ExecuteTransforms(selector.Filters{Service: &emr})
+	h.Ok(t, err)
+	emrOnEKS := "emr-6.10.0,eks"
+	emrOnEKSFilters, err := registry.ExecuteTransforms(selector.Filters{Service: &emrOnEKS})
+	h.Ok(t, err)
+	h.Equals(t, *emrFilters.InstanceTypes, *emrOnEKSFilters.InstanceTypes)
+	h.Equals(t, *emrFilters.VirtualizationType, *emrOnEKSFilters.VirtualizationType)
+}
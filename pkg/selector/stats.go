@@ -0,0 +1,115 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// FilterStatistics are the number of instance types each of the active filters excluded.
+type FilterStatistics struct {
+	// Candidates is the number of instance types which were filtered
+	Candidates int
+	// Matches is the number of instance types which matched all of the filters
+	Matches int
+	// Filters are the statistics of the active filters sorted by Excluded, most first
+	Filters []FilterStatistic
+}
+
+// FilterStatistic is the number of instance types a single filter excluded.
+type FilterStatistic struct {
+	// Filter is the name of the field in the Filters struct
+	Filter string
+	// Flag is the CLI flag of the filter
+	Flag string
+	// Excluded is the number of instance types which matched all of the other filters but not this one
+	Excluded int
+	// ExcludedTotal is the number of instance types which this filter excluded regardless of the other filters
+	ExcludedTotal int
+}
+
+// FilterStats evaluates every filter against every instance type and returns how many instance types each of the active
+// filters excluded after the other filters, which shows the filters that are the most restrictive.
+func (s Selector) FilterStats(ctx context.Context, filters Filters) (FilterStatistics, error) {
+	evaluations, transformedFilters, err := s.evaluateFilters(ctx, filters)
+	if err != nil {
+		return FilterStatistics{}, err
+	}
+	stats := FilterStatistics{Candidates: len(evaluations)}
+	filterStats := map[string]*FilterStatistic{}
+	if len(evaluations) > 0 {
+		// the same filter pairs are evaluated for every instance type
+		for _, field := range activeFilters(transformedFilters, evaluations[0].pairs) {
+			filterStats[field] = &FilterStatistic{Filter: field, Flag: filterFlagName(field)}
+		}
+	}
+	for _, evaluation := range evaluations {
+		if len(evaluation.excludedBy) == 0 {
+			stats.Matches++
+		}
+		for _, field := range evaluation.excludedBy {
+			filterStat, ok := filterStats[field]
+			if !ok {
+				filterStat = &FilterStatistic{Filter: field, Flag: filterFlagName(field)}
+				filterStats[field] = filterStat
+			}
+			filterStat.ExcludedTotal++
+			if len(evaluation.excludedBy) == 1 {
+				filterStat.Excluded++
+			}
+		}
+	}
+	for _, filterStat := range filterStats {
+		stats.Filters = append(stats.Filters, *filterStat)
+	}
+	sort.Slice(stats.Filters, func(i, j int) bool {
+		if stats.Filters[i].Excluded != stats.Filters[j].Excluded {
+			return stats.Filters[i].Excluded > stats.Filters[j].Excluded
+		}
+		if stats.Filters[i].ExcludedTotal != stats.Filters[j].ExcludedTotal {
+			return stats.Filters[i].ExcludedTotal > stats.Filters[j].ExcludedTotal
+		}
+		return stats.Filters[i].Flag < stats.Filters[j].Flag
+	})
+	return stats, nil
+}
+
+// activeFilters returns the names of the Filters fields which instance types are filtered by, the aggregate filters
+// must already be transformed.
+func activeFilters(filters Filters, pairs map[string]filterPair) []string {
+	fields := []string{}
+	for filterName, pair := range pairs {
+		if !reflect.ValueOf(pair.filterValue).IsNil() {
+			fields = append(fields, filterFieldName(filters, pair.filterValue, filterName))
+		}
+	}
+	if filters.DenyList != nil {
+		fields = append(fields, "DenyList")
+	}
+	if filters.AllowList != nil {
+		fields = append(fields, "AllowList")
+	}
+	if filters.AvailabilityZones != nil {
+		fields = append(fields, "AvailabilityZones")
+	} else if filters.Region != nil {
+		fields = append(fields, "Region")
+	}
+	if aws.ToBool(filters.ExcludeDeprecated) {
+		fields = append(fields, "ExcludeDeprecated")
+	}
+	return fields
+}
@@ -0,0 +1,60 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestFilterStats(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	filters := selector.Filters{
+		MemoryRange: &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(192), UpperBound: bytequantity.ByteQuantity{Quantity: math.MaxUint64}},
+		VCpusRange:  &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+		DenyList:    regexp.MustCompile("^g"),
+	}
+	stats, err := itf.FilterStats(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, selector.FilterStatistics{
+		Candidates: 25,
+		Matches:    0,
+		Filters: []selector.FilterStatistic{
+			{Filter: "MemoryRange", Flag: "memory", Excluded: 8, ExcludedTotal: 24},
+			{Filter: "VCpusRange", Flag: "vcpus", Excluded: 1, ExcludedTotal: 17},
+			{Filter: "DenyList", Flag: "deny-list", Excluded: 0, ExcludedTotal: 0},
+		},
+	}, stats)
+}
+
+func TestFilterStats_Matches(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	filters := selector.Filters{
+		VCpusRange: &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2},
+	}
+	stats, err := itf.FilterStats(context.Background(), filters)
+	h.Ok(t, err)
+	results, err := itf.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, len(results), stats.Matches)
+	h.Equals(t, 1, len(stats.Filters))
+	h.Equals(t, stats.Candidates-stats.Matches, stats.Filters[0].Excluded)
+}
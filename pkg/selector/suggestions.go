@@ -0,0 +1,265 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// Suggestion is a single filter which can be relaxed so that instance types match.
+type Suggestion struct {
+	// Filter is the name of the field in the Filters struct
+	Filter string
+	// Flag is the CLI flag which relaxes the filter, i.e. memory-min for the lower bound of MemoryRange
+	Flag string
+	// From is the current bound of a range filter, it is empty when the filter is removed
+	From string `json:",omitempty"`
+	// To is the relaxed bound of a range filter, it is empty when the filter is removed
+	To string `json:",omitempty"`
+	// Matches is the number of instance types which match when only this filter is relaxed
+	Matches int
+}
+
+func (s Suggestion) String() string {
+	if s.To == "" {
+		return fmt.Sprintf("removing --%s would match %d instance types", s.Flag, s.Matches)
+	}
+	return fmt.Sprintf("relaxing --%s from %s to %s would match %d instance types", s.Flag, s.From, s.To, s.Matches)
+}
+
+// filterEvaluation is the result of evaluating every filter against an instance type rather than stopping at the first
+// filter which excludes it.
+type filterEvaluation struct {
+	instanceType instancetypes.Details
+	pairs        map[string]filterPair
+	// excludedBy are the names of the Filters fields which exclude the instance type sorted by name
+	excludedBy []string
+}
+
+// SuggestWiderCriteria suggests how to relax the filters when they are too narrow to match any instance types. Each suggestion
+// relaxes a single filter, range filters are relaxed to the closest bound which matches an instance type, and the suggestions
+// are sorted by the number of instance types they would match, most first.
+func (s Selector) SuggestWiderCriteria(ctx context.Context, filters Filters) ([]Suggestion, error) {
+	evaluations, transformedFilters, err := s.evaluateFilters(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	// only the instance types excluded by a single filter match when that filter is relaxed
+	excludedByOnly := map[string][]filterEvaluation{}
+	for _, evaluation := range evaluations {
+		if len(evaluation.excludedBy) == 1 {
+			excludedByOnly[evaluation.excludedBy[0]] = append(excludedByOnly[evaluation.excludedBy[0]], evaluation)
+		}
+	}
+	suggestions := []Suggestion{}
+	for field, excluded := range excludedByOnly {
+		// instance types which are not offered in the region can only be selected from another region
+		if field == "Region" {
+			continue
+		}
+		suggestions = append(suggestions, suggestRelaxing(transformedFilters, field, excluded)...)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Matches != suggestions[j].Matches {
+			return suggestions[i].Matches > suggestions[j].Matches
+		}
+		return suggestions[i].Flag < suggestions[j].Flag
+	})
+	return suggestions, nil
+}
+
+// evaluateFilters evaluates every filter against each of the instance types and returns the evaluations along with the
+// filters after the aggregate filters are transformed.
+func (s Selector) evaluateFilters(ctx context.Context, filters Filters) ([]filterEvaluation, Filters, error) {
+	pass, err := s.prepareFilterPass(ctx, filters)
+	if err != nil {
+		return nil, filters, err
+	}
+	evaluations := make([]filterEvaluation, len(pass.instanceTypeDetails))
+	var wg sync.WaitGroup
+	for i, instanceTypeInfo := range pass.instanceTypeDetails {
+		wg.Add(1)
+		go func(i int, instanceTypeInfo instancetypes.Details) {
+			defer wg.Done()
+			evaluations[i] = s.evaluateInstanceType(ctx, pass, instanceTypeInfo)
+		}(i, *instanceTypeInfo)
+	}
+	wg.Wait()
+	return evaluations, pass.filters, nil
+}
+
+// evaluateInstanceType returns the names of all of the Filters fields which exclude the instance type.
+func (s Selector) evaluateInstanceType(ctx context.Context, pass filterPass, instanceTypeInfo instancetypes.Details) filterEvaluation {
+	filters := pass.filters
+	instanceTypeInfo, pairs := s.prepareInstanceType(ctx, filters, instanceTypeInfo, pass.availabilityZones)
+	instanceTypeName := instanceTypeInfo.InstanceType
+	excludedBy := []string{}
+	if isInDenyList(filters.DenyList, instanceTypeName) {
+		excludedBy = append(excludedBy, "DenyList")
+	}
+	if !isInAllowList(filters.AllowList, instanceTypeName) {
+		excludedBy = append(excludedBy, "AllowList")
+	}
+	if !isSupportedInLocation(pass.locationInstanceOfferings, instanceTypeName) {
+		if filters.AvailabilityZones != nil {
+			excludedBy = append(excludedBy, "AvailabilityZones")
+		} else {
+			excludedBy = append(excludedBy, "Region")
+		}
+	}
+	if aws.ToBool(filters.ExcludeDeprecated) && instanceTypeInfo.Deprecation != nil {
+		excludedBy = append(excludedBy, "ExcludeDeprecated")
+	}
+	for filterName, pair := range pairs {
+		ok, err := exec(instanceTypeName, filterName, pair)
+		if err != nil {
+			s.Logger.Printf("Unable to evaluate filter for %s, %v", instanceTypeName, err)
+		}
+		if !ok {
+			excludedBy = append(excludedBy, filterFieldName(filters, pair.filterValue, filterName))
+		}
+	}
+	sort.Strings(excludedBy)
+	return filterEvaluation{instanceType: instanceTypeInfo, pairs: pairs, excludedBy: excludedBy}
+}
+
+// filterFieldName returns the name of the Filters field which holds filterValue, or the filter key if none of them do.
+func filterFieldName(filters Filters, filterValue interface{}, filterName string) string {
+	filtersValue := reflect.ValueOf(filters)
+	for i := 0; i < filtersValue.NumField(); i++ {
+		field := filtersValue.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() && field.Interface() == filterValue {
+			return filtersValue.Type().Field(i).Name
+		}
+	}
+	return filterName
+}
+
+// filterFlagName returns the CLI flag of the Filters field, or the field name if it does not have a flag.
+func filterFlagName(field string) string {
+	if structField, ok := reflect.TypeOf(Filters{}).FieldByName(field); ok {
+		if flag := structField.Tag.Get("flag"); flag != "" {
+			return flag
+		}
+	}
+	return field
+}
+
+// suggestRelaxing suggests relaxing the bounds of range filters to the closest instance types excluded by only that filter,
+// and removing all other filters.
+func suggestRelaxing(filters Filters, field string, excluded []filterEvaluation) []Suggestion {
+	flag := filterFlagName(field)
+	filterValue := reflect.ValueOf(filters).FieldByName(field).Interface()
+	lowerBound, upperBound, format, isRange := getRangeBounds(filterValue)
+	if !isRange {
+		return []Suggestion{{Filter: field, Flag: flag, Matches: len(excluded)}}
+	}
+	var below, above []float64
+	unrelaxable := 0
+	for _, evaluation := range excluded {
+		spec, ok := getRangeSpec(evaluation.pairs, filterValue)
+		switch {
+		case !ok:
+			unrelaxable++
+		case spec < lowerBound:
+			below = append(below, spec)
+		case spec > upperBound:
+			above = append(above, spec)
+		}
+	}
+	suggestions := []Suggestion{}
+	if len(below) > 0 {
+		sort.Float64s(below)
+		relaxedBound := below[len(below)-1]
+		suggestions = append(suggestions, Suggestion{Filter: field, Flag: flag + "-min", From: format(lowerBound), To: format(relaxedBound), Matches: countEqual(below, relaxedBound)})
+	}
+	if len(above) > 0 {
+		sort.Float64s(above)
+		relaxedBound := above[0]
+		suggestions = append(suggestions, Suggestion{Filter: field, Flag: flag + "-max", From: format(upperBound), To: format(relaxedBound), Matches: countEqual(above, relaxedBound)})
+	}
+	if unrelaxable > 0 {
+		suggestions = append(suggestions, Suggestion{Filter: field, Flag: flag, Matches: len(excluded)})
+	}
+	return suggestions
+}
+
+// getRangeBounds returns the bounds of range filters as float64s along with a function which formats them in the units
+// they are passed in with, isRange is false for filters which are not ranges.
+func getRangeBounds(filterValue interface{}) (lowerBound float64, upperBound float64, format func(float64) string, isRange bool) {
+	formatNumber := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	switch filter := filterValue.(type) {
+	case *IntRangeFilter:
+		return float64(filter.LowerBound), float64(filter.UpperBound), formatNumber, true
+	case *Int32RangeFilter:
+		return float64(filter.LowerBound), float64(filter.UpperBound), formatNumber, true
+	case *Float64RangeFilter:
+		return filter.LowerBound, filter.UpperBound, formatNumber, true
+	case *ByteQuantityRangeFilter:
+		formatGiB := func(v float64) string {
+			return formatNumber(bytequantity.ByteQuantity{Quantity: uint64(v)}.GiB()) + " GiB"
+		}
+		return float64(filter.LowerBound.Quantity), float64(filter.UpperBound.Quantity), formatGiB, true
+	}
+	return 0, 0, nil, false
+}
+
+// getRangeSpec returns the instance spec the range filter is compared against as a float64, ok is false when the instance
+// type does not have one.
+func getRangeSpec(pairs map[string]filterPair, filterValue interface{}) (spec float64, ok bool) {
+	for _, pair := range pairs {
+		if pair.filterValue != filterValue {
+			continue
+		}
+		switch instanceSpec := pair.instanceSpec.(type) {
+		case *int:
+			if instanceSpec != nil {
+				return float64(*instanceSpec), true
+			}
+		case *int32:
+			if instanceSpec != nil {
+				return float64(*instanceSpec), true
+			}
+		case *int64:
+			if instanceSpec != nil {
+				return float64(*instanceSpec), true
+			}
+		case *float64:
+			if instanceSpec != nil {
+				return *instanceSpec, true
+			}
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+func countEqual(values []float64, value float64) int {
+	count := 0
+	for _, v := range values {
+		if v == value {
+			count++
+		}
+	}
+	return count
+}
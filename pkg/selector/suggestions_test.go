@@ -0,0 +1,70 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestSuggestWiderCriteria_Ranges(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	filters := selector.Filters{
+		MemoryRange: &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(192), UpperBound: bytequantity.ByteQuantity{Quantity: math.MaxUint64}},
+		VCpusRange:  &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+	}
+	ctx := context.Background()
+	results, err := itf.Filter(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, 0, len(results))
+
+	suggestions, err := itf.SuggestWiderCriteria(ctx, filters)
+	h.Ok(t, err)
+	h.Equals(t, []selector.Suggestion{
+		{Filter: "MemoryRange", Flag: "memory-min", From: "192 GiB", To: "8 GiB", Matches: 1},
+		{Filter: "VCpusRange", Flag: "vcpus-max", From: "4", To: "96", Matches: 1},
+	}, suggestions)
+	h.Equals(t, "relaxing --memory-min from 192 GiB to 8 GiB would match 1 instance types", suggestions[0].String())
+}
+
+func TestSuggestWiderCriteria_Remove(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	arm64 := ec2types.ArchitectureTypeArm64
+	filters := selector.Filters{
+		CPUArchitecture: &arm64,
+		MemoryRange:     &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(64), UpperBound: bytequantity.ByteQuantity{Quantity: math.MaxUint64}},
+	}
+	suggestions, err := itf.SuggestWiderCriteria(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, []selector.Suggestion{
+		{Filter: "CPUArchitecture", Flag: "cpu-architecture", Matches: 4},
+		{Filter: "MemoryRange", Flag: "memory-min", From: "64 GiB", To: "32 GiB", Matches: 2},
+	}, suggestions)
+	h.Equals(t, "removing --cpu-architecture would match 4 instance types", suggestions[0].String())
+}
+
+func TestSuggestWiderCriteria_InvalidFilters(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "25_instances.json"))
+	version := "5"
+	_, err := itf.SuggestWiderCriteria(context.Background(), selector.Filters{LaunchTemplateVersion: &version})
+	h.Nok(t, err)
+}
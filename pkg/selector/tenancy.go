@@ -0,0 +1,61 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// DescribeInstanceTypes only returns whether an instance type supports Dedicated Hosts, so shared and dedicated tenancy
+// support is derived from the instance family. Mac instances can only be launched on Dedicated Hosts and T2 instances
+// can not be launched as Dedicated Instances, see https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/dedicated-instance.html
+var (
+	// hostOnlyFamilyPrefixes are the prefixes of the families which only support host tenancy
+	hostOnlyFamilyPrefixes = []string{"mac"}
+	// sharedOnlyFamilies are the families which do not support dedicated tenancy
+	sharedOnlyFamilies = []string{"t1", "t2"}
+)
+
+// tenancySupport is the instance spec of the Tenancy filter, the tenancies the instance type can be launched with.
+type tenancySupport []string
+
+// getTenancySupport returns the tenancies (shared, dedicated, host) instances of the instance type can be launched with.
+func getTenancySupport(instanceTypeInfo *ec2types.InstanceTypeInfo) tenancySupport {
+	family, _, _ := strings.Cut(string(instanceTypeInfo.InstanceType), ".")
+	tenancies := tenancySupport{}
+	isHostOnly := slices.ContainsFunc(hostOnlyFamilyPrefixes, func(prefix string) bool { return strings.HasPrefix(family, prefix) })
+	if !isHostOnly {
+		tenancies = append(tenancies, TenancyShared)
+		if !slices.Contains(sharedOnlyFamilies, family) {
+			tenancies = append(tenancies, TenancyDedicated)
+		}
+	}
+	if aws.ToBool(instanceTypeInfo.DedicatedHostsSupported) {
+		tenancies = append(tenancies, TenancyHost)
+	}
+	return tenancies
+}
+
+// isSupportedTenancies returns true if the instance type supports all of the target tenancies.
+func isSupportedTenancies(instanceTypeValue tenancySupport, target []string) bool {
+	for _, tenancy := range target {
+		if !slices.Contains(instanceTypeValue, tenancy) {
+			return false
+		}
+	}
+	return true
+}
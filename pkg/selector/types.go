@@ -15,6 +15,7 @@ package selector
 import (
 	"encoding/json"
 	"log"
+	"reflect"
 	"regexp"
 
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -23,6 +24,7 @@ import (
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/offerings"
 )
 
 // InstanceTypesOutput can be implemented to provide custom output to instance type results.
@@ -44,45 +46,63 @@ type Selector struct {
 	EC2                   awsapi.SelectorInterface
 	EC2Pricing            ec2pricing.EC2PricingIface
 	InstanceTypesProvider *instancetypes.Provider
-	ServiceRegistry       ServiceRegistry
-	Logger                *log.Logger
+	// CustomInstanceTypesProvider retrieves the instance types instead of InstanceTypesProvider when it is not nil, such as
+	// the provider passed to WithInstanceTypesProvider
+	CustomInstanceTypesProvider instancetypes.ProviderIface
+	// OfferingsProvider retrieves the instance types offered in locations, offerings are not cached if it is nil
+	OfferingsProvider offerings.ProviderIface
+	ServiceRegistry   ServiceRegistry
+	Logger            *log.Logger
+	// CarbonData overrides the built-in carbon score estimates of instance types
+	CarbonData map[ec2types.InstanceType]float64
+	// Deprecations are instance type names or families keyed to the reason they are deprecated, they are applied on top of
+	// the built-in previous generation families and an empty reason removes a built-in deprecation
+	Deprecations map[string]string
 }
 
 // IntRangeFilter holds an upper and lower bound int
 // The lower and upper bound are used to range filter resource specs.
 type IntRangeFilter struct {
-	UpperBound int
-	LowerBound int
+	UpperBound int `yaml:"UpperBound"`
+	LowerBound int `yaml:"LowerBound"`
 }
 
 // Int32RangeFilter holds an upper and lower bound int
 // The lower and upper bound are used to range filter resource specs.
 type Int32RangeFilter struct {
-	UpperBound int32
-	LowerBound int32
+	UpperBound int32 `yaml:"UpperBound"`
+	LowerBound int32 `yaml:"LowerBound"`
 }
 
 // Uint64RangeFilter holds an upper and lower bound uint64
 // The lower and upper bound are used to range filter resource specs.
 type Uint64RangeFilter struct {
-	UpperBound uint64
-	LowerBound uint64
+	UpperBound uint64 `yaml:"UpperBound"`
+	LowerBound uint64 `yaml:"LowerBound"`
 }
 
 // ByteQuantityRangeFilter holds an upper and lower bound byte quantity
 // The lower and upper bound are used to range filter resource specs.
 type ByteQuantityRangeFilter struct {
-	UpperBound bytequantity.ByteQuantity
-	LowerBound bytequantity.ByteQuantity
+	UpperBound bytequantity.ByteQuantity `yaml:"UpperBound"`
+	LowerBound bytequantity.ByteQuantity `yaml:"LowerBound"`
 }
 
 // Float64RangeFilter holds an upper and lower bound float64
 // The lower and upper bound are used to range filter resource specs.
 type Float64RangeFilter struct {
-	UpperBound float64
-	LowerBound float64
+	UpperBound float64 `yaml:"UpperBound"`
+	LowerBound float64 `yaml:"LowerBound"`
 }
 
+// Int32SetFilter holds the exact values an instance type spec must be equal to one of.
+// It expresses sizing policies like powers of two which a contiguous Int32RangeFilter can not.
+type Int32SetFilter []int32
+
+// NotFilter holds values which an instance type spec must not be equal to, ignoring case.
+// Instance types without the spec (i.e. no GPUs when excluding a GPU manufacturer) are not excluded.
+type NotFilter []string
+
 // filterPair holds a tuple of the passed in filter value and the instance resource spec value.
 type filterPair struct {
 	filterValue  interface{}
@@ -111,175 +131,303 @@ func (f *Filters) MarshalIndent(prefix, indent string) ([]byte, error) {
 	}, prefix, indent)
 }
 
+// Merge returns a copy of the filters where each filter that is not set is taken from defaults.
+func (f Filters) Merge(defaults Filters) Filters {
+	merged := f
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	defaultsValue := reflect.ValueOf(defaults)
+	for i := 0; i < mergedValue.NumField(); i++ {
+		if mergedValue.Field(i).IsNil() {
+			mergedValue.Field(i).Set(defaultsValue.Field(i))
+		}
+	}
+	return merged
+}
+
+// FilterSet selects the instance types matching any of its groups of filters.
+// Each group is a conjunction of filters so a FilterSet can express policies such as
+// "(at least 1 GPU and 64 GiB of memory) or the inf2 family" which a single Filters struct can not.
+type FilterSet struct {
+	// Groups are the Filters of which an instance type must match at least one
+	Groups []Filters
+
+	// MaxResults limits the number of instance types returned across all groups
+	MaxResults *int
+}
+
 // Filters is used to group instance type resource attributes for filtering.
 type Filters struct {
 	// AvailabilityZones is the AWS Availability Zones where instances will be provisioned.
 	// Instance type capacity can vary between availability zones.
-	// Will accept zone names or ids
-	// Example: us-east-1a, us-east-1b, us-east-2a, etc. OR use1-az1, use2-az2, etc.
-	AvailabilityZones *[]string
+	// Will accept zone names or ids, including Local Zones and Wavelength Zones, or Outpost ARNs
+	// Example: us-east-1a, us-east-1b, us-east-2a, etc. OR use1-az1, use2-az2, etc. OR us-west-2-lax-1a OR arn:aws:outposts:us-west-2:123456789012:outpost/op-0123456789abcdef0
+	AvailabilityZones *[]string `flag:"availability-zones" short:"z" description:"Availability zones, zone ids, Local Zones, Wavelength Zones, or Outpost ARNs to check EC2 capacity offered in specific locations (table-wide, interactive, and verbose outputs show both the zone names and ids)"`
+
+	// LocationType is the type of the locations in AvailabilityZones, it is detected from each location if not set
+	LocationType *ec2types.LocationType `flag:"location-type" description:"Location type of the availability zones: [region, availability-zone, availability-zone-id, or outpost] (detected from each location by default)" options:"region,availability-zone,availability-zone-id,outpost"`
 
 	// BareMetal is used to only return bare metal instance type results
-	BareMetal *bool
+	BareMetal *bool `flag:"baremetal" description:"Bare Metal instance types (.metal instances)"`
 
 	// Burstable is used to only return burstable instance type results like the t* series
-	Burstable *bool
+	Burstable *bool `flag:"burst-support" short:"b" description:"Burstable instance types"`
 
 	// AutoRecovery is used to filter by instance types that support auto recovery
-	AutoRecovery *bool
+	AutoRecovery *bool `flag:"auto-recovery" description:"EC2 Auto-Recovery supported"`
 
-	// FreeTier is used to filter by instance types that can be used as part of the EC2 free tier
-	FreeTier *bool
+	// FreeTier is used to filter by instance types that can be used as part of the EC2 free tier in the region, as described
+	// by the FreeTierEligible attribute of DescribeInstanceTypes
+	FreeTier *bool `flag:"free-tier" description:"Free Tier eligible in the region (i.e. t2.micro, or t3.micro in regions without t2 instance types)"`
 
 	// CPUArchitecture of the EC2 instance type
-	CPUArchitecture *ec2types.ArchitectureType
+	CPUArchitecture *ec2types.ArchitectureType `flag:"cpu-architecture" short:"a" description:"CPU architecture [x86_64, amd64, x86_64_mac, i386, arm64, or auto for the architecture of this machine]" options:"x86_64,x86_64_mac,amd64,i386,arm64,auto"`
 
 	// CPUManufacturer is used to filter instance types with a specific CPU manufacturer
-	CPUManufacturer *CPUManufacturer
+	CPUManufacturer *CPUManufacturer `flag:"cpu-manufacturer" description:"CPU manufacturer [amd, intel, aws]" options:"amd,intel,aws"`
+
+	// CPUManufacturerNot excludes instance types with any of the CPU manufacturers
+	CPUManufacturerNot *NotFilter `flag:"cpu-manufacturer-not" description:"CPU manufacturers to exclude [amd, intel, aws]"`
 
 	// CurrentGeneration returns the latest generation of instance types
-	CurrentGeneration *bool
+	CurrentGeneration *bool `flag:"current-generation" description:"Current generation instance types (explicitly set this to false to not return current generation instance types)"`
 
 	// EnaSupport returns instances that can support an Elastic Network Adapter.
-	EnaSupport *bool
+	EnaSupport *bool `flag:"ena-support" short:"e" description:"Instance types where ENA is supported or required"`
 
 	// EfaSupport returns instances that can support an Elastic Fabric Adapter.
-	EfaSupport *bool
+	EfaSupport *bool `flag:"efa-support" description:"Instance types that support Elastic Fabric Adapters (EFA)"`
 
 	// FPGA is used to only return FPGA instance type results
-	Fpga *bool
+	Fpga *bool `flag:"fpga-support" short:"f" description:"FPGA instance types"`
+
+	// FpgasRange filter is a range of acceptable FPGA count available to an EC2 instance type
+	FpgasRange *Int32RangeFilter `flag:"fpgas" description:"Total Number of FPGAs (Example: 1)"`
+
+	// FpgaMemoryRange filter is a range of acceptable FPGA memory in Gibibytes (GiB) available to an EC2 instance type in aggregate across all FPGAs.
+	FpgaMemoryRange *ByteQuantityRangeFilter `flag:"fpga-memory-total" description:"Number of FPGAs' total memory (Example: 64 GiB)" units:"GiB"`
+
+	// FPGAManufacturer filters by FPGA manufacturer
+	FPGAManufacturer *string `flag:"fpga-manufacturer" description:"FPGA Manufacturer name (Example: Xilinx)"`
 
 	// GpusRange filter is a range of acceptable GPU count available to an EC2 instance type
-	GpusRange *Int32RangeFilter
+	GpusRange *Int32RangeFilter `flag:"gpus" short:"g" description:"Total Number of GPUs (Example: 4)"`
+
+	// GpusList filter is a set of the exact GPU counts the instance type may have
+	GpusList *Int32SetFilter `flag:"gpus-list" description:"Exact numbers of GPUs (Example: 1,2,4,8)"`
 
 	// GpuMemoryRange filter is a range of acceptable GPU memory in Gibibytes (GiB) available to an EC2 instance type in aggreagte across all GPUs.
-	GpuMemoryRange *ByteQuantityRangeFilter
+	GpuMemoryRange *ByteQuantityRangeFilter `flag:"gpu-memory-total" description:"Number of GPUs' total memory (Example: 4 GiB)" units:"GiB"`
 
 	// GPUManufacturer filters by GPU manufacturer
-	GPUManufacturer *string
+	GPUManufacturer *string `flag:"gpu-manufacturer" description:"GPU Manufacturer name (Example: NVIDIA)"`
+
+	// GPUManufacturerNot excludes instance types with GPUs from any of the manufacturers
+	GPUManufacturerNot *NotFilter `flag:"gpu-manufacturer-not" description:"GPU Manufacturer names to exclude (Example: NVIDIA)"`
 
 	// GPUModel filter by the GPU model name
-	GPUModel *string
+	GPUModel *string `flag:"gpu-model" description:"GPU Model name (Example: K520)"`
+
+	// GPUModelNot excludes instance types with any of the GPU models
+	GPUModelNot *NotFilter `flag:"gpu-model-not" description:"GPU Model names to exclude (Example: K520)"`
 
 	// InferenceAcceleratorsRange filters inference accelerators available to the instance type
-	InferenceAcceleratorsRange *IntRangeFilter
+	InferenceAcceleratorsRange *IntRangeFilter `flag:"inference-accelerators" description:"Total Number of inference accelerators (Example: 4)"`
 
 	// InferenceAcceleratorManufacturer filters by inference acceleartor manufacturer
-	InferenceAcceleratorManufacturer *string
+	InferenceAcceleratorManufacturer *string `flag:"inference-accelerator-manufacturer" description:"Inference Accelerator Manufacturer name (Example: AWS)"`
+
+	// InferenceAcceleratorManufacturerNot excludes instance types with inference accelerators from any of the manufacturers
+	InferenceAcceleratorManufacturerNot *NotFilter `flag:"inference-accelerator-manufacturer-not" description:"Inference Accelerator Manufacturer names to exclude (Example: AWS)"`
 
 	// InferenceAcceleratorModel filters by inference accelerator model name
-	InferenceAcceleratorModel *string
+	InferenceAcceleratorModel *string `flag:"inference-accelerator-model" description:"Inference Accelerator Model name (Example: Inferentia)"`
+
+	// InferenceAcceleratorModelNot excludes instance types with any of the inference accelerator models
+	InferenceAcceleratorModelNot *NotFilter `flag:"inference-accelerator-model-not" description:"Inference Accelerator Model names to exclude (Example: Inferentia)"`
 
 	// HibernationSupported denotes whether EC2 hibernate is supported
 	// Possible values are: true or false
-	HibernationSupported *bool
+	HibernationSupported *bool `flag:"hibernation-support" description:"Hibernation supported, instance types must also have less than 150 GiB of memory to hibernate"`
+
+	// HibernationStrict requires hibernation support, EBS root volumes and EBS encryption which hibernation saves the RAM to
+	HibernationStrict *bool `flag:"hibernation-strict" description:"Hibernation supported with the strict prerequisites: less than 150 GiB of memory, EBS root volumes and EBS encryption"`
 
 	// Hypervisor is used to return only a specific hypervisor backed instance type
 	// Possibly values are: xen or nitro
-	Hypervisor *ec2types.InstanceTypeHypervisor
+	Hypervisor *ec2types.InstanceTypeHypervisor `flag:"hypervisor" description:"Hypervisor: [xen or nitro]" options:"xen,nitro"`
 
 	// MaxResults is the maximum number of instance types to return that match the filter criteria
-	MaxResults *int
+	MaxResults *int `description:"Maximum number of instance types to return"`
 
 	// MemoryRange filter is a range of acceptable DRAM memory in Gibibytes (GiB) for the instance type
-	MemoryRange *ByteQuantityRangeFilter
+	MemoryRange *ByteQuantityRangeFilter `flag:"memory" short:"m" description:"Amount of Memory available (Example: 4 GiB)" units:"GiB"`
 
 	// NetworkInterfaces filter is a range of the number of ENI attachments an instance type can support
-	NetworkInterfaces *Int32RangeFilter
+	NetworkInterfaces *Int32RangeFilter `flag:"network-interfaces" description:"Number of network interfaces (ENIs) that can be attached to the instance"`
+
+	// NetworkCards filter is a range of the number of network cards an instance type has, each network card has its own
+	// network bandwidth and ENI attachments
+	NetworkCards *Int32RangeFilter `flag:"network-cards" description:"Number of network cards the instance type has"`
+
+	// ENAQueuesPerInterface filter is a range of the maximum number of ENA queues of each network interface of an instance type
+	ENAQueuesPerInterface *Int32RangeFilter `flag:"ena-queues-per-interface" description:"Maximum number of ENA queues per network interface, from a bundled dataset (Example: 8)"`
+
+	// ENAQueues filter is a range of the maximum number of ENA queues across all of the network interfaces of an instance type
+	ENAQueues *Int32RangeFilter `flag:"ena-queues" description:"Maximum number of ENA queues across all network interfaces, from a bundled dataset (Example: 64)"`
 
 	// NetworkPerformance filter is a range of network bandwidth an instance type can support
-	NetworkPerformance *IntRangeFilter
+	NetworkPerformance *IntRangeFilter `flag:"network-performance" description:"Bandwidth in Gib/s of network performance (Example: 100)" units:"Gbps"`
 
 	// NetworkEncryption filters for instance types that automatically encrypt network traffic in-transit
-	NetworkEncryption *bool
+	NetworkEncryption *bool `flag:"network-encryption" description:"Instance Types that support automatic network encryption in-transit"`
 
 	// IPv6 filters for instance types that support IPv6
-	IPv6 *bool
+	IPv6 *bool `flag:"ipv6" description:"Instance Types that support IPv6"`
 
 	// PlacementGroupStrategy is used to return instance types based on its support
 	// for a specific placement group strategy
 	// Possible values are: cluster, spread, or partition
-	PlacementGroupStrategy *string
+	PlacementGroupStrategy *string `flag:"placement-group-strategy" description:"Placement group strategy: [cluster, partition, spread]" options:"cluster,partition,spread"`
+
+	// PlacementGroupStrategies is used to return instance types based on their support for all, or any if
+	// PlacementGroupStrategyMatch is any, of the placement group strategies, along with PlacementGroupStrategy
+	// Possible values are: cluster, spread, or partition
+	PlacementGroupStrategies *[]string `flag:"placement-group-strategies" description:"Placement group strategies which must all be supported, or any of them with --placement-group-strategy-match any: [cluster, partition, spread] (Example: cluster,partition)" options:"cluster,partition,spread"`
+
+	// PlacementGroupStrategyMatch is whether instance types must support all (the default) or any of the PlacementGroupStrategy
+	// and PlacementGroupStrategies strategies
+	// Possible values are: all or any
+	PlacementGroupStrategyMatch *string `flag:"placement-group-strategy-match" description:"Whether all or any of the --placement-group-strategies strategies must be supported: [all or any] (defaults to all)" options:"all,any"`
 
 	// Region is the AWS Region where instances will be provisioned.
 	// Instance type availability can vary between AWS Regions.
 	// Example: us-east-1, us-east-2, eu-west-1, etc.
-	Region *string
+	Region *string `description:"AWS Region to select instance types from"`
 
 	// RootDeviceType is the backing device of the root storage volume
 	// Possible values are: instance-store or ebs
-	RootDeviceType *ec2types.RootDeviceType
+	RootDeviceType *ec2types.RootDeviceType `flag:"root-device-type" description:"Supported root device types: [ebs or instance-store]" options:"ebs,instance-store"`
 
 	// UsageClass of the instance EC2 instance type
 	// Possible values are: spot or on-demand
-	UsageClass *ec2types.UsageClassType
+	UsageClass *ec2types.UsageClassType `flag:"usage-class" short:"u" description:"Usage class: [spot, on-demand, or capacity-block]" options:"spot,on-demand,capacity-block"`
 
 	// VCpusRange filter is a range of acceptable VCpus for the instance type
-	VCpusRange *Int32RangeFilter
+	VCpusRange *Int32RangeFilter `flag:"vcpus" short:"c" description:"Number of vcpus available to the instance type."`
+
+	// VCpusList filter is a set of the exact numbers of VCpus the instance type may have
+	VCpusList *Int32SetFilter `flag:"vcpus-list" description:"Exact numbers of vcpus available to the instance type (Example: 2,4,8)"`
 
 	// VcpusToMemoryRatio is a ratio of vcpus to memory expressed as a floating point
-	VCpusToMemoryRatio *float64
+	VCpusToMemoryRatio *float64 `flag:"vcpus-to-memory-ratio" flagKind:"ratio" description:"The ratio of vcpus to GiBs of memory. (Example: 1:2)" units:"GiB per vCPU"`
 
 	// AllowList is a regex of allowed instance types
-	AllowList *regexp.Regexp
+	// NOTE that CompileInstanceTypePattern compiles globs like m5*.large to an anchored regex
+	AllowList *regexp.Regexp `flag:"allow-list" description:"List of allowed instance types to select from w/ regex syntax or a glob which must match the whole name (Example: m[3-5]\\.* or m5*.large)"`
 
 	// DenyList is a regex of excluded instance types
-	DenyList *regexp.Regexp
+	// NOTE that CompileInstanceTypePattern compiles globs like c?.xlarge to an anchored regex
+	DenyList *regexp.Regexp `flag:"deny-list" description:"List of instance types which should be excluded w/ regex syntax or a glob which must match the whole name (Example: m[1-2]\\.* or c?.xlarge)"`
 
 	// InstanceTypeBase is a base instance type which is used to retrieve similarly spec'd instance types
-	InstanceTypeBase *string
+	InstanceTypeBase *string `flag:"base-instance-type" flagSet:"suite" description:"Instance Type used to retrieve similarly spec'd instance types"`
+
+	// LaunchTemplateID is a launch template whose AMI, network interfaces, and placement are used
+	// to retrieve compatible instance types
+	LaunchTemplateID *string `description:"Launch template whose AMI, network interfaces, and placement instance types must be compatible with"`
+
+	// LaunchTemplateVersion is the version of the LaunchTemplateID to use
+	// Example: 5, $Latest, or $Default (the default if not specified)
+	LaunchTemplateVersion *string `description:"Version of the launch template (Example: 5, $Latest, or $Default)"`
 
 	// Flexible finds an opinionated set of general (c, m, r, t, a, etc.) instance types that match a criteria specified
 	// or defaults to 4 vcpus
-	Flexible *bool
+	Flexible *bool `flag:"flexible" flagSet:"suite" description:"Retrieves a group of instance types spanning multiple generations based on opinionated defaults and user overridden resource filters"`
 
 	// Service filters instance types based on a service's supported list of instance types
-	// Example: eks or emr
-	Service *string
+	// Example: eks, emr, sagemaker-training or emr-6.10.0,eks
+	Service *string `flag:"service" flagSet:"suite" description:"Filter instance types based on service support, separate multiple services with commas to require support by all of them (Example: emr-5.20.0 or emr-6.10.0,eks)"`
 
 	// InstanceTypes filters instance types and only allows instance types in this slice
-	InstanceTypes *[]string
+	InstanceTypes *[]string `description:"Instance type names to select from"`
 
 	// VirtualizationType is used to return instance types that match either hvm or pv virtualization types
-	VirtualizationType *ec2types.VirtualizationType
+	VirtualizationType *ec2types.VirtualizationType `flag:"virtualization-type" description:"Virtualization Type supported: [hvm or pv]" options:"hvm,paravirtual,pv"`
 
 	// PricePerHour is used to return instance types that are equal to or cheaper than the specified price
-	PricePerHour *Float64RangeFilter
+	PricePerHour *Float64RangeFilter `flag:"price-per-hour" description:"Price/hour in USD (Example: 0.09)" units:"USD"`
+
+	// PricePerMonth is transformed into a PricePerHour range using HoursPerMonth
+	PricePerMonth *Float64RangeFilter `flag:"price-per-month" description:"Price/month in USD derived from the price/hour and --hours-per-month (Example: 70)" units:"USD"`
+
+	// PricePerYear is transformed into a PricePerHour range using 12 times HoursPerMonth
+	PricePerYear *Float64RangeFilter `flag:"price-per-year" description:"Price/year in USD derived from the price/hour and 12 times --hours-per-month (Example: 800)" units:"USD"`
+
+	// HoursPerMonth is the number of hours an instance runs each month which monthly and annual prices are derived with
+	// DefaultHoursPerMonth is used when it is not set
+	HoursPerMonth *float64 `flag:"hours-per-month" description:"Hours an instance runs each month which monthly and annual prices are derived with (Default: 730)" units:"hours"`
+
+	// SpotSavings filters on a range of the percentage the spot price saves compared to the on-demand price
+	SpotSavings *Float64RangeFilter `flag:"spot-savings" description:"Percentage the 30 day average spot price saves compared to the on-demand price (Example: 60)" units:"percent"`
+
+	// BaselineCPURange filters on a range of baseline CPU utilization percentage per vCPU
+	// Instance types which are not burstable have a baseline of 100
+	BaselineCPURange *Float64RangeFilter `flag:"baseline-cpu" description:"Baseline CPU utilization percentage per vCPU, instance types that are not burstable have a baseline of 100 (Example: 40)" units:"percent"`
 
 	// InstanceStorageRange filters on a range of storage available as local disk
-	InstanceStorageRange *ByteQuantityRangeFilter
+	InstanceStorageRange *ByteQuantityRangeFilter `flag:"instance-storage" description:"Amount of local instance storage (Example: 4 GiB)" units:"GiB"`
 
 	// DiskType is the backing storage medium
 	// Possible values are: hdd or ssd
-	DiskType *string
+	DiskType *string `flag:"disk-type" description:"Disk Type: [hdd or ssd]" options:"hdd,ssd"`
 
 	// NVME filters for NVME disks, including both EBS and local instance storage
-	NVME *bool
+	NVME *bool `flag:"nvme" description:"EBS or local instance storage where NVME is supported or required"`
 
 	// EBSOptimized filters for instance types that support EBS Optimized
-	EBSOptimized *bool
+	EBSOptimized *bool `flag:"ebs-optimized" description:"EBS Optimized is supported or default"`
+
+	// EBSOptimizedSupport filters on whether EBS optimization is on by default, or only supported which is an extra cost
+	// for older families
+	// Possible values are: default, supported, or unsupported
+	EBSOptimizedSupport *ec2types.EbsOptimizedSupport `flag:"ebs-optimized-support" description:"EBS Optimized support: [default, supported (opt-in at an extra cost), or unsupported]" options:"default,supported,unsupported"`
 
 	// DiskEncryption filters for instance types that support EBS Encryption or local storage encryption
-	DiskEncryption *bool
+	DiskEncryption *bool `flag:"disk-encryption" description:"EBS or local instance storage where encryption is supported or required"`
 
 	// EBSOptimizedBaselineBandwidth filters on a range of bandwidth that an EBS Optimized volume supports
-	EBSOptimizedBaselineBandwidth *ByteQuantityRangeFilter
+	EBSOptimizedBaselineBandwidth *ByteQuantityRangeFilter `flag:"ebs-optimized-baseline-bandwidth" description:"EBS Optimized baseline bandwidth (Example: 4 GiB)" units:"Mbps"`
 
 	// EBSOptimizedBaselineThroughput filters on a range of throughput that an EBS Optimized volume supports
-	EBSOptimizedBaselineThroughput *ByteQuantityRangeFilter
+	EBSOptimizedBaselineThroughput *ByteQuantityRangeFilter `flag:"ebs-optimized-baseline-throughput" description:"EBS Optimized baseline throughput per second (Example: 4 GiB)" units:"MB/s"`
 
 	// EBSOptimizedBaselineIOPS filters on a range of IOPS that an EBS Optimized volume supports
-	EBSOptimizedBaselineIOPS *IntRangeFilter
+	EBSOptimizedBaselineIOPS *IntRangeFilter `flag:"ebs-optimized-baseline-iops" description:"EBS Optimized baseline IOPS per second (Example: 10000)" units:"IOPS"`
 
 	// DedicatedHosts filters on instance types that support dedicated hosts tenancy
-	DedicatedHosts *bool
+	DedicatedHosts *bool `flag:"dedicated-hosts" description:"Dedicated Hosts supported"`
+
+	// Tenancy filters on instance types that support all of the tenancies, shared, dedicated (Dedicated Instances), and
+	// host (Dedicated Hosts)
+	Tenancy *[]string `flag:"tenancy" description:"Tenancies which must all be supported: [shared, dedicated, host] (Example: dedicated,host)" options:"shared,dedicated,host"`
 
 	// Generation filters on the instance type generation
 	// i.e. c7i.xlarge is 7
 	// NOTE that generation is only comparable per instance family
 	// For example, i3 and c5 are both 5th generation, but the Generation filter will
 	// only filter on the number in the instance type name.
-	Generation *IntRangeFilter
+	Generation *IntRangeFilter `flag:"generation" description:"Generation of the instance type (i.e. c7i.xlarge is 7)"`
+
+	// NitroGeneration filters on the generation of the Nitro cards the instance type is built on, Xen instance types are 0
+	// NOTE that the Nitro generation is derived from the instance family
+	NitroGeneration *IntRangeFilter `flag:"nitro-generation" description:"Generation of the Nitro cards the instance type is built on, derived from the instance family (i.e. m6i is 4, Xen instance types are 0)"`
+
+	// IPv6OnlySubnetCapable filters for instance types that can be launched in IPv6-only subnets
+	// NOTE that this is derived from IPv6 support and the Nitro System since the EC2 API does not return it
+	IPv6OnlySubnetCapable *bool `flag:"ipv6-only-subnet-capable" description:"Instance Types that can be launched in IPv6-only subnets"`
+
+	// ExcludeDeprecated filters out instance types of previous generation families and those deprecated by Selector.Deprecations
+	ExcludeDeprecated *bool `flag:"exclude-deprecated" description:"Exclude instance types of previous generation families and those deprecated by --deprecations-file"`
 }
 
 type CPUManufacturer string
@@ -302,12 +450,27 @@ func (CPUManufacturer) Values() []CPUManufacturer {
 	}
 }
 
-// ArchitectureTypeAMD64 is a legacy type we support for b/c that isn't in the API.
+// Values of Filters.PlacementGroupStrategyMatch.
+const (
+	PlacementGroupStrategyMatchAll = "all"
+	PlacementGroupStrategyMatchAny = "any"
+)
+
+// Values of Filters.Tenancy.
+const (
+	TenancyShared    = "shared"
+	TenancyDedicated = "dedicated"
+	TenancyHost      = "host"
+)
+
+// ArchitectureTypeAMD64 is a legacy type we support for b/c that isn't in the API. ArchitectureTypeAuto is replaced by the
+// CLI with the architecture of the machine running it, the selector returns an error when it is filtered on.
 const (
 	ArchitectureTypeAMD64 ec2types.ArchitectureType = "amd64"
+	ArchitectureTypeAuto  ec2types.ArchitectureType = "auto"
 )
 
-// ArchitectureTypeAMD64 is a legacy type we support for b/c that isn't in the API.
+// VirtualizationTypePv is a legacy type we support for b/c that isn't in the API.
 const (
 	VirtualizationTypePv ec2types.VirtualizationType = "pv"
 )
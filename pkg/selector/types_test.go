@@ -13,7 +13,9 @@
 package selector_test
 
 import (
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
@@ -55,3 +57,53 @@ func TestMarshalIndent_nil(t *testing.T) {
 	h.Assert(t, strings.Contains(outStr, "AllowList") && strings.Contains(outStr, "null"), "Does not include AllowList null entry")
 	h.Assert(t, strings.Contains(outStr, "DenyList") && strings.Contains(outStr, denyRegex), "Does not include DenyList regex string")
 }
+
+func TestMerge(t *testing.T) {
+	flagArch := ec2types.ArchitectureTypeArm64
+	fileArch := ec2types.ArchitectureTypeX8664
+	filters := selector.Filters{
+		CPUArchitecture: &flagArch,
+	}
+	defaults := selector.Filters{
+		CPUArchitecture: &fileArch,
+		VCpusRange:      &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+	}
+	merged := filters.Merge(defaults)
+	h.Equals(t, flagArch, *merged.CPUArchitecture)
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4}, *merged.VCpusRange)
+	h.Assert(t, filters.VCpusRange == nil, "Merge should not modify the original filters")
+}
+
+func TestFilterSchema(t *testing.T) {
+	schema := selector.FilterSchema()
+	h.Equals(t, reflect.TypeOf(selector.Filters{}).NumField(), len(schema))
+	knownTypes := map[string]bool{
+		selector.FilterTypeBool:              true,
+		selector.FilterTypeInt:               true,
+		selector.FilterTypeFloat:             true,
+		selector.FilterTypeString:            true,
+		selector.FilterTypeStringList:        true,
+		selector.FilterTypeIntList:           true,
+		selector.FilterTypeRegex:             true,
+		selector.FilterTypeIntRange:          true,
+		selector.FilterTypeFloatRange:        true,
+		selector.FilterTypeByteQuantityRange: true,
+	}
+	fields := map[string]selector.FilterSchemaField{}
+	for _, field := range schema {
+		h.Assert(t, knownTypes[field.Type], "%s has an unknown filter type %s", field.Name, field.Type)
+		h.Assert(t, field.Description != "", "%s should have a description", field.Name)
+		fields[field.Name] = field
+	}
+
+	h.Equals(t, selector.FilterTypeByteQuantityRange, fields["MemoryRange"].Type)
+	h.Equals(t, "GiB", fields["MemoryRange"].Units)
+	h.Equals(t, selector.FilterTypeRegex, fields["AllowList"].Type)
+	h.Equals(t, selector.FilterTypeStringList, fields["AvailabilityZones"].Type)
+	h.Equals(t, selector.FilterTypeIntList, fields["VCpusList"].Type)
+	h.Equals(t, []string{"cluster", "partition", "spread"}, fields["PlacementGroupStrategy"].Options)
+	h.Equals(t, []string{"cluster", "partition", "spread"}, fields["PlacementGroupStrategies"].Options)
+	h.Equals(t, []string{"amd", "intel", "aws"}, fields["CPUManufacturer"].Options)
+	h.Assert(t, slices.Contains(fields["CPUArchitecture"].Options, string(ec2types.ArchitectureTypeArm64)), "CPUArchitecture options should include arm64")
+	h.Equals(t, 0, len(fields["BareMetal"].Options))
+}
@@ -0,0 +1,98 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// InstanceTypesDiff holds the instance types which were added to and removed from the results of a filter between two runs.
+type InstanceTypesDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// Changed returns true if any instance types were added or removed.
+func (d InstanceTypesDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+// DiffInstanceTypes returns the instance types in current which are not in previous as added
+// and the instance types in previous which are not in current as removed.
+func DiffInstanceTypes(previous []string, current []string) InstanceTypesDiff {
+	previousSet := map[string]bool{}
+	for _, instanceType := range previous {
+		previousSet[instanceType] = true
+	}
+	currentSet := map[string]bool{}
+	diff := InstanceTypesDiff{}
+	for _, instanceType := range current {
+		currentSet[instanceType] = true
+		if !previousSet[instanceType] {
+			diff.Added = append(diff.Added, instanceType)
+		}
+	}
+	for _, instanceType := range previous {
+		if !currentSet[instanceType] {
+			diff.Removed = append(diff.Removed, instanceType)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// WatchFn is called each time a watched filter is run with the matching instance types and the diff from the previous run.
+// Returning an error stops the watch.
+type WatchFn func(instanceTypes []string, diff InstanceTypesDiff) error
+
+// Watch runs the filters immediately and then once every interval until the context is done or watchFn returns an error.
+// The first run is diffed against previous, if previous is nil the first run is used as the baseline and has an empty diff.
+// MaxResults is ignored so that changes to the full set of matching instance types are detected.
+func (s Selector) Watch(ctx context.Context, filters Filters, interval time.Duration, previous []string, watchFn WatchFn) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be greater than 0, got %s", interval)
+	}
+	filters.MaxResults = nil
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		instanceTypes, err := s.Filter(ctx, filters)
+		if err != nil {
+			return err
+		}
+		if instanceTypes == nil {
+			instanceTypes = []string{}
+		}
+		diff := InstanceTypesDiff{}
+		if previous != nil {
+			diff = DiffInstanceTypes(previous, instanceTypes)
+		}
+		if err := watchFn(instanceTypes, diff); err != nil {
+			return err
+		}
+		previous = instanceTypes
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// select picks randomly when the context was canceled while a tick was pending
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
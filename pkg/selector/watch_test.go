@@ -0,0 +1,70 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestDiffInstanceTypes(t *testing.T) {
+	diff := selector.DiffInstanceTypes([]string{"m5.large", "t3.micro"}, []string{"t3.micro", "c8g.large", "c7g.large"})
+	h.Equals(t, []string{"c7g.large", "c8g.large"}, diff.Added)
+	h.Equals(t, []string{"m5.large"}, diff.Removed)
+	h.Assert(t, diff.Changed(), "Diff should be changed")
+
+	diff = selector.DiffInstanceTypes([]string{"t3.micro"}, []string{"t3.micro"})
+	h.Assert(t, !diff.Changed(), "Diff should not be changed")
+}
+
+func TestWatch(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	diffs := []selector.InstanceTypesDiff{}
+	err := itf.Watch(ctx, selector.Filters{MaxResults: aws.Int(1)}, time.Millisecond, nil, func(instanceTypes []string, diff selector.InstanceTypesDiff) error {
+		h.Equals(t, 2, len(instanceTypes))
+		diffs = append(diffs, diff)
+		if len(diffs) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	h.Assert(t, errors.Is(err, context.Canceled), "Watch should stop when the context is canceled")
+	h.Equals(t, 2, len(diffs))
+	h.Assert(t, !diffs[0].Changed(), "The first run should be the baseline when there is no previous run")
+	h.Assert(t, !diffs[1].Changed(), "The second run should not be changed")
+}
+
+func TestWatch_Previous(t *testing.T) {
+	itf := getSelector(setupMock(t, describeInstanceTypes, "t3_micro_and_p3_16xl.json"))
+	errStop := errors.New("stop")
+	err := itf.Watch(context.Background(), selector.Filters{}, time.Hour, []string{"m5.large", "t3.micro"}, func(instanceTypes []string, diff selector.InstanceTypesDiff) error {
+		h.Equals(t, []string{"p3.16xlarge"}, diff.Added)
+		h.Equals(t, []string{"m5.large"}, diff.Removed)
+		return errStop
+	})
+	h.Assert(t, errors.Is(err, errStop), "Watch should return the error of the watch function")
+
+	err = itf.Watch(context.Background(), selector.Filters{}, 0, nil, func([]string, selector.InstanceTypesDiff) error { return nil })
+	h.Nok(t, err)
+}
@@ -0,0 +1,201 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v3"
+)
+
+var regexpType = reflect.TypeOf(&regexp.Regexp{})
+
+// anyOfKey is the key of the filter groups in a filters file.
+const anyOfKey = "AnyOf"
+
+// enumAliases are values accepted for enum filters in addition to the values defined by the EC2 API.
+var enumAliases = map[reflect.Type][]string{
+	reflect.TypeOf(ArchitectureTypeAMD64): {string(ArchitectureTypeAMD64)},
+	reflect.TypeOf(VirtualizationTypePv):  {string(VirtualizationTypePv)},
+}
+
+// LoadFiltersFile reads Filters from a YAML file.
+// Errors include the file name and the line of the invalid filter.
+func LoadFiltersFile(path string) (*Filters, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand filters file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read filters file %s: %w", expandedPath, err)
+	}
+	filters := &Filters{}
+	if err := yaml.Unmarshal(data, filters); err != nil {
+		return nil, fmt.Errorf("invalid filters file %s: %w", expandedPath, err)
+	}
+	return filters, nil
+}
+
+// LoadFilterSetFile reads a FilterSet from a YAML file.
+// The file is either a mapping of filters which is loaded as a single group, or a mapping with an AnyOf
+// sequence of filter groups where the other filters of the mapping apply to every group (Example:
+// {Region: us-east-1, AnyOf: [{GpusRange: {LowerBound: 1, UpperBound: 8}}, {AllowList: "inf2*"}]}).
+func LoadFilterSetFile(path string) (*FilterSet, error) {
+	expandedPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to expand filters file path %s: %w", path, err)
+	}
+	data, err := os.ReadFile(expandedPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read filters file %s: %w", expandedPath, err)
+	}
+	filterSet := &FilterSet{}
+	if err := yaml.Unmarshal(data, filterSet); err != nil {
+		return nil, fmt.Errorf("invalid filters file %s: %w", expandedPath, err)
+	}
+	return filterSet, nil
+}
+
+// UnmarshalYAML decodes a mapping of filters and an optional AnyOf sequence of filter groups.
+// Filters set in a group take precedence over the filters shared by all of the groups.
+func (f *FilterSet) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: filters must be a mapping of filter names to values", value.Line)
+	}
+	sharedNode := &yaml.Node{Kind: yaml.MappingNode, Line: value.Line}
+	var groupsNode *yaml.Node
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == anyOfKey {
+			groupsNode = value.Content[i+1]
+			continue
+		}
+		sharedNode.Content = append(sharedNode.Content, value.Content[i], value.Content[i+1])
+	}
+	shared := Filters{}
+	if err := shared.UnmarshalYAML(sharedNode); err != nil {
+		return err
+	}
+	filterSet := FilterSet{MaxResults: shared.MaxResults}
+	if groupsNode == nil {
+		filterSet.Groups = []Filters{shared}
+		*f = filterSet
+		return nil
+	}
+	if groupsNode.Kind != yaml.SequenceNode || len(groupsNode.Content) == 0 {
+		return fmt.Errorf("line %d: %s must be a sequence of at least one group of filters", groupsNode.Line, anyOfKey)
+	}
+	for _, groupNode := range groupsNode.Content {
+		group := Filters{}
+		if err := group.UnmarshalYAML(groupNode); err != nil {
+			return err
+		}
+		filterSet.Groups = append(filterSet.Groups, group.Merge(shared))
+	}
+	*f = filterSet
+	return nil
+}
+
+// MarshalYAML returns a YAML mapping of the set filters keyed by their field names.
+// Filters which are not set are omitted and regular expressions are written as strings.
+func (f Filters) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	filtersValue := reflect.ValueOf(f)
+	filtersType := filtersValue.Type()
+	for i := 0; i < filtersType.NumField(); i++ {
+		field := filtersValue.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		var value interface{} = field.Interface()
+		if field.Type() == regexpType {
+			value = field.Interface().(*regexp.Regexp).String()
+		}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			return nil, fmt.Errorf("unable to marshal filter %s: %w", filtersType.Field(i).Name, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: filtersType.Field(i).Name}, valueNode)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML decodes a YAML mapping of filter field names to filter values.
+// Unknown filters, invalid values, and invalid regular expressions return an error with the line of the filter.
+func (f *Filters) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: filters must be a mapping of filter names to values", value.Line)
+	}
+	filters := Filters{}
+	filtersValue := reflect.ValueOf(&filters).Elem()
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		keyNode, valueNode := value.Content[i], value.Content[i+1]
+		field := filtersValue.FieldByName(keyNode.Value)
+		if !field.IsValid() {
+			return fmt.Errorf("line %d: unknown filter %q", keyNode.Line, keyNode.Value)
+		}
+		if valueNode.Tag == "!!null" {
+			continue
+		}
+		if field.Type() == regexpType {
+			var regexStr string
+			if err := valueNode.Decode(&regexStr); err != nil {
+				return fmt.Errorf("line %d: invalid value for filter %s: %w", valueNode.Line, keyNode.Value, err)
+			}
+			regex, err := CompileInstanceTypePattern(regexStr)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid regex for filter %s: %w", valueNode.Line, keyNode.Value, err)
+			}
+			field.Set(reflect.ValueOf(regex))
+			continue
+		}
+		decoded := reflect.New(field.Type().Elem())
+		if err := valueNode.Decode(decoded.Interface()); err != nil {
+			return fmt.Errorf("line %d: invalid value for filter %s: %w", valueNode.Line, keyNode.Value, err)
+		}
+		if err := validateEnum(decoded.Elem()); err != nil {
+			return fmt.Errorf("line %d: invalid value for filter %s: %w", valueNode.Line, keyNode.Value, err)
+		}
+		field.Set(decoded)
+	}
+	*f = filters
+	return nil
+}
+
+// validateEnum checks that string enum values are one of the values returned by the type's Values method.
+// Values of types without a Values method are always valid.
+func validateEnum(value reflect.Value) error {
+	if value.Kind() != reflect.String {
+		return nil
+	}
+	valuesFn := value.MethodByName("Values")
+	if !valuesFn.IsValid() {
+		return nil
+	}
+	validValues := append([]string{}, enumAliases[value.Type()]...)
+	enumValues := valuesFn.Call(nil)[0]
+	for i := 0; i < enumValues.Len(); i++ {
+		validValues = append(validValues, enumValues.Index(i).String())
+	}
+	for _, validValue := range validValues {
+		if value.String() == validValue {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must be one of: %s", value.String(), strings.Join(validValues, ", "))
+}
@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Tests
+
+func TestFiltersYAML_RoundTrip(t *testing.T) {
+	cpuArch := ec2types.ArchitectureTypeArm64
+	filters := selector.Filters{
+		VCpusRange:      &selector.Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+		MemoryRange:     &selector.ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(4), UpperBound: bytequantity.FromGiB(8)},
+		CPUArchitecture: &cpuArch,
+		DenyList:        regexp.MustCompile(`^t2\.`),
+		BareMetal:       aws.Bool(false),
+	}
+	out, err := yaml.Marshal(filters)
+	h.Ok(t, err)
+	h.Assert(t, !strings.Contains(string(out), "AllowList"), "Should omit filters which are not set")
+	h.Assert(t, strings.Contains(string(out), "4096 MiB"), "Should write byte quantities as strings")
+
+	unmarshaled := selector.Filters{}
+	h.Ok(t, yaml.Unmarshal(out, &unmarshaled))
+	h.Equals(t, *filters.VCpusRange, *unmarshaled.VCpusRange)
+	h.Equals(t, *filters.MemoryRange, *unmarshaled.MemoryRange)
+	h.Equals(t, cpuArch, *unmarshaled.CPUArchitecture)
+	h.Equals(t, filters.DenyList.String(), unmarshaled.DenyList.String())
+	h.Equals(t, false, *unmarshaled.BareMetal)
+	h.Assert(t, unmarshaled.AllowList == nil, "Should not set filters which are not in the YAML")
+}
+
+func TestFiltersYAML_Unmarshal(t *testing.T) {
+	filtersYAML := `
+CPUArchitecture: amd64
+MemoryRange:
+  LowerBound: 2
+  UpperBound: 1 TiB
+AllowList: m[5-6]\..*
+InstanceTypes: [m5.large, m6i.large]
+GpusRange: ~
+`
+	filters := selector.Filters{}
+	h.Ok(t, yaml.Unmarshal([]byte(filtersYAML), &filters))
+	h.Equals(t, selector.ArchitectureTypeAMD64, *filters.CPUArchitecture)
+	h.Equals(t, bytequantity.FromGiB(2), filters.MemoryRange.LowerBound)
+	h.Equals(t, bytequantity.FromTiB(1), filters.MemoryRange.UpperBound)
+	h.Assert(t, filters.AllowList.MatchString("m5.large") && !filters.AllowList.MatchString("m6i.large"), "Should parse the allow list regex")
+	h.Equals(t, []string{"m5.large", "m6i.large"}, *filters.InstanceTypes)
+	h.Assert(t, filters.GpusRange == nil, "Should not set null filters")
+}
+
+func TestFiltersYAML_UnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		filtersYAML string
+		errContains string
+	}{
+		{filtersYAML: "BareMetal: true\nVCpus: 2", errContains: `line 2: unknown filter "VCpus"`},
+		{filtersYAML: "DenyList: '(('", errContains: "line 1: invalid regex for filter DenyList"},
+		{filtersYAML: "\nUsageClass: reserved", errContains: `line 2: invalid value for filter UsageClass: "reserved" must be one of`},
+		{filtersYAML: "MemoryRange:\n  LowerBound: lots", errContains: "line 2: invalid value for filter MemoryRange"},
+		{filtersYAML: "VCpusRange: {LowerBound: two}", errContains: "line 1: invalid value for filter VCpusRange"},
+		{filtersYAML: "- BareMetal", errContains: "line 1: filters must be a mapping"},
+	} {
+		filters := selector.Filters{}
+		err := yaml.Unmarshal([]byte(tc.filtersYAML), &filters)
+		h.Nok(t, err)
+		h.Assert(t, strings.Contains(err.Error(), tc.errContains), "error %q should contain %q", err.Error(), tc.errContains)
+	}
+}
+
+func TestLoadFiltersFile(t *testing.T) {
+	filtersFile := filepath.Join(t.TempDir(), "filters.yaml")
+	h.Ok(t, os.WriteFile(filtersFile, []byte("VCpusRange:\n  LowerBound: 2\n  UpperBound: 2\n"), 0o600))
+	filters, err := selector.LoadFiltersFile(filtersFile)
+	h.Ok(t, err)
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2}, *filters.VCpusRange)
+
+	h.Ok(t, os.WriteFile(filtersFile, []byte("VCpusRange: 2\n"), 0o600))
+	_, err = selector.LoadFiltersFile(filtersFile)
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), filtersFile), "error should contain the filters file name")
+
+	_, err = selector.LoadFiltersFile(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	h.Nok(t, err)
+}
+
+func TestLoadFilterSetFile(t *testing.T) {
+	filtersFile := filepath.Join(t.TempDir(), "filters.yaml")
+	h.Ok(t, os.WriteFile(filtersFile, []byte("VCpusRange:\n  LowerBound: 2\n  UpperBound: 2\n"), 0o600))
+	filterSet, err := selector.LoadFilterSetFile(filtersFile)
+	h.Ok(t, err)
+	h.Equals(t, 1, len(filterSet.Groups))
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 2, UpperBound: 2}, *filterSet.Groups[0].VCpusRange)
+
+	filterSetYAML := `
+Region: us-east-1
+MaxResults: 5
+VCpusRange: {LowerBound: 2, UpperBound: 64}
+AnyOf:
+  - GpusRange: {LowerBound: 1, UpperBound: 8}
+    MemoryRange: {LowerBound: 64 GiB, UpperBound: 512 GiB}
+  - AllowList: "inf2*"
+    VCpusRange: {LowerBound: 4, UpperBound: 4}
+`
+	h.Ok(t, os.WriteFile(filtersFile, []byte(filterSetYAML), 0o600))
+	filterSet, err = selector.LoadFilterSetFile(filtersFile)
+	h.Ok(t, err)
+	h.Equals(t, 5, *filterSet.MaxResults)
+	h.Equals(t, 2, len(filterSet.Groups))
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 1, UpperBound: 8}, *filterSet.Groups[0].GpusRange)
+	h.Equals(t, bytequantity.FromGiB(64), filterSet.Groups[0].MemoryRange.LowerBound)
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 2, UpperBound: 64}, *filterSet.Groups[0].VCpusRange)
+	h.Equals(t, "^inf2.*$", filterSet.Groups[1].AllowList.String())
+	h.Equals(t, selector.Int32RangeFilter{LowerBound: 4, UpperBound: 4}, *filterSet.Groups[1].VCpusRange)
+	h.Assert(t, filterSet.Groups[1].GpusRange == nil, "Filters of other groups should not be set")
+	for _, group := range filterSet.Groups {
+		h.Equals(t, "us-east-1", aws.ToString(group.Region))
+	}
+
+	for _, invalidYAML := range []string{"AnyOf: []\n", "AnyOf: {VCpusRange: 2}\n", "AnyOf:\n  - VCpus: 2\n"} {
+		h.Ok(t, os.WriteFile(filtersFile, []byte(invalidYAML), 0o600))
+		_, err = selector.LoadFilterSetFile(filtersFile)
+		h.Nok(t, err)
+	}
+}
@@ -0,0 +1,266 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selectorapi is the stable Go API of the instance selector.
+//
+// It exposes the filters, the details of the matching instance types, and the functions to filter instance types
+// without the EC2 and pricing clients or caches which the selector package uses internally.
+// Within a major version of the module the exported identifiers of this package are only added to, never removed or changed,
+// and fields are only added to the Filters and Details structs. Every type of the module which is reachable from the types
+// of this package, such as the types of their fields, is aliased here so that callers don't import the other packages. The other packages of the module may change between
+// minor versions as the CLI evolves.
+package selectorapi
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+)
+
+// Filters and the range types used to build them.
+type (
+	// Filters holds the criteria instance types are selected by. Nil fields are not filtered on.
+	Filters = selector.Filters
+	// FilterSet holds groups of Filters, instance types matching any of the groups are selected.
+	FilterSet = selector.FilterSet
+	// IntRangeFilter holds an inclusive upper and lower bound int.
+	IntRangeFilter = selector.IntRangeFilter
+	// Int32RangeFilter holds an inclusive upper and lower bound int32.
+	Int32RangeFilter = selector.Int32RangeFilter
+	// Float64RangeFilter holds an inclusive upper and lower bound float64.
+	Float64RangeFilter = selector.Float64RangeFilter
+	// ByteQuantityRangeFilter holds an inclusive upper and lower bound ByteQuantity.
+	ByteQuantityRangeFilter = selector.ByteQuantityRangeFilter
+	// Int32SetFilter holds the exact values an instance type spec must be equal to one of.
+	Int32SetFilter = selector.Int32SetFilter
+	// NotFilter holds values which an instance type spec must not be equal to, ignoring case.
+	NotFilter = selector.NotFilter
+	// ByteQuantity is a quantity of bytes stored in MiB.
+	ByteQuantity = bytequantity.ByteQuantity
+	// CPUManufacturer is the manufacturer of an instance type's CPUs.
+	CPUManufacturer = selector.CPUManufacturer
+	// FilterSchemaField describes a field of the Filters struct.
+	FilterSchemaField = selector.FilterSchemaField
+)
+
+// Cache hydration options and progress.
+type (
+	// HydrateOptions configures which caches HydrateCaches hydrates.
+	HydrateOptions = selector.HydrateOptions
+	// HydrateProgress is the progress of hydrating a single cache.
+	HydrateProgress = selector.HydrateProgress
+	// HydrateProgressFn is called with the progress of each cache.
+	HydrateProgressFn = selector.HydrateProgressFn
+	// HydrateEvent is the stage of hydrating a cache.
+	HydrateEvent = selector.HydrateEvent
+)
+
+// Caches which can be hydrated and the stages of hydrating them.
+const (
+	OnDemandPricingCache = selector.OnDemandPricingCache
+	SpotPricingCache     = selector.SpotPricingCache
+	InstanceTypesCache   = selector.InstanceTypesCache
+	OfferingsCache       = selector.OfferingsCache
+
+	HydrateStarted   = selector.HydrateStarted
+	HydrateCompleted = selector.HydrateCompleted
+	HydrateFailed    = selector.HydrateFailed
+	HydrateSkipped   = selector.HydrateSkipped
+)
+
+// Locations returned by Regions and Zones.
+type (
+	// Region is an AWS Region which is enabled for the account.
+	Region = selector.Region
+	// Zone is an availability zone, Local Zone, or Wavelength Zone of a region.
+	Zone = selector.Zone
+)
+
+// Errors returned by the Selector which can be matched with errors.Is.
+var (
+	ErrNotFound                 = selector.ErrNotFound
+	ErrRegionResolution         = selector.ErrRegionResolution
+	ErrPricingUnavailable       = selector.ErrPricingUnavailable
+	ErrInvalidFilterCombination = selector.ErrInvalidFilterCombination
+)
+
+// APIError wraps an error returned by an AWS API with the name of the API which failed, it can be matched with errors.As.
+type APIError = selector.APIError
+
+// CacheError wraps an error saving a cache with the name of the cache which failed, it can be matched with errors.As.
+type CacheError = selector.CacheError
+
+// PricingCaches is the Cache of a CacheError for the on-demand and spot pricing caches of a pricing client which saves
+// them together, the default pricing client returns OnDemandPricingCache and SpotPricingCache CacheErrors instead.
+const PricingCaches = selector.PricingCaches
+
+// Details of matching instance types.
+type (
+	// Details are the EC2 instance type info of a matching instance type along with its prices.
+	Details = instancetypes.Details
+	// AvailabilityZone holds the name and ID of an availability zone an instance type is offered in.
+	AvailabilityZone = instancetypes.AvailabilityZone
+)
+
+// Annotations of the Details of matching instance types.
+const (
+	AnnotationPreviousGeneration       = selector.AnnotationPreviousGeneration
+	AnnotationDeprecated               = selector.AnnotationDeprecated
+	AnnotationOnDemandPriceUnavailable = selector.AnnotationOnDemandPriceUnavailable
+	AnnotationOnDemandPriceDiscounted  = selector.AnnotationOnDemandPriceDiscounted
+	AnnotationSpotPriceUnavailable     = selector.AnnotationSpotPriceUnavailable
+	AnnotationSpotPriceStale           = selector.AnnotationSpotPriceStale
+	AnnotationSpotUnsupported          = selector.AnnotationSpotUnsupported
+)
+
+// InstanceTypesCatalog is every instance type of a region indexed by name, family, and size.
+type InstanceTypesCatalog = selector.InstanceTypesCatalog
+
+// CPU manufacturers which can be filtered on.
+const (
+	CPUManufacturerAMD   = selector.CPUManufacturerAMD
+	CPUManufacturerIntel = selector.CPUManufacturerIntel
+	CPUManufacturerAWS   = selector.CPUManufacturerAWS
+)
+
+// Tenancies which can be filtered on.
+const (
+	TenancyShared    = selector.TenancyShared
+	TenancyDedicated = selector.TenancyDedicated
+	TenancyHost      = selector.TenancyHost
+)
+
+// Selector selects the instance types matching Filters.
+type Selector struct {
+	selector *selector.Selector
+}
+
+// Option configures a Selector created by New.
+type Option func(*options)
+
+type options struct {
+	cacheTTL      time.Duration
+	cacheDir      string
+	cacheReadOnly bool
+	logger        *log.Logger
+	appID         string
+}
+
+// WithCache caches instance types and pricing in cacheDir for ttl. Caching is off by default.
+func WithCache(ttl time.Duration, cacheDir string) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+		o.cacheDir = cacheDir
+	}
+}
+
+// WithCacheReadOnly loads the caches configured by WithCache without saving or removing them.
+func WithCacheReadOnly() Option {
+	return func(o *options) {
+		o.cacheReadOnly = true
+	}
+}
+
+// WithLogger logs what the selector is doing, such as API timings, to logger. Nothing is logged by default.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithAppID appends appID to the user agent of the AWS API calls so they can be attributed to the application in CloudTrail.
+func WithAppID(appID string) Option {
+	return func(o *options) {
+		o.appID = appID
+	}
+}
+
+// New creates a Selector which retrieves instance types and pricing with the passed in aws config.
+func New(ctx context.Context, cfg aws.Config, opts ...Option) (*Selector, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var selectorOpts []selector.Option
+	if o.appID != "" {
+		selectorOpts = append(selectorOpts, selector.WithAppID(o.appID))
+	}
+	instanceSelector, err := selector.NewWithCache(ctx, cfg, o.cacheTTL, o.cacheDir, selectorOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if o.logger != nil {
+		instanceSelector.SetLogger(o.logger)
+	}
+	instanceSelector.SetCacheReadOnly(o.cacheReadOnly)
+	return newFromSelector(instanceSelector), nil
+}
+
+func newFromSelector(instanceSelector *selector.Selector) *Selector {
+	return &Selector{selector: instanceSelector}
+}
+
+// Filter returns the names of the instance types matching filters, sorted by name.
+func (s *Selector) Filter(ctx context.Context, filters Filters) ([]string, error) {
+	return s.selector.Filter(ctx, filters)
+}
+
+// FilterVerbose returns the details of the instance types matching filters, sorted by name.
+func (s *Selector) FilterVerbose(ctx context.Context, filters Filters) ([]*Details, error) {
+	return s.selector.FilterVerbose(ctx, filters)
+}
+
+// FilterGroups returns the names of the instance types matching any of the groups of filters, sorted by name.
+func (s *Selector) FilterGroups(ctx context.Context, filterSet FilterSet) ([]string, error) {
+	return s.selector.FilterGroups(ctx, filterSet)
+}
+
+// FilterGroupsVerbose returns the details of the instance types matching any of the groups of filters, sorted by name.
+func (s *Selector) FilterGroupsVerbose(ctx context.Context, filterSet FilterSet) ([]*Details, error) {
+	return s.selector.FilterGroupsVerbose(ctx, filterSet)
+}
+
+// Catalog returns every instance type of the configured region from the instance types cache without filtering them.
+func (s *Selector) Catalog(ctx context.Context) (*InstanceTypesCatalog, error) {
+	return s.selector.Catalog(ctx)
+}
+
+// HydrateCaches hydrates the pricing and instance type caches in parallel, reporting the progress of each cache to progressFn.
+func (s *Selector) HydrateCaches(ctx context.Context, opts HydrateOptions, progressFn HydrateProgressFn) error {
+	return s.selector.HydrateCaches(ctx, opts, progressFn)
+}
+
+// Regions returns the regions enabled for the account sorted by name.
+func (s *Selector) Regions(ctx context.Context) ([]Region, error) {
+	return s.selector.Regions(ctx)
+}
+
+// Zones returns all of the zones of the configured region sorted by name.
+func (s *Selector) Zones(ctx context.Context) ([]Zone, error) {
+	return s.selector.Zones(ctx)
+}
+
+// Save persists the instance type and pricing caches to the cache directory if caching is configured.
+func (s *Selector) Save() error {
+	return s.selector.Save()
+}
+
+// FilterSchema returns the name, type, description, units, and options of every field in the Filters struct.
+func FilterSchema() []FilterSchemaField {
+	return selector.FilterSchema()
+}
@@ -0,0 +1,169 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selectorapi
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/bytequantity"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/test/fixtures"
+)
+
+// Mocking helpers
+
+const (
+	mockFilesPath = "../../test/static"
+	// modulePath prefixes the package paths of the module's packages
+	modulePath = "github.com/aws/amazon-ec2-instance-selector/v3/"
+)
+
+func getTestSelector(t *testing.T) (*Selector, *selector.Selector) {
+	replayEC2, err := fixtures.NewReplayEC2(mockFilesPath, "25_instances.json")
+	h.Ok(t, err)
+	instanceSelector, err := selector.New(context.Background(), aws.Config{Region: "us-east-1"},
+		selector.WithInstanceTypesProvider(instancetypes.NewProvider("us-east-1", replayEC2)),
+		selector.WithPricing(ec2pricing.NewFilePricing(nil, nil, nil)))
+	h.Ok(t, err)
+	return newFromSelector(instanceSelector), instanceSelector
+}
+
+// aliasedTypes parses selectorapi.go and returns the package path qualified names of the types it aliases.
+func aliasedTypes(t *testing.T) map[string]bool {
+	file, err := parser.ParseFile(token.NewFileSet(), "selectorapi.go", nil, parser.SkipObjectResolution)
+	h.Ok(t, err)
+	importPaths := map[string]string{}
+	for _, importSpec := range file.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		h.Ok(t, err)
+		importPaths[path.Base(importPath)] = importPath
+	}
+	aliases := map[string]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		typeSpec, ok := node.(*ast.TypeSpec)
+		if !ok || !typeSpec.Assign.IsValid() {
+			return true
+		}
+		if selectorExpr, ok := typeSpec.Type.(*ast.SelectorExpr); ok {
+			aliases[importPaths[selectorExpr.X.(*ast.Ident).Name]+"."+selectorExpr.Sel.Name] = true
+		}
+		return true
+	})
+	return aliases
+}
+
+// moduleTypes adds the named types of the module which are reachable from ty, through its elements, exported fields, and
+// the signatures of its methods, to types.
+func moduleTypes(ty reflect.Type, types map[reflect.Type]bool) {
+	if types[ty] {
+		return
+	}
+	if ty.Name() != "" {
+		if !strings.HasPrefix(ty.PkgPath(), modulePath) {
+			return
+		}
+		types[ty] = true
+		for i := 0; i < reflect.PointerTo(ty).NumMethod(); i++ {
+			moduleTypes(reflect.PointerTo(ty).Method(i).Type, types)
+		}
+	}
+	switch ty.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		moduleTypes(ty.Elem(), types)
+	case reflect.Map:
+		moduleTypes(ty.Key(), types)
+		moduleTypes(ty.Elem(), types)
+	case reflect.Func:
+		for i := 0; i < ty.NumIn(); i++ {
+			moduleTypes(ty.In(i), types)
+		}
+		for i := 0; i < ty.NumOut(); i++ {
+			moduleTypes(ty.Out(i), types)
+		}
+	case reflect.Struct:
+		for i := 0; i < ty.NumField(); i++ {
+			if ty.Field(i).IsExported() {
+				moduleTypes(ty.Field(i).Type, types)
+			}
+		}
+	}
+}
+
+// Tests
+
+func TestFilter(t *testing.T) {
+	s, instanceSelector := getTestSelector(t)
+	filters := Filters{
+		VCpusRange:  &Int32RangeFilter{LowerBound: 2, UpperBound: 4},
+		MemoryRange: &ByteQuantityRangeFilter{LowerBound: bytequantity.FromGiB(4), UpperBound: bytequantity.FromGiB(16)},
+	}
+	results, err := s.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Assert(t, len(results) > 0, "Should return at least 1 instance type")
+	expected, err := instanceSelector.Filter(context.Background(), filters)
+	h.Ok(t, err)
+	h.Equals(t, expected, results)
+	h.Ok(t, s.Save())
+}
+
+func TestFilterVerbose(t *testing.T) {
+	s, _ := getTestSelector(t)
+	details, err := s.FilterVerbose(context.Background(), Filters{
+		VCpusRange: &Int32RangeFilter{LowerBound: 2, UpperBound: 2},
+	})
+	h.Ok(t, err)
+	h.Assert(t, len(details) > 0, "Should return at least 1 instance type")
+	for _, it := range details {
+		h.Equals(t, int32(2), aws.ToInt32(it.VCpuInfo.DefaultVCpus))
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	s, _ := getTestSelector(t)
+	catalog, err := s.Catalog(context.Background())
+	h.Ok(t, err)
+	h.Equals(t, 25, len(catalog.InstanceTypes))
+	h.Equals(t, 6, len(catalog.Family("a1")))
+}
+
+func TestAliases(t *testing.T) {
+	aliases := aliasedTypes(t)
+	types := map[reflect.Type]bool{}
+	moduleTypes(reflect.TypeOf(&Selector{}), types)
+	for _, ty := range []reflect.Type{reflect.TypeOf(FilterSchemaField{}), reflect.TypeOf(APIError{}), reflect.TypeOf(CacheError{})} {
+		moduleTypes(ty, types)
+	}
+	for ty := range types {
+		if ty.PkgPath() == reflect.TypeOf(Selector{}).PkgPath() {
+			continue
+		}
+		h.Assert(t, aliases[ty.PkgPath()+"."+ty.Name()], "%s.%s is reachable from the selectorapi types and should be aliased", ty.PkgPath(), ty.Name())
+	}
+}
+
+func TestFilterSchema(t *testing.T) {
+	h.Equals(t, selector.FilterSchema(), FilterSchema())
+}
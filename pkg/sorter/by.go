@@ -0,0 +1,141 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sorter
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+)
+
+// LessFunc reports whether instance type a sorts before instance type b.
+type LessFunc func(a, b *instancetypes.Details) bool
+
+// Key returns the value an instance type is sorted by, which can be computed (Ex: the price per vCPU).
+// Values can be numbers, strings, bools, or pointers to them, nil values are sorted after all other values.
+type Key func(instanceType *instancetypes.Details) any
+
+// descendingValue marks the values of a Desc key.
+type descendingValue struct {
+	value any
+}
+
+// SortWith returns the instance types sorted by less without modifying instanceTypes.
+// Instance types which are neither less than each other keep their order.
+func SortWith(instanceTypes []*instancetypes.Details, less LessFunc) []*instancetypes.Details {
+	sorted := slices.Clone(instanceTypes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// By returns a LessFunc which sorts instance types in ascending order of the values of keys, each key breaks the ties
+// of the keys before it (Ex: By(vcpus, Desc(memory)) sorts by vCPUs and then by the most memory).
+func By(keys ...Key) LessFunc {
+	return func(a, b *instancetypes.Details) bool {
+		for _, key := range keys {
+			if c := compareKeyValues(key(a), key(b)); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	}
+}
+
+// Desc returns a Key which sorts the values of key in descending order, nil values are still sorted last.
+func Desc(key Key) Key {
+	return func(instanceType *instancetypes.Details) any {
+		return descendingValue{value: key(instanceType)}
+	}
+}
+
+// compareKeyValues returns -1 if a sorts before b, 1 if b sorts before a, and 0 if they are equal.
+// Values of different or unsortable kinds are equal.
+func compareKeyValues(a, b any) int {
+	descending := false
+	if descendingA, ok := a.(descendingValue); ok {
+		a, descending = descendingA.value, true
+	}
+	if descendingB, ok := b.(descendingValue); ok {
+		b = descendingB.value
+	}
+	valA, valB := indirect(reflect.ValueOf(a)), indirect(reflect.ValueOf(b))
+	switch {
+	case !valA.IsValid() && !valB.IsValid():
+		return 0
+	case !valA.IsValid():
+		return 1
+	case !valB.IsValid():
+		return -1
+	}
+	c := compareValues(valA, valB)
+	if descending {
+		return -c
+	}
+	return c
+}
+
+// indirect dereferences pointers and interfaces, an invalid value is returned for nil.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+func compareValues(a, b reflect.Value) int {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case isNumber(a) && isNumber(b):
+		return cmp.Compare(toFloat(a), toFloat(b))
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		// false sorts before true
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		default:
+			return 1
+		}
+	}
+	return 0
+}
+
+func isNumber(value reflect.Value) bool {
+	return value.CanInt() || value.CanUint() || value.CanFloat()
+}
+
+func toFloat(value reflect.Value) float64 {
+	switch {
+	case value.CanInt():
+		return float64(value.Int())
+	case value.CanUint():
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
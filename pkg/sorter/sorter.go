@@ -16,9 +16,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/oliveagle/jsonpath"
 
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
@@ -43,9 +46,17 @@ const (
 	VCPUs                          = "vcpus"
 	Memory                         = "memory"
 	GPUMemoryTotal                 = "gpu-memory-total"
+	FPGAMemoryTotal                = "fpga-memory-total"
 	NetworkInterfaces              = "network-interfaces"
+	NetworkCards                   = "network-cards"
+	ENAQueues                      = "ena-queues"
+	NFU                            = "nfu"
 	SpotPrice                      = "spot-price"
 	ODPrice                        = "on-demand-price"
+	SpotSavings                    = "spot-savings"
+	CapacityBlockPrice             = "capacity-block-price"
+	BaselineCPU                    = "baseline-cpu"
+	Carbon                         = "carbon"
 	InstanceStorage                = "instance-storage"
 	EBSOptimizedBaselineBandwidth  = "ebs-optimized-baseline-bandwidth"
 	EBSOptimizedBaselineThroughput = "ebs-optimized-baseline-throughput"
@@ -57,15 +68,45 @@ const (
 	vcpuPath                           = ".VCpuInfo.DefaultVCpus"
 	memoryPath                         = ".MemoryInfo.SizeInMiB"
 	gpuMemoryTotalPath                 = ".GpuInfo.TotalGpuMemoryInMiB"
+	fpgaMemoryTotalPath                = ".FpgaInfo.TotalFpgaMemoryInMiB"
 	networkInterfacesPath              = ".NetworkInfo.MaximumNetworkInterfaces"
+	networkCardsPath                   = ".NetworkInfo.MaximumNetworkCards"
+	enaQueuesPath                      = ".EnaQueues"
+	nfuPath                            = ".NormalizationFactor"
 	spotPricePath                      = ".SpotPrice"
 	odPricePath                        = ".OndemandPricePerHour"
+	spotSavingsPath                    = ".SpotSavings"
+	capacityBlockPricePath             = ".CapacityBlockPricePerHour"
+	baselineCPUPath                    = ".BaselineCPUUtilization"
+	carbonPath                         = ".CarbonScore"
 	instanceStoragePath                = ".InstanceStorageInfo.TotalSizeInGB"
 	ebsOptimizedBaselineBandwidthPath  = ".EbsInfo.EbsOptimizedInfo.BaselineBandwidthInMbps"
 	ebsOptimizedBaselineThroughputPath = ".EbsInfo.EbsOptimizedInfo.BaselineThroughputInMBps"
 	ebsOptimizedBaselineIOPSPath       = ".EbsInfo.EbsOptimizedInfo.BaselineIops"
 )
 
+// sortingKeysMap maps the shorthand flags to their json paths
+var sortingKeysMap = map[string]string{
+	VCPUs:                          vcpuPath,
+	Memory:                         memoryPath,
+	GPUMemoryTotal:                 gpuMemoryTotalPath,
+	FPGAMemoryTotal:                fpgaMemoryTotalPath,
+	NetworkInterfaces:              networkInterfacesPath,
+	NetworkCards:                   networkCardsPath,
+	ENAQueues:                      enaQueuesPath,
+	NFU:                            nfuPath,
+	SpotPrice:                      spotPricePath,
+	ODPrice:                        odPricePath,
+	SpotSavings:                    spotSavingsPath,
+	CapacityBlockPrice:             capacityBlockPricePath,
+	BaselineCPU:                    baselineCPUPath,
+	Carbon:                         carbonPath,
+	InstanceStorage:                instanceStoragePath,
+	EBSOptimizedBaselineBandwidth:  ebsOptimizedBaselineBandwidthPath,
+	EBSOptimizedBaselineThroughput: ebsOptimizedBaselineThroughputPath,
+	EBSOptimizedBaselineIOPS:       ebsOptimizedBaselineIOPSPath,
+}
+
 // sorterNode represents a sortable instance type which holds the value
 // to sort by instance sort.
 type sorterNode struct {
@@ -89,23 +130,8 @@ type sorter struct {
 //
 // sortDirection represents the direction to sort in. Valid options: "ascending", "asc", "descending", "desc".
 func Sort(instanceTypes []*instancetypes.Details, sortField string, sortDirection string) ([]*instancetypes.Details, error) {
-	sortingKeysMap := map[string]string{
-		VCPUs:                          vcpuPath,
-		Memory:                         memoryPath,
-		GPUMemoryTotal:                 gpuMemoryTotalPath,
-		NetworkInterfaces:              networkInterfacesPath,
-		SpotPrice:                      spotPricePath,
-		ODPrice:                        odPricePath,
-		InstanceStorage:                instanceStoragePath,
-		EBSOptimizedBaselineBandwidth:  ebsOptimizedBaselineBandwidthPath,
-		EBSOptimizedBaselineThroughput: ebsOptimizedBaselineThroughputPath,
-		EBSOptimizedBaselineIOPS:       ebsOptimizedBaselineIOPSPath,
-	}
-
 	// determine if user used a shorthand for sorting flag
-	if sortFieldShorthandPath, ok := sortingKeysMap[sortField]; ok {
-		sortField = sortFieldShorthandPath
-	}
+	sortField = expandShorthand(sortField)
 
 	sorter, err := newSorter(instanceTypes, sortField, sortDirection)
 	if err != nil {
@@ -119,6 +145,68 @@ func Sort(instanceTypes []*instancetypes.Details, sortField string, sortDirectio
 	return sorter.instanceTypes(), nil
 }
 
+// Lookup returns the value of a field of the instance type the same way Sort looks up the field to sort by
+//
+// fieldPath is a json path to a field in the instancetypes.Details struct (Ex: ".MemoryInfo.SizeInMiB"), the
+// shorthand flags (memory, gpus, etc.) are also accepted. nil is returned for fields which are not set.
+func Lookup(instanceType *instancetypes.Details, fieldPath string) (interface{}, error) {
+	node, err := newSorterNode(instanceType, formatSortField(expandShorthand(fieldPath)))
+	if err != nil {
+		return nil, err
+	}
+	value := node.fieldValue
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, nil
+	}
+	return value.Interface(), nil
+}
+
+// expandShorthand returns the json path of a shorthand flag, other fields are returned unchanged.
+func expandShorthand(sortField string) string {
+	if sortFieldShorthandPath, ok := sortingKeysMap[sortField]; ok {
+		return sortFieldShorthandPath
+	}
+	return sortField
+}
+
+// PreviousGenerationLast moves the previous generation instance types after the current generation instance types and
+// otherwise keeps the order of the instance types.
+func PreviousGenerationLast(instanceTypes []*instancetypes.Details) []*instancetypes.Details {
+	sorted := make([]*instancetypes.Details, 0, len(instanceTypes))
+	previousGeneration := []*instancetypes.Details{}
+	for _, instanceType := range instanceTypes {
+		if instanceType.CurrentGeneration != nil && !*instanceType.CurrentGeneration {
+			previousGeneration = append(previousGeneration, instanceType)
+			continue
+		}
+		sorted = append(sorted, instanceType)
+	}
+	return append(sorted, previousGeneration...)
+}
+
+// PreferredFirst moves the instance types in preferredInstanceTypes before the other instance types in the order they
+// are listed in, the other instance types keep their order.
+func PreferredFirst(instanceTypes []*instancetypes.Details, preferredInstanceTypes []ec2types.InstanceType) []*instancetypes.Details {
+	ranks := map[ec2types.InstanceType]int{}
+	for rank, instanceType := range preferredInstanceTypes {
+		if _, ok := ranks[instanceType]; !ok {
+			ranks[instanceType] = rank
+		}
+	}
+	return SortWith(instanceTypes, By(func(instanceType *instancetypes.Details) any {
+		if rank, ok := ranks[instanceType.InstanceType]; ok {
+			return rank
+		}
+		return nil
+	}))
+}
+
 // newSorter creates a new Sorter object to be used to sort the given instance types
 // based on the sorting field and direction
 //
@@ -189,6 +277,14 @@ func newSorterNode(instanceType *instancetypes.Details, sortField string) (*sort
 		}, nil
 	}
 
+	// look up simple paths of field names with reflection rather than converting the instance type into json
+	if fieldValue, ok := lookupFieldPath(reflect.ValueOf(instanceType), sortField); ok {
+		return &sorterNode{
+			instanceType: instanceType,
+			fieldValue:   fieldValue,
+		}, nil
+	}
+
 	// convert instance type into json
 	jsonInstanceType, err := json.Marshal(instanceType)
 	if err != nil {
@@ -222,6 +318,88 @@ func newSorterNode(instanceType *instancetypes.Details, sortField string) (*sort
 	}, nil
 }
 
+// lookupFieldPath returns the value of a json path of field names (Ex: "$.MemoryInfo.SizeInMiB") the same way the
+// json path lookup of the instance type's json does: fields are matched by their json names, and missing fields,
+// fields omitted from the json because they are empty, and fields of nil parents are an invalid value.
+// false is returned for paths which are not only field names or which pass through a custom json marshaler.
+func lookupFieldPath(value reflect.Value, sortField string) (reflect.Value, bool) {
+	if !fieldPathRegex.MatchString(sortField) {
+		return reflect.Value{}, false
+	}
+	for _, name := range strings.Split(strings.TrimPrefix(sortField, "$."), ".") {
+		for value.Kind() == reflect.Pointer {
+			if value.Type().Implements(jsonMarshalerType) {
+				return reflect.Value{}, false
+			}
+			if value.IsNil() {
+				return reflect.Value{}, true
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		if value.Type().Implements(jsonMarshalerType) {
+			return reflect.Value{}, false
+		}
+		field, ok := jsonFields(value.Type())[name]
+		if !ok {
+			return reflect.Value{}, true
+		}
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil || (field.omitEmpty && fieldValue.IsZero()) {
+			// the embedded struct the field is promoted from is nil or the field is omitted from the json
+			return reflect.Value{}, true
+		}
+		value = fieldValue
+	}
+	if value.Type().Implements(jsonMarshalerType) || (value.CanAddr() && value.Addr().Type().Implements(jsonMarshalerType)) {
+		return reflect.Value{}, false
+	}
+	return value, true
+}
+
+// jsonField is the index of a struct field with its json name.
+type jsonField struct {
+	index     []int
+	omitEmpty bool
+}
+
+var (
+	fieldPathRegex    = regexp.MustCompile(`^\$(\.[A-Za-z0-9_]+)+$`)
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	// jsonFieldsCache caches the fields of each struct type keyed by their json names
+	jsonFieldsCache sync.Map
+)
+
+// jsonFields returns the exported fields of the struct type, including the fields promoted from embedded structs,
+// keyed by their json names.
+func jsonFields(structType reflect.Type) map[string]jsonField {
+	if fields, ok := jsonFieldsCache.Load(structType); ok {
+		return fields.(map[string]jsonField)
+	}
+	fields := map[string]jsonField{}
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		// fields of the outer struct take precedence over promoted fields with the same name
+		if existing, ok := fields[name]; ok && len(existing.index) <= len(field.Index) {
+			continue
+		}
+		fields[name] = jsonField{index: field.Index, omitEmpty: strings.Contains(options, "omitempty")}
+	}
+	jsonFieldsCache.Store(structType, fields)
+	return fields
+}
+
 // sort the instance types in the Sorter based on the Sorter's sort field and
 // direction.
 func (s *sorter) sort() error {
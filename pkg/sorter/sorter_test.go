@@ -19,6 +19,8 @@ import (
 	"strings"
 	"testing"
 
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/selector/outputs"
 	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/sorter"
@@ -34,7 +36,7 @@ const (
 
 // getInstanceTypeDetails unmarshalls the json file in the given testing folder
 // and returns a list of instance type details.
-func getInstanceTypeDetails(t *testing.T, file string) []*instancetypes.Details {
+func getInstanceTypeDetails(t testing.TB, file string) []*instancetypes.Details {
 	folder := "FilterVerbose"
 	mockFilename := fmt.Sprintf("%s/%s/%s", mockFilesPath, folder, file)
 	mockFile, err := os.ReadFile(mockFilename)
@@ -320,3 +322,137 @@ func TestSort_Bool(t *testing.T) {
 	h.Ok(t, err)
 	h.Assert(t, checkSortResults(sortedInstances, expectedResults), fmt.Sprintf("Expected descending order: [%s], but actual order: %s", strings.Join(expectedResults, ","), outputs.OneLineOutput(sortedInstances)))
 }
+
+func TestPreviousGenerationLast(t *testing.T) {
+	currentGeneration, previousGeneration := true, false
+	instanceTypes := []*instancetypes.Details{
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "c3.large", CurrentGeneration: &previousGeneration}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "c5.large", CurrentGeneration: &currentGeneration}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "c1.medium", CurrentGeneration: &previousGeneration}},
+		{InstanceTypeInfo: ec2types.InstanceTypeInfo{InstanceType: "a1.large", CurrentGeneration: &currentGeneration}},
+	}
+	sortedInstanceTypes := sorter.PreviousGenerationLast(instanceTypes)
+	h.Assert(t, checkSortResults(sortedInstanceTypes, []string{"c5.large", "a1.large", "c3.large", "c1.medium"}), "previous generation instance types should be sorted last in their original order")
+	h.Equals(t, 0, len(sorter.PreviousGenerationLast(nil)))
+}
+
+func TestPreferredFirst(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	preferences := []ec2types.InstanceType{"a1.large", "m5.large", "a1.4xlarge", "a1.large"}
+	sortedInstances := sorter.PreferredFirst(instanceTypes, preferences)
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.large", "a1.4xlarge", "a1.2xlarge"}), fmt.Sprintf("Should order preferred instance types first in the listed order, got %v", sortedInstances))
+	h.Assert(t, checkSortResults(instanceTypes, []string{"a1.2xlarge", "a1.4xlarge", "a1.large"}), "Should not modify the instance types being sorted")
+
+	sortedInstances = sorter.PreferredFirst(instanceTypes, nil)
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.2xlarge", "a1.4xlarge", "a1.large"}), "Should keep the order without preferred instance types")
+}
+
+func TestSortWith(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+
+	// sort by the on-demand price per vCPU, which is computed rather than a field
+	pricePerVCPU := func(instanceType *instancetypes.Details) any {
+		return *instanceType.OndemandPricePerHour / float64(*instanceType.VCpuInfo.DefaultVCpus)
+	}
+	sortedInstances := sorter.SortWith(instanceTypes, sorter.By(pricePerVCPU, func(instanceType *instancetypes.Details) any {
+		return instanceType.InstanceType
+	}))
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.2xlarge", "a1.4xlarge", "a1.large"}), fmt.Sprintf("Should sort by price per vCPU and then by name, got %v", sortedInstances))
+	h.Assert(t, checkSortResults(instanceTypes, []string{"a1.2xlarge", "a1.4xlarge", "a1.large"}), "Should not modify the instance types being sorted")
+
+	sortedInstances = sorter.SortWith(instanceTypes, func(a, b *instancetypes.Details) bool {
+		return *a.MemoryInfo.SizeInMiB > *b.MemoryInfo.SizeInMiB
+	})
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.4xlarge", "a1.2xlarge", "a1.large"}), "Should sort by the less function")
+}
+
+func TestBy_Desc(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+
+	spotPrice := func(instanceType *instancetypes.Details) any {
+		return instanceType.SpotPrice
+	}
+	sortedInstances := sorter.SortWith(instanceTypes, sorter.By(spotPrice))
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.large", "a1.2xlarge", "a1.4xlarge"}), "Should sort instance types without a spot price last")
+
+	sortedInstances = sorter.SortWith(instanceTypes, sorter.By(sorter.Desc(spotPrice)))
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.2xlarge", "a1.large", "a1.4xlarge"}), "Should sort instance types without a spot price last in descending order")
+}
+
+func TestSort_OmittedField(t *testing.T) {
+	instanceTypes := getInstanceTypeDetails(t, "3_instances.json")
+	region := "us-east-1"
+	instanceTypes[2].Region = region
+
+	// empty fields which are omitted from the json are sorted last like nil values
+	sortedInstances, err := sorter.Sort(instanceTypes, ".Region", "asc")
+	h.Ok(t, err)
+	h.Equals(t, region, sortedInstances[0].Region)
+
+	sortedInstances, err = sorter.Sort(instanceTypes, ".NotAField", "asc")
+	h.Ok(t, err)
+	h.Assert(t, checkSortResults(sortedInstances, []string{"a1.2xlarge", "a1.4xlarge", "a1.large"}), "Should not reorder instance types by a missing field")
+}
+
+// getBenchmarkInstanceTypes returns about as many instance types as a region offers.
+func getBenchmarkInstanceTypes(b *testing.B) []*instancetypes.Details {
+	instanceTypes := []*instancetypes.Details{}
+	for i := 0; i < 300; i++ {
+		instanceTypes = append(instanceTypes, getInstanceTypeDetails(b, "3_instances.json")...)
+	}
+	return instanceTypes
+}
+
+func BenchmarkSort_FieldPath(b *testing.B) {
+	instanceTypes := getBenchmarkInstanceTypes(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := sorter.Sort(instanceTypes, sorter.Memory, sorter.SortDescending)
+		h.Ok(b, err)
+	}
+}
+
+func BenchmarkSort_JSONPath(b *testing.B) {
+	instanceTypes := getBenchmarkInstanceTypes(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := sorter.Sort(instanceTypes, ".NetworkInfo.NetworkCards[0].MaximumNetworkInterfaces", sorter.SortDescending)
+		h.Ok(b, err)
+	}
+}
+
+func BenchmarkSortWith(b *testing.B) {
+	instanceTypes := getBenchmarkInstanceTypes(b)
+	memory := func(instanceType *instancetypes.Details) any {
+		return instanceType.MemoryInfo.SizeInMiB
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sorter.SortWith(instanceTypes, sorter.By(sorter.Desc(memory)))
+	}
+}
+
+func TestLookup(t *testing.T) {
+	instanceType := getInstanceTypeDetails(t, "1_instance.json")[0]
+
+	value, err := sorter.Lookup(instanceType, ".MemoryInfo.SizeInMiB")
+	h.Ok(t, err)
+	h.Equals(t, *instanceType.MemoryInfo.SizeInMiB, value)
+
+	// shorthand flags are resolved to their json paths
+	value, err = sorter.Lookup(instanceType, sorter.VCPUs)
+	h.Ok(t, err)
+	h.Equals(t, *instanceType.VCpuInfo.DefaultVCpus, value)
+
+	value, err = sorter.Lookup(instanceType, ".ProcessorInfo.SupportedArchitectures[0]")
+	h.Ok(t, err)
+	h.Equals(t, string(instanceType.ProcessorInfo.SupportedArchitectures[0]), value)
+
+	// fields which are not set are nil
+	value, err = sorter.Lookup(instanceType, ".GpuInfo.TotalGpuMemoryInMiB")
+	h.Ok(t, err)
+	h.Equals(t, nil, value)
+
+	_, err = sorter.Lookup(instanceType, "fdsafdsafdjskalfjlsf #@")
+	h.Nok(t, err)
+}
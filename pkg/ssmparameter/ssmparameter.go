@@ -0,0 +1,116 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ssmparameter stores lists of instance types in AWS Systems Manager Parameter Store as StringList parameters,
+// so that approved instance types can be distributed to automation which reads them from Parameter Store.
+package ssmparameter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// maxNameLength is the maximum length of a parameter name including its path.
+const maxNameLength = 1011
+
+// description is the description of the parameters which are stored.
+const description = "EC2 instance types selected by ec2-instance-selector"
+
+// namePattern matches the characters parameter names may contain.
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+
+// PutParameterAPI is the subset of the SSM client used to store parameters.
+type PutParameterAPI interface {
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// Writer stores lists of instance types in a StringList parameter.
+type Writer struct {
+	Name   string
+	client PutParameterAPI
+}
+
+// New creates a Writer which stores instance types in the parameter with the passed in name.
+func New(client PutParameterAPI, name string) *Writer {
+	return &Writer{
+		Name:   name,
+		client: client,
+	}
+}
+
+// ValidateName returns an error if the name is not a valid parameter name (Example: /org/approved-instance-types).
+func ValidateName(name string) error {
+	if name == "" || len(name) > maxNameLength || !namePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a valid parameter name, names may only contain letters, numbers, and the symbols _.-/ (Example: /org/approved-instance-types)", name)
+	}
+	if strings.Contains(name, "/") && !strings.HasPrefix(name, "/") {
+		return fmt.Errorf("%q is not a valid parameter name, hierarchical names must begin with a / (Example: /org/approved-instance-types)", name)
+	}
+	return nil
+}
+
+// Value returns the StringList value of the instance types, which are comma separated in order without duplicates.
+func Value(instanceTypes []string) string {
+	unique := []string{}
+	for _, instanceType := range instanceTypes {
+		if !slices.Contains(unique, instanceType) {
+			unique = append(unique, instanceType)
+		}
+	}
+	return strings.Join(unique, ",")
+}
+
+// PutCommand returns the AWS CLI command which stores the instance types in the parameter like Put, for dry runs. It
+// returns the same error as Put when there are no instance types to store.
+func (w Writer) PutCommand(instanceTypes []string) (string, error) {
+	if err := w.validateValue(instanceTypes); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("aws ssm put-parameter --name %s --type %s --tier %s --overwrite --description %q --value %s",
+		w.Name, ssmtypes.ParameterTypeStringList, ssmtypes.ParameterTierIntelligentTiering, description, Value(instanceTypes)), nil
+}
+
+// validateValue returns an error if there are no instance types to store, since StringList parameters can't be empty.
+func (w Writer) validateValue(instanceTypes []string) error {
+	if len(instanceTypes) == 0 {
+		return fmt.Errorf("unable to store SSM parameter %s: there are no instance types to store", w.Name)
+	}
+	return nil
+}
+
+// Put stores the instance types in the parameter, overwriting its previous value, and returns the version of the
+// parameter. The intelligent tier is used so that lists which are too long for a standard parameter are stored as an
+// advanced parameter.
+func (w Writer) Put(ctx context.Context, instanceTypes []string) (int64, error) {
+	if err := w.validateValue(instanceTypes); err != nil {
+		return 0, err
+	}
+	output, err := w.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:        aws.String(w.Name),
+		Value:       aws.String(Value(instanceTypes)),
+		Type:        ssmtypes.ParameterTypeStringList,
+		Tier:        ssmtypes.ParameterTierIntelligentTiering,
+		Overwrite:   aws.Bool(true),
+		Description: aws.String(description),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to store SSM parameter %s: %w", w.Name, err)
+	}
+	return output.Version, nil
+}
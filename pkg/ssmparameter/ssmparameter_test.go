@@ -0,0 +1,91 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssmparameter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ssmparameter"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+)
+
+// Mocks
+
+type mockedSSM struct {
+	putParameterInputs []*ssm.PutParameterInput
+	putParameterErr    error
+}
+
+func (m *mockedSSM) PutParameter(_ context.Context, params *ssm.PutParameterInput, _ ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	m.putParameterInputs = append(m.putParameterInputs, params)
+	return &ssm.PutParameterOutput{Version: 3}, m.putParameterErr
+}
+
+// Tests
+
+func TestValidateName(t *testing.T) {
+	h.Ok(t, ssmparameter.ValidateName("/org/approved-instance-types"))
+	h.Ok(t, ssmparameter.ValidateName("approved_instance.types"))
+	h.Nok(t, ssmparameter.ValidateName(""))
+	h.Nok(t, ssmparameter.ValidateName("/org/approved instance types"))
+	h.Nok(t, ssmparameter.ValidateName("org/approved-instance-types"))
+	h.Nok(t, ssmparameter.ValidateName("/"+strings.Repeat("a", 1011)))
+}
+
+func TestValue(t *testing.T) {
+	h.Equals(t, "m5.large,c5.large", ssmparameter.Value([]string{"m5.large", "c5.large", "m5.large"}))
+	h.Equals(t, "", ssmparameter.Value(nil))
+}
+
+func TestWriter_Put(t *testing.T) {
+	client := &mockedSSM{}
+	writer := ssmparameter.New(client, "/org/approved-instance-types")
+	version, err := writer.Put(context.Background(), []string{"m5.large", "c5.large"})
+	h.Ok(t, err)
+	h.Equals(t, int64(3), version)
+	h.Equals(t, 1, len(client.putParameterInputs))
+	input := client.putParameterInputs[0]
+	h.Equals(t, "/org/approved-instance-types", aws.ToString(input.Name))
+	h.Equals(t, "m5.large,c5.large", aws.ToString(input.Value))
+	h.Equals(t, ssmtypes.ParameterTypeStringList, input.Type)
+	h.Equals(t, ssmtypes.ParameterTierIntelligentTiering, input.Tier)
+	h.Assert(t, aws.ToBool(input.Overwrite), "Should overwrite the previous value")
+
+	_, err = writer.Put(context.Background(), nil)
+	h.Nok(t, err)
+	h.Equals(t, 1, len(client.putParameterInputs))
+
+	client.putParameterErr = errors.New("AccessDeniedException")
+	_, err = writer.Put(context.Background(), []string{"m5.large"})
+	h.Nok(t, err)
+	h.Assert(t, strings.Contains(err.Error(), "/org/approved-instance-types"), "The error should name the parameter, got %v", err)
+}
+
+func TestWriter_PutCommand(t *testing.T) {
+	writer := ssmparameter.New(&mockedSSM{}, "/org/approved-instance-types")
+	command, err := writer.PutCommand([]string{"m5.large", "c5.large"})
+	h.Ok(t, err)
+	h.Assert(t, strings.HasPrefix(command, "aws ssm put-parameter --name /org/approved-instance-types --type StringList"), "Unexpected command %s", command)
+	h.Assert(t, strings.HasSuffix(command, "--value m5.large,c5.large"), "Unexpected command %s", command)
+
+	// dry runs fail like Put when there are no instance types
+	_, err = writer.PutCommand(nil)
+	h.Nok(t, err)
+}
@@ -0,0 +1,114 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixtures_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/test/fixtures"
+)
+
+const mockFilesPath = "../../../test/static"
+
+func TestSanitize(t *testing.T) {
+	sanitized, err := fixtures.Sanitize([]byte(`{"NextToken":"abc","ResultMetadata":{},"Owner":"arn:aws:iam::987654321098:root","Empty":null,"Count":1}`))
+	h.Ok(t, err)
+	h.Equals(t, "{\n    \"Count\": 1,\n    \"Owner\": \"arn:aws:iam::123456789012:root\"\n}", string(sanitized))
+
+	_, err = fixtures.Sanitize([]byte(`{`))
+	h.Nok(t, err)
+}
+
+func TestFileName(t *testing.T) {
+	h.Equals(t, "m5_large.json", fixtures.FileName("m5.large"))
+}
+
+func TestReplayEC2(t *testing.T) {
+	replayEC2, err := fixtures.NewReplayEC2(mockFilesPath, "t3_micro_and_p3_16xl.json")
+	h.Ok(t, err)
+	ctx := context.Background()
+	output, err := replayEC2.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{InstanceTypes: []ec2types.InstanceType{ec2types.InstanceTypeT3Micro}})
+	h.Ok(t, err)
+	h.Assert(t, len(output.InstanceTypes) == 1, "should only replay the requested instance type")
+	h.Equals(t, ec2types.InstanceTypeT3Micro, output.InstanceTypes[0].InstanceType)
+
+	details, err := instancetypes.NewProvider("us-east-1", replayEC2).Get(ctx, nil)
+	h.Ok(t, err)
+	h.Assert(t, len(details) == 2, "should replay all recorded instance types")
+
+	_, err = fixtures.NewReplayEC2(mockFilesPath, "does_not_exist.json")
+	h.Nok(t, err)
+}
+
+func TestReplayPricing(t *testing.T) {
+	replayPricing, err := fixtures.NewReplayPricing(mockFilesPath, "m5_large.json")
+	h.Ok(t, err)
+	ctx := context.Background()
+	odPricing, err := ec2pricing.LoadODCacheOrNew(ctx, replayPricing, "us-east-1", 0, "")
+	h.Ok(t, err)
+	price, err := odPricing.Get(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
+
+func TestRecorder(t *testing.T) {
+	replayEC2, err := fixtures.NewReplayEC2(mockFilesPath, "t3_micro_and_p3_16xl.json")
+	h.Ok(t, err)
+	replayPricing, err := fixtures.NewReplayPricing(mockFilesPath, "m5_large.json")
+	h.Ok(t, err)
+
+	dir := t.TempDir()
+	recorder := fixtures.NewRecorder(dir)
+	ctx := context.Background()
+	_, err = instancetypes.NewProvider("us-east-1", recorder.EC2(replayEC2)).Get(ctx, []ec2types.InstanceType{ec2types.InstanceTypeT3Micro})
+	h.Ok(t, err)
+	_, err = recorder.Pricing(replayPricing).GetProducts(ctx, &pricing.GetProductsInput{
+		Filters: []pricingtypes.Filter{{Type: pricingtypes.FilterTypeTermMatch, Field: aws.String("instanceType"), Value: aws.String("m5.large")}},
+	})
+	h.Ok(t, err)
+
+	savedFiles, err := recorder.Save("t3_micro")
+	h.Ok(t, err)
+	h.Equals(t, []string{
+		filepath.Join(dir, fixtures.DescribeInstanceTypes, "t3_micro.json"),
+		filepath.Join(dir, fixtures.GetProducts, "m5_large.json"),
+	}, savedFiles)
+
+	recordedEC2, err := fixtures.NewReplayEC2(dir, "t3_micro.json")
+	h.Ok(t, err)
+	h.Assert(t, len(recordedEC2.Output.InstanceTypes) == 1, "should record only the requested instance type")
+	recordedEC2File, err := os.ReadFile(savedFiles[0])
+	h.Ok(t, err)
+	h.Assert(t, !strings.Contains(string(recordedEC2File), "null"), "should remove null fields from recorded fixtures")
+
+	recordedPricing, err := fixtures.NewReplayPricing(dir, "m5_large.json")
+	h.Ok(t, err)
+	odPricing, err := ec2pricing.LoadODCacheOrNew(ctx, recordedPricing, "us-east-1", 0, "")
+	h.Ok(t, err)
+	price, err := odPricing.Get(ctx, ec2types.InstanceTypeM5Large)
+	h.Ok(t, err)
+	h.Equals(t, float64(0.096), price)
+}
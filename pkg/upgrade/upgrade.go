@@ -0,0 +1,137 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upgrade checks the GitHub releases of the CLI for a newer version.
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// DefaultReleasesURL is the GitHub API URL of the latest release of the CLI.
+	DefaultReleasesURL = "https://api.github.com/repos/aws/amazon-ec2-instance-selector/releases/latest"
+	// DefaultTimeout is short so that the check fails fast when there is no network access.
+	DefaultTimeout = 5 * time.Second
+)
+
+var (
+	// ErrUnversionedBuild is returned when the current version is not a semantic version, such as for development builds.
+	ErrUnversionedBuild = errors.New("unversioned build")
+	// ErrUnknownLatestVersion is returned when the tag of the latest release is not a semantic version.
+	ErrUnknownLatestVersion = errors.New("unknown latest version")
+)
+
+// Release is a GitHub release.
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Highlights returns up to max of the bullet points of the release notes without their bullets.
+func (r Release) Highlights(max int) []string {
+	highlights := []string{}
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimSpace(line)
+		for _, bullet := range []string{"- ", "* "} {
+			if strings.HasPrefix(line, bullet) {
+				highlights = append(highlights, strings.TrimSpace(strings.TrimPrefix(line, bullet)))
+				break
+			}
+		}
+		if len(highlights) == max {
+			break
+		}
+	}
+	return highlights
+}
+
+// Result is the outcome of checking for a newer release.
+type Result struct {
+	CurrentVersion string
+	Latest         Release
+	// UpgradeAvailable is true when the latest release is newer than the current version
+	UpgradeAvailable bool
+}
+
+// Checker retrieves the latest release from the GitHub releases API.
+type Checker struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewChecker creates a Checker of the CLI's releases which gives up after DefaultTimeout.
+func NewChecker() *Checker {
+	return &Checker{
+		URL:    DefaultReleasesURL,
+		Client: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Latest returns the latest release.
+func (c Checker) Latest(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("unable to create the releases request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("unable to retrieve the latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Release{}, fmt.Errorf("retrieving the latest release failed with status %s", resp.Status)
+	}
+	release := Release{}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("unable to parse the latest release: %w", err)
+	}
+	return release, nil
+}
+
+// Check returns the latest release and whether it is newer than currentVersion. Development builds which are not
+// versioned return an ErrUnversionedBuild error along with the latest release, and latest releases whose tag is not a
+// version return an ErrUnknownLatestVersion error along with the latest release.
+func (c Checker) Check(ctx context.Context, currentVersion string) (Result, error) {
+	release, err := c.Latest(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{CurrentVersion: currentVersion, Latest: release}
+	result.UpgradeAvailable, err = IsNewer(currentVersion, release.TagName)
+	return result, err
+}
+
+// IsNewer returns true if the latest version is newer than the current version, both may be prefixed with a v.
+func IsNewer(currentVersion string, latestVersion string) (bool, error) {
+	current, err := semver.ParseTolerant(currentVersion)
+	if err != nil {
+		return false, fmt.Errorf("unable to compare the current version %s: %w: %w", currentVersion, ErrUnversionedBuild, err)
+	}
+	latest, err := semver.ParseTolerant(latestVersion)
+	if err != nil {
+		return false, fmt.Errorf("unable to compare the latest version %s: %w: %w", latestVersion, ErrUnknownLatestVersion, err)
+	}
+	return latest.GT(current), nil
+}
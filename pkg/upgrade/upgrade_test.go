@@ -0,0 +1,86 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	h "github.com/aws/amazon-ec2-instance-selector/v3/pkg/test"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/upgrade"
+)
+
+const latestRelease = `{
+	"tag_name": "v3.2.0",
+	"name": "v3.2.0",
+	"html_url": "https://github.com/aws/amazon-ec2-instance-selector/releases/tag/v3.2.0",
+	"body": "## Changes\r\n- Add the c8g instance family\r\n* Fix sorting by spot price\r\n",
+	"published_at": "2026-09-01T00:00:00Z"
+}`
+
+// Tests
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Equals(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(latestRelease))
+	}))
+	defer server.Close()
+	checker := upgrade.NewChecker()
+	checker.URL = server.URL
+
+	result, err := checker.Check(context.Background(), "v3.1.0")
+	h.Ok(t, err)
+	h.Assert(t, result.UpgradeAvailable, "v3.2.0 should be newer than v3.1.0")
+	h.Equals(t, []string{"Add the c8g instance family", "Fix sorting by spot price"}, result.Latest.Highlights(10))
+	h.Equals(t, []string{"Add the c8g instance family"}, result.Latest.Highlights(1))
+
+	result, err = checker.Check(context.Background(), "3.2.0")
+	h.Ok(t, err)
+	h.Assert(t, !result.UpgradeAvailable, "v3.2.0 should not be newer than itself")
+
+	result, err = checker.Check(context.Background(), "dev")
+	h.Assert(t, errors.Is(err, upgrade.ErrUnversionedBuild), fmt.Sprintf("Should not compare unversioned builds, got %v", err))
+	h.Equals(t, "v3.2.0", result.Latest.TagName)
+}
+
+func TestIsNewer(t *testing.T) {
+	_, err := upgrade.IsNewer("v3.1.0", "nightly")
+	h.Assert(t, errors.Is(err, upgrade.ErrUnknownLatestVersion), fmt.Sprintf("Should not compare latest tags which are not versions, got %v", err))
+	_, err = upgrade.IsNewer("dev", "v3.2.0")
+	h.Assert(t, errors.Is(err, upgrade.ErrUnversionedBuild), fmt.Sprintf("Should not compare unversioned builds, got %v", err))
+}
+
+func TestCheck_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	checker := upgrade.NewChecker()
+	checker.URL = server.URL
+	_, err := checker.Check(context.Background(), "v3.1.0")
+	h.Nok(t, err)
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slowServer.Close()
+	checker = &upgrade.Checker{URL: slowServer.URL, Client: &http.Client{Timeout: 10 * time.Millisecond}}
+	_, err = checker.Check(context.Background(), "v3.1.0")
+	h.Nok(t, err)
+}
@@ -0,0 +1,115 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/ec2pricing"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/instancetypes"
+	"github.com/aws/amazon-ec2-instance-selector/v3/pkg/test/fixtures"
+)
+
+// main records real DescribeInstanceTypes and GetProducts responses into sanitized static test fixtures.
+// Without --record, the existing fixtures are replayed through the same code paths to check they still parse.
+// Recording requires AWS credentials and is intended for maintainers refreshing fixtures that drift from real API shapes.
+func main() {
+	record := flag.Bool("record", false, "Record fixtures from real AWS API responses (requires AWS credentials)")
+	region := flag.String("region", "us-east-1", "AWS Region to record fixtures from")
+	profile := flag.String("profile", "", "AWS CLI profile to use for credentials and config")
+	instanceTypesFlag := flag.String("instance-types", "", "Comma separated list of instance types to record (Example: m5.large,t3.micro)")
+	name := flag.String("name", "", "DescribeInstanceTypes fixture name (defaults to the instance types joined with _and_)")
+	staticDir := flag.String("static-dir", "test/static", "Static test fixtures directory")
+	flag.Parse()
+
+	if *instanceTypesFlag == "" {
+		log.Fatalf("--instance-types is required")
+	}
+	instanceTypes := []ec2types.InstanceType{}
+	pricingFiles := []string{}
+	fileNames := []string{}
+	for _, instanceType := range strings.Split(*instanceTypesFlag, ",") {
+		instanceType = strings.TrimSpace(instanceType)
+		instanceTypes = append(instanceTypes, ec2types.InstanceType(instanceType))
+		pricingFiles = append(pricingFiles, fixtures.FileName(instanceType))
+		fileNames = append(fileNames, strings.TrimSuffix(fixtures.FileName(instanceType), ".json"))
+	}
+	if *name == "" {
+		*name = strings.Join(fileNames, "_and_")
+	}
+
+	ctx := context.Background()
+	var ec2Client ec2.DescribeInstanceTypesAPIClient
+	var pricingClient pricing.GetProductsAPIClient
+	recorder := fixtures.NewRecorder(*staticDir)
+	if *record {
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(*profile), config.WithRegion(*region))
+		if err != nil {
+			log.Fatalf("Failed to load default AWS configuration: %v", err)
+		}
+		ec2Client = recorder.EC2(ec2.NewFromConfig(cfg))
+		// pricing only has endpoints in us-east-1 and ap-south-1
+		pricingClient = recorder.Pricing(pricing.NewFromConfig(cfg, func(o *pricing.Options) { o.Region = "us-east-1" }))
+	} else {
+		replayEC2, err := fixtures.NewReplayEC2(*staticDir, *name+".json")
+		if err != nil {
+			log.Fatalf("Unable to replay fixtures: %v", err)
+		}
+		replayPricing, err := fixtures.NewReplayPricing(*staticDir, pricingFiles...)
+		if err != nil {
+			log.Fatalf("Unable to replay fixtures: %v", err)
+		}
+		ec2Client = replayEC2
+		pricingClient = replayPricing
+	}
+
+	instanceTypesDetails, err := instancetypes.NewProvider(*region, ec2Client).Get(ctx, instanceTypes)
+	if err != nil {
+		log.Fatalf("Unable to retrieve instance types: %v", err)
+	}
+	odPricing, err := ec2pricing.LoadODCacheOrNew(ctx, pricingClient, *region, 0, "")
+	if err != nil {
+		log.Fatalf("Unable to initialize on-demand pricing: %v", err)
+	}
+	for _, instanceType := range instanceTypes {
+		price, err := odPricing.Get(ctx, instanceType)
+		if err != nil {
+			log.Fatalf("Unable to retrieve on-demand price of %s: %v", instanceType, err)
+		}
+		fmt.Printf("%s: $%v/hr\n", instanceType, price)
+	}
+	if len(instanceTypesDetails) != len(instanceTypes) {
+		log.Fatalf("Expected %d instance types but retrieved %d", len(instanceTypes), len(instanceTypesDetails))
+	}
+
+	if !*record {
+		fmt.Println("✅ Fixtures replayed successfully")
+		return
+	}
+	savedFiles, err := recorder.Save(*name)
+	if err != nil {
+		log.Fatalf("Unable to save fixtures: %v", err)
+	}
+	for _, file := range savedFiles {
+		fmt.Printf("Recorded %s\n", file)
+	}
+}
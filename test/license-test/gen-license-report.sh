@@ -8,6 +8,6 @@ GOBIN=$(go env GOPATH | sed 's+:+/bin+g')/bin
 export PATH="$PATH:$GOBIN"
 
 go install github.com/mitchellh/golicense@v0.2.0
-go build -o $BUILD_DIR/aeis $SCRIPTPATH/../../.
+go -C $SCRIPTPATH/../../cmd build -o $BUILD_DIR/aeis .
 golicense -out-xlsx=$BUILD_DIR/report.xlsx $SCRIPTPATH/license-config.hcl $BUILD_DIR/aeis
 